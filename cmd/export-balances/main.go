@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	NITRO_ENDPOINT = "nitroendpoint"
+	CHANNEL_ID     = "channelid"
+	FROM           = "from"
+	TO             = "to"
+	INTERVAL       = "interval"
+	FORMAT         = "format"
+	OUTPUT         = "output"
+)
+
+func main() {
+	app := &cli.App{
+		Name:  "export-balances",
+		Usage: "Exports the balance history of a Nitro node as CSV or JSON. By default, the total balance of each asset is exported at every interval. If a channel id is supplied, every balance snapshot of that channel is exported instead.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    NITRO_ENDPOINT,
+				Usage:   "Specifies the endpoint of the Nitro RPC server to connect to. This should be in the form 'host:port/api/v1'",
+				Value:   "localhost:4005/api/v1",
+				Aliases: []string{"n"},
+			},
+			&cli.StringFlag{
+				Name:    CHANNEL_ID,
+				Usage:   "Specifies the channel to export the balance snapshots of. If not specified, balances are aggregated per asset.",
+				Aliases: []string{"c"},
+			},
+			&cli.TimestampFlag{
+				Name:     FROM,
+				Usage:    "Specifies the start of the exported period, as an RFC3339 timestamp. Defaults to 24 hours ago.",
+				Layout:   time.RFC3339,
+				Timezone: time.UTC,
+			},
+			&cli.TimestampFlag{
+				Name:     TO,
+				Usage:    "Specifies the end of the exported period, as an RFC3339 timestamp. Defaults to now.",
+				Layout:   time.RFC3339,
+				Timezone: time.UTC,
+			},
+			&cli.DurationFlag{
+				Name:  INTERVAL,
+				Usage: "Specifies the interval between aggregated asset balances (e.g. 24h). Ignored if a channel id is supplied.",
+				Value: 24 * time.Hour,
+			},
+			&cli.StringFlag{
+				Name:    FORMAT,
+				Usage:   "Specifies the output format: csv or json",
+				Value:   "csv",
+				Aliases: []string{"f"},
+			},
+			&cli.StringFlag{
+				Name:    OUTPUT,
+				Usage:   "Specifies the file to write to. If not specified, the export is written to stdout.",
+				Aliases: []string{"o"},
+			},
+		},
+		Action: func(c *cli.Context) error {
+			to := time.Now()
+			if c.Timestamp(TO) != nil {
+				to = *c.Timestamp(TO)
+			}
+			from := to.Add(-24 * time.Hour)
+			if c.Timestamp(FROM) != nil {
+				from = *c.Timestamp(FROM)
+			}
+
+			format := c.String(FORMAT)
+			if format != "csv" && format != "json" {
+				return fmt.Errorf("unsupported format %q", format)
+			}
+
+			client, err := rpc.NewHttpRpcClient(c.String(NITRO_ENDPOINT))
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			var out io.Writer = os.Stdout
+			if c.String(OUTPUT) != "" {
+				f, err := os.Create(c.String(OUTPUT))
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+
+			if c.String(CHANNEL_ID) != "" {
+				snapshots, err := client.GetBalanceHistory(types.Destination(common.HexToHash(c.String(CHANNEL_ID))), from, to)
+				if err != nil {
+					return err
+				}
+				if format == "json" {
+					return writeJSON(out, snapshots)
+				}
+				return writeSnapshotsCSV(out, snapshots)
+			}
+
+			balances, err := client.GetAssetBalanceHistory(from, to, c.Duration(INTERVAL))
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				return writeJSON(out, balances)
+			}
+			return writeAssetBalancesCSV(out, balances)
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeSnapshotsCSV(w io.Writer, snapshots []query.BalanceSnapshot) error {
+	records := [][]string{{"timestamp", "channel_id", "kind", "status", "asset", "my_balance", "their_balance"}}
+	for _, s := range snapshots {
+		records = append(records, []string{
+			s.Timestamp.UTC().Format(time.RFC3339Nano),
+			s.ChannelId.String(),
+			s.Kind,
+			string(s.Status),
+			s.AssetAddress.String(),
+			s.MyBalance.ToInt().String(),
+			s.TheirBalance.ToInt().String(),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeAssetBalancesCSV(w io.Writer, balances []query.AssetBalance) error {
+	records := [][]string{{"timestamp", "asset", "my_balance", "their_balance", "channels"}}
+	for _, b := range balances {
+		records = append(records, []string{
+			b.Timestamp.UTC().Format(time.RFC3339Nano),
+			b.AssetAddress.String(),
+			b.MyBalance.ToInt().String(),
+			b.TheirBalance.ToInt().String(),
+			strconv.FormatUint(uint64(b.Channels), 10),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
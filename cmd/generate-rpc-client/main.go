@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	DOCUMENT   = "document"
+	TYPESCRIPT = "typescript"
+)
+
+func main() {
+	app := &cli.App{
+		Name:  "generate-rpc-client",
+		Usage: "Generates the OpenRPC document of the Nitro RPC server, and a typed TypeScript client of the api it describes.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  DOCUMENT,
+				Usage: "Specifies the file to write the OpenRPC document to, as JSON.",
+			},
+			&cli.StringFlag{
+				Name:  TYPESCRIPT,
+				Usage: "Specifies the file to write the TypeScript client to.",
+			},
+		},
+		Action: func(cCtx *cli.Context) error {
+			document, err := rpc.OpenRpcDocument()
+			if err != nil {
+				return err
+			}
+
+			if path := cCtx.String(DOCUMENT); path != "" {
+				data, err := json.MarshalIndent(document, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := writeFile(path, append(data, '\n')); err != nil {
+					return err
+				}
+			}
+
+			if path := cCtx.String(TYPESCRIPT); path != "" {
+				data, err := openrpc.TypeScript(document, "go-nitro/cmd/generate-rpc-client")
+				if err != nil {
+					return err
+				}
+				if err := writeFile(path, data); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -16,6 +16,17 @@ const (
 	PROXY_ADDRESS   = "proxyaddress"
 	DESTINATION_URL = "destinationurl"
 	COST_PER_BYTE   = "costperbyte"
+	PRICING_FILE    = "pricingfilepath"
+	METERING        = "metering"
+	STREAM_BILLING  = "streambilling"
+	PAYMENT_TIMEOUT = "paymenttimeout"
+	UPSTREAMS_FILE  = "upstreamsfilepath"
+	HEALTH_INTERVAL = "healthcheckinterval"
+	LOCAL_VERIFY    = "localverification"
+	BATCH_INTERVAL  = "voucherbatchinterval"
+	USAGE_TOKEN     = "usagetoken"
+	USAGE_REPORT    = "usagereportpath"
+	REPORT_INTERVAL = "usagereportinterval"
 
 	TLS_CERT_FILEPATH = "tlscertfilepath"
 	TLS_KEY_FILEPATH  = "tlskeyfilepath"
@@ -51,6 +62,62 @@ func main() {
 				Value:   1,
 				Aliases: []string{"c"},
 			},
+			&cli.StringFlag{
+				Name:  PRICING_FILE,
+				Usage: "Filepath to a TOML file of per-request fees, per-route prices, size tiers and per-second streaming prices. If specified, it replaces the cost per byte.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  UPSTREAMS_FILE,
+				Usage: "Filepath to a TOML file of upstream services that requests are routed to by host and path, each with its own replicas, pricing and Nitro endpoint. Requests that match none of them are forwarded to the destination URL, if it is specified.",
+				Value: "",
+			},
+			&cli.DurationFlag{
+				Name:  HEALTH_INTERVAL,
+				Usage: "Specifies how often the replicas of upstreams with a health path are checked.",
+				Value: paymentproxy.DEFAULT_HEALTH_CHECK_INTERVAL,
+			},
+			&cli.BoolFlag{
+				Name:  LOCAL_VERIFY,
+				Usage: "Specifies whether the proxy verifies vouchers itself, forwarding them to the Nitro node in batches, rather than waiting for the node to receive each voucher. The proxy must be the only receiver of vouchers on the node's channels.",
+				Value: false,
+			},
+			&cli.DurationFlag{
+				Name:  BATCH_INTERVAL,
+				Usage: "Specifies how often vouchers verified by the proxy are forwarded to the Nitro node.",
+				Value: paymentproxy.DEFAULT_VOUCHER_BATCH_INTERVAL,
+			},
+			&cli.BoolFlag{
+				Name:  METERING,
+				Usage: "Specifies whether requests are paid for from credit bought ahead of them. Vouchers are credited to their channel, either on the " + paymentproxy.CREDIT_PATH + " endpoint or along with a request, and any overpayment carries over to later requests.",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  STREAM_BILLING,
+				Usage: "Specifies whether metered responses are charged for as they are sent, rather than buffered and charged for upfront. A response is paused when the credit of its channel runs out, until the channel is credited again. Requires metering.",
+				Value: false,
+			},
+			&cli.DurationFlag{
+				Name:  PAYMENT_TIMEOUT,
+				Usage: "Specifies how long a paused response waits for the channel to be credited before it is ended.",
+				Value: paymentproxy.DEFAULT_PAYMENT_TIMEOUT,
+			},
+			&cli.StringFlag{
+				Name:    USAGE_TOKEN,
+				Usage:   "Enables the " + paymentproxy.USAGE_PATH + " endpoint, which reports the requests, bytes served, amounts charged and rejected payments of each channel to requests with the token as a bearer token.",
+				Value:   "",
+				EnvVars: []string{"NITRO_PROXY_USAGE_TOKEN"},
+			},
+			&cli.StringFlag{
+				Name:  USAGE_REPORT,
+				Usage: "Filepath that a report of the usage of each channel is written to periodically. It is written as CSV if the filepath has a .csv extension, and otherwise as JSON.",
+				Value: "",
+			},
+			&cli.DurationFlag{
+				Name:  REPORT_INTERVAL,
+				Usage: "Specifies how often the usage report is written.",
+				Value: paymentproxy.DEFAULT_USAGE_REPORT_INTERVAL,
+			},
 			&cli.StringFlag{
 				Name:  TLS_CERT_FILEPATH,
 				Usage: "Filepath to the TLS certificate. If not specified, TLS will not be used.",
@@ -68,14 +135,47 @@ func main() {
 
 			logging.SetupDefaultLogger(os.Stdout, slog.LevelDebug)
 
-			proxy = paymentproxy.NewPaymentProxy(
-				proxyEndpoint,
-				nitroEndpoint,
-				c.String(DESTINATION_URL),
-				c.Uint64(COST_PER_BYTE),
-				c.String(TLS_CERT_FILEPATH),
-				c.String(TLS_KEY_FILEPATH),
-			)
+			pricing := paymentproxy.Pricing{Default: paymentproxy.Price{PerByte: c.Uint64(COST_PER_BYTE)}}
+			if pricingFile := c.String(PRICING_FILE); pricingFile != "" {
+				var err error
+				pricing, err = paymentproxy.LoadPricing(pricingFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			destinationUrl := c.String(DESTINATION_URL)
+			var upstreams []paymentproxy.Upstream
+			if upstreamsFile := c.String(UPSTREAMS_FILE); upstreamsFile != "" {
+				var err error
+				upstreams, err = paymentproxy.LoadUpstreams(upstreamsFile)
+				if err != nil {
+					return err
+				}
+				// With upstreams, requests are only forwarded to the destination URL if it is specified
+				if !c.IsSet(DESTINATION_URL) {
+					destinationUrl = ""
+				}
+			}
+
+			proxy = paymentproxy.NewPaymentProxyWithConfig(paymentproxy.Config{
+				ProxyAddress:         proxyEndpoint,
+				NitroEndpoint:        nitroEndpoint,
+				DestinationUrl:       destinationUrl,
+				Pricing:              pricing,
+				Upstreams:            upstreams,
+				HealthCheckInterval:  c.Duration(HEALTH_INTERVAL),
+				LocalVerification:    c.Bool(LOCAL_VERIFY),
+				VoucherBatchInterval: c.Duration(BATCH_INTERVAL),
+				UsageToken:           c.String(USAGE_TOKEN),
+				UsageReportPath:      c.String(USAGE_REPORT),
+				UsageReportInterval:  c.Duration(REPORT_INTERVAL),
+				Metering:             c.Bool(METERING),
+				StreamBilling:        c.Bool(STREAM_BILLING),
+				PaymentTimeout:       c.Duration(PAYMENT_TIMEOUT),
+				CertFilePath:         c.String(TLS_CERT_FILEPATH),
+				CertKeyPath:          c.String(TLS_KEY_FILEPATH),
+			})
 
 			return proxy.Start()
 		},
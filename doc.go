@@ -30,8 +30,14 @@
 //	      Specifies the private key used by the node. Default is Alice's private key. (default "2d999770f7b5d49b694080f987b82bbc9fc9ac2b4dcc10b0f8aba7d700f69c6d")
 //	-rpcport int
 //	      Specifies the tcp port for the rpc server. (default 4005)
+//	-rpcsocketmode string
+//	      Specifies the octal file mode of the rpc socket. Only users that can write to the socket can connect. (default "0600")
+//	-rpcsocketpath string
+//	      Specifies the path of a unix domain socket for the rpc server to listen on instead of a tcp port. Access is controlled by the permissions of the socket file.
 //	-usedurablestore
 //	      Specifies whether to use a durable store or an in-memory store.
+//	-usegrpc
+//	      Specifies whether to use gRPC rather than http/ws for the rpc server.
 //	-usenats
 //	      Specifies whether to use NATS or http/ws for the rpc server.
 //
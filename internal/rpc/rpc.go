@@ -2,36 +2,105 @@ package rpc
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 
+	"github.com/BurntSushi/toml"
 	"github.com/statechannels/go-nitro/node"
 	"github.com/statechannels/go-nitro/rpc"
 	"github.com/statechannels/go-nitro/rpc/transport"
+	grpcTransport "github.com/statechannels/go-nitro/rpc/transport/grpc"
 	httpTransport "github.com/statechannels/go-nitro/rpc/transport/http"
 	"github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/rpc/transport/unix"
 )
 
-func InitializeRpcServer(node *node.Node, rpcPort int, useNats bool, cert *tls.Certificate) (*rpc.RpcServer, error) {
-	var transport transport.Responder
+// InitializeRpcServer starts an rpc server for the node, using the given transport
+func InitializeRpcServer(node *node.Node, rpcPort int, transportType transport.TransportType, cert *tls.Certificate, authConfig rpc.AuthConfig) (*rpc.RpcServer, error) {
+	var responder transport.Responder
 	var err error
 
-	if useNats {
+	clientAuth := tls.NoClientCert
+	if authConfig.RequiresClientCerts() {
+		// Client certificates are matched against the registered fingerprints rather than a certificate authority
+		clientAuth = tls.RequestClientCert
+	}
+
+	switch transportType {
+	case transport.Nats:
+		if authConfig.RequiresClientCerts() {
+			return nil, errors.New("clients can only authenticate with a TLS certificate when using the http or grpc transport")
+		}
 		slog.Info("Initializing NATS RPC transport...")
-		transport, err = nats.NewNatsTransportAsServer(rpcPort)
-	} else {
+		responder, err = nats.NewNatsTransportAsServer(rpcPort)
+	case transport.Http:
 		slog.Info("Initializing Http RPC transport...")
-		transport, err = httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert)
+		responder, err = httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert, clientAuth)
+	case transport.Grpc:
+		slog.Info("Initializing gRPC RPC transport...")
+		responder, err = grpcTransport.NewGrpcTransportAsServer(fmt.Sprint(rpcPort), cert, clientAuth)
+	default:
+		return nil, fmt.Errorf("unknown rpc transport %q", transportType)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	rpcServer, err := rpc.NewRpcServer(node, transport)
+	return startRpcServer(node, responder, authConfig)
+}
+
+// InitializeUnixRpcServer starts an rpc server for the node that listens on a unix domain socket.
+// Only processes that can write to the socket file, as determined by mode, can connect.
+func InitializeUnixRpcServer(node *node.Node, socketPath string, mode fs.FileMode, authConfig rpc.AuthConfig) (*rpc.RpcServer, error) {
+	if authConfig.RequiresClientCerts() {
+		return nil, errors.New("clients can only authenticate with a TLS certificate when using the http or grpc transport")
+	}
+
+	slog.Info("Initializing unix socket RPC transport...", "path", socketPath)
+	responder, err := unix.NewUnixTransportAsServer(socketPath, mode)
 	if err != nil {
 		return nil, err
 	}
 
+	return startRpcServer(node, responder, authConfig)
+}
+
+func startRpcServer(node *node.Node, responder transport.Responder, authConfig rpc.AuthConfig) (*rpc.RpcServer, error) {
+	rpcServer, err := rpc.NewRpcServer(node, responder, authConfig)
+	if err != nil {
+		responder.Close()
+		return nil, err
+	}
+
 	slog.Info("Completed RPC server initialization")
 	return rpcServer, nil
 }
+
+// LoadRpcClients reads the clients that are allowed to request rpc auth tokens from a TOML file, e.g.
+//
+//	[[clients]]
+//	id = "wallet-ui"
+//	apikey = "..."
+//	permissions = ["read", "pay"]
+//
+//	[[clients.spendinglimits]]
+//	asset = "0x0000000000000000000000000000000000000000"
+//	amount = 1000000
+//	period = "24h"
+//
+//	[[clients]]
+//	id = "operator"
+//	certfingerprint = "..."
+//	permissions = ["admin"]
+func LoadRpcClients(path string) ([]rpc.ClientCredentials, error) {
+	var file struct {
+		Clients []rpc.ClientCredentials `toml:"clients"`
+	}
+	_, err := toml.DecodeFile(path, &file)
+	if err != nil {
+		return nil, fmt.Errorf("could not load rpc clients from %s: %w", path, err)
+	}
+	return file.Clients, nil
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"fmt"
+	"io/fs"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/internal/logging"
@@ -16,6 +22,11 @@ import (
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/metrics"
+	"github.com/statechannels/go-nitro/node/tracing"
+	"github.com/statechannels/go-nitro/node/webhook"
+	nitroRpc "github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
 )
@@ -27,6 +38,7 @@ func main() {
 		// Connectivity
 		CONNECTIVITY_CATEGORY = "Connectivity:"
 		USE_NATS              = "usenats"
+		USE_GRPC              = "usegrpc"
 		CHAIN_URL             = "chainurl"
 		CHAIN_START_BLOCK     = "chainstartblock"
 		CHAIN_AUTH_TOKEN      = "chainauthtoken"
@@ -36,6 +48,8 @@ func main() {
 		PUBLIC_IP             = "publicip"
 		MSG_PORT              = "msgport"
 		RPC_PORT              = "rpcport"
+		RPC_SOCKET_PATH       = "rpcsocketpath"
+		RPC_SOCKET_MODE       = "rpcsocketmode"
 		GUI_PORT              = "guiport"
 		BOOT_PEERS            = "bootpeers"
 
@@ -48,19 +62,56 @@ func main() {
 		STORAGE_CATEGORY     = "Storage:"
 		USE_DURABLE_STORE    = "usedurablestore"
 		DURABLE_STORE_FOLDER = "durablestorefolder"
+		RETENTION_PERIOD     = "retentionperiod"
+		EVENT_LOG_SIZE       = "eventlogsize"
 
 		// TLS
 		TLS_CATEGORY      = "TLS:"
 		TLS_CERT_FILEPATH = "tlscertfilepath"
 		TLS_KEY_FILEPATH  = "tlskeyfilepath"
+
+		// RPC authentication
+		RPC_AUTH_CATEGORY    = "RPC Authentication:"
+		RPC_AUTH_SECRET      = "rpcauthsecret"
+		RPC_TOKEN_EXPIRY     = "rpctokenexpiry"
+		RPC_CLIENTS_FILEPATH = "rpcclientsfilepath"
+		RPC_INSECURE         = "rpcinsecure"
+
+		// Webhooks
+		WEBHOOK_CATEGORY = "Webhooks:"
+		WEBHOOK_URLS     = "webhookurls"
+		WEBHOOK_SECRET   = "webhooksecret"
+		WEBHOOK_EVENTS   = "webhookevents"
+
+		// Metrics
+		METRICS_CATEGORY = "Metrics:"
+		METRICS_ADDRESS  = "metricsaddress"
+
+		// Tracing
+		TRACING_CATEGORY = "Tracing:"
+		OTLP_ENDPOINT    = "otlpendpoint"
 	)
 	var pkString, chainUrl, chainAuthToken, naAddress, vpaAddress, caAddress, chainPk, durableStoreFolder, bootPeers, publicIp string
 	var msgPort, rpcPort, guiPort int
 	var chainStartBlock uint64
-	var useNats, useDurableStore bool
+	var useNats, useGrpc, useDurableStore bool
+	var retentionPeriod time.Duration
+	var eventLogSize uint64
 
 	var tlsCertFilepath, tlsKeyFilepath string
 
+	var rpcSocketPath, rpcSocketMode string
+
+	var rpcAuthSecret, rpcClientsFilepath string
+	var rpcInsecure bool
+	var rpcTokenExpiry time.Duration
+
+	var webhookUrls, webhookSecret, webhookEvents string
+
+	var metricsAddress string
+
+	var otlpEndpoint string
+
 	// urfave default precedence for flag value sources (highest to lowest):
 	// 1. Command line flag value
 	// 2. Environment variable (if specified)
@@ -80,6 +131,13 @@ func main() {
 			Category:    CONNECTIVITY_CATEGORY,
 			Destination: &useNats,
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        USE_GRPC,
+			Usage:       "Specifies whether to use gRPC rather than http/ws for the rpc server.",
+			Value:       false,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &useGrpc,
+		}),
 		altsrc.NewBoolFlag(&cli.BoolFlag{
 			Name:        USE_DURABLE_STORE,
 			Usage:       "Specifies whether to use a durable store or an in-memory store.",
@@ -166,6 +224,19 @@ func main() {
 			Category:    CONNECTIVITY_CATEGORY,
 			Destination: &rpcPort,
 		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        RPC_SOCKET_PATH,
+			Usage:       "Specifies the path of a unix domain socket for the rpc server to listen on instead of a tcp port. Access is controlled by the permissions of the socket file.",
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &rpcSocketPath,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        RPC_SOCKET_MODE,
+			Usage:       "Specifies the octal file mode of the rpc socket. Only users that can write to the socket can connect.",
+			Value:       "0600",
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &rpcSocketMode,
+		}),
 		altsrc.NewIntFlag(&cli.IntFlag{
 			Name:        GUI_PORT,
 			Usage:       "Specifies the tcp port for the Nitro Connect GUI.",
@@ -180,6 +251,20 @@ func main() {
 			Destination: &durableStoreFolder,
 			Value:       "./data/nitro-store",
 		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        RETENTION_PERIOD,
+			Usage:       "Specifies how long completed objectives and finalized channels are kept in the store before being archived (e.g. 720h). A value of 0 disables archival.",
+			Value:       0,
+			Category:    STORAGE_CATEGORY,
+			Destination: &retentionPeriod,
+		}),
+		altsrc.NewUint64Flag(&cli.Uint64Flag{
+			Name:        EVENT_LOG_SIZE,
+			Usage:       "Specifies how many of the latest rpc notifications are kept in the store, for clients to replay the notifications they missed.",
+			Value:       store.DefaultEventLogSize,
+			Category:    STORAGE_CATEGORY,
+			Destination: &eventLogSize,
+		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        BOOT_PEERS,
 			Usage:       "Comma-delimited list of peer multiaddrs the messaging service will connect to when initialized.",
@@ -201,6 +286,64 @@ func main() {
 			Category:    TLS_CATEGORY,
 			Destination: &tlsKeyFilepath,
 		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        RPC_AUTH_SECRET,
+			Usage:       "Specifies the secret used to sign RPC auth tokens. If not specified, a random secret is generated at startup, so tokens do not survive a restart.",
+			Category:    RPC_AUTH_CATEGORY,
+			Destination: &rpcAuthSecret,
+			EnvVars:     []string{"NITRO_RPC_AUTH_SECRET"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        RPC_TOKEN_EXPIRY,
+			Usage:       "Specifies how long an RPC auth token is valid for.",
+			Value:       nitroRpc.DefaultTokenExpiry,
+			Category:    RPC_AUTH_CATEGORY,
+			Destination: &rpcTokenExpiry,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        RPC_CLIENTS_FILEPATH,
+			Usage:       "Filepath to a TOML file listing the clients allowed to request RPC auth tokens, with their api keys or TLS certificate fingerprints and permissions. If not specified, only clients connected over the RPC socket can request a token, unless " + RPC_INSECURE + " is set.",
+			Category:    RPC_AUTH_CATEGORY,
+			Destination: &rpcClientsFilepath,
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        RPC_INSECURE,
+			Usage:       "Allows any client to request an RPC auth token with every permission when no clients file is specified. Only use this for development, with an RPC server that is not reachable from other machines.",
+			Value:       false,
+			Category:    RPC_AUTH_CATEGORY,
+			Destination: &rpcInsecure,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        WEBHOOK_URLS,
+			Usage:       "Comma-delimited list of urls that node events are POSTed to. If not specified, no webhooks are called.",
+			Category:    WEBHOOK_CATEGORY,
+			Destination: &webhookUrls,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        WEBHOOK_SECRET,
+			Usage:       "Specifies the secret used to sign webhook deliveries. Required if webhook urls are specified.",
+			Category:    WEBHOOK_CATEGORY,
+			Destination: &webhookSecret,
+			EnvVars:     []string{"NITRO_WEBHOOK_SECRET"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        WEBHOOK_EVENTS,
+			Usage:       "Comma-delimited list of the event types delivered to webhooks. If not specified, every event is delivered.",
+			Category:    WEBHOOK_CATEGORY,
+			Destination: &webhookEvents,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        METRICS_ADDRESS,
+			Usage:       "Specifies the tcp address, in the form 'host:port', to serve Prometheus metrics on at " + metrics.METRICS_PATH + ". If not specified, metrics are not served.",
+			Category:    METRICS_CATEGORY,
+			Destination: &metricsAddress,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        OTLP_ENDPOINT,
+			Usage:       "Specifies the address, in the form 'host:port', of an OpenTelemetry collector that traces are exported to over OTLP/gRPC. If not specified, operations are not traced.",
+			Category:    TRACING_CATEGORY,
+			Destination: &otlpEndpoint,
+		}),
 	}
 	app := &cli.App{
 		Name:   "go-nitro",
@@ -222,6 +365,8 @@ func main() {
 				PkBytes:            common.Hex2Bytes(pkString),
 				UseDurableStore:    useDurableStore,
 				DurableStoreFolder: durableStoreFolder,
+				RetentionPeriod:    retentionPeriod,
+				EventLogSize:       eventLogSize,
 			}
 
 			var peerSlice []string
@@ -242,6 +387,38 @@ func main() {
 			if err != nil {
 				return err
 			}
+
+			var dispatcher *webhook.Dispatcher
+			if webhookUrls != "" {
+				config := webhook.Config{Urls: strings.Split(webhookUrls, ","), Secret: []byte(webhookSecret)}
+				if webhookEvents != "" {
+					for _, e := range strings.Split(webhookEvents, ",") {
+						config.Events = append(config.Events, webhook.EventType(e))
+					}
+				}
+				dispatcher, err = webhook.NewDispatcher(config)
+				if err != nil {
+					return err
+				}
+				node.AddEngineEventHandler(dispatcher.HandleEngineEvent)
+			}
+
+			var shutdownTracing func(context.Context) error
+			if otlpEndpoint != "" {
+				shutdownTracing, err = tracing.Setup(cCtx.Context, otlpEndpoint, node.Address.String())
+				if err != nil {
+					return err
+				}
+			}
+
+			var metricsServer *http.Server
+			if metricsAddress != "" {
+				metricsServer, err = metrics.Serve(metricsAddress)
+				if err != nil {
+					return err
+				}
+			}
+
 			var cert tls.Certificate
 
 			if tlsCertFilepath != "" && tlsKeyFilepath != "" {
@@ -251,18 +428,71 @@ func main() {
 				}
 			}
 
-			rpcServer, err := rpc.InitializeRpcServer(node, rpcPort, useNats, &cert)
-			if err != nil {
-				return err
+			authConfig := nitroRpc.AuthConfig{
+				SigningSecret: []byte(rpcAuthSecret),
+				TokenExpiry:   rpcTokenExpiry,
+				Insecure:      rpcInsecure,
+			}
+			if rpcClientsFilepath != "" {
+				authConfig.Clients, err = rpc.LoadRpcClients(rpcClientsFilepath)
+				if err != nil {
+					return err
+				}
 			}
 
-			hostNitroUI(uint(guiPort), uint(rpcPort))
+			var rpcServer *nitroRpc.RpcServer
+			if rpcSocketPath != "" {
+				if useNats || useGrpc {
+					return fmt.Errorf("%s cannot be combined with %s or %s", RPC_SOCKET_PATH, USE_NATS, USE_GRPC)
+				}
+				mode, err := strconv.ParseUint(rpcSocketMode, 8, 32)
+				if err != nil || mode > uint64(fs.ModePerm) {
+					return fmt.Errorf("invalid %s %q", RPC_SOCKET_MODE, rpcSocketMode)
+				}
+				rpcServer, err = rpc.InitializeUnixRpcServer(node, rpcSocketPath, fs.FileMode(mode), authConfig)
+				if err != nil {
+					return err
+				}
+			} else {
+				rpcTransport := transport.Http
+				switch {
+				case useNats && useGrpc:
+					return fmt.Errorf("only one of %s and %s can be set", USE_NATS, USE_GRPC)
+				case useNats:
+					rpcTransport = transport.Nats
+				case useGrpc:
+					rpcTransport = transport.Grpc
+				}
+
+				rpcServer, err = rpc.InitializeRpcServer(node, rpcPort, rpcTransport, &cert, authConfig)
+				if err != nil {
+					return err
+				}
+
+				// The GUI connects to the rpc server over its tcp port
+				hostNitroUI(uint(guiPort), uint(rpcPort))
+			}
 
 			stopChan := make(chan os.Signal, 2)
 			signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 			<-stopChan // wait for interrupt or terminate signal
 
-			return rpcServer.Close()
+			err = rpcServer.Close()
+			if dispatcher != nil {
+				dispatcher.Close()
+			}
+			if metricsServer != nil {
+				metricsServer.Close()
+			}
+			if shutdownTracing != nil {
+				// Export the spans that are yet to be exported
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := shutdownTracing(ctx); err != nil {
+					slog.Error("Could not export traces", "error", err)
+				}
+			}
+			return err
 		},
 	}
 
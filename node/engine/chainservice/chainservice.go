@@ -108,6 +108,11 @@ func (cr ChallengeRegisteredEvent) StateHash(fp state.FixedPart) (common.Hash, e
 	return state.StateFromFixedAndVariablePart(fp, cr.candidate).Hash()
 }
 
+// TurnNum returns the turn number of the challenged state
+func (cr ChallengeRegisteredEvent) TurnNum() uint64 {
+	return cr.candidate.TurnNum
+}
+
 // Outcome returns the outcome which will have been stored on chain in the adjudicator after the ChallengeRegistered Event fires.
 func (cr ChallengeRegisteredEvent) Outcome() outcome.Exit {
 	return cr.candidate.Outcome
@@ -20,7 +20,9 @@ import (
 	"github.com/statechannels/go-nitro/node/engine/messageservice"
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/metrics"
 	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/node/tracing"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
@@ -28,6 +30,8 @@ import (
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrUnhandledChainEvent is an engine error when the the engine cannot process a chain event
@@ -50,11 +54,18 @@ func (e *ErrGetObjective) Error() string {
 	return fmt.Sprintf("unexpected error getting/creating objective %s: %v", e.objectiveId, e.wrappedError)
 }
 
+// ErrPaymentFailed is returned when a payment requested through the API cannot be made, e.g. because the channel has insufficient funds
+var ErrPaymentFailed = errors.New("payment failed")
+
+// archiveInterval is how often the engine asks the store to archive completed objectives and downsample balance snapshots
+const archiveInterval = time.Hour
+
 // nonFatalErrors is a list of errors for which the engine should not panic
 var nonFatalErrors = []error{
 	&ErrGetObjective{},
 	store.ErrLoadVouchers,
 	directfund.ErrLedgerChannelExists,
+	ErrPaymentFailed,
 }
 
 // Engine is the imperative part of the core business logic of a go-nitro Node
@@ -62,7 +73,7 @@ type Engine struct {
 	// inbound go channels
 
 	// From API
-	ObjectiveRequestsFromAPI chan protocols.ObjectiveRequest
+	ObjectiveRequestsFromAPI chan ObjectiveRequest
 	PaymentRequestsFromAPI   chan PaymentRequest
 
 	fromChain    <-chan chainservice.Event
@@ -84,10 +95,21 @@ type Engine struct {
 	cancel context.CancelFunc
 }
 
+// ObjectiveRequest represents a request from the API to start an objective
+type ObjectiveRequest struct {
+	protocols.ObjectiveRequest
+	// Ctx is the context of the API call, whose trace the objective continues
+	Ctx context.Context
+}
+
 // PaymentRequest represents a request from the API to make a payment using a channel
 type PaymentRequest struct {
 	ChannelId types.Destination
 	Amount    *big.Int
+	// Ctx is the context of the API call, whose trace the payment continues
+	Ctx context.Context
+	// Result, if set, receives nil once the voucher has been sent, or the reason the payment failed. It must be buffered.
+	Result chan<- error
 }
 
 // EngineEvent is a struct that contains a list of changes caused by handling a message/chain event/api event
@@ -97,12 +119,14 @@ type EngineEvent struct {
 	// These are objectives that have failed
 	FailedObjectives []protocols.ObjectiveId
 	// ReceivedVouchers are vouchers we've received from other participants
-	ReceivedVouchers []payments.Voucher
+	ReceivedVouchers []payments.ReceivedVoucher
 
 	// LedgerChannelUpdates contains channel info for ledger channels that have been updated
 	LedgerChannelUpdates []query.LedgerChannelInfo
 	// PaymentChannelUpdates contains channel info for payment channels that have been updated
 	PaymentChannelUpdates []query.PaymentChannelInfo
+	// ChallengesRegistered are challenges registered on chain against channels we are a participant in
+	ChallengesRegistered []chainservice.ChallengeRegisteredEvent
 }
 
 // IsEmpty returns true if the EngineEvent contains no changes
@@ -111,7 +135,8 @@ func (ee *EngineEvent) IsEmpty() bool {
 		len(ee.FailedObjectives) == 0 &&
 		len(ee.ReceivedVouchers) == 0 &&
 		len(ee.LedgerChannelUpdates) == 0 &&
-		len(ee.PaymentChannelUpdates) == 0
+		len(ee.PaymentChannelUpdates) == 0 &&
+		len(ee.ChallengesRegistered) == 0
 }
 
 func (ee *EngineEvent) Merge(other EngineEvent) {
@@ -120,6 +145,7 @@ func (ee *EngineEvent) Merge(other EngineEvent) {
 	ee.ReceivedVouchers = append(ee.ReceivedVouchers, other.ReceivedVouchers...)
 	ee.LedgerChannelUpdates = append(ee.LedgerChannelUpdates, other.LedgerChannelUpdates...)
 	ee.PaymentChannelUpdates = append(ee.PaymentChannelUpdates, other.PaymentChannelUpdates...)
+	ee.ChallengesRegistered = append(ee.ChallengesRegistered, other.ChallengesRegistered...)
 }
 
 type CompletedObjectiveEvent struct {
@@ -137,7 +163,7 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 
 	e.fromLedger = make(chan consensus_channel.Proposal, 100)
 	// bind to inbound chans
-	e.ObjectiveRequestsFromAPI = make(chan protocols.ObjectiveRequest)
+	e.ObjectiveRequestsFromAPI = make(chan ObjectiveRequest)
 	e.PaymentRequestsFromAPI = make(chan PaymentRequest)
 
 	e.fromChain = chain.EventFeed()
@@ -179,29 +205,49 @@ func (e *Engine) Close() error {
 // run kicks of an infinite loop that waits for communications on the supplied channels, and handles them accordingly
 // The loop exits when the context is cancelled.
 func (e *Engine) run(ctx context.Context) {
+	archiveTicker := time.NewTicker(archiveInterval)
+	defer archiveTicker.Stop()
+
 	for {
 		var res EngineEvent
 		var err error
 
+		// The time at which the event was received, and the kind of event, for the crank latency metric
+		var start time.Time
+		var event string
+
 		blockTicker := time.NewTicker(15 * time.Second)
 
 		select {
 
 		case or := <-e.ObjectiveRequestsFromAPI:
+			start, event = time.Now(), "objective_request"
 			res, err = e.handleObjectiveRequest(or)
 		case pr := <-e.PaymentRequestsFromAPI:
+			start, event = time.Now(), "payment_request"
 			res, err = e.handlePaymentRequest(pr)
 		case chainEvent := <-e.fromChain:
+			start, event = time.Now(), "chain_event"
 			res, err = e.handleChainEvent(chainEvent)
 		case message := <-e.fromMsg:
+			start, event = time.Now(), "message"
 			res, err = e.handleMessage(message)
 		case proposal := <-e.fromLedger:
+			start, event = time.Now(), "proposal"
 			res, err = e.handleProposal(proposal)
 		case signReq := <-e.signRequests:
+			start, event = time.Now(), "sign_request"
 			err = e.handleSignRequest(signReq)
 		case <-blockTicker.C:
+			start, event = time.Now(), "block_tick"
 			blockNum := e.chain.GetLastConfirmedBlockNum()
 			err = e.store.SetLastBlockNumSeen(blockNum)
+		case <-archiveTicker.C:
+			start, event = time.Now(), "archive"
+			err = e.archiveCompleted()
+			if err == nil {
+				err = e.downsampleBalanceSnapshots()
+			}
 		case <-ctx.Done():
 			e.wg.Done()
 			return
@@ -215,19 +261,30 @@ func (e *Engine) run(ctx context.Context) {
 
 			for _, obj := range res.CompletedObjectives {
 				e.logger.Info("Objective is complete & returned to API", logging.WithObjectiveIdAttribute(obj.Id()))
+				metrics.RecordObjective(obj.Id(), obj.GetStatus())
+			}
+			for _, id := range res.FailedObjectives {
+				metrics.RecordFailedObjective(id)
 			}
 			e.eventHandler(res)
 		}
 
+		metrics.ObserveCrank(event, start)
 	}
 }
 
 // handleProposal handles a Proposal returned to the engine from
 // a running ledger channel by pulling its corresponding objective
 // from the store and attempting progress.
-func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (EngineEvent, error) {
+func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (ee EngineEvent, err error) {
 	id := getProposalObjectiveId(proposal)
+	ctx, span := e.startSpan(context.Background(), "engine.handleProposal", attribute.String("nitro.objective_id", string(id)))
+	defer func() { tracing.End(span, err) }()
 
+	if e.isArchived(id) {
+		e.logger.Info("Ignoring proposal for archived objective", logging.WithObjectiveIdAttribute(id))
+		return EngineEvent{}, nil
+	}
 	obj, err := e.store.GetObjectiveById(id)
 	if err != nil {
 		return EngineEvent{}, err
@@ -236,7 +293,7 @@ func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (EngineEven
 		e.logger.Info("Ignoring proposal for completed objective", logging.WithObjectiveIdAttribute(id))
 		return EngineEvent{}, nil
 	}
-	return e.attemptProgress(obj)
+	return e.attemptProgress(ctx, obj)
 }
 
 func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
@@ -262,12 +319,21 @@ func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
 //   - generates an updated objective,
 //   - attempts progress on the target Objective,
 //   - attempts progress on related objectives which may have become unblocked.
-func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
+//
+// The handling of the message continues the trace of the operation that sent it, if its metadata carries one.
+func (e *Engine) handleMessage(message protocols.Message) (ee EngineEvent, err error) {
 	e.logMessage(message, Incoming)
+	ctx, span := e.startSpan(tracing.Extract(context.Background(), message.Metadata), "engine.handleMessage", attribute.String("nitro.from", message.From.String()))
+	defer func() { tracing.End(span, err) }()
 	allCompleted := EngineEvent{}
 
 	for _, payload := range message.ObjectivePayloads {
 
+		if e.isArchived(payload.ObjectiveId) {
+			e.logger.Info("Ignoring payload for archived objective", logging.WithObjectiveIdAttribute(payload.ObjectiveId))
+			continue
+		}
+
 		objective, err := e.getOrCreateObjective(payload)
 		if err != nil {
 			return EngineEvent{}, err
@@ -277,6 +343,7 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			e.logger.Info("Policymaker for objective", "policy-maker", e.policymaker, logging.WithObjectiveIdAttribute(objective.Id()))
 			if e.policymaker.ShouldApprove(objective) {
 				objective = objective.Approve()
+				metrics.RecordObjective(objective.Id(), protocols.Approved)
 
 				ddfo, ok := objective.(*directdefund.Objective)
 				if ok {
@@ -295,7 +362,7 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 				allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
 
-				err = e.executeSideEffects(sideEffects)
+				err = e.executeSideEffects(ctx, sideEffects)
 				// An error would mean we failed to send a message. But the objective is still "completed".
 				// So, we should return allCompleted even if there was an error.
 				return allCompleted, err
@@ -317,7 +384,7 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			return EngineEvent{}, err
 		}
 
-		progressEvent, err := e.attemptProgress(updatedObjective)
+		progressEvent, err := e.attemptProgress(ctx, updatedObjective)
 		if err != nil {
 			return EngineEvent{}, err
 		}
@@ -334,6 +401,10 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		// Here we rely on the sender having packed them into the message in that order, and do not apply any checks or sorting of our own.
 		id := getProposalObjectiveId(entry.Proposal)
 
+		if e.isArchived(id) {
+			e.logger.Info("Ignoring proposal for archived objective", logging.WithObjectiveIdAttribute(id))
+			continue
+		}
 		o, err := e.store.GetObjectiveById(id)
 		if err != nil {
 			return EngineEvent{}, err
@@ -353,7 +424,7 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			return EngineEvent{}, err
 		}
 
-		progressEvent, err := e.attemptProgress(updatedObjective)
+		progressEvent, err := e.attemptProgress(ctx, updatedObjective)
 		if err != nil {
 			return EngineEvent{}, err
 		}
@@ -363,6 +434,10 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	}
 
 	for _, entry := range message.RejectedObjectives {
+		if e.isArchived(entry) {
+			e.logger.Info("Ignoring rejection for archived objective", logging.WithObjectiveIdAttribute(entry))
+			continue
+		}
 		objective, err := e.store.GetObjectiveById(entry)
 		if err != nil {
 			return EngineEvent{}, err
@@ -387,17 +462,21 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 	for _, voucher := range message.Payments {
 
-		// TODO: return the amount we paid?
-		_, _, err := e.vm.Receive(voucher)
-
-		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
+		total, delta, err := e.vm.Receive(voucher)
 		if err != nil {
 			return EngineEvent{}, fmt.Errorf("error accepting payment voucher: %w", err)
 		}
+		metrics.RecordVoucher(metrics.Received, delta)
 		c, ok := e.store.GetChannelById(voucher.ChannelId)
 		if !ok {
 			return EngineEvent{}, fmt.Errorf("could not fetch channel for voucher %+v", voucher)
 		}
+		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, payments.ReceivedVoucher{
+			ChannelId: voucher.ChannelId,
+			Payer:     payments.GetPayer(c.Participants),
+			Delta:     delta,
+			Total:     total,
+		})
 
 		// Vouchers only count as payment channel updates if the channel is open.
 		if !c.FinalCompleted() {
@@ -422,9 +501,17 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 //   - reads an objective from the store,
 //   - generates an updated objective, and
 //   - attempts progress.
-func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, error) {
+func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (ee EngineEvent, err error) {
 	e.logger.Info("Handling chain event", "blockNum", chainEvent.BlockNum(), "event", chainEvent)
-	err := e.store.SetLastBlockNumSeen(chainEvent.BlockNum())
+	metrics.RecordChainEvent(chainEvent)
+	ctx, span := e.startSpan(context.Background(), "engine.handleChainEvent",
+		attribute.String("nitro.event_type", fmt.Sprintf("%T", chainEvent)),
+		attribute.String("nitro.channel_id", chainEvent.ChannelID().String()),
+		attribute.Int64("nitro.block_num", int64(chainEvent.BlockNum())),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	err = e.store.SetLastBlockNumSeen(chainEvent.BlockNum())
 	if err != nil {
 		return EngineEvent{}, err
 	}
@@ -447,17 +534,27 @@ func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, e
 		return EngineEvent{}, err
 	}
 
+	event := EngineEvent{}
+	if challenge, isChallenge := chainEvent.(chainservice.ChallengeRegisteredEvent); isChallenge {
+		event.ChallengesRegistered = append(event.ChallengesRegistered, challenge)
+	}
+
 	objective, ok := e.store.GetObjectiveByChannelId(chainEvent.ChannelID())
 
 	if ok {
-		return e.attemptProgress(objective)
+		progress, err := e.attemptProgress(ctx, objective)
+		event.Merge(progress)
+		return event, err
 	}
-	return EngineEvent{}, nil
+	return event, nil
 }
 
 // handleObjectiveRequest handles an ObjectiveRequest (triggered by a client API call).
-// It will attempt to spawn a new, approved objective.
-func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEvent, error) {
+// It will attempt to spawn a new, approved objective, continuing the trace of the API call.
+func (e *Engine) handleObjectiveRequest(or ObjectiveRequest) (ee EngineEvent, err error) {
+	ctx, span := e.startSpan(or.Ctx, "engine.handleObjectiveRequest")
+	defer func() { tracing.End(span, err) }()
+
 	myAddress := *e.store.GetAddress()
 
 	chainId, err := e.chain.GetChainId()
@@ -466,10 +563,11 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 	}
 
 	objectiveId := or.Id(myAddress, chainId)
+	span.SetAttributes(attribute.String("nitro.objective_id", string(objectiveId)))
 	failedEngineEvent := EngineEvent{FailedObjectives: []protocols.ObjectiveId{objectiveId}}
 	e.logger.Info("handling new objective request", logging.WithObjectiveIdAttribute(objectiveId))
 	defer or.SignalObjectiveStarted()
-	switch request := or.(type) {
+	switch request := or.ObjectiveRequest.(type) {
 
 	case virtualfund.ObjectiveRequest:
 		vfo, err := virtualfund.NewObjective(request, true, myAddress, chainId, e.store.GetConsensusChannel)
@@ -488,7 +586,8 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("could not register channel with payment/receipt manager: %w", err)
 		}
-		return e.attemptProgress(&vfo)
+		metrics.RecordObjective(objectiveId, protocols.Approved)
+		return e.attemptProgress(ctx, &vfo)
 
 	case virtualdefund.ObjectiveRequest:
 		minAmount := big.NewInt(0)
@@ -503,14 +602,16 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create virtualdefund objective for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&vdfo)
+		metrics.RecordObjective(objectiveId, protocols.Approved)
+		return e.attemptProgress(ctx, &vdfo)
 
 	case directfund.ObjectiveRequest:
 		dfo, err := directfund.NewObjective(request, true, myAddress, chainId, e.store.GetChannelsByParticipant, e.store.GetConsensusChannel)
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create directfund objective for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&dfo)
+		metrics.RecordObjective(objectiveId, protocols.Approved)
+		return e.attemptProgress(ctx, &dfo)
 
 	case directdefund.ObjectiveRequest:
 		ddfo, err := directdefund.NewObjective(request, true, e.store.GetConsensusChannelById)
@@ -522,7 +623,8 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&ddfo)
+		metrics.RecordObjective(objectiveId, protocols.Approved)
+		return e.attemptProgress(ctx, &ddfo)
 
 	default:
 		return failedEngineEvent, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
@@ -531,19 +633,25 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 
 // handlePaymentRequest handles an PaymentRequest (triggered by a client API call).
 // It prepares and dispatches a payment message to the counterparty.
-func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, error) {
-	ee := EngineEvent{}
-	if (request == PaymentRequest{}) {
+func (e *Engine) handlePaymentRequest(request PaymentRequest) (ee EngineEvent, err error) {
+	if request.Result != nil {
+		defer func() { request.Result <- err }()
+	}
+	if request.ChannelId == (types.Destination{}) && request.Amount == nil {
 		return ee, fmt.Errorf("handleAPIEvent: Empty payment request")
 	}
 	cId := request.ChannelId
+	ctx, span := e.startSpan(request.Ctx, "engine.handlePaymentRequest", attribute.String("nitro.channel_id", cId.String()))
+	defer func() { tracing.End(span, err) }()
+
 	voucher, err := e.vm.Pay(
 		cId,
 		request.Amount,
 		*e.store.GetChannelSecretKey())
 	if err != nil {
-		return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
+		return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w: %w", ErrPaymentFailed, err)
 	}
+	metrics.RecordVoucher(metrics.Sent, request.Amount)
 	c, ok := e.store.GetChannelById(cId)
 	if !ok {
 		return ee, fmt.Errorf("handleAPIEvent: Could not get channel from the store %s", cId)
@@ -559,14 +667,18 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 	ee.PaymentChannelUpdates = append(ee.PaymentChannelUpdates, info)
 
 	se := protocols.SideEffects{MessagesToSend: protocols.CreateVoucherMessage(voucher, payee)}
-	return ee, e.executeSideEffects(se)
+	return ee, e.executeSideEffects(ctx, se)
 }
 
 // sendMessages sends out the messages and records the metrics.
-func (e *Engine) sendMessages(msgs []protocols.Message) {
+// Each message carries the trace context of its span in its metadata, so that the recipient continues the trace of ctx.
+func (e *Engine) sendMessages(ctx context.Context, msgs []protocols.Message) {
 	for _, message := range msgs {
 		message.From = *e.store.GetAddress()
+		spanCtx, span := e.startSpan(ctx, "engine.sendMessage", attribute.String("nitro.to", message.To.String()))
+		message.Metadata = tracing.Inject(spanCtx)
 		err := e.msg.Send(message)
+		tracing.End(span, err)
 		if err != nil {
 			e.logger.Error(err.Error())
 			panic(err)
@@ -577,15 +689,21 @@ func (e *Engine) sendMessages(msgs []protocols.Message) {
 }
 
 // executeSideEffects executes the SideEffects declared by cranking an Objective or handling a payment request.
-func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
+func (e *Engine) executeSideEffects(ctx context.Context, sideEffects protocols.SideEffects) error {
 	e.wg.Add(1)
 	// Send messages in a go routine so that we don't block on message delivery
-	go e.sendMessages(sideEffects.MessagesToSend)
+	go e.sendMessages(ctx, sideEffects.MessagesToSend)
 
 	for _, tx := range sideEffects.TransactionsToSubmit {
 		e.logger.Info("Sending chain transaction", "channel", tx.ChannelId().String())
 
+		_, span := e.startSpan(ctx, "engine.sendTransaction",
+			attribute.String("nitro.transaction_type", fmt.Sprintf("%T", tx)),
+			attribute.String("nitro.channel_id", tx.ChannelId().String()),
+		)
 		err := e.chain.SendTransaction(tx)
+		metrics.RecordTransaction(tx, err)
+		tracing.End(span, err)
 		if err != nil {
 			return err
 		}
@@ -603,7 +721,10 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 //  3. It commits the cranked objective to the store
 //  4. It executes any side effects that were declared during cranking
 //  5. It updates progress metadata in the store
-func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing EngineEvent, err error) {
+func (e *Engine) attemptProgress(ctx context.Context, objective protocols.Objective) (outgoing EngineEvent, err error) {
+	ctx, span := e.startSpan(ctx, "engine.attemptProgress", attribute.String("nitro.objective_id", string(objective.Id())))
+	defer func() { tracing.End(span, err) }()
+
 	secretKey := e.store.GetChannelSecretKey()
 	var crankedObjective protocols.Objective
 	var sideEffects protocols.SideEffects
@@ -626,6 +747,7 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 	outgoing.Merge(notifEvents)
 
 	e.logger.Info("Objective cranked", logging.WithObjectiveIdAttribute(objective.Id()), "waiting-for", string(waitingFor))
+	span.SetAttributes(attribute.String("nitro.waiting_for", string(waitingFor)))
 
 	// If our protocol is waiting for nothing then we know the objective is complete
 	// TODO: If attemptProgress is called on a completed objective CompletedObjectives would include that objective id
@@ -641,7 +763,7 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 			return
 		}
 	}
-	err = e.executeSideEffects(sideEffects)
+	err = e.executeSideEffects(ctx, sideEffects)
 	return
 }
 
@@ -721,6 +843,37 @@ func (e Engine) spawnConsensusChannelIfDirectFundObjective(crankedObjective prot
 	return nil
 }
 
+// isArchived returns true if the objective has been moved to the store's archive.
+// Archived objectives are complete, so any further messages about them can be safely ignored.
+func (e *Engine) isArchived(id protocols.ObjectiveId) bool {
+	_, err := e.store.GetArchivedObjective(id)
+	return err == nil
+}
+
+// archiveCompleted moves objectives that completed more than one retention period ago to the store's archive.
+func (e *Engine) archiveCompleted() error {
+	archived, err := e.store.ArchiveCompleted(time.Now())
+	if err != nil {
+		return fmt.Errorf("could not archive completed objectives: %w", err)
+	}
+	if archived > 0 {
+		e.logger.Info("Archived completed objectives", "count", archived)
+	}
+	return nil
+}
+
+// downsampleBalanceSnapshots reduces old balance snapshots to one per channel per store.BalanceSnapshotResolution, so that they do not grow with every update.
+func (e *Engine) downsampleBalanceSnapshots() error {
+	removed, err := e.store.DownsampleBalanceSnapshots(time.Now())
+	if err != nil {
+		return fmt.Errorf("could not downsample balance snapshots: %w", err)
+	}
+	if removed > 0 {
+		e.logger.Info("Downsampled balance snapshots", "removed", removed)
+	}
+	return nil
+}
+
 // getOrCreateObjective retrieves the objective from the store.
 // If the objective does not exist, it creates the objective using the supplied payload and stores it in the store
 func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.Objective, error) {
@@ -854,6 +1007,16 @@ func (e *Engine) logMessage(msg protocols.Message, direction messageDirection) {
 	}
 }
 
+// startSpan starts a span of the engine as a child of the span of ctx, if it has one.
+// The span is labelled with the address of the engine, to tell the engines of a trace apart.
+func (e *Engine) startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attributes = append(attributes, attribute.String("nitro.address", e.store.GetAddress().String()))
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
 func (e *Engine) checkError(err error) {
 	if err != nil {
 		e.logger.Error("error in run loop", "err", err)
@@ -21,6 +21,7 @@ import (
 	"github.com/multiformats/go-multiaddr"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/node/metrics"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -272,6 +273,7 @@ func (ms *P2PMessageService) msgStreamHandler(stream network.Stream) {
 		ms.logger.Error("error deserializing message", "err", err)
 		return
 	}
+	metrics.RecordMessage(metrics.Received, len(raw))
 	ms.toEngine <- m
 }
 
@@ -331,6 +333,7 @@ func (ms *P2PMessageService) Send(msg protocols.Message) error {
 
 			writer.Flush()
 			s.Close()
+			metrics.RecordMessage(metrics.Sent, len(raw)+1) // The size includes the delimiter, as it does for received messages
 			return nil
 		}
 
@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -29,14 +33,27 @@ type DurableStore struct {
 	channelToObjective *buntdb.DB
 	vouchers           *buntdb.DB
 	lastBlockNumSeen   *buntdb.DB
-
-	key     string // the signing key of the store's engine
-	address string // the (Ethereum) address associated to the signing key
-	folder  string // the folder where the store's data is stored
+	completedAt        *buntdb.DB // records the time at which each objective in objectives completed
+	archivedObjectives *buntdb.DB
+	archivedChannels   *buntdb.DB
+	paymentHistory     *buntdb.DB // records are keyed by channel id and index, see paymentRecordKey
+	channelCreatedAt   *buntdb.DB // records the time at which each (consensus) channel was first stored
+	balanceSnapshots   *buntdb.DB // records are keyed by time and channel id, see balanceSnapshotKey
+	snapshotsByChannel *buntdb.DB // indexes balanceSnapshots by channel id and time, see balanceSnapshotIndexKey
+	events             *buntdb.DB // records are keyed by sequence number, see eventKey
+	meta               *buntdb.DB // records store-wide metadata, such as the schema version
+
+	key             string        // the signing key of the store's engine
+	address         string        // the (Ethereum) address associated to the signing key
+	folder          string        // the folder where the store's data is stored
+	retentionPeriod time.Duration // how long completed objectives are kept before being archived
+	eventLogSize    uint64        // how many events are kept in the event log
 }
 
 // NewDurableStore creates a new DurableStore that uses the given folder to store its data
 // It will create the folder if it does not exist
+// Existing data is migrated to the current SchemaVersion. If the data was written with a newer schema
+// than this binary supports, an error wrapping ErrSchemaTooNew is returned.
 func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, error) {
 	ps := DurableStore{}
 
@@ -78,6 +95,53 @@ func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, er
 		return nil, err
 	}
 
+	ps.completedAt, err = ps.openDB("objective_completion", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.archivedObjectives, err = ps.openDB("archived_objectives", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.archivedChannels, err = ps.openDB("archived_channels", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.paymentHistory, err = ps.openDB("payment_history", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.channelCreatedAt, err = ps.openDB("channel_creation", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.balanceSnapshots, err = ps.openDB("balance_snapshots", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.snapshotsByChannel, err = ps.openDB("balance_snapshots_by_channel", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.events, err = ps.openDB("events", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.meta, err = ps.openDB("meta", config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ps.migrate(migrations, SchemaVersion)
+	if err != nil {
+		return nil, errors.Join(err, ps.Close())
+	}
+
 	return &ps, nil
 }
 
@@ -110,6 +174,46 @@ func (ds *DurableStore) Close() error {
 	if err != nil {
 		return err
 	}
+	err = ds.lastBlockNumSeen.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.completedAt.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.archivedObjectives.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.archivedChannels.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.paymentHistory.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.channelCreatedAt.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.balanceSnapshots.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.snapshotsByChannel.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.events.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.meta.Close()
+	if err != nil {
+		return err
+	}
 	return ds.vouchers.Close()
 }
 
@@ -165,6 +269,12 @@ func (ds *DurableStore) SetObjective(obj protocols.Objective) error {
 	if err != nil {
 		return err
 	}
+	if isTerminal(obj.GetStatus()) {
+		err = ds.setCompletedAt(obj.Id(), time.Now())
+		if err != nil {
+			return err
+		}
+	}
 	for _, rel := range obj.Related() {
 		switch ch := rel.(type) {
 		case *channel.VirtualChannel:
@@ -259,7 +369,24 @@ func (ds *DurableStore) SetChannel(ch *channel.Channel) error {
 		_, _, err := tx.Set(ch.Id.String(), string(chJSON), nil)
 		return err
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	return setTimeOnce(ds.channelCreatedAt, ch.Id.String(), time.Now())
+}
+
+// GetChannelCreatedAt returns the time at which the (consensus) channel with the supplied id was first stored.
+func (ds *DurableStore) GetChannelCreatedAt(id types.Destination) (time.Time, bool) {
+	var createdAt time.Time
+	err := ds.channelCreatedAt.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(id.String())
+		if err != nil {
+			return err
+		}
+		createdAt, err = time.Parse(time.RFC3339Nano, val)
+		return err
+	})
+	return createdAt, err == nil
 }
 
 // DestroyChannel deletes the channel with id id.
@@ -284,8 +411,11 @@ func (ps *DurableStore) SetConsensusChannel(ch *consensus_channel.ConsensusChann
 		_, _, err := tx.Set(ch.Id.String(), string(chJSON), nil)
 		return err
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	return setTimeOnce(ps.channelCreatedAt, ch.Id.String(), time.Now())
 }
 
 // DestroyChannel deletes the channel with id id.
@@ -395,6 +525,37 @@ func (ds *DurableStore) GetChannelsByAppDefinition(appDef types.Address) ([]*cha
 	return toReturn, nil
 }
 
+func (ds *DurableStore) RangeChannels(after types.Destination, f func(*channel.Channel) bool) error {
+	return rangeRecords(ds.channels, after, f)
+}
+
+// rangeRecords calls f with each record of db keyed by an id ordered after the given id, in order of id, until f returns false.
+// Ids are keyed by their hex string, whose order is that of the ids.
+func rangeRecords[T any](db *buntdb.DB, after types.Destination, f func(*T) bool) error {
+	pivot := ""
+	if !after.IsZero() {
+		pivot = after.String()
+	}
+	var unmarshErr error
+	err := db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendGreaterOrEqual("", pivot, func(key, value string) bool {
+			if key == pivot {
+				return true
+			}
+			var record T
+			unmarshErr = json.Unmarshal([]byte(value), &record)
+			if unmarshErr != nil {
+				return false
+			}
+			return f(&record)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return unmarshErr
+}
+
 // GetChannelsByParticipant returns any channels that include the given participant
 func (ds *DurableStore) GetChannelsByParticipant(participant types.Address) ([]*channel.Channel, error) {
 	toReturn := []*channel.Channel{}
@@ -448,6 +609,10 @@ func (ds *DurableStore) GetAllConsensusChannels() ([]*consensus_channel.Consensu
 	return toReturn, nil
 }
 
+func (ds *DurableStore) RangeConsensusChannels(after types.Destination, f func(*consensus_channel.ConsensusChannel) bool) error {
+	return rangeRecords(ds.consensusChannels, after, f)
+}
+
 // GetConsensusChannelById returns a ConsensusChannel with the given channel id
 func (ds *DurableStore) GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
 	var ch *consensus_channel.ConsensusChannel
@@ -648,3 +813,629 @@ func (ds *DurableStore) RemoveVoucherInfo(channelId types.Destination) error {
 		return err
 	})
 }
+
+// paymentRecordKey returns the key of the record with the given index in the channel's payment history.
+// Indices are zero-padded so that a channel's records are ordered by index.
+func paymentRecordKey(channelId types.Destination, index uint64) string {
+	return fmt.Sprintf("%s:%020d", channelId.String(), index)
+}
+
+func (ds *DurableStore) AppendPaymentRecord(r payments.PaymentRecord) error {
+	return ds.paymentHistory.Update(func(tx *buntdb.Tx) error {
+		prefix := r.ChannelId.String() + ":"
+
+		// Find the last record for the channel, if any
+		r.Index = 0
+		duplicate := false
+		var iterErr error
+		err := tx.DescendLessOrEqual("", prefix+"~", func(key, value string) bool {
+			if !strings.HasPrefix(key, prefix) {
+				return false
+			}
+			var last payments.PaymentRecord
+			iterErr = json.Unmarshal([]byte(value), &last)
+			r.Index = last.Index + 1
+			duplicate = last.IsSameVoucher(r)
+			return false
+		})
+		if err != nil {
+			return err
+		}
+		if iterErr != nil {
+			return iterErr
+		}
+		if duplicate {
+			return nil
+		}
+
+		rJSON, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(paymentRecordKey(r.ChannelId, r.Index), string(rJSON), nil)
+		return err
+	})
+}
+
+func (ds *DurableStore) GetPaymentHistory(channelId types.Destination, offset, limit uint64) ([]payments.PaymentRecord, error) {
+	toReturn := []payments.PaymentRecord{}
+	prefix := channelId.String() + ":"
+
+	var unmarshErr error
+	err := ds.paymentHistory.View(func(tx *buntdb.Tx) error {
+		return tx.AscendGreaterOrEqual("", paymentRecordKey(channelId, offset), func(key, value string) bool {
+			if !strings.HasPrefix(key, prefix) || (limit != 0 && uint64(len(toReturn)) == limit) {
+				return false
+			}
+			var r payments.PaymentRecord
+			unmarshErr = json.Unmarshal([]byte(value), &r)
+			if unmarshErr != nil {
+				return false
+			}
+			toReturn = append(toReturn, r)
+			return true
+		})
+	})
+	if err != nil {
+		return []payments.PaymentRecord{}, err
+	}
+	if unmarshErr != nil {
+		return []payments.PaymentRecord{}, unmarshErr
+	}
+	return toReturn, nil
+}
+
+// balanceSnapshotNanos returns the time t as a number of nanoseconds for use in a balance snapshot key. Times which cannot be
+// represented as non-negative UnixNano values are clamped, so that they may be used as range bounds.
+func balanceSnapshotNanos(t time.Time) int64 {
+	switch {
+	case t.Before(time.Unix(0, 0)):
+		return 0
+	case t.After(time.Unix(0, math.MaxInt64)):
+		return math.MaxInt64
+	default:
+		return t.UnixNano()
+	}
+}
+
+// balanceSnapshotKey returns the key of a snapshot of the given channel taken at time t.
+// Timestamps are zero-padded so that snapshots are ordered by time.
+func balanceSnapshotKey(t time.Time, channelId types.Destination) string {
+	return fmt.Sprintf("%020d:%s", balanceSnapshotNanos(t), channelId.String())
+}
+
+// balanceSnapshotIndexKey returns the key under which a snapshot of the given channel taken at time t is indexed.
+// The index is ordered by channel, and then by time, so that the snapshots of a channel can be read without scanning those of other channels.
+func balanceSnapshotIndexKey(channelId types.Destination, t time.Time) string {
+	return fmt.Sprintf("%s:%020d", channelId.String(), balanceSnapshotNanos(t))
+}
+
+// indexKeyOfSnapshot returns the index key of the snapshot stored under the given balanceSnapshotKey
+func indexKeyOfSnapshot(key string) string {
+	nanos, channelId, _ := strings.Cut(key, ":")
+	return channelId + ":" + nanos
+}
+
+func (ds *DurableStore) AddBalanceSnapshot(s BalanceSnapshot) error {
+	sJSON, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	key := balanceSnapshotKey(s.Timestamp, s.ChannelId)
+	err = ds.balanceSnapshots.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(key, string(sJSON), nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return ds.indexBalanceSnapshots([]string{key})
+}
+
+// indexBalanceSnapshots adds the snapshots stored under the given keys to the index by channel
+func (ds *DurableStore) indexBalanceSnapshots(keys []string) error {
+	return ds.snapshotsByChannel.Update(func(tx *buntdb.Tx) error {
+		for _, key := range keys {
+			if _, _, err := tx.Set(indexKeyOfSnapshot(key), key, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (ds *DurableStore) GetBalanceSnapshots(channelId types.Destination, from, to time.Time) ([]BalanceSnapshot, error) {
+	keys := []string{}
+	err := ds.snapshotsByChannel.View(func(tx *buntdb.Tx) error {
+		return tx.AscendRange("", balanceSnapshotIndexKey(channelId, from), balanceSnapshotIndexKey(channelId, to), func(_, key string) bool {
+			keys = append(keys, key)
+			return true
+		})
+	})
+	if err != nil {
+		return []BalanceSnapshot{}, err
+	}
+	return ds.loadBalanceSnapshots(keys)
+}
+
+func (ds *DurableStore) GetAllBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) {
+	toReturn := []BalanceSnapshot{}
+	var unmarshErr error
+	err := ds.balanceSnapshots.View(func(tx *buntdb.Tx) error {
+		return tx.AscendRange("", balanceSnapshotKey(from, types.Destination{}), balanceSnapshotKey(to, types.Destination{}), func(key, value string) bool {
+			var s BalanceSnapshot
+			unmarshErr = json.Unmarshal([]byte(value), &s)
+			if unmarshErr != nil {
+				return false
+			}
+			toReturn = append(toReturn, s)
+			return true
+		})
+	})
+	if err != nil {
+		return []BalanceSnapshot{}, err
+	}
+	if unmarshErr != nil {
+		return []BalanceSnapshot{}, unmarshErr
+	}
+	return toReturn, nil
+}
+
+func (ds *DurableStore) GetLatestBalanceSnapshots(before time.Time) ([]BalanceSnapshot, error) {
+	keys := []string{}
+	err := ds.snapshotsByChannel.View(func(tx *buntdb.Tx) error {
+		next := ""
+		for {
+			// Find the next channel in the index
+			channelId, found := "", false
+			err := tx.AscendGreaterOrEqual("", next, func(indexKey, _ string) bool {
+				channelId, _, found = strings.Cut(indexKey, ":")
+				return false
+			})
+			if err != nil || !found {
+				return err
+			}
+
+			bound := channelId + ":" + fmt.Sprintf("%020d", balanceSnapshotNanos(before))
+			err = tx.DescendLessOrEqual("", bound, func(indexKey, key string) bool {
+				if indexKey != bound && strings.HasPrefix(indexKey, channelId+":") {
+					keys = append(keys, key)
+					return false
+				}
+				return indexKey == bound
+			})
+			if err != nil {
+				return err
+			}
+			// ';' follows ':', so this skips the remaining keys of the channel
+			next = channelId + ";"
+		}
+	})
+	if err != nil {
+		return []BalanceSnapshot{}, err
+	}
+	// Snapshot keys are ordered by time
+	sort.Strings(keys)
+	return ds.loadBalanceSnapshots(keys)
+}
+
+// loadBalanceSnapshots returns the snapshots stored under the given keys, in the same order
+func (ds *DurableStore) loadBalanceSnapshots(keys []string) ([]BalanceSnapshot, error) {
+	toReturn := make([]BalanceSnapshot, 0, len(keys))
+	err := ds.balanceSnapshots.View(func(tx *buntdb.Tx) error {
+		for _, key := range keys {
+			value, err := tx.Get(key)
+			if err != nil {
+				return fmt.Errorf("error getting balance snapshot %s: %w", key, err)
+			}
+			var s BalanceSnapshot
+			if err := json.Unmarshal([]byte(value), &s); err != nil {
+				return err
+			}
+			toReturn = append(toReturn, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return []BalanceSnapshot{}, err
+	}
+	return toReturn, nil
+}
+
+// DownsampleBalanceSnapshots reduces the snapshots taken in each completed period of BalanceSnapshotResolution before now
+// to the last snapshot of each channel in the period. The periods that have already been downsampled are recorded, so that
+// each snapshot is only visited once.
+func (ds *DurableStore) DownsampleBalanceSnapshots(now time.Time) (int, error) {
+	cutoff := downsamplingCutoff(now)
+	from, err := ds.getDownsampledBefore()
+	if err != nil {
+		return 0, err
+	}
+	if !from.Before(cutoff) {
+		return 0, nil
+	}
+
+	type period struct {
+		channelId string
+		n         int64
+	}
+	last := map[period]string{}
+	stale := []string{}
+	err = ds.balanceSnapshots.View(func(tx *buntdb.Tx) error {
+		return tx.AscendRange("", balanceSnapshotKey(from, types.Destination{}), balanceSnapshotKey(cutoff, types.Destination{}), func(key, _ string) bool {
+			nanos, channelId, _ := strings.Cut(key, ":")
+			n, _ := strconv.ParseInt(nanos, 10, 64)
+			p := period{channelId, n / int64(BalanceSnapshotResolution)}
+			if previous, ok := last[p]; ok {
+				stale = append(stale, previous)
+			}
+			last[p] = key
+			return true
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// The index is updated first, so that it never refers to a snapshot that has been deleted
+	err = ds.snapshotsByChannel.Update(func(tx *buntdb.Tx) error {
+		for _, key := range stale {
+			if _, err := tx.Delete(indexKeyOfSnapshot(key)); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = ds.balanceSnapshots.Update(func(tx *buntdb.Tx) error {
+		for _, key := range stale {
+			if _, err := tx.Delete(key); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(stale), ds.meta.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(downsampledBeforeKey, strconv.FormatInt(balanceSnapshotNanos(cutoff), 10), nil)
+		return err
+	})
+}
+
+// getDownsampledBefore returns the time before which balance snapshots have been downsampled, or the zero time if they never have been
+func (ds *DurableStore) getDownsampledBefore() (time.Time, error) {
+	var before time.Time
+	err := ds.meta.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(downsampledBeforeKey)
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		nanos, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		before = time.Unix(0, nanos)
+		return nil
+	})
+	return before, err
+}
+
+// eventKey returns the key of the event with the given sequence number.
+// Sequence numbers are zero-padded so that events are ordered by sequence number.
+func eventKey(seq uint64) string {
+	return fmt.Sprintf("%020d", seq)
+}
+
+func (ds *DurableStore) AppendEvent(e Event) (Event, error) {
+	err := ds.events.Update(func(tx *buntdb.Tx) error {
+		last, err := lastEventSeq(tx)
+		if err != nil {
+			return err
+		}
+		e.Seq = last + 1
+
+		eJSON, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(eventKey(e.Seq), string(eJSON), nil)
+		if err != nil {
+			return err
+		}
+
+		// Drop the events which no longer fit in the log
+		capacity := eventLogCapacity(ds.eventLogSize)
+		if e.Seq <= capacity {
+			return nil
+		}
+		dropped := []string{}
+		err = tx.AscendLessThan("", eventKey(e.Seq-capacity+1), func(key, value string) bool {
+			dropped = append(dropped, key)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range dropped {
+			_, err = tx.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+func (ds *DurableStore) GetEvents(since, limit uint64) ([]Event, error) {
+	toReturn := []Event{}
+	var unmarshErr error
+	err := ds.events.View(func(tx *buntdb.Tx) error {
+		return tx.AscendGreaterOrEqual("", eventKey(since+1), func(key, value string) bool {
+			if limit != 0 && uint64(len(toReturn)) == limit {
+				return false
+			}
+			var e Event
+			unmarshErr = json.Unmarshal([]byte(value), &e)
+			if unmarshErr != nil {
+				return false
+			}
+			toReturn = append(toReturn, e)
+			return true
+		})
+	})
+	if err != nil {
+		return []Event{}, err
+	}
+	if unmarshErr != nil {
+		return []Event{}, unmarshErr
+	}
+	return toReturn, nil
+}
+
+func (ds *DurableStore) GetLastEventSeq() (uint64, error) {
+	var last uint64
+	err := ds.events.View(func(tx *buntdb.Tx) error {
+		var err error
+		last, err = lastEventSeq(tx)
+		return err
+	})
+	return last, err
+}
+
+// lastEventSeq returns the sequence number of the last event in the log, or 0 if the log is empty.
+// The log is only empty if no event has been appended, since appending an event never drops it.
+func lastEventSeq(tx *buntdb.Tx) (uint64, error) {
+	var last uint64
+	var parseErr error
+	err := tx.Descend("", func(key, value string) bool {
+		last, parseErr = strconv.ParseUint(key, 10, 64)
+		return false
+	})
+	if err != nil {
+		return 0, err
+	}
+	return last, parseErr
+}
+
+// setCompletedAt records the time at which the objective completed, if it has not already been recorded.
+func (ds *DurableStore) setCompletedAt(id protocols.ObjectiveId, completedAt time.Time) error {
+	return setTimeOnce(ds.completedAt, string(id), completedAt)
+}
+
+// setTimeOnce records t against key in db, unless a time has already been recorded against key.
+func setTimeOnce(db *buntdb.DB, key string, t time.Time) error {
+	return db.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Get(key)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, buntdb.ErrNotFound) {
+			return err
+		}
+		_, _, err = tx.Set(key, t.Format(time.RFC3339Nano), nil)
+		return err
+	})
+}
+
+// ArchiveCompleted moves objectives which completed at least one retention period ago, and any finalized channels they own, to the archive.
+func (ds *DurableStore) ArchiveCompleted(now time.Time) (int, error) {
+	if ds.retentionPeriod == 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-ds.retentionPeriod)
+
+	due := map[protocols.ObjectiveId]time.Time{}
+	var parseErr error
+	err := ds.completedAt.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(id, val string) bool {
+			completedAt, err := time.Parse(time.RFC3339Nano, val)
+			if err != nil {
+				parseErr = fmt.Errorf("error parsing completion time of objective %s: %w", id, err)
+				return false
+			}
+			if !completedAt.After(cutoff) {
+				due[protocols.ObjectiveId(id)] = completedAt
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if parseErr != nil {
+		return 0, parseErr
+	}
+
+	archived := 0
+	for id, completedAt := range due {
+		err := ds.archiveObjective(id, completedAt, now)
+		if err != nil {
+			return archived, err
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+// archiveObjective moves the objective with the given id to the archive, along with the channel it owns if that channel is finalized.
+// Records are written to the archive before being removed from the live store, so an interrupted archival is completed by the next one.
+func (ds *DurableStore) archiveObjective(id protocols.ObjectiveId, completedAt, now time.Time) error {
+	var objJSON string
+	err := ds.objectives.View(func(tx *buntdb.Tx) error {
+		var err error
+		objJSON, err = tx.Get(string(id))
+		return err
+	})
+	if errors.Is(err, buntdb.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	record, channelId, err := newArchivedObjective(id, []byte(objJSON), completedAt, now)
+	if err != nil {
+		return err
+	}
+
+	ch, err := ds.getChannelById(channelId)
+	owner, isOwned := ds.getChannelOwner(channelId)
+	if err == nil && ch.FinalCompleted() && (!isOwned || owner == id) {
+		chJSON, err := ch.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		err = ds.archivedChannels.Update(func(tx *buntdb.Tx) error {
+			_, _, err := tx.Set(channelId.String(), string(chJSON), nil)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		err = ds.DestroyChannel(channelId)
+		if err != nil {
+			return err
+		}
+		if isOwned {
+			err = ds.ReleaseChannelFromOwnership(channelId)
+			if err != nil {
+				return err
+			}
+		}
+		record.ArchivedChannel = channelId
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	err = ds.archivedObjectives.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(string(id), string(recordJSON), nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ds.objectives.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(string(id))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return ds.completedAt.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(string(id))
+		return err
+	})
+}
+
+// getChannelOwner returns the id of the objective which owns the given channel, if any.
+func (ds *DurableStore) getChannelOwner(channelId types.Destination) (protocols.ObjectiveId, bool) {
+	var owner string
+	err := ds.channelToObjective.View(func(tx *buntdb.Tx) error {
+		var err error
+		owner, err = tx.Get(channelId.String())
+		return err
+	})
+	if err != nil {
+		return "", false
+	}
+	return protocols.ObjectiveId(owner), true
+}
+
+// GetArchivedObjectives returns every archived objective, ordered by completion time.
+func (ds *DurableStore) GetArchivedObjectives() ([]ArchivedObjective, error) {
+	toReturn := []ArchivedObjective{}
+	var unmarshErr error
+	err := ds.archivedObjectives.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, recordJSON string) bool {
+			var record ArchivedObjective
+			unmarshErr = json.Unmarshal([]byte(recordJSON), &record)
+			if unmarshErr != nil {
+				return false
+			}
+			toReturn = append(toReturn, record)
+			return true
+		})
+	})
+	if err != nil {
+		return []ArchivedObjective{}, err
+	}
+	if unmarshErr != nil {
+		return []ArchivedObjective{}, unmarshErr
+	}
+
+	sort.SliceStable(toReturn, func(i, j int) bool { return toReturn[i].CompletedAt.Before(toReturn[j].CompletedAt) })
+	return toReturn, nil
+}
+
+// GetArchivedObjective returns the archived objective with the given id.
+func (ds *DurableStore) GetArchivedObjective(id protocols.ObjectiveId) (ArchivedObjective, error) {
+	var record ArchivedObjective
+	err := ds.archivedObjectives.View(func(tx *buntdb.Tx) error {
+		recordJSON, err := tx.Get(string(id))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return fmt.Errorf("objective %s: %w", id, ErrNotArchived)
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(recordJSON), &record)
+	})
+	return record, err
+}
+
+// GetArchivedChannel returns the archived channel with the given id.
+func (ds *DurableStore) GetArchivedChannel(id types.Destination) (*channel.Channel, error) {
+	ch := &channel.Channel{}
+	err := ds.archivedChannels.View(func(tx *buntdb.Tx) error {
+		chJSON, err := tx.Get(id.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return fmt.Errorf("channel %s: %w", id, ErrNotArchived)
+		}
+		if err != nil {
+			return err
+		}
+		err = ch.UnmarshalJSON([]byte(chJSON))
+		if err != nil {
+			return fmt.Errorf("error unmarshaling channel %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/node/metrics"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// measuredStore records the latency of each operation of the Store it wraps.
+// GetAddress and GetChannelSecretKey only read fields, so are not measured.
+type measuredStore struct {
+	Store
+}
+
+// NewMeasuredStore returns a Store that records the latency of each operation of s in the node's metrics
+func NewMeasuredStore(s Store) Store {
+	return &measuredStore{Store: s}
+}
+
+func (s *measuredStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Objective, error) {
+	defer metrics.ObserveStoreOperation("GetObjectiveById", time.Now())
+	return s.Store.GetObjectiveById(id)
+}
+
+func (s *measuredStore) GetObjectiveByChannelId(channelId types.Destination) (protocols.Objective, bool) {
+	defer metrics.ObserveStoreOperation("GetObjectiveByChannelId", time.Now())
+	return s.Store.GetObjectiveByChannelId(channelId)
+}
+
+func (s *measuredStore) SetObjective(obj protocols.Objective) error {
+	defer metrics.ObserveStoreOperation("SetObjective", time.Now())
+	return s.Store.SetObjective(obj)
+}
+
+func (s *measuredStore) GetChannelsByIds(ids []types.Destination) ([]*channel.Channel, error) {
+	defer metrics.ObserveStoreOperation("GetChannelsByIds", time.Now())
+	return s.Store.GetChannelsByIds(ids)
+}
+
+func (s *measuredStore) GetChannelById(id types.Destination) (*channel.Channel, bool) {
+	defer metrics.ObserveStoreOperation("GetChannelById", time.Now())
+	return s.Store.GetChannelById(id)
+}
+
+func (s *measuredStore) GetChannelsByParticipant(participant types.Address) ([]*channel.Channel, error) {
+	defer metrics.ObserveStoreOperation("GetChannelsByParticipant", time.Now())
+	return s.Store.GetChannelsByParticipant(participant)
+}
+
+func (s *measuredStore) SetChannel(ch *channel.Channel) error {
+	defer metrics.ObserveStoreOperation("SetChannel", time.Now())
+	return s.Store.SetChannel(ch)
+}
+
+func (s *measuredStore) DestroyChannel(id types.Destination) error {
+	defer metrics.ObserveStoreOperation("DestroyChannel", time.Now())
+	return s.Store.DestroyChannel(id)
+}
+
+func (s *measuredStore) GetChannelsByAppDefinition(appDef types.Address) ([]*channel.Channel, error) {
+	defer metrics.ObserveStoreOperation("GetChannelsByAppDefinition", time.Now())
+	return s.Store.GetChannelsByAppDefinition(appDef)
+}
+
+func (s *measuredStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
+	defer metrics.ObserveStoreOperation("ReleaseChannelFromOwnership", time.Now())
+	return s.Store.ReleaseChannelFromOwnership(channelId)
+}
+
+func (s *measuredStore) GetLastBlockNumSeen() (uint64, error) {
+	defer metrics.ObserveStoreOperation("GetLastBlockNumSeen", time.Now())
+	return s.Store.GetLastBlockNumSeen()
+}
+
+func (s *measuredStore) SetLastBlockNumSeen(blockNumber uint64) error {
+	defer metrics.ObserveStoreOperation("SetLastBlockNumSeen", time.Now())
+	return s.Store.SetLastBlockNumSeen(blockNumber)
+}
+
+func (s *measuredStore) GetChannelCreatedAt(id types.Destination) (time.Time, bool) {
+	defer metrics.ObserveStoreOperation("GetChannelCreatedAt", time.Now())
+	return s.Store.GetChannelCreatedAt(id)
+}
+
+func (s *measuredStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) {
+	defer metrics.ObserveStoreOperation("GetAllConsensusChannels", time.Now())
+	return s.Store.GetAllConsensusChannels()
+}
+
+func (s *measuredStore) GetConsensusChannel(counterparty types.Address) (*consensus_channel.ConsensusChannel, bool) {
+	defer metrics.ObserveStoreOperation("GetConsensusChannel", time.Now())
+	return s.Store.GetConsensusChannel(counterparty)
+}
+
+func (s *measuredStore) GetConsensusChannelById(id types.Destination) (*consensus_channel.ConsensusChannel, error) {
+	defer metrics.ObserveStoreOperation("GetConsensusChannelById", time.Now())
+	return s.Store.GetConsensusChannelById(id)
+}
+
+func (s *measuredStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel) error {
+	defer metrics.ObserveStoreOperation("SetConsensusChannel", time.Now())
+	return s.Store.SetConsensusChannel(ch)
+}
+
+func (s *measuredStore) DestroyConsensusChannel(id types.Destination) error {
+	defer metrics.ObserveStoreOperation("DestroyConsensusChannel", time.Now())
+	return s.Store.DestroyConsensusChannel(id)
+}
+
+func (s *measuredStore) ArchiveCompleted(now time.Time) (int, error) {
+	defer metrics.ObserveStoreOperation("ArchiveCompleted", time.Now())
+	return s.Store.ArchiveCompleted(now)
+}
+
+func (s *measuredStore) GetArchivedObjectives() ([]ArchivedObjective, error) {
+	defer metrics.ObserveStoreOperation("GetArchivedObjectives", time.Now())
+	return s.Store.GetArchivedObjectives()
+}
+
+func (s *measuredStore) GetArchivedObjective(id protocols.ObjectiveId) (ArchivedObjective, error) {
+	defer metrics.ObserveStoreOperation("GetArchivedObjective", time.Now())
+	return s.Store.GetArchivedObjective(id)
+}
+
+func (s *measuredStore) GetArchivedChannel(id types.Destination) (*channel.Channel, error) {
+	defer metrics.ObserveStoreOperation("GetArchivedChannel", time.Now())
+	return s.Store.GetArchivedChannel(id)
+}
+
+func (s *measuredStore) AddBalanceSnapshot(snapshot BalanceSnapshot) error {
+	defer metrics.ObserveStoreOperation("AddBalanceSnapshot", time.Now())
+	return s.Store.AddBalanceSnapshot(snapshot)
+}
+
+func (s *measuredStore) GetBalanceSnapshots(channelId types.Destination, from, to time.Time) ([]BalanceSnapshot, error) {
+	defer metrics.ObserveStoreOperation("GetBalanceSnapshots", time.Now())
+	return s.Store.GetBalanceSnapshots(channelId, from, to)
+}
+
+func (s *measuredStore) GetAllBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) {
+	defer metrics.ObserveStoreOperation("GetAllBalanceSnapshots", time.Now())
+	return s.Store.GetAllBalanceSnapshots(from, to)
+}
+
+func (s *measuredStore) GetLatestBalanceSnapshots(before time.Time) ([]BalanceSnapshot, error) {
+	defer metrics.ObserveStoreOperation("GetLatestBalanceSnapshots", time.Now())
+	return s.Store.GetLatestBalanceSnapshots(before)
+}
+
+func (s *measuredStore) DownsampleBalanceSnapshots(now time.Time) (int, error) {
+	defer metrics.ObserveStoreOperation("DownsampleBalanceSnapshots", time.Now())
+	return s.Store.DownsampleBalanceSnapshots(now)
+}
+
+func (s *measuredStore) AppendEvent(e Event) (Event, error) {
+	defer metrics.ObserveStoreOperation("AppendEvent", time.Now())
+	return s.Store.AppendEvent(e)
+}
+
+func (s *measuredStore) GetEvents(since, limit uint64) ([]Event, error) {
+	defer metrics.ObserveStoreOperation("GetEvents", time.Now())
+	return s.Store.GetEvents(since, limit)
+}
+
+func (s *measuredStore) GetLastEventSeq() (uint64, error) {
+	defer metrics.ObserveStoreOperation("GetLastEventSeq", time.Now())
+	return s.Store.GetLastEventSeq()
+}
+
+func (s *measuredStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	defer metrics.ObserveStoreOperation("SetVoucherInfo", time.Now())
+	return s.Store.SetVoucherInfo(channelId, v)
+}
+
+func (s *measuredStore) GetVoucherInfo(channelId types.Destination) (*payments.VoucherInfo, error) {
+	defer metrics.ObserveStoreOperation("GetVoucherInfo", time.Now())
+	return s.Store.GetVoucherInfo(channelId)
+}
+
+func (s *measuredStore) RemoveVoucherInfo(channelId types.Destination) error {
+	defer metrics.ObserveStoreOperation("RemoveVoucherInfo", time.Now())
+	return s.Store.RemoveVoucherInfo(channelId)
+}
+
+func (s *measuredStore) AppendPaymentRecord(r payments.PaymentRecord) error {
+	defer metrics.ObserveStoreOperation("AppendPaymentRecord", time.Now())
+	return s.Store.AppendPaymentRecord(r)
+}
+
+func (s *measuredStore) GetPaymentHistory(channelId types.Destination, offset, limit uint64) ([]payments.PaymentRecord, error) {
+	defer metrics.ObserveStoreOperation("GetPaymentHistory", time.Now())
+	return s.Store.GetPaymentHistory(channelId, offset, limit)
+}
+
+func (s *measuredStore) Close() error {
+	defer metrics.ObserveStoreOperation("Close", time.Now())
+	return s.Store.Close()
+}
+
+func (s *measuredStore) RangeChannels(after types.Destination, f func(*channel.Channel) bool) error {
+	defer metrics.ObserveStoreOperation("RangeChannels", time.Now())
+	return s.Store.RangeChannels(after, f)
+}
+
+func (s *measuredStore) RangeConsensusChannels(after types.Destination, f func(*consensus_channel.ConsensusChannel) bool) error {
+	defer metrics.ObserveStoreOperation("RangeConsensusChannels", time.Now())
+	return s.Store.RangeConsensusChannels(after, f)
+}
@@ -3,7 +3,10 @@ package store
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -31,9 +34,22 @@ type MemStore struct {
 	channelToObjective safesync.Map[protocols.ObjectiveId]
 	vouchers           safesync.Map[[]byte]
 	lastBlockSeen      blockData
-
-	key     string // the signing key of the store's engine
-	address string // the (Ethereum) address associated to the signing key
+	completedAt        safesync.Map[time.Time] // the time at which each objective in objectives completed
+	archivedObjectives safesync.Map[[]byte]
+	archivedChannels   safesync.Map[[]byte]
+	paymentHistory     safesync.Map[[]payments.PaymentRecord]
+	paymentHistoryMu   sync.Mutex              // serializes appends to paymentHistory
+	channelCreatedAt   safesync.Map[time.Time] // the time at which each (consensus) channel was first stored
+	balanceSnapshots   []BalanceSnapshot
+	balanceSnapshotsMu sync.RWMutex // guards balanceSnapshots
+	events             []Event      // the event log, ordered by sequence number
+	lastEventSeq       uint64
+	eventsMu           sync.RWMutex // guards events and lastEventSeq
+
+	key             string        // the signing key of the store's engine
+	address         string        // the (Ethereum) address associated to the signing key
+	retentionPeriod time.Duration // how long completed objectives are kept before being archived
+	eventLogSize    uint64        // how many events are kept in the event log
 }
 
 func NewMemStore(key []byte) Store {
@@ -47,6 +63,11 @@ func NewMemStore(key []byte) Store {
 	ms.channelToObjective = safesync.Map[protocols.ObjectiveId]{}
 	ms.vouchers = safesync.Map[[]byte]{}
 	ms.lastBlockSeen = blockData{}
+	ms.completedAt = safesync.Map[time.Time]{}
+	ms.archivedObjectives = safesync.Map[[]byte]{}
+	ms.archivedChannels = safesync.Map[[]byte]{}
+	ms.paymentHistory = safesync.Map[[]payments.PaymentRecord]{}
+	ms.channelCreatedAt = safesync.Map[time.Time]{}
 	return &ms
 }
 
@@ -96,6 +117,9 @@ func (ms *MemStore) SetObjective(obj protocols.Objective) error {
 	}
 
 	ms.objectives.Store(string(obj.Id()), objJSON)
+	if isTerminal(obj.GetStatus()) {
+		ms.completedAt.LoadOrStore(string(obj.Id()), time.Now())
+	}
 
 	for _, rel := range obj.Related() {
 		switch ch := rel.(type) {
@@ -157,6 +181,7 @@ func (ms *MemStore) SetChannel(ch *channel.Channel) error {
 	}
 
 	ms.channels.Store(ch.Id.String(), chJSON)
+	ms.channelCreatedAt.LoadOrStore(ch.Id.String(), time.Now())
 	return nil
 }
 
@@ -177,9 +202,15 @@ func (ms *MemStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel)
 	}
 
 	ms.consensusChannels.Store(ch.Id.String(), chJSON)
+	ms.channelCreatedAt.LoadOrStore(ch.Id.String(), time.Now())
 	return nil
 }
 
+// GetChannelCreatedAt returns the time at which the (consensus) channel with the supplied id was first stored.
+func (ms *MemStore) GetChannelCreatedAt(id types.Destination) (time.Time, bool) {
+	return ms.channelCreatedAt.Load(id.String())
+}
+
 // DestroyChannel deletes the channel with id id.
 func (ms *MemStore) DestroyConsensusChannel(id types.Destination) error {
 	ms.consensusChannels.Delete(id.String())
@@ -268,6 +299,39 @@ func (ms *MemStore) GetChannelsByAppDefinition(appDef types.Address) ([]*channel
 	return toReturn, nil
 }
 
+func (ms *MemStore) RangeChannels(after types.Destination, f func(*channel.Channel) bool) error {
+	return rangeMapRecords(&ms.channels, after, f)
+}
+
+// rangeMapRecords calls f with each record of m keyed by an id ordered after the given id, in order of id, until f returns false.
+func rangeMapRecords[T any](m *safesync.Map[[]byte], after types.Destination, f func(*T) bool) error {
+	keys := []string{}
+	m.Range(func(key string, _ []byte) bool {
+		keys = append(keys, key)
+		return true
+	})
+	// Ids are keyed by their hex string, whose order is that of the ids
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !after.IsZero() && key <= after.String() {
+			continue
+		}
+		recordJSON, ok := m.Load(key)
+		if !ok {
+			continue
+		}
+		var record T
+		if err := json.Unmarshal(recordJSON, &record); err != nil {
+			return err
+		}
+		if !f(&record) {
+			return nil
+		}
+	}
+	return nil
+}
+
 // GetChannelsByParticipant returns any channels that include the given participant
 func (ms *MemStore) GetChannelsByParticipant(participant types.Address) ([]*channel.Channel, error) {
 	toReturn := []*channel.Channel{}
@@ -354,6 +418,10 @@ func (ms *MemStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusCha
 	return toReturn, nil
 }
 
+func (ms *MemStore) RangeConsensusChannels(after types.Destination, f func(*consensus_channel.ConsensusChannel) bool) error {
+	return rangeMapRecords(&ms.consensusChannels, after, f)
+}
+
 func (ms *MemStore) GetObjectiveByChannelId(channelId types.Destination) (protocols.Objective, bool) {
 	// todo: locking
 	id, found := ms.channelToObjective.Load(channelId.String())
@@ -515,6 +583,263 @@ func (ms *MemStore) RemoveVoucherInfo(channelId types.Destination) error {
 	return nil
 }
 
+func (ms *MemStore) AppendPaymentRecord(r payments.PaymentRecord) error {
+	ms.paymentHistoryMu.Lock()
+	defer ms.paymentHistoryMu.Unlock()
+
+	history, _ := ms.paymentHistory.Load(r.ChannelId.String())
+	if len(history) > 0 && history[len(history)-1].IsSameVoucher(r) {
+		return nil
+	}
+	r.Index = uint64(len(history))
+
+	// Copy the history so that slices previously returned by GetPaymentHistory are not modified
+	updated := make([]payments.PaymentRecord, len(history), len(history)+1)
+	copy(updated, history)
+	ms.paymentHistory.Store(r.ChannelId.String(), append(updated, r.Clone()))
+	return nil
+}
+
+func (ms *MemStore) GetPaymentHistory(channelId types.Destination, offset, limit uint64) ([]payments.PaymentRecord, error) {
+	history, _ := ms.paymentHistory.Load(channelId.String())
+
+	toReturn := []payments.PaymentRecord{}
+	for i := offset; i < uint64(len(history)); i++ {
+		if limit != 0 && uint64(len(toReturn)) == limit {
+			break
+		}
+		toReturn = append(toReturn, history[i].Clone())
+	}
+	return toReturn, nil
+}
+
+func (ms *MemStore) AddBalanceSnapshot(s BalanceSnapshot) error {
+	ms.balanceSnapshotsMu.Lock()
+	defer ms.balanceSnapshotsMu.Unlock()
+
+	ms.balanceSnapshots = append(ms.balanceSnapshots, s.clone())
+	return nil
+}
+
+func (ms *MemStore) GetBalanceSnapshots(channelId types.Destination, from, to time.Time) ([]BalanceSnapshot, error) {
+	return ms.getBalanceSnapshots(func(s BalanceSnapshot) bool { return s.ChannelId == channelId }, from, to), nil
+}
+
+func (ms *MemStore) GetAllBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) {
+	return ms.getBalanceSnapshots(func(s BalanceSnapshot) bool { return true }, from, to), nil
+}
+
+// getBalanceSnapshots returns the snapshots taken in [from, to) which satisfy the include function, ordered by time.
+func (ms *MemStore) getBalanceSnapshots(include func(BalanceSnapshot) bool, from, to time.Time) []BalanceSnapshot {
+	ms.balanceSnapshotsMu.RLock()
+	defer ms.balanceSnapshotsMu.RUnlock()
+
+	toReturn := []BalanceSnapshot{}
+	for _, s := range ms.balanceSnapshots {
+		if s.Timestamp.Before(from) || !s.Timestamp.Before(to) || !include(s) {
+			continue
+		}
+		toReturn = append(toReturn, s.clone())
+	}
+	sort.SliceStable(toReturn, func(i, j int) bool { return toReturn[i].Timestamp.Before(toReturn[j].Timestamp) })
+	return toReturn
+}
+
+func (ms *MemStore) GetLatestBalanceSnapshots(before time.Time) ([]BalanceSnapshot, error) {
+	ms.balanceSnapshotsMu.RLock()
+	defer ms.balanceSnapshotsMu.RUnlock()
+
+	latest := map[types.Destination]BalanceSnapshot{}
+	for _, s := range ms.balanceSnapshots {
+		if !s.Timestamp.Before(before) {
+			continue
+		}
+		if l, ok := latest[s.ChannelId]; !ok || !s.Timestamp.Before(l.Timestamp) {
+			latest[s.ChannelId] = s
+		}
+	}
+	toReturn := make([]BalanceSnapshot, 0, len(latest))
+	for _, s := range latest {
+		toReturn = append(toReturn, s.clone())
+	}
+	sort.SliceStable(toReturn, func(i, j int) bool { return toReturn[i].Timestamp.Before(toReturn[j].Timestamp) })
+	return toReturn, nil
+}
+
+// DownsampleBalanceSnapshots keeps only the last snapshot of each channel in each period of BalanceSnapshotResolution that ended at least one period before now
+func (ms *MemStore) DownsampleBalanceSnapshots(now time.Time) (int, error) {
+	ms.balanceSnapshotsMu.Lock()
+	defer ms.balanceSnapshotsMu.Unlock()
+
+	type period struct {
+		channelId types.Destination
+		start     time.Time
+	}
+	cutoff := downsamplingCutoff(now)
+	last := map[period]BalanceSnapshot{}
+	for _, s := range ms.balanceSnapshots {
+		p := period{s.ChannelId, s.Timestamp.Truncate(BalanceSnapshotResolution)}
+		if l, ok := last[p]; s.Timestamp.Before(cutoff) && (!ok || !s.Timestamp.Before(l.Timestamp)) {
+			last[p] = s
+		}
+	}
+
+	kept := ms.balanceSnapshots[:0]
+	for _, s := range ms.balanceSnapshots {
+		p := period{s.ChannelId, s.Timestamp.Truncate(BalanceSnapshotResolution)}
+		if l, ok := last[p]; !ok || l.Timestamp.Equal(s.Timestamp) {
+			kept = append(kept, s)
+		}
+	}
+	removed := len(ms.balanceSnapshots) - len(kept)
+	clear(ms.balanceSnapshots[len(kept):])
+	ms.balanceSnapshots = kept
+	return removed, nil
+}
+
+func (ms *MemStore) AppendEvent(e Event) (Event, error) {
+	ms.eventsMu.Lock()
+	defer ms.eventsMu.Unlock()
+
+	ms.lastEventSeq++
+	e.Seq = ms.lastEventSeq
+	e.Payload = slices.Clone(e.Payload)
+	ms.events = append(ms.events, e)
+
+	// Drop the oldest events, reusing the backing array so that the log does not grow
+	if capacity := eventLogCapacity(ms.eventLogSize); uint64(len(ms.events)) > capacity {
+		n := copy(ms.events, ms.events[uint64(len(ms.events))-capacity:])
+		clear(ms.events[n:])
+		ms.events = ms.events[:n]
+	}
+	return e, nil
+}
+
+func (ms *MemStore) GetEvents(since, limit uint64) ([]Event, error) {
+	ms.eventsMu.RLock()
+	defer ms.eventsMu.RUnlock()
+
+	start := sort.Search(len(ms.events), func(i int) bool { return ms.events[i].Seq > since })
+	toReturn := []Event{}
+	for _, e := range ms.events[start:] {
+		if limit != 0 && uint64(len(toReturn)) == limit {
+			break
+		}
+		e.Payload = slices.Clone(e.Payload)
+		toReturn = append(toReturn, e)
+	}
+	return toReturn, nil
+}
+
+func (ms *MemStore) GetLastEventSeq() (uint64, error) {
+	ms.eventsMu.RLock()
+	defer ms.eventsMu.RUnlock()
+	return ms.lastEventSeq, nil
+}
+
+// ArchiveCompleted moves objectives which completed at least one retention period ago, and any finalized channels they own, to the archive.
+func (ms *MemStore) ArchiveCompleted(now time.Time) (int, error) {
+	if ms.retentionPeriod == 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-ms.retentionPeriod)
+
+	archived := 0
+	var err error
+	ms.completedAt.Range(func(id string, completedAt time.Time) bool {
+		if completedAt.After(cutoff) {
+			return true
+		}
+		err = ms.archiveObjective(protocols.ObjectiveId(id), completedAt, now)
+		if err != nil {
+			return false
+		}
+		archived++
+		return true
+	})
+	return archived, err
+}
+
+// archiveObjective moves the objective with the given id to the archive, along with the channel it owns if that channel is finalized.
+func (ms *MemStore) archiveObjective(id protocols.ObjectiveId, completedAt, now time.Time) error {
+	objJSON, ok := ms.objectives.Load(string(id))
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
+	}
+
+	record, channelId, err := newArchivedObjective(id, objJSON, completedAt, now)
+	if err != nil {
+		return err
+	}
+
+	ch, err := ms.getChannelById(channelId)
+	owner, isOwned := ms.channelToObjective.Load(channelId.String())
+	if err == nil && ch.FinalCompleted() && (!isOwned || owner == id) {
+		chJSON, _ := ms.channels.Load(channelId.String())
+		ms.archivedChannels.Store(channelId.String(), chJSON)
+		ms.channels.Delete(channelId.String())
+		ms.channelToObjective.Delete(channelId.String())
+		record.ArchivedChannel = channelId
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ms.archivedObjectives.Store(string(id), recordJSON)
+	ms.objectives.Delete(string(id))
+	ms.completedAt.Delete(string(id))
+	return nil
+}
+
+// GetArchivedObjectives returns every archived objective, ordered by completion time.
+func (ms *MemStore) GetArchivedObjectives() ([]ArchivedObjective, error) {
+	toReturn := []ArchivedObjective{}
+	var err error
+	ms.archivedObjectives.Range(func(key string, recordJSON []byte) bool {
+		var record ArchivedObjective
+		err = json.Unmarshal(recordJSON, &record)
+		if err != nil {
+			return false
+		}
+		toReturn = append(toReturn, record)
+		return true
+	})
+	if err != nil {
+		return []ArchivedObjective{}, err
+	}
+
+	sort.SliceStable(toReturn, func(i, j int) bool { return toReturn[i].CompletedAt.Before(toReturn[j].CompletedAt) })
+	return toReturn, nil
+}
+
+// GetArchivedObjective returns the archived objective with the given id.
+func (ms *MemStore) GetArchivedObjective(id protocols.ObjectiveId) (ArchivedObjective, error) {
+	recordJSON, ok := ms.archivedObjectives.Load(string(id))
+	if !ok {
+		return ArchivedObjective{}, fmt.Errorf("objective %s: %w", id, ErrNotArchived)
+	}
+
+	var record ArchivedObjective
+	err := json.Unmarshal(recordJSON, &record)
+	return record, err
+}
+
+// GetArchivedChannel returns the archived channel with the given id.
+func (ms *MemStore) GetArchivedChannel(id types.Destination) (*channel.Channel, error) {
+	chJSON, ok := ms.archivedChannels.Load(id.String())
+	if !ok {
+		return nil, fmt.Errorf("channel %s: %w", id, ErrNotArchived)
+	}
+
+	ch := &channel.Channel{}
+	err := ch.UnmarshalJSON(chJSON)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling channel %s", id)
+	}
+	return ch, nil
+}
+
 // contains is a helper function which returns true if the given item is included in col
 func contains[T types.Destination | protocols.ObjectiveId](col []T, item T) bool {
 	for _, i := range col {
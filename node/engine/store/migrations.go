@@ -0,0 +1,225 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+const (
+	// SchemaVersion is the version of the DurableStore data format written by this binary.
+	// It must be incremented whenever a change is made to the JSON encoding of any stored record
+	// (objectives, channels, consensus channels or voucher info), and a matching migration must be
+	// appended to the migrations slice.
+	SchemaVersion uint64 = 3
+
+	ErrSchemaTooNew   = types.ConstError("store: data folder was written by a newer version of go-nitro")
+	ErrMigrationFails = types.ConstError("store: could not migrate data folder")
+
+	schemaVersionKey = "schemaVersion"
+	// downsampledBeforeKey records the time before which balance snapshots have been downsampled
+	downsampledBeforeKey = "balanceSnapshotsDownsampledBefore"
+)
+
+// migration upgrades the records of a DurableStore from schema version Version-1 to Version.
+type migration struct {
+	Version     uint64
+	Description string
+	Migrate     func(ds *DurableStore) error
+}
+
+// migrations is the ordered list of every schema migration. The migration at index i upgrades
+// the store from version i to version i+1.
+var migrations = []migration{
+	{
+		Version:     1,
+		Description: "tag data folders created before schema versioning was introduced",
+		// The record formats are unchanged: version 1 is the format written by every release
+		// prior to the introduction of schema versioning.
+		Migrate: func(ds *DurableStore) error { return nil },
+	},
+	{
+		Version:     2,
+		Description: "record a completion time for objectives completed before archival was introduced",
+		Migrate: func(ds *DurableStore) error {
+			now := time.Now()
+			terminal := []protocols.ObjectiveId{}
+			var decodeErr error
+			err := ds.objectives.View(func(tx *buntdb.Tx) error {
+				return tx.Ascend("", func(key, objJSON string) bool {
+					obj, err := decodeObjective(protocols.ObjectiveId(key), []byte(objJSON))
+					if err != nil {
+						decodeErr = fmt.Errorf("error decoding objective %s: %w", key, err)
+						return false
+					}
+					if isTerminal(obj.GetStatus()) {
+						terminal = append(terminal, obj.Id())
+					}
+					return true
+				})
+			})
+			if err != nil {
+				return err
+			}
+			if decodeErr != nil {
+				return decodeErr
+			}
+
+			for _, id := range terminal {
+				if err := ds.setCompletedAt(id, now); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     3,
+		Description: "index balance snapshots by channel",
+		Migrate: func(ds *DurableStore) error {
+			keys := []string{}
+			err := ds.balanceSnapshots.View(func(tx *buntdb.Tx) error {
+				return tx.Ascend("", func(key, _ string) bool {
+					keys = append(keys, key)
+					return true
+				})
+			})
+			if err != nil {
+				return err
+			}
+			return ds.indexBalanceSnapshots(keys)
+		},
+	},
+}
+
+// getSchemaVersion returns the schema version recorded in the store.
+// A data folder with no recorded version is either new (in which case it is at SchemaVersion)
+// or was written before schema versioning was introduced (in which case it is at version 0).
+func (ds *DurableStore) getSchemaVersion() (uint64, error) {
+	var version uint64
+	err := ds.meta.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(schemaVersionKey)
+		if err != nil {
+			return err
+		}
+		version, err = strconv.ParseUint(val, 10, 64)
+		return err
+	})
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, buntdb.ErrNotFound) {
+		return 0, err
+	}
+
+	empty, err := ds.isEmpty()
+	if err != nil {
+		return 0, err
+	}
+	if empty {
+		return SchemaVersion, nil
+	}
+	return 0, nil
+}
+
+// setSchemaVersion records the given schema version in the store.
+func (ds *DurableStore) setSchemaVersion(version uint64) error {
+	return ds.meta.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(schemaVersionKey, strconv.FormatUint(version, 10), nil)
+		return err
+	})
+}
+
+// isEmpty returns true if none of the record databases contain any data.
+func (ds *DurableStore) isEmpty() (bool, error) {
+	for _, db := range []*buntdb.DB{ds.objectives, ds.channels, ds.consensusChannels, ds.channelToObjective, ds.vouchers, ds.lastBlockNumSeen, ds.completedAt, ds.archivedObjectives, ds.archivedChannels, ds.paymentHistory, ds.channelCreatedAt, ds.balanceSnapshots, ds.snapshotsByChannel} {
+		var n int
+		err := db.View(func(tx *buntdb.Tx) error {
+			var err error
+			n, err = tx.Len()
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// migrate upgrades the store to the given target version by running the supplied migrations in order.
+// It returns ErrSchemaTooNew if the store has been written with a more recent schema than target.
+func (ds *DurableStore) migrate(migrations []migration, target uint64) error {
+	current, err := ds.getSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if current > target {
+		return fmt.Errorf("%w: data folder %s is at schema version %d, but this binary only supports up to version %d", ErrSchemaTooNew, ds.folder, current, target)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current || m.Version > target {
+			continue
+		}
+		if m.Version != current+1 {
+			return fmt.Errorf("%w: no migration from schema version %d to %d", ErrMigrationFails, current, current+1)
+		}
+
+		slog.Info("Migrating durable store", "from", current, "to", m.Version, "description", m.Description)
+		if err := m.Migrate(ds); err != nil {
+			return fmt.Errorf("%w: migration to schema version %d failed: %w", ErrMigrationFails, m.Version, err)
+		}
+		if err := ds.setSchemaVersion(m.Version); err != nil {
+			return err
+		}
+		current = m.Version
+	}
+
+	if current != target {
+		return fmt.Errorf("%w: no migration from schema version %d to %d", ErrMigrationFails, current, current+1)
+	}
+
+	// Ensure that a fresh data folder is tagged with the version it was created with
+	return ds.setSchemaVersion(current)
+}
+
+// migrateRecords rewrites every record in db using the supplied transform function.
+// All records are rewritten in a single transaction, so either every record is migrated or none are.
+func migrateRecords(db *buntdb.DB, transform func(key, value string) (string, error)) error {
+	return db.Update(func(tx *buntdb.Tx) error {
+		updated := map[string]string{}
+		var transformErr error
+		err := tx.Ascend("", func(key, value string) bool {
+			newValue, err := transform(key, value)
+			if err != nil {
+				transformErr = fmt.Errorf("record %s: %w", key, err)
+				return false
+			}
+			updated[key] = newValue
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if transformErr != nil {
+			return transformErr
+		}
+
+		for key, value := range updated {
+			if _, _, err := tx.Set(key, value, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
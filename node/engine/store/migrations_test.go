@@ -0,0 +1,128 @@
+package store
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+var migrationTestKey = common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+func TestNewDurableStoreRecordsSchemaVersion(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	s, err := NewDurableStore(migrationTestKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	defer s.Close()
+
+	got, err := s.(*DurableStore).getSchemaVersion()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, SchemaVersion, got)
+}
+
+func TestMigrateLegacyData(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	s, err := NewDurableStore(migrationTestKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	ds := s.(*DurableStore)
+
+	// Simulate a data folder written before schema versioning was introduced
+	testhelpers.Ok(t, ds.SetLastBlockNumSeen(10))
+	testhelpers.Ok(t, ds.meta.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(schemaVersionKey)
+		return err
+	}))
+
+	version, err := ds.getSchemaVersion()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(0), version)
+
+	testMigrations := []migration{
+		migrations[0],
+		{
+			Version:     2,
+			Description: "prefix block numbers",
+			Migrate: func(ds *DurableStore) error {
+				return migrateRecords(ds.lastBlockNumSeen, func(key, value string) (string, error) {
+					return "block-" + value, nil
+				})
+			},
+		},
+	}
+	testhelpers.Ok(t, ds.migrate(testMigrations, 2))
+
+	version, err = ds.getSchemaVersion()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(2), version)
+
+	var got string
+	testhelpers.Ok(t, ds.lastBlockNumSeen.View(func(tx *buntdb.Tx) error {
+		got, err = tx.Get(lastBlockNumSeenKey)
+		return err
+	}))
+	testhelpers.Equals(t, "block-10", got)
+	testhelpers.Ok(t, ds.Close())
+}
+
+func TestRefuseNewerSchema(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	s, err := NewDurableStore(migrationTestKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	testhelpers.Ok(t, s.(*DurableStore).setSchemaVersion(SchemaVersion+1))
+	testhelpers.Ok(t, s.Close())
+
+	_, err = NewDurableStore(migrationTestKey, dataFolder, buntdb.Config{})
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "only supports up to version") {
+		t.Fatalf("expected a descriptive error, got %v", err)
+	}
+}
+
+func TestMissingMigration(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	s, err := NewDurableStore(migrationTestKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	defer s.Close()
+
+	err = s.(*DurableStore).migrate(migrations, SchemaVersion+1)
+	if !errors.Is(err, ErrMigrationFails) {
+		t.Fatalf("expected ErrMigrationFails, got %v", err)
+	}
+}
+
+func TestIndexBalanceSnapshotsMigration(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	s, err := NewDurableStore(migrationTestKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	ds := s.(*DurableStore)
+
+	// Simulate snapshots written before they were indexed by channel
+	channelId := types.Destination{1}
+	testhelpers.Ok(t, ds.AddBalanceSnapshot(BalanceSnapshot{ChannelId: channelId, Timestamp: time.Unix(100, 0), MyBalance: big.NewInt(1), TheirBalance: big.NewInt(2)}))
+	testhelpers.Ok(t, ds.snapshotsByChannel.Update(func(tx *buntdb.Tx) error { return tx.DeleteAll() }))
+	testhelpers.Ok(t, ds.setSchemaVersion(2))
+
+	testhelpers.Ok(t, ds.migrate(migrations, SchemaVersion))
+	got, err := ds.GetBalanceSnapshots(channelId, time.Time{}, time.Unix(200, 0))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, 1, len(got))
+	testhelpers.Ok(t, ds.Close())
+}
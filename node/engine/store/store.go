@@ -2,9 +2,13 @@
 package store // import "github.com/statechannels/go-nitro/node/engine/store"
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
+	"math/big"
 	"path/filepath"
+	"time"
 
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
@@ -19,6 +23,7 @@ const (
 	ErrNoSuchObjective  = types.ConstError("store: no such objective")
 	ErrNoSuchChannel    = types.ConstError("store: failed to find required channel data")
 	ErrLoadVouchers     = types.ConstError("store: could not load vouchers")
+	ErrNotArchived      = types.ConstError("store: no such archived record")
 	lastBlockNumSeenKey = "lastBlockNumSeen"
 )
 
@@ -38,25 +43,130 @@ type Store interface {
 	ReleaseChannelFromOwnership(types.Destination) error                         // Release channel from being owned by any objective
 	GetLastBlockNumSeen() (uint64, error)
 	SetLastBlockNumSeen(uint64) error
+	GetChannelCreatedAt(id types.Destination) (createdAt time.Time, ok bool) // Get the time at which the (consensus) channel was first stored
+	// RangeChannels calls f with each channel whose id is ordered after the given id (or with every channel if it is the zero id), in order of id, until f returns false
+	RangeChannels(after types.Destination, f func(*channel.Channel) bool) error
 
 	ConsensusChannelStore
+	ArchiveStore
+	BalanceSnapshotStore
+	EventStore
 	payments.VoucherStore
 	io.Closer
 }
 
 type ConsensusChannelStore interface {
 	GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error)
+	// RangeConsensusChannels calls f with each consensus channel whose id is ordered after the given id (or with every consensus channel if it is the zero id), in order of id, until f returns false
+	RangeConsensusChannels(after types.Destination, f func(*consensus_channel.ConsensusChannel) bool) error
 	GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool)
 	GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error)
 	SetConsensusChannel(*consensus_channel.ConsensusChannel) error
 	DestroyConsensusChannel(id types.Destination) error
 }
 
+// ArchiveStore moves completed objectives and finalized channels out of the live store, so that
+// the live store only grows with the number of active objectives and channels.
+type ArchiveStore interface {
+	// ArchiveCompleted archives every objective that completed (or was rejected) at least one retention period before now,
+	// along with the channel it owned if that channel has been finalized. It returns the number of archived objectives.
+	// If the store has no retention period, nothing is archived.
+	ArchiveCompleted(now time.Time) (int, error)
+	GetArchivedObjectives() ([]ArchivedObjective, error)                      // Returns every archived objective, ordered by completion time
+	GetArchivedObjective(id protocols.ObjectiveId) (ArchivedObjective, error) // Returns ErrNotArchived if the objective has not been archived
+	GetArchivedChannel(id types.Destination) (*channel.Channel, error)        // Returns ErrNotArchived if the channel has not been archived
+}
+
+// ArchivedObjective is a record of an objective that has been moved to the archive.
+type ArchivedObjective struct {
+	Id          protocols.ObjectiveId
+	Status      protocols.ObjectiveStatus
+	CompletedAt time.Time
+	ArchivedAt  time.Time
+	// ArchivedChannel is the id of the finalized channel archived alongside the objective, if any
+	ArchivedChannel types.Destination
+	// Objective is the serialized objective, as it was held in the live store
+	Objective json.RawMessage
+}
+
+// BalanceSnapshotStore records the balances of channels over time.
+// Snapshots older than BalanceSnapshotResolution are downsampled, so that only the last snapshot of each channel in each period is kept.
+type BalanceSnapshotStore interface {
+	AddBalanceSnapshot(s BalanceSnapshot) error
+	// GetBalanceSnapshots returns the snapshots of the given channel taken in [from, to), ordered by time
+	GetBalanceSnapshots(channelId types.Destination, from, to time.Time) ([]BalanceSnapshot, error)
+	// GetAllBalanceSnapshots returns the snapshots of every channel taken in [from, to), ordered by time
+	GetAllBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error)
+	// GetLatestBalanceSnapshots returns the last snapshot of each channel taken before the given time, ordered by time
+	GetLatestBalanceSnapshots(before time.Time) ([]BalanceSnapshot, error)
+	// DownsampleBalanceSnapshots keeps only the last snapshot of each channel in each period of BalanceSnapshotResolution
+	// that ended at least one period before now. It returns the number of snapshots removed.
+	DownsampleBalanceSnapshots(now time.Time) (int, error)
+}
+
+// BalanceSnapshotResolution is the period to which old balance snapshots are downsampled.
+const BalanceSnapshotResolution = time.Hour
+
+// downsamplingCutoff returns the time before which snapshots are downsampled: the start of the period before the one containing now
+func downsamplingCutoff(now time.Time) time.Time {
+	return now.Add(-BalanceSnapshotResolution).Truncate(BalanceSnapshotResolution)
+}
+
+// EventStore keeps a bounded log of the notifications sent to rpc clients, so that clients which miss notifications can replay them.
+// Once the log holds the store's event log size, the oldest event is dropped whenever an event is appended.
+type EventStore interface {
+	// AppendEvent assigns the event the next sequence number, starting from 1, appends it to the log and returns it
+	AppendEvent(e Event) (Event, error)
+	// GetEvents returns at most limit events with a sequence number greater than since, ordered by sequence number.
+	// A limit of 0 returns every such event.
+	GetEvents(since, limit uint64) ([]Event, error)
+	// GetLastEventSeq returns the sequence number of the last event appended, or 0 if no event has been appended
+	GetLastEventSeq() (uint64, error)
+}
+
+// Event is a notification sent to rpc clients.
+type Event struct {
+	Seq       uint64
+	Method    string
+	Timestamp time.Time
+	// Payload is the serialized payload of the notification
+	Payload json.RawMessage
+}
+
+// DefaultEventLogSize is the number of events kept by a store whose event log size is not set.
+const DefaultEventLogSize = 10_000
+
+type ChannelKind string
+
+const (
+	LedgerChannel  ChannelKind = "Ledger"
+	PaymentChannel ChannelKind = "Payment"
+)
+
+// BalanceSnapshot is a record of the balance of a channel at a point in time.
+type BalanceSnapshot struct {
+	ChannelId    types.Destination
+	Kind         ChannelKind
+	Timestamp    time.Time
+	Status       string
+	AssetAddress types.Address
+	// MyBalance is the amount of the asset held in the channel on our behalf.
+	// For payment channels this is the remaining funds if we are the payer, and the amount paid so far if we are the payee.
+	MyBalance *big.Int
+	// TheirBalance is the amount of the asset held in the channel on behalf of other participants.
+	TheirBalance *big.Int
+}
+
 type StoreOpts struct {
 	PkBytes            []byte
 	UseDurableStore    bool
 	DurableStoreFolder string
 	BuntDbConfig       buntdb.Config
+	// RetentionPeriod is how long completed objectives and finalized channels are kept in the live store before being archived.
+	// A zero value disables archival.
+	RetentionPeriod time.Duration
+	// EventLogSize is the number of events kept for clients to replay. A zero value keeps DefaultEventLogSize events.
+	EventLogSize uint64
 }
 
 func NewStore(options StoreOpts) (Store, error) {
@@ -76,10 +186,56 @@ func NewStore(options StoreOpts) (Store, error) {
 		if err != nil {
 			return nil, err
 		}
+		ourStore.(*DurableStore).retentionPeriod = options.RetentionPeriod
+		ourStore.(*DurableStore).eventLogSize = options.EventLogSize
 	} else {
 		slog.Info("Initialising mem store...")
 		ourStore = NewMemStore(options.PkBytes)
+		ourStore.(*MemStore).retentionPeriod = options.RetentionPeriod
+		ourStore.(*MemStore).eventLogSize = options.EventLogSize
+	}
+
+	return NewMeasuredStore(ourStore), nil
+}
+
+// isTerminal returns true if the objective will not make any further progress.
+func isTerminal(status protocols.ObjectiveStatus) bool {
+	return status == protocols.Completed || status == protocols.Rejected
+}
+
+// newArchivedObjective constructs an ArchivedObjective from the serialized objective.
+// It also returns the id of the channel owned by the objective.
+func newArchivedObjective(id protocols.ObjectiveId, objJSON []byte, completedAt, archivedAt time.Time) (ArchivedObjective, types.Destination, error) {
+	obj, err := decodeObjective(id, objJSON)
+	if err != nil {
+		return ArchivedObjective{}, types.Destination{}, fmt.Errorf("error decoding objective %s: %w", id, err)
+	}
+
+	return ArchivedObjective{
+		Id:          id,
+		Status:      obj.GetStatus(),
+		CompletedAt: completedAt,
+		ArchivedAt:  archivedAt,
+		Objective:   json.RawMessage(objJSON),
+	}, obj.OwnsChannel(), nil
+}
+
+// eventLogCapacity returns the number of events kept by a store with the given event log size
+func eventLogCapacity(size uint64) uint64 {
+	if size == 0 {
+		return DefaultEventLogSize
 	}
+	return size
+}
 
-	return ourStore, nil
+// clone returns a deep copy of the snapshot.
+func (s BalanceSnapshot) clone() BalanceSnapshot {
+	clone := s
+	if s.MyBalance != nil {
+		clone.MyBalance = new(big.Int).Set(s.MyBalance)
+	}
+	if s.TheirBalance != nil {
+		clone.TheirBalance = new(big.Int).Set(s.TheirBalance)
+	}
+	return clone
 }
@@ -1,9 +1,13 @@
 package store_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/go-cmp/cmp"
@@ -16,6 +20,7 @@ import (
 	td "github.com/statechannels/go-nitro/internal/testdata"
 	"github.com/statechannels/go-nitro/internal/testhelpers"
 	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
@@ -216,6 +221,37 @@ func TestGetChannelsByParticipant(t *testing.T) {
 	}
 }
 
+func TestRangeChannels(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	durableStore, err := store.NewDurableStore(sk, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	defer durableStore.Close()
+
+	for _, s := range []store.Store{store.NewMemStore(sk), durableStore} {
+		// Channels are not necessarily stored in order of id
+		for _, id := range []byte{3, 1, 4, 2} {
+			c := td.Objectives.Directfund.GenericDFO().C
+			c.Id = types.Destination{id}
+			testhelpers.Ok(t, s.SetChannel(c))
+		}
+
+		visit := func(after types.Destination, max int) []types.Destination {
+			visited := []types.Destination{}
+			testhelpers.Ok(t, s.RangeChannels(after, func(c *channel.Channel) bool {
+				visited = append(visited, c.Id)
+				return len(visited) < max
+			}))
+			return visited
+		}
+		testhelpers.Equals(t, []types.Destination{{1}, {2}, {3}, {4}}, visit(types.Destination{}, 10))
+		testhelpers.Equals(t, []types.Destination{{3}, {4}}, visit(types.Destination{2}, 10))
+		testhelpers.Equals(t, []types.Destination{{2}}, visit(types.Destination{1}, 1))
+	}
+}
+
 func TestGetLastBlockNumSeenMemStore(t *testing.T) {
 	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
 	ms := store.NewMemStore(sk)
@@ -304,3 +340,283 @@ func TestBigNumberStorage(t *testing.T) {
 		}
 	}
 }
+
+func TestArchiveCompleted(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	for _, useDurableStore := range []bool{false, true} {
+		s, err := store.NewStore(store.StoreOpts{
+			PkBytes:            pk,
+			UseDurableStore:    useDurableStore,
+			DurableStoreFolder: dataFolder,
+			RetentionPeriod:    time.Hour,
+		})
+		testhelpers.Ok(t, err)
+
+		completed := td.Objectives.Directfund.GenericDFO()
+		completed.Status = protocols.Completed
+		testhelpers.Ok(t, s.SetObjective(&completed))
+
+		active := td.Objectives.Virtualfund.GenericVFO()
+		testhelpers.Ok(t, s.SetObjective(&active))
+
+		// Nothing has been complete for longer than the retention period
+		archived, err := s.ArchiveCompleted(time.Now())
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 0, archived)
+
+		archived, err = s.ArchiveCompleted(time.Now().Add(2 * time.Hour))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, archived)
+
+		_, err = s.GetObjectiveById(completed.Id())
+		testhelpers.Assert(t, errors.Is(err, store.ErrNoSuchObjective), "expected the completed objective to be removed from the live store, got %v", err)
+		_, err = s.GetObjectiveById(active.Id())
+		testhelpers.Ok(t, err)
+
+		record, err := s.GetArchivedObjective(completed.Id())
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, protocols.Completed, record.Status)
+
+		history, err := s.GetArchivedObjectives()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, len(history))
+
+		// The channel was not finalized, so it is kept in the live store
+		_, ok := s.GetChannelById(completed.C.Id)
+		testhelpers.Assert(t, ok, "expected the unfinalized channel to remain in the live store")
+		_, err = s.GetArchivedChannel(completed.C.Id)
+		testhelpers.Assert(t, errors.Is(err, store.ErrNotArchived), "expected ErrNotArchived, got %v", err)
+
+		_, err = s.GetArchivedObjective(active.Id())
+		testhelpers.Assert(t, errors.Is(err, store.ErrNotArchived), "expected ErrNotArchived, got %v", err)
+
+		testhelpers.Ok(t, s.Close())
+	}
+}
+
+func TestPaymentHistory(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	defer durableStore.Close()
+
+	channelId := types.Destination{1}
+	otherChannelId := types.Destination{2}
+
+	for _, s := range []store.Store{store.NewMemStore(pk), durableStore} {
+		for i := int64(1); i <= 3; i++ {
+			testhelpers.Ok(t, s.AppendPaymentRecord(payments.PaymentRecord{
+				ChannelId: channelId,
+				Direction: payments.Received,
+				Delta:     big.NewInt(10),
+				Total:     big.NewInt(10 * i),
+			}))
+		}
+		// A record for the same voucher as the last one is not appended again
+		testhelpers.Ok(t, s.AppendPaymentRecord(payments.PaymentRecord{
+			ChannelId: channelId,
+			Direction: payments.Received,
+			Delta:     big.NewInt(10),
+			Total:     big.NewInt(30),
+		}))
+		testhelpers.Ok(t, s.AppendPaymentRecord(payments.PaymentRecord{ChannelId: otherChannelId, Delta: big.NewInt(5), Total: big.NewInt(5)}))
+
+		all, err := s.GetPaymentHistory(channelId, 0, 0)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 3, len(all))
+		for i, r := range all {
+			testhelpers.Equals(t, uint64(i), r.Index)
+			testhelpers.Equals(t, big.NewInt(10*int64(i+1)), r.Total)
+		}
+
+		page, err := s.GetPaymentHistory(channelId, 1, 1)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, all[1:2], page)
+
+		page, err = s.GetPaymentHistory(channelId, 3, 10)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 0, len(page))
+
+		other, err := s.GetPaymentHistory(otherChannelId, 0, 0)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, len(other))
+		testhelpers.Equals(t, uint64(0), other[0].Index)
+	}
+}
+
+func TestBalanceSnapshots(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	defer durableStore.Close()
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	channelId := types.Destination{1}
+	otherChannelId := types.Destination{2}
+	snapshot := func(id types.Destination, hours int, balance int64) store.BalanceSnapshot {
+		return store.BalanceSnapshot{
+			ChannelId:    id,
+			Kind:         store.LedgerChannel,
+			Timestamp:    start.Add(time.Duration(hours) * time.Hour),
+			Status:       "Open",
+			MyBalance:    big.NewInt(balance),
+			TheirBalance: big.NewInt(100 - balance),
+		}
+	}
+
+	for _, s := range []store.Store{store.NewMemStore(pk), durableStore} {
+		// Snapshots are not necessarily added in time order
+		testhelpers.Ok(t, s.AddBalanceSnapshot(snapshot(channelId, 2, 80)))
+		testhelpers.Ok(t, s.AddBalanceSnapshot(snapshot(channelId, 0, 100)))
+		testhelpers.Ok(t, s.AddBalanceSnapshot(snapshot(otherChannelId, 1, 50)))
+		testhelpers.Ok(t, s.AddBalanceSnapshot(snapshot(channelId, 3, 70)))
+
+		got, err := s.GetBalanceSnapshots(channelId, start, start.Add(3*time.Hour))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 2, len(got))
+		testhelpers.Equals(t, big.NewInt(100), got[0].MyBalance)
+		testhelpers.Equals(t, big.NewInt(80), got[1].MyBalance)
+
+		all, err := s.GetAllBalanceSnapshots(time.Time{}, start.Add(24*time.Hour))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 4, len(all))
+		testhelpers.Equals(t, otherChannelId, all[1].ChannelId)
+		for i := 1; i < len(all); i++ {
+			testhelpers.Assert(t, !all[i].Timestamp.Before(all[i-1].Timestamp), "expected snapshots to be ordered by time")
+		}
+	}
+}
+
+func TestDownsampleBalanceSnapshots(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	defer durableStore.Close()
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	channelId := types.Destination{1}
+	otherChannelId := types.Destination{2}
+	snapshot := func(id types.Destination, minutes int, balance int64) store.BalanceSnapshot {
+		return store.BalanceSnapshot{
+			ChannelId:    id,
+			Kind:         store.LedgerChannel,
+			Timestamp:    start.Add(time.Duration(minutes) * time.Minute),
+			Status:       "Open",
+			MyBalance:    big.NewInt(balance),
+			TheirBalance: big.NewInt(100 - balance),
+		}
+	}
+
+	for _, s := range []store.Store{store.NewMemStore(pk), durableStore} {
+		for _, sn := range []store.BalanceSnapshot{
+			snapshot(channelId, 10, 90),
+			snapshot(channelId, 20, 80),
+			snapshot(otherChannelId, 30, 50),
+			snapshot(channelId, 70, 70),
+			snapshot(channelId, 80, 60),
+			snapshot(channelId, 130, 50),
+			snapshot(channelId, 140, 40),
+		} {
+			testhelpers.Ok(t, s.AddBalanceSnapshot(sn))
+		}
+
+		latest, err := s.GetLatestBalanceSnapshots(start.Add(75 * time.Minute))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 2, len(latest))
+		testhelpers.Equals(t, otherChannelId, latest[0].ChannelId)
+		testhelpers.Equals(t, big.NewInt(70), latest[1].MyBalance)
+
+		// At 2:30, the first hour is downsampled. The second hour is not, as it ended less than an hour ago.
+		removed, err := s.DownsampleBalanceSnapshots(start.Add(150 * time.Minute))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, removed)
+		got, err := s.GetBalanceSnapshots(channelId, time.Time{}, start.Add(24*time.Hour))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 5, len(got))
+		testhelpers.Equals(t, big.NewInt(80), got[0].MyBalance)
+
+		removed, err = s.DownsampleBalanceSnapshots(start.Add(200 * time.Minute))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, removed)
+		all, err := s.GetAllBalanceSnapshots(time.Time{}, start.Add(24*time.Hour))
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 5, len(all))
+		testhelpers.Equals(t, []int64{80, 50, 60, 50, 40}, []int64{
+			all[0].MyBalance.Int64(), all[1].MyBalance.Int64(), all[2].MyBalance.Int64(), all[3].MyBalance.Int64(), all[4].MyBalance.Int64(),
+		})
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	for _, useDurableStore := range []bool{false, true} {
+		s, err := store.NewStore(store.StoreOpts{
+			PkBytes:            pk,
+			UseDurableStore:    useDurableStore,
+			DurableStoreFolder: dataFolder,
+			EventLogSize:       3,
+		})
+		testhelpers.Ok(t, err)
+
+		last, err := s.GetLastEventSeq()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, uint64(0), last)
+
+		for i := 1; i <= 5; i++ {
+			e, err := s.AppendEvent(store.Event{
+				Method:    "objective_completed",
+				Timestamp: time.Unix(int64(i), 0).UTC(),
+				Payload:   json.RawMessage(fmt.Sprintf(`"objective-%d"`, i)),
+			})
+			testhelpers.Ok(t, err)
+			testhelpers.Equals(t, uint64(i), e.Seq)
+		}
+
+		last, err = s.GetLastEventSeq()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, uint64(5), last)
+
+		// Only the last three events are kept
+		all, err := s.GetEvents(0, 0)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 3, len(all))
+		for i, e := range all {
+			testhelpers.Equals(t, uint64(i+3), e.Seq)
+			testhelpers.Equals(t, json.RawMessage(fmt.Sprintf(`"objective-%d"`, i+3)), e.Payload)
+			testhelpers.Equals(t, time.Unix(int64(i+3), 0).UTC(), e.Timestamp)
+		}
+
+		page, err := s.GetEvents(3, 1)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, all[1:2], page)
+
+		page, err = s.GetEvents(5, 0)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 0, len(page))
+
+		testhelpers.Ok(t, s.Close())
+	}
+
+	// Sequence numbers carry on from the last event of a durable store when it is reopened
+	s, err := store.NewStore(store.StoreOpts{PkBytes: pk, UseDurableStore: true, DurableStoreFolder: dataFolder, EventLogSize: 3})
+	testhelpers.Ok(t, err)
+	defer s.Close()
+	e, err := s.AppendEvent(store.Event{Method: "objective_completed"})
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(6), e.Seq)
+}
@@ -0,0 +1,213 @@
+// Package metrics records Prometheus metrics of a go-nitro node, and serves them on a /metrics endpoint.
+//
+// The metrics are held in a registry shared by every node in the process.
+package metrics // import "github.com/statechannels/go-nitro/node/metrics"
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// METRICS_PATH is the path that metrics are served on
+const METRICS_PATH = "/metrics"
+
+// Directions of messages and vouchers
+const (
+	Sent     = "sent"
+	Received = "received"
+)
+
+// Registry holds the metrics of the node, along with those of the go runtime and the process
+var Registry = prometheus.NewRegistry()
+
+var (
+	objectives = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Name:      "objectives_total",
+		Help:      "Number of objectives that reached each status, by objective type.",
+	}, []string{"type", "status"})
+
+	crankDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nitro",
+		Subsystem: "engine",
+		Name:      "crank_duration_seconds",
+		Help:      "Time taken by the engine to handle each event, by the kind of event.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"event"})
+
+	messages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Name:      "messages_total",
+		Help:      "Number of messages sent to and received from peers.",
+	}, []string{"direction"})
+
+	messageSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nitro",
+		Name:      "message_size_bytes",
+		Help:      "Size of the messages sent to and received from peers, as written to the stream.",
+		Buckets:   prometheus.ExponentialBuckets(256, 2, 12),
+	}, []string{"direction"})
+
+	chainEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Subsystem: "chain",
+		Name:      "events_total",
+		Help:      "Number of chain events processed by the engine, by event type.",
+	}, []string{"type"})
+
+	transactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Subsystem: "chain",
+		Name:      "transactions_total",
+		Help:      "Number of transactions submitted to the chain, by transaction type.",
+	}, []string{"type"})
+
+	transactionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Subsystem: "chain",
+		Name:      "transaction_failures_total",
+		Help:      "Number of transactions that could not be submitted to the chain, by transaction type.",
+	}, []string{"type"})
+
+	vouchers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Name:      "vouchers_total",
+		Help:      "Number of payment vouchers sent and received.",
+	}, []string{"direction"})
+
+	voucherAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nitro",
+		Name:      "voucher_amount_total",
+		Help:      "Amount paid by the payment vouchers sent and received.",
+	}, []string{"direction"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nitro",
+		Subsystem: "store",
+		Name:      "operation_duration_seconds",
+		Help:      "Time taken by each store operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.00005, 2, 14),
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		objectives, crankDuration, messages, messageSize, chainEvents, transactions, transactionFailures, vouchers, voucherAmount, storeDuration,
+	)
+}
+
+// objectiveType returns the type of an objective, which prefixes its id
+func objectiveType(id protocols.ObjectiveId) string {
+	objectiveType, _, _ := strings.Cut(string(id), "-")
+	return objectiveType
+}
+
+// typeName returns the name of the type of v, without its package
+func typeName(v any) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "unknown"
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// RecordObjective records that an objective reached a status
+func RecordObjective(id protocols.ObjectiveId, status protocols.ObjectiveStatus) {
+	var label string
+	switch status {
+	case protocols.Unapproved:
+		label = "unapproved"
+	case protocols.Approved:
+		label = "approved"
+	case protocols.Rejected:
+		label = "rejected"
+	case protocols.Completed:
+		label = "completed"
+	default:
+		label = "unknown"
+	}
+	objectives.WithLabelValues(objectiveType(id), label).Inc()
+}
+
+// RecordFailedObjective records an objective that could not be created
+func RecordFailedObjective(id protocols.ObjectiveId) {
+	objectives.WithLabelValues(objectiveType(id), "failed").Inc()
+}
+
+// ObserveCrank records how long the engine took to handle an event, since start
+func ObserveCrank(event string, start time.Time) {
+	crankDuration.WithLabelValues(event).Observe(time.Since(start).Seconds())
+}
+
+// RecordMessage records a message of size bytes sent to or received from a peer
+func RecordMessage(direction string, size int) {
+	messages.WithLabelValues(direction).Inc()
+	messageSize.WithLabelValues(direction).Observe(float64(size))
+}
+
+// RecordChainEvent records a chain event processed by the engine
+func RecordChainEvent(event any) {
+	chainEvents.WithLabelValues(typeName(event)).Inc()
+}
+
+// RecordTransaction records a transaction submitted to the chain, and whether its submission failed
+func RecordTransaction(tx any, err error) {
+	txType := typeName(tx)
+	transactions.WithLabelValues(txType).Inc()
+	if err != nil {
+		transactionFailures.WithLabelValues(txType).Inc()
+	}
+}
+
+// RecordVoucher records a voucher sent or received, paying amount
+func RecordVoucher(direction string, amount *big.Int) {
+	vouchers.WithLabelValues(direction).Inc()
+	if amount != nil {
+		paid, _ := new(big.Float).SetInt(amount).Float64()
+		voucherAmount.WithLabelValues(direction).Add(paid)
+	}
+}
+
+// ObserveStoreOperation records how long a store operation took, since start
+func ObserveStoreOperation(operation string, start time.Time) {
+	storeDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// Handler returns a handler that serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Serve listens on address and serves the metrics on METRICS_PATH, until the returned server is closed
+func Serve(address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(METRICS_PATH, Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	slog.Info("Serving metrics", "address", listener.Addr().String(), "path", METRICS_PATH)
+	return server, nil
+}
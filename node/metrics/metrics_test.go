@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordObjective(t *testing.T) {
+	before := testutil.ToFloat64(objectives.WithLabelValues("DirectFunding", "completed"))
+
+	RecordObjective("DirectFunding-0x1234", protocols.Completed)
+	RecordObjective("DirectFunding-0x5678", protocols.Completed)
+	RecordFailedObjective("VirtualFund-0x1234")
+
+	assert.Equal(t, before+2, testutil.ToFloat64(objectives.WithLabelValues("DirectFunding", "completed")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(objectives.WithLabelValues("VirtualFund", "failed")))
+}
+
+func TestRecordChainActivity(t *testing.T) {
+	RecordChainEvent(chainservice.DepositedEvent{})
+	RecordChainEvent(&chainservice.ConcludedEvent{})
+	RecordTransaction(protocols.DepositTransaction{}, nil)
+	RecordTransaction(protocols.ChallengeTransaction{}, io.EOF)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(chainEvents.WithLabelValues("DepositedEvent")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(chainEvents.WithLabelValues("ConcludedEvent")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(transactions.WithLabelValues("DepositTransaction")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(transactionFailures.WithLabelValues("DepositTransaction")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(transactionFailures.WithLabelValues("ChallengeTransaction")))
+}
+
+func TestRecordVoucher(t *testing.T) {
+	RecordVoucher(Received, big.NewInt(30))
+	RecordVoucher(Received, big.NewInt(12))
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(vouchers.WithLabelValues(Received)))
+	assert.Equal(t, float64(42), testutil.ToFloat64(voucherAmount.WithLabelValues(Received)))
+}
+
+func TestHandler(t *testing.T) {
+	RecordMessage(Sent, 500)
+	ObserveCrank("message", time.Now())
+	ObserveStoreOperation("SetObjective", time.Now())
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	for _, expected := range []string{
+		`nitro_messages_total{direction="sent"} 1`,
+		`nitro_message_size_bytes_count{direction="sent"} 1`,
+		`nitro_engine_crank_duration_seconds_count{event="message"} 1`,
+		`nitro_store_operation_duration_seconds_count{operation="SetObjective"} 1`,
+		"go_goroutines",
+	} {
+		assert.Contains(t, string(body), expected)
+	}
+}
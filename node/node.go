@@ -2,10 +2,13 @@
 package node // import "github.com/statechannels/go-nitro/node"
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/big"
 	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/statechannels/go-nitro/channel/state/outcome"
@@ -35,10 +38,13 @@ type Node struct {
 	completedObjectivesForRPC chan protocols.ObjectiveId // This is only used by the RPC server
 	completedObjectives       *safesync.Map[chan struct{}]
 	failedObjectives          chan protocols.ObjectiveId
-	receivedVouchers          chan payments.Voucher
+	receivedVouchers          chan payments.ReceivedVoucher
 	chainId                   *big.Int
 	store                     store.Store
 	vm                        *payments.VoucherManager
+
+	eventHandlersMu *sync.RWMutex
+	eventHandlers   []func(engine.EngineEvent)
 }
 
 // New is the constructor for a Node. It accepts a messaging service, a chain service, and a store as injected dependencies.
@@ -60,9 +66,10 @@ func New(messageService messageservice.MessageService, chainservice chainservice
 
 	n.failedObjectives = make(chan protocols.ObjectiveId, 100)
 	// Using a larger buffer since payments can be sent frequently.
-	n.receivedVouchers = make(chan payments.Voucher, 1000)
+	n.receivedVouchers = make(chan payments.ReceivedVoucher, 1000)
 
 	n.channelNotifier = notifier.NewChannelNotifier(store, n.vm)
+	n.eventHandlersMu = &sync.RWMutex{}
 
 	return n
 }
@@ -98,6 +105,12 @@ func (n *Node) handleEngineEvent(update engine.EngineEvent) {
 		err := n.channelNotifier.NotifyPaymentUpdated(updated)
 		n.handleError(err)
 	}
+
+	n.eventHandlersMu.RLock()
+	defer n.eventHandlersMu.RUnlock()
+	for _, handler := range n.eventHandlers {
+		handler(update)
+	}
 }
 
 // Begin API
@@ -161,8 +174,16 @@ func (n *Node) FailedObjectives() <-chan protocols.ObjectiveId {
 	return n.failedObjectives
 }
 
-// ReceivedVouchers returns a chan that receives a voucher every time we receive a payment voucher
-func (n *Node) ReceivedVouchers() <-chan payments.Voucher {
+// AddEngineEventHandler registers a handler that is called with every change the engine makes, such as completed objectives or received vouchers.
+// Unlike the node's chans, any number of handlers can be registered. Handlers are called on the engine's go-routine, so they must not block.
+func (n *Node) AddEngineEventHandler(handler func(engine.EngineEvent)) {
+	n.eventHandlersMu.Lock()
+	defer n.eventHandlersMu.Unlock()
+	n.eventHandlers = append(n.eventHandlers, handler)
+}
+
+// ReceivedVouchers returns a chan that receives the details of a voucher every time we receive a payment voucher
+func (n *Node) ReceivedVouchers() <-chan payments.ReceivedVoucher {
 	return n.receivedVouchers
 }
 
@@ -192,8 +213,8 @@ func (c *Node) ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummar
 }
 
 // CreatePaymentChannel creates a virtual channel with the counterParty using ledger channels
-// with the supplied intermediaries.
-func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+// with the supplied intermediaries. The objective continues the trace of ctx, if it carries one.
+func (n *Node) CreatePaymentChannel(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
 	objectiveRequest := virtualfund.NewObjectiveRequest(
 		Intermediaries,
 		CounterParty,
@@ -204,25 +225,27 @@ func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty
 	)
 
 	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	n.engine.ObjectiveRequestsFromAPI <- engine.ObjectiveRequest{ObjectiveRequest: objectiveRequest, Ctx: ctx}
 
 	objectiveRequest.WaitForObjectiveToStart()
 	return objectiveRequest.Response(*n.Address), nil
 }
 
 // ClosePaymentChannel attempts to close and defund the given virtually funded channel.
-func (n *Node) ClosePaymentChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+// The objective continues the trace of ctx, if it carries one.
+func (n *Node) ClosePaymentChannel(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
 	objectiveRequest := virtualdefund.NewObjectiveRequest(channelId)
 
 	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	n.engine.ObjectiveRequestsFromAPI <- engine.ObjectiveRequest{ObjectiveRequest: objectiveRequest, Ctx: ctx}
 	objectiveRequest.WaitForObjectiveToStart()
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
 // CreateLedgerChannel creates a directly funded ledger channel with the given counterparty.
 // The channel will run under full consensus rules (it is not possible to provide a custom AppDefinition or AppData).
-func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+// The objective continues the trace of ctx, if it carries one.
+func (n *Node) CreateLedgerChannel(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
 	objectiveRequest := directfund.NewObjectiveRequest(
 		Counterparty,
 		ChallengeDuration,
@@ -245,25 +268,30 @@ func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration
 	}
 
 	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	n.engine.ObjectiveRequestsFromAPI <- engine.ObjectiveRequest{ObjectiveRequest: objectiveRequest, Ctx: ctx}
 	objectiveRequest.WaitForObjectiveToStart()
 	return objectiveRequest.Response(*n.Address, n.chainId), nil
 }
 
 // CloseLedgerChannel attempts to close and defund the given directly funded channel.
-func (n *Node) CloseLedgerChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+// The objective continues the trace of ctx, if it carries one.
+func (n *Node) CloseLedgerChannel(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
 	objectiveRequest := directdefund.NewObjectiveRequest(channelId)
 
 	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	n.engine.ObjectiveRequestsFromAPI <- engine.ObjectiveRequest{ObjectiveRequest: objectiveRequest, Ctx: ctx}
 	objectiveRequest.WaitForObjectiveToStart()
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
 // Pay will send a signed voucher to the payee that they can redeem for the given amount.
-func (n *Node) Pay(channelId types.Destination, amount *big.Int) {
+// It returns once the voucher has been sent, or with an error if the payment could not be made.
+// The payment continues the trace of ctx, if it carries one.
+func (n *Node) Pay(ctx context.Context, channelId types.Destination, amount *big.Int) error {
+	result := make(chan error, 1)
 	// Send the event to the engine
-	n.engine.PaymentRequestsFromAPI <- engine.PaymentRequest{ChannelId: channelId, Amount: amount}
+	n.engine.PaymentRequestsFromAPI <- engine.PaymentRequest{ChannelId: channelId, Amount: amount, Ctx: ctx, Result: result}
+	return <-result
 }
 
 // GetPaymentChannel returns the payment channel with the given id.
@@ -272,6 +300,11 @@ func (n *Node) GetPaymentChannel(id types.Destination) (query.PaymentChannelInfo
 	return query.GetPaymentChannelInfo(id, n.store, n.vm)
 }
 
+// GetPaymentHistory returns a page of at most limit payments made on the given payment channel, starting at index offset.
+func (n *Node) GetPaymentHistory(id types.Destination, offset, limit uint64) (query.PaymentHistory, error) {
+	return query.GetPaymentHistory(id, n.vm, offset, limit)
+}
+
 // GetPaymentChannelsByLedger returns all active payment channels that are funded by the given ledger channel.
 func (n *Node) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
 	return query.GetPaymentChannelsByLedger(ledgerId, n.store, n.vm)
@@ -282,6 +315,42 @@ func (n *Node) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
 	return query.GetAllLedgerChannels(n.store, n.engine.GetConsensusAppAddress())
 }
 
+// GetLedgerChannels returns a page of at most limit ledger channels matching the filter, starting after the channel identified by cursor.
+func (n *Node) GetLedgerChannels(filter query.ChannelFilter, cursor string, limit uint64) (query.LedgerChannelsPage, error) {
+	return query.GetLedgerChannels(n.store, n.engine.GetConsensusAppAddress(), filter, cursor, limit)
+}
+
+// GetPaymentChannels returns a page of at most limit payment channels matching the filter, starting after the channel identified by cursor.
+func (n *Node) GetPaymentChannels(filter query.ChannelFilter, cursor string, limit uint64) (query.PaymentChannelsPage, error) {
+	return query.GetPaymentChannels(n.store, n.vm, n.engine.GetVirtualPaymentAppAddress(), filter, cursor, limit)
+}
+
+// GetBalanceHistory returns the balance snapshots of the given channel taken in [from, to), ordered by time.
+// A snapshot is taken whenever the channel is updated.
+func (n *Node) GetBalanceHistory(id types.Destination, from, to time.Time) ([]query.BalanceSnapshot, error) {
+	return query.GetBalanceHistory(n.store, id, from, to)
+}
+
+// GetAssetBalanceHistory returns the total balance of each asset held in the node's channels, at each interval after from up to and including to.
+func (n *Node) GetAssetBalanceHistory(from, to time.Time, interval time.Duration) ([]query.AssetBalance, error) {
+	return query.GetAssetBalanceHistory(n.store, from, to, interval)
+}
+
+// RecordEvent appends a notification sent to rpc clients to the node's event log, and returns it with its sequence number.
+// The log is bounded by the store's event log size, so that clients can replay recent notifications they missed.
+func (n *Node) RecordEvent(method string, payload json.RawMessage) (query.Event, error) {
+	e, err := n.store.AppendEvent(store.Event{Method: method, Timestamp: time.Now(), Payload: payload})
+	if err != nil {
+		return query.Event{}, err
+	}
+	return query.Event{Seq: e.Seq, Method: e.Method, Timestamp: e.Timestamp, Payload: e.Payload}, nil
+}
+
+// GetEvents returns a page of at most limit notifications sent to rpc clients after the notification with sequence number since.
+func (n *Node) GetEvents(since, limit uint64) (query.EventsPage, error) {
+	return query.GetEvents(n.store, since, limit)
+}
+
 // GetLastBlockNum returns last confirmed blockNum read from store
 func (n *Node) GetLastBlockNum() (uint64, error) {
 	return n.store.GetLastBlockNumSeen()
@@ -293,6 +362,18 @@ func (n *Node) GetLedgerChannel(id types.Destination) (query.LedgerChannelInfo,
 	return query.GetLedgerChannelInfo(id, n.store)
 }
 
+// GetObjectiveHistory returns every objective that has been archived, ordered by completion time.
+// Objectives are archived once they have been complete for longer than the store's retention period.
+func (n *Node) GetObjectiveHistory() ([]store.ArchivedObjective, error) {
+	return n.store.GetArchivedObjectives()
+}
+
+// GetArchivedObjective returns the archived objective with the given id.
+// If the objective has not been archived an error is returned.
+func (n *Node) GetArchivedObjective(id protocols.ObjectiveId) (store.ArchivedObjective, error) {
+	return n.store.GetArchivedObjective(id)
+}
+
 // Close stops the node from responding to any input.
 func (n *Node) Close() error {
 	if err := n.engine.Close(); err != nil {
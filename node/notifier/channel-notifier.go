@@ -1,6 +1,10 @@
 package notifier
 
 import (
+	"fmt"
+	"math/big"
+	"time"
+
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/query"
@@ -52,26 +56,60 @@ func (cn *ChannelNotifier) RegisterForPaymentChannelUpdates(cId types.Destinatio
 	return li.createNewListener()
 }
 
-// NotifyLedgerUpdated notifies all listeners of a ledger channel update.
+// NotifyLedgerUpdated notifies all listeners of a ledger channel update, and records a snapshot of the channel's balance.
 // It should be called whenever a ledger channel is updated.
 func (cn *ChannelNotifier) NotifyLedgerUpdated(info query.LedgerChannelInfo) error {
+	err := cn.store.AddBalanceSnapshot(store.BalanceSnapshot{
+		ChannelId:    info.ID,
+		Kind:         store.LedgerChannel,
+		Timestamp:    time.Now(),
+		Status:       string(info.Status),
+		AssetAddress: info.Balance.AssetAddress,
+		MyBalance:    new(big.Int).Set(info.Balance.MyBalance.ToInt()),
+		TheirBalance: new(big.Int).Set(info.Balance.TheirBalance.ToInt()),
+	})
+	if err != nil {
+		return fmt.Errorf("could not record balance snapshot for ledger channel %s: %w", info.ID, err)
+	}
+
 	li, _ := cn.ledgerListeners.LoadOrStore(info.ID.String(), newLedgerChannelListeners())
 	li.Notify(info)
 	allLi, _ := cn.ledgerListeners.LoadOrStore(ALL_NOTIFICATIONS, newLedgerChannelListeners())
 	allLi.Notify(info)
-
 	return nil
 }
 
-// NotifyPaymentUpdated notifies all listeners of a payment channel update.
+// NotifyPaymentUpdated notifies all listeners of a payment channel update, and records a snapshot of the channel's balance.
 // It should be called whenever a payment channel is updated.
 func (cn *ChannelNotifier) NotifyPaymentUpdated(info query.PaymentChannelInfo) error {
+	paid := info.Balance.PaidSoFar.ToInt()
+	remaining := info.Balance.RemainingFunds.ToInt()
+	mine, theirs := big.NewInt(0), new(big.Int).Add(paid, remaining)
+	switch *cn.store.GetAddress() {
+	case info.Balance.Payer:
+		mine, theirs = new(big.Int).Set(remaining), new(big.Int).Set(paid)
+	case info.Balance.Payee:
+		mine, theirs = new(big.Int).Set(paid), new(big.Int).Set(remaining)
+	}
+
+	err := cn.store.AddBalanceSnapshot(store.BalanceSnapshot{
+		ChannelId:    info.ID,
+		Kind:         store.PaymentChannel,
+		Timestamp:    time.Now(),
+		Status:       string(info.Status),
+		AssetAddress: info.Balance.AssetAddress,
+		MyBalance:    mine,
+		TheirBalance: theirs,
+	})
+	if err != nil {
+		return fmt.Errorf("could not record balance snapshot for payment channel %s: %w", info.ID, err)
+	}
+
 	li, _ := cn.paymentListeners.LoadOrStore(info.ID.String(), newPaymentChannelListeners())
 	li.Notify(info)
 
 	allLi, _ := cn.paymentListeners.LoadOrStore(ALL_NOTIFICATIONS, newPaymentChannelListeners())
 	allLi.Notify(info)
-
 	return nil
 }
 
@@ -1,9 +1,13 @@
 package query
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
+	"sort"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/channel"
@@ -125,12 +129,12 @@ func GetVoucherBalance(id types.Destination, vm *payments.VoucherManager) (paid,
 
 // GetPaymentChannelInfo returns the PaymentChannelInfo for the given channel
 // It does this by querying the provided store and voucher manager
-func GetPaymentChannelInfo(id types.Destination, store store.Store, vm *payments.VoucherManager) (PaymentChannelInfo, error) {
+func GetPaymentChannelInfo(id types.Destination, s store.Store, vm *payments.VoucherManager) (PaymentChannelInfo, error) {
 	if (id == types.Destination{}) {
 		return PaymentChannelInfo{}, errors.New("a valid channel id must be provided")
 	}
 	// Otherwise we can just check the store
-	c, channelFound := store.GetChannelById(id)
+	c, channelFound := s.GetChannelById(id)
 
 	if channelFound {
 		paid, remaining, err := GetVoucherBalance(id, vm)
@@ -140,7 +144,85 @@ func GetPaymentChannelInfo(id types.Destination, store store.Store, vm *payments
 
 		return ConstructPaymentInfo(c, paid, remaining)
 	}
-	return PaymentChannelInfo{}, fmt.Errorf("could not find channel with id %v", id)
+
+	// The channel may have been finalized and moved to the archive
+	c, err := s.GetArchivedChannel(id)
+	if err == nil {
+		paid, remaining, err := GetVoucherBalance(id, vm)
+		if err != nil {
+			return PaymentChannelInfo{}, err
+		}
+		return ConstructPaymentInfo(c, paid, remaining)
+	}
+	return PaymentChannelInfo{}, fmt.Errorf("could not find channel with id %v: %w", id, store.ErrNoSuchChannel)
+}
+
+// MaxPaymentHistoryPageSize is the largest number of payment records returned by a single GetPaymentHistory call
+const MaxPaymentHistoryPageSize = 1000
+
+// GetPaymentHistory returns a page of at most limit payment records for the given channel, starting at index offset.
+// A limit of 0 (or a limit larger than MaxPaymentHistoryPageSize) returns a page of MaxPaymentHistoryPageSize records.
+func GetPaymentHistory(id types.Destination, vm *payments.VoucherManager, offset, limit uint64) (PaymentHistory, error) {
+	if (id == types.Destination{}) {
+		return PaymentHistory{}, errors.New("a valid channel id must be provided")
+	}
+	if limit == 0 || limit > MaxPaymentHistoryPageSize {
+		limit = MaxPaymentHistoryPageSize
+	}
+
+	// Fetch one more record than requested to find out if there is another page
+	records, err := vm.PaymentHistory(id, offset, limit+1)
+	if err != nil {
+		return PaymentHistory{}, fmt.Errorf("could not get payment history for channel %s: %w", id, err)
+	}
+
+	hasMore := uint64(len(records)) > limit
+	if hasMore {
+		records = records[:limit]
+	}
+	return PaymentHistory{
+		ID:         id,
+		Payments:   records,
+		NextOffset: offset + uint64(len(records)),
+		HasMore:    hasMore,
+	}, nil
+}
+
+// MaxEventsPageSize is the largest number of events returned by a single GetEvents call
+const MaxEventsPageSize = 1000
+
+// GetEvents returns a page of at most limit events with a sequence number greater than since, ordered by sequence number.
+// A limit of 0 (or a limit larger than MaxEventsPageSize) returns a page of MaxEventsPageSize events.
+func GetEvents(s store.Store, since, limit uint64) (EventsPage, error) {
+	if limit == 0 || limit > MaxEventsPageSize {
+		limit = MaxEventsPageSize
+	}
+
+	// The last sequence number is read first, so that any event appended while the page is read is left for the next page
+	last, err := s.GetLastEventSeq()
+	if err != nil {
+		return EventsPage{}, fmt.Errorf("could not get last event: %w", err)
+	}
+	if since >= last {
+		return EventsPage{Events: []Event{}, LastSeq: last}, nil
+	}
+
+	// Fetch one more event than requested to find out if there is another page
+	events, err := s.GetEvents(since, limit+1)
+	if err != nil {
+		return EventsPage{}, fmt.Errorf("could not get events since %d: %w", since, err)
+	}
+
+	page := EventsPage{Events: []Event{}, LastSeq: last}
+	page.Missed = len(events) == 0 || events[0].Seq > since+1
+	for _, e := range events {
+		if e.Seq > last || uint64(len(page.Events)) == limit {
+			page.HasMore = e.Seq <= last
+			break
+		}
+		page.Events = append(page.Events, Event{Seq: e.Seq, Method: e.Method, Timestamp: e.Timestamp, Payload: e.Payload})
+	}
+	return page, nil
 }
 
 // GetAllLedgerChannels returns a `LedgerChannelInfo` for each ledger channel in the store.
@@ -182,6 +264,270 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 	return toReturn, err
 }
 
+// MaxChannelPageSize is the largest number of channels returned by a single GetLedgerChannels or GetPaymentChannels call
+const MaxChannelPageSize = 1000
+
+// GetLedgerChannels returns a page of at most limit ledger channels matching the filter, ordered by channel id.
+// The page starts after the channel identified by cursor, or at the first channel if cursor is empty.
+// A limit of 0 (or a limit larger than MaxChannelPageSize) returns a page of MaxChannelPageSize channels.
+// Channels are read from the store in order of id, starting at the cursor, and only until the page is full.
+func GetLedgerChannels(s store.Store, consensusAppDefinition types.Address, filter ChannelFilter, cursor string, limit uint64) (LedgerChannelsPage, error) {
+	after, err := parseCursor(cursor)
+	if err != nil {
+		return LedgerChannelsPage{}, err
+	}
+	myAddress := *s.GetAddress()
+	limit = pageSize(limit)
+
+	// Ledger channels are stored either as consensus channels or as channels, each in order of id.
+	// The first limit+1 matches of each are enough to find the first limit+1 matches of both.
+	matches := func(info LedgerChannelInfo) bool {
+		b := info.Balance
+		return filter.matches(s, info.ID, info.Status, b.AssetAddress, []types.Address{b.Them}, b.MyBalance)
+	}
+	fromConsensus := []LedgerChannelInfo{}
+	var constructErr error
+	err = s.RangeConsensusChannels(after, func(con *consensus_channel.ConsensusChannel) bool {
+		info, err := ConstructLedgerInfoFromConsensus(con, myAddress)
+		if err != nil {
+			constructErr = err
+			return false
+		}
+		if matches(info) {
+			fromConsensus = append(fromConsensus, info)
+		}
+		return uint64(len(fromConsensus)) <= limit
+	})
+	if err != nil {
+		return LedgerChannelsPage{}, err
+	}
+	if constructErr != nil {
+		return LedgerChannelsPage{}, constructErr
+	}
+	fromChannels := []LedgerChannelInfo{}
+	err = s.RangeChannels(after, func(c *channel.Channel) bool {
+		if c.AppDefinition != consensusAppDefinition {
+			return true
+		}
+		info, err := ConstructLedgerInfoFromChannel(c, myAddress)
+		if err != nil {
+			constructErr = err
+			return false
+		}
+		if matches(info) {
+			fromChannels = append(fromChannels, info)
+		}
+		return uint64(len(fromChannels)) <= limit
+	})
+	if err != nil {
+		return LedgerChannelsPage{}, err
+	}
+	if constructErr != nil {
+		return LedgerChannelsPage{}, constructErr
+	}
+
+	candidates := append(fromConsensus, fromChannels...)
+	sort.Slice(candidates, func(i, j int) bool { return bytes.Compare(candidates[i].ID[:], candidates[j].ID[:]) < 0 })
+	page := LedgerChannelsPage{Channels: candidates}
+	if uint64(len(candidates)) > limit {
+		page.Channels = candidates[:limit]
+		page.NextCursor = page.Channels[limit-1].ID.String()
+	}
+	return page, nil
+}
+
+// GetPaymentChannels returns a page of at most limit payment channels matching the filter, ordered by channel id.
+// The page starts after the channel identified by cursor, or at the first channel if cursor is empty.
+// A limit of 0 (or a limit larger than MaxChannelPageSize) returns a page of MaxChannelPageSize channels.
+// Channels are read from the store in order of id, starting at the cursor, and only until the page is full.
+func GetPaymentChannels(s store.Store, vm *payments.VoucherManager, paymentAppDefinition types.Address, filter ChannelFilter, cursor string, limit uint64) (PaymentChannelsPage, error) {
+	after, err := parseCursor(cursor)
+	if err != nil {
+		return PaymentChannelsPage{}, err
+	}
+	myAddress := *s.GetAddress()
+
+	page := PaymentChannelsPage{Channels: []PaymentChannelInfo{}}
+	limit = pageSize(limit)
+	var constructErr error
+	err = s.RangeChannels(after, func(c *channel.Channel) bool {
+		if c.AppDefinition != paymentAppDefinition {
+			return true
+		}
+		paid, remaining, err := GetVoucherBalance(c.Id, vm)
+		if err != nil {
+			constructErr = err
+			return false
+		}
+		info, err := ConstructPaymentInfo(c, paid, remaining)
+		if err != nil {
+			constructErr = err
+			return false
+		}
+
+		counterparties := []types.Address{}
+		for _, p := range c.Participants {
+			if p != myAddress {
+				counterparties = append(counterparties, p)
+			}
+		}
+		if !filter.matches(s, info.ID, info.Status, info.Balance.AssetAddress, counterparties, info.Balance.RemainingFunds) {
+			return true
+		}
+		if uint64(len(page.Channels)) == limit {
+			page.NextCursor = page.Channels[limit-1].ID.String()
+			return false
+		}
+		page.Channels = append(page.Channels, info)
+		return true
+	})
+	if err != nil {
+		return PaymentChannelsPage{}, err
+	}
+	if constructErr != nil {
+		return PaymentChannelsPage{}, constructErr
+	}
+	return page, nil
+}
+
+// matches returns true if a channel with the given properties passes the filter.
+func (f ChannelFilter) matches(s store.Store, id types.Destination, status ChannelStatus, asset types.Address, counterparties []types.Address, balance *hexutil.Big) bool {
+	if f.Status != "" && f.Status != status {
+		return false
+	}
+	if f.AssetAddress != nil && *f.AssetAddress != asset {
+		return false
+	}
+	if f.MinBalance != nil && (balance == nil || balance.ToInt().Cmp(f.MinBalance.ToInt()) < 0) {
+		return false
+	}
+	if (f.Counterparty != types.Address{}) && !slices.Contains(counterparties, f.Counterparty) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() {
+		// Channels stored before creation times were recorded have an unknown creation time, so are not excluded
+		if createdAt, ok := s.GetChannelCreatedAt(id); ok && !createdAt.After(f.CreatedAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCursor parses a pagination cursor, which is the id of the last channel of the previous page.
+func parseCursor(cursor string) (types.Destination, error) {
+	if cursor == "" {
+		return types.Destination{}, nil
+	}
+	b, err := hexutil.Decode(cursor)
+	if err != nil || len(b) != len(types.Destination{}) {
+		return types.Destination{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return types.Destination(b), nil
+}
+
+// pageSize returns the number of channels to return for the requested limit.
+func pageSize(limit uint64) uint64 {
+	if limit == 0 || limit > MaxChannelPageSize {
+		return MaxChannelPageSize
+	}
+	return limit
+}
+
+// MaxAssetBalancePoints is the largest number of points in time returned by a single GetAssetBalanceHistory call
+const MaxAssetBalancePoints = 10_000
+
+// GetBalanceHistory returns the balance snapshots of the given channel taken in [from, to), ordered by time.
+func GetBalanceHistory(s store.Store, id types.Destination, from, to time.Time) ([]BalanceSnapshot, error) {
+	snapshots, err := s.GetBalanceSnapshots(id, from, to)
+	if err != nil {
+		return []BalanceSnapshot{}, fmt.Errorf("could not get balance snapshots for channel %s: %w", id, err)
+	}
+
+	toReturn := make([]BalanceSnapshot, len(snapshots))
+	for i, snapshot := range snapshots {
+		toReturn[i] = BalanceSnapshot{
+			ChannelId:    snapshot.ChannelId,
+			Kind:         string(snapshot.Kind),
+			Timestamp:    snapshot.Timestamp,
+			Status:       ChannelStatus(snapshot.Status),
+			AssetAddress: snapshot.AssetAddress,
+			MyBalance:    (*hexutil.Big)(snapshot.MyBalance),
+			TheirBalance: (*hexutil.Big)(snapshot.TheirBalance),
+		}
+	}
+	return toReturn, nil
+}
+
+// GetAssetBalanceHistory returns the total balance of each asset held in our channels at regular intervals.
+// A point is returned for each asset at from+interval, from+2*interval, ..., up to and including to.
+// Each point reflects the latest snapshot of every channel taken at or before that time. Completed channels are excluded.
+// An interval of 0 returns the balances at to.
+func GetAssetBalanceHistory(s store.Store, from, to time.Time, interval time.Duration) ([]AssetBalance, error) {
+	if to.Before(from) {
+		return []AssetBalance{}, fmt.Errorf("the end of the period (%s) is before its start (%s)", to, from)
+	}
+	points := []time.Time{to}
+	if interval > 0 {
+		if to.Sub(from)/interval > MaxAssetBalancePoints {
+			return []AssetBalance{}, fmt.Errorf("requested more than %d points", MaxAssetBalancePoints)
+		}
+		points = []time.Time{}
+		for t := from.Add(interval); !t.After(to); t = t.Add(interval) {
+			points = append(points, t)
+		}
+	}
+
+	// The balances at the start of the period are those of the last snapshot of each channel taken before it
+	initial, err := s.GetLatestBalanceSnapshots(from)
+	if err != nil {
+		return []AssetBalance{}, fmt.Errorf("could not get balance snapshots: %w", err)
+	}
+	snapshots, err := s.GetAllBalanceSnapshots(from, to.Add(time.Nanosecond))
+	if err != nil {
+		return []AssetBalance{}, fmt.Errorf("could not get balance snapshots: %w", err)
+	}
+
+	toReturn := []AssetBalance{}
+	latest := map[types.Destination]store.BalanceSnapshot{}
+	for _, snapshot := range initial {
+		latest[snapshot.ChannelId] = snapshot
+	}
+	next := 0
+	for _, point := range points {
+		for ; next < len(snapshots) && !snapshots[next].Timestamp.After(point); next++ {
+			latest[snapshots[next].ChannelId] = snapshots[next]
+		}
+
+		byAsset := map[types.Address]*AssetBalance{}
+		for _, snapshot := range latest {
+			if ChannelStatus(snapshot.Status) == Complete {
+				continue
+			}
+			total, ok := byAsset[snapshot.AssetAddress]
+			if !ok {
+				total = &AssetBalance{
+					Timestamp:    point,
+					AssetAddress: snapshot.AssetAddress,
+					MyBalance:    (*hexutil.Big)(big.NewInt(0)),
+					TheirBalance: (*hexutil.Big)(big.NewInt(0)),
+				}
+				byAsset[snapshot.AssetAddress] = total
+			}
+			total.MyBalance.ToInt().Add(total.MyBalance.ToInt(), snapshot.MyBalance)
+			total.TheirBalance.ToInt().Add(total.TheirBalance.ToInt(), snapshot.TheirBalance)
+			total.Channels++
+		}
+
+		assets := make([]AssetBalance, 0, len(byAsset))
+		for _, total := range byAsset {
+			assets = append(assets, *total)
+		}
+		sort.Slice(assets, func(i, j int) bool { return bytes.Compare(assets[i].AssetAddress[:], assets[j].AssetAddress[:]) < 0 })
+		toReturn = append(toReturn, assets...)
+	}
+	return toReturn, nil
+}
+
 // GetPaymentChannelsByLedger returns a `PaymentChannelInfo` for each active payment channel funded by the given ledger channel.
 func GetPaymentChannelsByLedger(ledgerId types.Destination, s store.Store, vm *payments.VoucherManager) ([]PaymentChannelInfo, error) {
 	// If a ledger channel is actively funding payment channels it must be in the form of a consensus channel
@@ -228,11 +574,16 @@ func GetLedgerChannelInfo(id types.Destination, store store.Store) (LedgerChanne
 	}
 
 	con, err := store.GetConsensusChannelById(id)
-	if err != nil {
-		return LedgerChannelInfo{}, err
+	if err == nil {
+		return ConstructLedgerInfoFromConsensus(con, myAddress)
 	}
 
-	return ConstructLedgerInfoFromConsensus(con, myAddress)
+	// The channel may have been finalized and moved to the archive
+	c, archiveErr := store.GetArchivedChannel(id)
+	if archiveErr == nil {
+		return ConstructLedgerInfoFromChannel(c, myAddress)
+	}
+	return LedgerChannelInfo{}, err
 }
 
 func ConstructLedgerInfoFromConsensus(con *consensus_channel.ConsensusChannel, myAddress types.Address) (LedgerChannelInfo, error) {
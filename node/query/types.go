@@ -1,7 +1,11 @@
 package query
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -31,6 +35,66 @@ type PaymentChannelInfo struct {
 	Balance PaymentChannelBalance
 }
 
+// PaymentHistory is a page of the payment history of a payment channel
+type PaymentHistory struct {
+	ID       types.Destination
+	Payments []payments.PaymentRecord
+	// NextOffset is the offset at which the next page of the history starts
+	NextOffset uint64
+	// HasMore is true if there are further records after this page
+	HasMore bool
+}
+
+// ChannelFilter restricts the channels returned by a channel query. Zero-valued fields match every channel.
+type ChannelFilter struct {
+	Status ChannelStatus
+	// Counterparty matches channels in which the address is a participant (other than us)
+	Counterparty types.Address
+	// AssetAddress matches channels holding the asset. It is a pointer since the zero address is the native asset.
+	AssetAddress *types.Address
+	// MinBalance matches ledger channels where our balance is at least MinBalance,
+	// and payment channels where the remaining funds are at least MinBalance
+	MinBalance *hexutil.Big
+	// CreatedAfter matches channels which were first stored after the given time.
+	// Channels stored before creation times were recorded have an unknown creation time, and always match.
+	CreatedAfter time.Time
+}
+
+// LedgerChannelsPage is a page of ledger channels, ordered by channel id
+type LedgerChannelsPage struct {
+	Channels []LedgerChannelInfo
+	// NextCursor is the cursor for the next page, or empty if this is the last page
+	NextCursor string
+}
+
+// PaymentChannelsPage is a page of payment channels, ordered by channel id
+type PaymentChannelsPage struct {
+	Channels []PaymentChannelInfo
+	// NextCursor is the cursor for the next page, or empty if this is the last page
+	NextCursor string
+}
+
+// BalanceSnapshot is the balance of a channel at a point in time
+type BalanceSnapshot struct {
+	ChannelId    types.Destination
+	Kind         string // Ledger or Payment
+	Timestamp    time.Time
+	Status       ChannelStatus
+	AssetAddress types.Address
+	MyBalance    *hexutil.Big
+	TheirBalance *hexutil.Big
+}
+
+// AssetBalance is the total balance of an asset across all of our unfinished channels at a point in time
+type AssetBalance struct {
+	Timestamp    time.Time
+	AssetAddress types.Address
+	MyBalance    *hexutil.Big
+	TheirBalance *hexutil.Big
+	// Channels is the number of channels holding the asset
+	Channels uint
+}
+
 // LedgerChannelInfo contains balance and status info about a ledger channel
 type LedgerChannelInfo struct {
 	ID      types.Destination
@@ -74,3 +138,23 @@ func (pcb PaymentChannelBalance) Equal(other PaymentChannelBalance) bool {
 		pcb.PaidSoFar.ToInt().Cmp(other.PaidSoFar.ToInt()) == 0 &&
 		pcb.RemainingFunds.ToInt().Cmp(other.RemainingFunds.ToInt()) == 0
 }
+
+// Event is a notification sent to rpc clients
+type Event struct {
+	// Seq is the sequence number of the event. Events are numbered in the order they were sent, starting from 1.
+	Seq       uint64
+	Method    string
+	Timestamp time.Time
+	Payload   json.RawMessage
+}
+
+// EventsPage is a page of the notifications sent to rpc clients
+type EventsPage struct {
+	Events []Event
+	// LastSeq is the sequence number of the last event sent so far
+	LastSeq uint64
+	// HasMore is true if there are further events after this page
+	HasMore bool
+	// Missed is true if events after the requested sequence number have been dropped from the node's bounded event log, and so cannot be replayed
+	Missed bool
+}
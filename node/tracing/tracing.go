@@ -0,0 +1,82 @@
+// Package tracing traces the operations of a go-nitro node with OpenTelemetry.
+//
+// Until Setup is called, the tracer and propagator are no-ops, so tracing costs nothing unless it is enabled.
+// The trace context of an operation is propagated from the rpc request that started it, through the engine,
+// to the messages sent to counterparties, whose engines continue the trace.
+package tracing // import "github.com/statechannels/go-nitro/node/tracing"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SERVICE_NAME is the name of the service that the spans of a node are reported for
+const SERVICE_NAME = "go-nitro"
+
+const tracerName = "github.com/statechannels/go-nitro"
+
+// Tracer returns the tracer that spans of the node are started with
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Setup exports the spans of the node to the OTLP collector listening for gRPC at endpoint, in the form 'host:port'.
+// Spans are reported for the SERVICE_NAME service, with instanceId identifying the node.
+// It returns a function that exports any spans that are yet to be exported and stops exporting them.
+func Setup(ctx context.Context, endpoint string, instanceId string) (shutdown func(context.Context) error, err error) {
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", SERVICE_NAME),
+			attribute.String("service.instance.id", instanceId),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Inject returns the trace context of ctx as a map of W3C trace context fields, or nil if ctx carries no trace context
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns a copy of ctx carrying the trace context in carrier, a map of W3C trace context fields as returned by Inject
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// Fields returns the names of the fields of the trace context that Inject returns
+func Fields() []string {
+	return otel.GetTextMapPropagator().Fields()
+}
+
+// End records err on the span, if it is not nil, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// record traces spans to a recorder, rather than exporting them, until the test ends
+func record(t *testing.T) *tracetest.SpanRecorder {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func TestInjectWithoutTracing(t *testing.T) {
+	_, span := Tracer().Start(context.Background(), "untraced")
+	defer span.End()
+
+	assert.Nil(t, Inject(trace.ContextWithSpan(context.Background(), span)))
+	assert.Equal(t, context.Background(), Extract(context.Background(), nil))
+}
+
+func TestInjectExtract(t *testing.T) {
+	recorder := record(t)
+
+	ctx, parent := Tracer().Start(context.Background(), "rpc.create_ledger_channel")
+	carrier := Inject(ctx)
+	require.Contains(t, carrier, "traceparent")
+	parent.End()
+
+	// The counterparty continues the trace from the carrier alone
+	_, child := Tracer().Start(Extract(context.Background(), carrier), "engine.handleMessage")
+	End(child, errors.New("could not handle message"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.True(t, spans[1].Parent().IsRemote())
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "could not handle message", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
+
+func TestFields(t *testing.T) {
+	record(t)
+
+	assert.Equal(t, []string{"traceparent", "tracestate"}, Fields())
+}
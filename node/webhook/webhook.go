@@ -0,0 +1,344 @@
+// Package webhook delivers the events of a nitro node to webhook endpoints, as signed JSON payloads POSTed over http.
+// It lets backends that cannot hold an rpc connection open, such as serverless functions, react to the node's events.
+package webhook // import "github.com/statechannels/go-nitro/node/webhook"
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// EventType is the type of an event delivered to webhooks
+type EventType string
+
+const (
+	ObjectiveCompleted    EventType = "objective_completed"
+	ObjectiveFailed       EventType = "objective_failed"
+	LedgerChannelUpdated  EventType = "ledger_channel_updated"
+	PaymentChannelUpdated EventType = "payment_channel_updated"
+	VoucherReceived       EventType = "voucher_received"
+	ChallengeRegistered   EventType = "challenge_registered"
+)
+
+const (
+	// SignatureHeader carries the signature of a delivery, as "t=<unix timestamp>,v1=<hex encoded HMAC-SHA256>".
+	// The HMAC is computed with the shared secret over the timestamp, a '.', and the body of the request.
+	SignatureHeader = "Nitro-Signature"
+	// IdHeader carries the id of the event, which is the same for every attempt to deliver it, so that receivers can ignore duplicates
+	IdHeader = "Nitro-Webhook-Id"
+	// EventTypeHeader carries the type of the event
+	EventTypeHeader = "Nitro-Event-Type"
+)
+
+// Defaults used for the zero values of a Config
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultTimeout        = 10 * time.Second
+	DefaultQueueSize      = 1000
+)
+
+// Event is the JSON body POSTed to webhooks
+type Event struct {
+	Id        string
+	Type      EventType
+	Timestamp time.Time
+	Data      any
+}
+
+// ObjectiveEvent is the data of objective_completed and objective_failed events
+type ObjectiveEvent struct {
+	ObjectiveId protocols.ObjectiveId
+}
+
+// ChallengeEvent is the data of challenge_registered events
+type ChallengeEvent struct {
+	ChannelId types.Destination
+	// TurnNum is the turn number of the challenged state
+	TurnNum uint64
+	// BlockNum is the number of the block in which the challenge was registered
+	BlockNum uint64
+}
+
+// Config configures the delivery of events to webhooks
+type Config struct {
+	// Urls are the endpoints every event is POSTed to
+	Urls []string
+	// Secret signs each delivery, so that receivers can check that it was sent by the node
+	Secret []byte
+	// Events are the types of the events delivered. If empty, every event is delivered.
+	Events []EventType
+	// MaxAttempts is how many times delivery of an event is attempted before it is dropped
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles after each further failure, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout is how long each attempt waits for a response
+	Timeout time.Duration
+	// QueueSize is how many events can wait to be delivered to each url. Events that do not fit are dropped.
+	QueueSize int
+}
+
+// Dispatcher delivers events to webhooks. Each url has its own queue, so that a slow or failing endpoint does not hold up the others.
+// Events are delivered to each url in the order they happened.
+type Dispatcher struct {
+	config  Config
+	client  *http.Client
+	queues  []chan Event
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      *sync.WaitGroup
+	logger  *slog.Logger
+	enabled map[EventType]bool
+}
+
+// NewDispatcher checks the config and starts delivering the events passed to HandleEngineEvent
+func NewDispatcher(config Config) (*Dispatcher, error) {
+	if len(config.Urls) == 0 {
+		return nil, errors.New("no webhook urls are configured")
+	}
+	if len(config.Secret) == 0 {
+		return nil, errors.New("a webhook secret is required to sign deliveries")
+	}
+	for _, u := range config.Urls {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid webhook url %q", u)
+		}
+	}
+	enabled := make(map[EventType]bool)
+	for _, t := range config.Events {
+		switch t {
+		case ObjectiveCompleted, ObjectiveFailed, LedgerChannelUpdated, PaymentChannelUpdated, VoucherReceived, ChallengeRegistered:
+			enabled[t] = true
+		default:
+			return nil, fmt.Errorf("unknown webhook event type %q", t)
+		}
+	}
+
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		queues:  make([]chan Event, len(config.Urls)),
+		ctx:     ctx,
+		cancel:  cancel,
+		wg:      &sync.WaitGroup{},
+		logger:  slog.Default(),
+		enabled: enabled,
+	}
+	for i, u := range config.Urls {
+		d.queues[i] = make(chan Event, config.QueueSize)
+		d.wg.Add(1)
+		go d.deliverQueued(u, d.queues[i])
+	}
+	return d, nil
+}
+
+// HandleEngineEvent queues the events of an engine event for delivery. It does not block, so it can be registered with Node.AddEngineEventHandler.
+func (d *Dispatcher) HandleEngineEvent(e engine.EngineEvent) {
+	for _, o := range e.CompletedObjectives {
+		d.Send(ObjectiveCompleted, ObjectiveEvent{ObjectiveId: o.Id()})
+	}
+	for _, id := range e.FailedObjectives {
+		d.Send(ObjectiveFailed, ObjectiveEvent{ObjectiveId: id})
+	}
+	for _, info := range e.LedgerChannelUpdates {
+		d.Send(LedgerChannelUpdated, info)
+	}
+	for _, info := range e.PaymentChannelUpdates {
+		d.Send(PaymentChannelUpdated, info)
+	}
+	for _, v := range e.ReceivedVouchers {
+		d.Send(VoucherReceived, v)
+	}
+	for _, c := range e.ChallengesRegistered {
+		d.Send(ChallengeRegistered, ChallengeEvent{ChannelId: c.ChannelID(), TurnNum: c.TurnNum(), BlockNum: c.BlockNum()})
+	}
+}
+
+// Send queues an event for delivery to every url, unless the event type is not enabled. The data is encoded as the Data of the event.
+func (d *Dispatcher) Send(t EventType, data any) {
+	if len(d.enabled) > 0 && !d.enabled[t] {
+		return
+	}
+	event := Event{Id: newEventId(), Type: t, Timestamp: time.Now().UTC(), Data: data}
+	for i, queue := range d.queues {
+		select {
+		case queue <- event:
+		default:
+			d.logger.Warn("Dropped webhook event as the queue is full", "url", d.config.Urls[i], "type", t)
+		}
+	}
+}
+
+func newEventId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// Close stops delivering events. Events that have not been delivered are dropped.
+func (d *Dispatcher) Close() error {
+	d.cancel()
+	d.wg.Wait()
+	return nil
+}
+
+// deliverQueued delivers the events queued for a url, one at a time, until the dispatcher is closed
+func (d *Dispatcher) deliverQueued(u string, queue <-chan Event) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case event := <-queue:
+			if err := d.deliver(u, event); err != nil {
+				d.logger.Error("Could not deliver webhook event", "url", u, "type", event.Type, "id", event.Id, "error", err)
+			}
+		}
+	}
+}
+
+// errPermanent wraps errors that retrying will not fix
+type errPermanent struct{ error }
+
+// deliver POSTs an event to a url, retrying with exponential backoff until it is accepted or MaxAttempts have failed
+func (d *Dispatcher) deliver(u string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := d.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = d.post(u, event, body)
+		if err == nil {
+			return nil
+		}
+		var permanent errPermanent
+		if errors.As(err, &permanent) || attempt == d.config.MaxAttempts {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+
+		d.logger.Warn("Webhook delivery failed, retrying", "url", u, "id", event.Id, "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-d.ctx.Done():
+			return d.ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, d.config.MaxBackoff)
+	}
+}
+
+func (d *Dispatcher) post(u string, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return errPermanent{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdHeader, event.Id)
+	req.Header.Set(EventTypeHeader, string(event.Type))
+	req.Header.Set(SignatureHeader, Sign(d.config.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return errPermanent{fmt.Errorf("webhook rejected the event with status %d", resp.StatusCode)}
+	}
+}
+
+// Sign returns the value of the SignatureHeader of a request with the body, sent at time t
+func Sign(secret []byte, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac(secret, timestamp, body))
+}
+
+// Verify checks the SignatureHeader of a request with the body. Signatures made more than tolerance before now are rejected, to stop old requests being replayed.
+// Receivers written in Go can use it to check that a delivery was sent by the node.
+func Verify(secret []byte, header string, body []byte, tolerance time.Duration) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("signature has no valid timestamp")
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return errors.New("signature timestamp is outside the tolerance")
+	}
+
+	expected := mac(secret, timestamp, body)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return errors.New("signature does not match")
+}
+
+func mac(secret []byte, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte{'.'})
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("webhook-secret")
+
+// receiver is a webhook endpoint that responds with the given statuses in turn, and then with 200
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	received []*http.Request
+	bodies   [][]byte
+	done     chan struct{}
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, string) {
+	r := &receiver{statuses: statuses, done: make(chan struct{}, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		r.mu.Lock()
+		r.received = append(r.received, req)
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+		r.done <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return r, server.URL
+}
+
+// wait waits for n requests to be received
+func (r *receiver) wait(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for webhook request %d", i+1)
+		}
+	}
+}
+
+func newTestDispatcher(t *testing.T, config Config) *Dispatcher {
+	config.Secret = testSecret
+	config.InitialBackoff = time.Millisecond
+	d, err := NewDispatcher(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestDeliverySignedEvent(t *testing.T) {
+	r, url := newReceiver(t)
+	d := newTestDispatcher(t, Config{Urls: []string{url}})
+
+	d.HandleEngineEvent(engine.EngineEvent{FailedObjectives: []protocols.ObjectiveId{"VirtualFund-0x01"}})
+	r.wait(t, 1)
+
+	req, body := r.received[0], r.bodies[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, string(ObjectiveFailed), req.Header.Get(EventTypeHeader))
+	require.NoError(t, Verify(testSecret, req.Header.Get(SignatureHeader), body, time.Minute))
+	assert.Error(t, Verify([]byte("other-secret"), req.Header.Get(SignatureHeader), body, time.Minute))
+	assert.Error(t, Verify(testSecret, req.Header.Get(SignatureHeader), append(body, ' '), time.Minute))
+
+	var event struct {
+		Id   string
+		Type EventType
+		Data ObjectiveEvent
+	}
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, req.Header.Get(IdHeader), event.Id)
+	assert.Equal(t, ObjectiveFailed, event.Type)
+	assert.Equal(t, protocols.ObjectiveId("VirtualFund-0x01"), event.Data.ObjectiveId)
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	r, url := newReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	d := newTestDispatcher(t, Config{Urls: []string{url}})
+
+	d.Send(ObjectiveFailed, ObjectiveEvent{ObjectiveId: "VirtualFund-0x01"})
+	r.wait(t, 3)
+
+	// Every attempt delivers the same event
+	ids := []string{}
+	for _, req := range r.received {
+		ids = append(ids, req.Header.Get(IdHeader))
+	}
+	assert.Equal(t, []string{ids[0], ids[0], ids[0]}, ids)
+}
+
+func TestDeliveryGivesUp(t *testing.T) {
+	r, url := newReceiver(t, http.StatusBadRequest, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	d := newTestDispatcher(t, Config{Urls: []string{url}, MaxAttempts: 2})
+
+	// A client error is not retried
+	d.Send(ObjectiveFailed, ObjectiveEvent{ObjectiveId: "VirtualFund-0x01"})
+	// A server error is retried until MaxAttempts have failed
+	d.Send(ObjectiveFailed, ObjectiveEvent{ObjectiveId: "VirtualFund-0x02"})
+	// Later events are still delivered
+	d.Send(ObjectiveFailed, ObjectiveEvent{ObjectiveId: "VirtualFund-0x03"})
+	r.wait(t, 4)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Len(t, r.received, 4)
+	assert.Equal(t, r.received[1].Header.Get(IdHeader), r.received[2].Header.Get(IdHeader))
+	assert.NotEqual(t, r.received[2].Header.Get(IdHeader), r.received[3].Header.Get(IdHeader))
+}
+
+func TestEnabledEvents(t *testing.T) {
+	r, url := newReceiver(t)
+	d := newTestDispatcher(t, Config{Urls: []string{url}, Events: []EventType{ObjectiveCompleted}})
+
+	d.Send(ObjectiveFailed, ObjectiveEvent{ObjectiveId: "VirtualFund-0x01"})
+	d.Send(ObjectiveCompleted, ObjectiveEvent{ObjectiveId: "VirtualFund-0x02"})
+	r.wait(t, 1)
+	assert.Equal(t, string(ObjectiveCompleted), r.received[0].Header.Get(EventTypeHeader))
+}
+
+func TestNewDispatcherInvalidConfig(t *testing.T) {
+	for name, config := range map[string]Config{
+		"no urls":       {Secret: testSecret},
+		"no secret":     {Urls: []string{"https://example.com"}},
+		"invalid url":   {Urls: []string{"example.com"}, Secret: testSecret},
+		"unknown event": {Urls: []string{"https://example.com"}, Secret: testSecret, Events: []EventType{"unknown"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDispatcher(config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVerifyRejectsOldSignatures(t *testing.T) {
+	body := []byte(`{}`)
+	assert.NoError(t, Verify(testSecret, Sign(testSecret, time.Now(), body), body, time.Minute))
+	assert.Error(t, Verify(testSecret, Sign(testSecret, time.Now().Add(-time.Hour), body), body, time.Minute))
+	assert.Error(t, Verify(testSecret, "v1=00", body, time.Minute))
+}
@@ -1,6 +1,7 @@
 package node_test
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"math/big"
@@ -163,7 +164,7 @@ func openLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, asset comm
 	// Set up an outcome that requires both participants to deposit
 	outcome := initialLedgerOutcome(*alpha.Address, *beta.Address, asset)
 
-	response, err := alpha.CreateLedgerChannel(*beta.Address, 0, outcome)
+	response, err := alpha.CreateLedgerChannel(context.Background(), *beta.Address, 0, outcome)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -179,7 +180,7 @@ func openLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, asset comm
 }
 
 func closeLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, channelId types.Destination) {
-	response, err := alpha.CloseLedgerChannel(channelId)
+	response, err := alpha.CloseLedgerChannel(context.Background(), channelId)
 	if err != nil {
 		t.Fatal(err)
 	}
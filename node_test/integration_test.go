@@ -135,6 +135,7 @@ func RunIntegrationTestCase(tc TestCase, t *testing.T) {
 		for i := 0; i < int(tc.NumOfChannels); i++ {
 			outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
 			response, err := clientA.CreatePaymentChannel(
+				context.Background(),
 				clientAddresses(intermediaries),
 				testactors.Bob.Address(),
 				0,
@@ -162,7 +163,7 @@ func RunIntegrationTestCase(tc TestCase, t *testing.T) {
 		// Send payments
 		for i := 0; i < len(virtualIds); i++ {
 			for j := 0; j < int(tc.NumOfPayments); j++ {
-				clientA.Pay(virtualIds[i], big.NewInt(int64(1)))
+				clientA.Pay(context.Background(), virtualIds[i], big.NewInt(int64(1)))
 			}
 		}
 
@@ -186,12 +187,12 @@ func RunIntegrationTestCase(tc TestCase, t *testing.T) {
 			// alternative who is responsible for closing the channel
 			switch i % 2 {
 			case 0:
-				closeVirtualIds[i], err = clientA.ClosePaymentChannel(virtualIds[i])
+				closeVirtualIds[i], err = clientA.ClosePaymentChannel(context.Background(), virtualIds[i])
 				if err != nil {
 					t.Fatal(err)
 				}
 			case 1:
-				closeVirtualIds[i], err = clientB.ClosePaymentChannel(virtualIds[i])
+				closeVirtualIds[i], err = clientB.ClosePaymentChannel(context.Background(), virtualIds[i])
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -1,6 +1,7 @@
 package node_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -19,7 +20,9 @@ import (
 	"github.com/statechannels/go-nitro/internal/logging"
 	ta "github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/paymentproxy"
+	"github.com/statechannels/go-nitro/paymentproxy/paymentclient"
 	"github.com/statechannels/go-nitro/rpc"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -30,6 +33,9 @@ const (
 	parseErrorResponseBody     = "could not parse voucher"
 	signatureErrorResponseBody = "error processing voucher"
 	proxyAddress               = ":5511"
+	meteringProxyAddress       = ":5512"
+	streamBillingProxyAddress  = ":5513"
+	verifyingProxyAddress      = ":5514"
 	bobRPCUrl                  = "127.0.0.1:4107/api/v1"
 	destPort                   = 6622
 	otherParam                 = "otherParam"
@@ -107,6 +113,32 @@ func TestPaymentProxy(t *testing.T) {
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource", proxyAddress))
 	checkResponse(t, resp, parseErrorResponseBody, http.StatusPaymentRequired)
 
+	// The payment required response tells the client who to pay
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource", proxyAddress))
+	var paymentRequired paymentproxy.PaymentRequired
+	if err := json.NewDecoder(resp.Body).Decode(&paymentRequired); err != nil {
+		t.Fatalf("Error decoding payment required response: %v", err)
+	}
+	if paymentRequired.Payee != ta.Bob.Address() {
+		t.Errorf("Expected the payee to be %s, but got %s", ta.Bob.Address(), paymentRequired.Payee)
+	}
+	if challenge, ok := paymentproxy.ParsePaymentChallenge(resp.Header.Get("WWW-Authenticate")); !ok || challenge.Get(paymentproxy.PAYEE_CHALLENGE_PARAM) != ta.Bob.Address().Hex() {
+		t.Errorf("Expected a payment challenge naming the payee, but got %q", resp.Header.Get("WWW-Authenticate"))
+	}
+
+	// A voucher can be sent in the Authorization header instead of the query params
+	voucher = createVoucher(t, aliceClient, paymentChannel, 5)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/resource", proxyAddress), nil)
+	if err != nil {
+		t.Fatalf("Error creating request: %v", err)
+	}
+	req.Header.Set("Authorization", paymentproxy.VoucherAuthorization(voucher))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error performing request: %v", err)
+	}
+	checkResponse(t, resp, smallResponse, http.StatusOK)
+
 	// A voucher less than 5 should be rejected
 	voucher = createVoucher(t, aliceClient, paymentChannel, 4)
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource?channelId=%s&amount=%d&signature=%s", proxyAddress, voucher.ChannelId, voucher.Amount.Uint64(), voucher.Signature.ToHexString()))
@@ -141,6 +173,13 @@ func TestPaymentProxy(t *testing.T) {
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/file?channelId=%s&amount=%d&signature=%s", proxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
 	checkResponse(t, resp, expectedPaymentErrorMessage(len(testFileContent), 5), http.StatusPaymentRequired)
 
+	// A paying http client is told the cost of a response by the proxy, and pays it
+	resp, err = paymentclient.NewClient(aliceClient, paymentChannel).Get(fmt.Sprintf("http://%s/file", proxyAddress))
+	if err != nil {
+		t.Fatalf("Error performing request: %v", err)
+	}
+	checkResponse(t, resp, testFileContent, http.StatusOK)
+
 	// It should handle a simple range request
 	voucher = createVoucher(t, aliceClient, paymentChannel, 5)
 	resp = performGetRequest(t, "bytes=0-4", fmt.Sprintf("http://%s/file?channelId=%s&amount=%d&signature=%s", proxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
@@ -172,6 +211,211 @@ func TestPaymentProxy(t *testing.T) {
 	voucher = createVoucher(t, aliceClient, paymentChannel, 1)
 	resp = performGetRequest(t, "bytes=0-1,3-4", fmt.Sprintf("http://%s/file?channelId=%s&amount=%d&signature=%s", proxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
 	checkResponse(t, resp, expectedPaymentErrorMessage(multiPartResponseSize, 1), http.StatusPaymentRequired)
+
+	// A proxy that verifies vouchers itself serves requests before the node has received their vouchers, and forwards them to the node
+	paidSoFar := getPaymentChannelInfo(t, bobClient, paymentChannel).Balance.PaidSoFar.ToInt().Int64()
+	verifyingProxy := paymentproxy.NewPaymentProxyWithConfig(paymentproxy.Config{
+		ProxyAddress:         verifyingProxyAddress,
+		NitroEndpoint:        bobRPCUrl,
+		DestinationUrl:       destinationServerUrl,
+		Pricing:              paymentproxy.Pricing{Default: paymentproxy.Price{PerByte: 1}},
+		LocalVerification:    true,
+		VoucherBatchInterval: time.Hour,
+	})
+	err = verifyingProxy.Start()
+	if err != nil {
+		t.Fatalf("Error starting proxy: %v", err)
+	}
+	waitForServer(t, fmt.Sprintf("http://%s/", verifyingProxyAddress), serverReadyMaxWait)
+
+	voucher = createVoucher(t, aliceClient, paymentChannel, 5)
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource?channelId=%s&amount=%d&signature=%s", verifyingProxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
+	checkResponse(t, resp, smallResponse, http.StatusOK)
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource?channelId=%s&amount=%d&signature=%s", verifyingProxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
+	checkResponse(t, resp, expectedPaymentErrorMessage(5, 0), http.StatusPaymentRequired)
+	if paid := getPaymentChannelInfo(t, bobClient, paymentChannel).Balance.PaidSoFar.ToInt().Int64(); paid != paidSoFar {
+		t.Errorf("Expected the voucher to be forwarded to the node in a later batch, but %d has been paid", paid)
+	}
+
+	// Stopping the proxy forwards the pending vouchers
+	err = verifyingProxy.Stop()
+	if err != nil {
+		t.Fatalf("Error stopping proxy: %v", err)
+	}
+	if paid := getPaymentChannelInfo(t, bobClient, paymentChannel).Balance.PaidSoFar.ToInt().Int64(); paid != paidSoFar+5 {
+		t.Errorf("Expected %d to have been paid, but got %d", paidSoFar+5, paid)
+	}
+}
+
+// getPaymentChannelInfo gets the payment channel info with the client
+// If any error occurs it will fail the test
+func getPaymentChannelInfo(t *testing.T, client rpc.RpcClientApi, channelId types.Destination) query.PaymentChannelInfo {
+	info, err := client.GetPaymentChannel(channelId)
+	if err != nil {
+		t.Fatalf("Error getting payment channel: %v", err)
+	}
+	return info
+}
+
+func TestPaymentProxyMetering(t *testing.T) {
+	logFile := "payment_proxy_metering.log"
+
+	aliceClient, ireneClient, bobClient, cleanup := setupNitroClients(t, logFile)
+	defer cleanup()
+
+	paymentChannel := createChannelData(t, aliceClient, ireneClient, bobClient)
+
+	destinationServerUrl, cleanupDestServer := runDestinationServer(t, destPort)
+	defer cleanupDestServer()
+
+	cleanupData := setupTestFile(t)
+	defer cleanupData()
+
+	proxy := paymentproxy.NewPaymentProxyWithConfig(paymentproxy.Config{
+		ProxyAddress:   meteringProxyAddress,
+		NitroEndpoint:  bobRPCUrl,
+		DestinationUrl: destinationServerUrl,
+		Pricing:        paymentproxy.Pricing{Default: paymentproxy.Price{PerByte: 1}},
+		Metering:       true,
+		UsageToken:     "usage-token",
+	})
+	defer func() {
+		err := proxy.Stop()
+		if err != nil {
+			t.Fatalf("Error stopping proxy: %v", err)
+		}
+	}()
+
+	err := proxy.Start()
+	if err != nil {
+		t.Fatalf("Error starting proxy: %v", err)
+	}
+	waitForServer(t, fmt.Sprintf("http://%s/", meteringProxyAddress), serverReadyMaxWait)
+
+	// Credit can be bought ahead of any request
+	voucher := createVoucher(t, aliceClient, paymentChannel, 20)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", meteringProxyAddress, paymentproxy.CREDIT_PATH), nil)
+	if err != nil {
+		t.Fatalf("Error creating request: %v", err)
+	}
+	req.Header.Set("Authorization", paymentproxy.VoucherAuthorization(voucher))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error performing request: %v", err)
+	}
+	credit := decodeCredit(t, resp)
+	if credit.ChannelId != paymentChannel || credit.Credit.Int64() != 20 || credit.Token == "" {
+		t.Fatalf("Expected 20 credit on channel %s, but got %+v", paymentChannel, credit)
+	}
+
+	// Requests are paid for from the credit, with no voucher
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource?%s=%s", meteringProxyAddress, paymentproxy.CREDIT_TOKEN_PARAM, credit.Token))
+	if resp.Header.Get(paymentproxy.CREDIT_HEADER) != "15" {
+		t.Errorf("Expected 15 credit to remain, but got %q", resp.Header.Get(paymentproxy.CREDIT_HEADER))
+	}
+	checkResponse(t, resp, smallResponse, http.StatusOK)
+
+	// A request costing more than the credit is not served, and no credit is spent
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/file?%s=%s", meteringProxyAddress, paymentproxy.CREDIT_TOKEN_PARAM, credit.Token))
+	var paymentRequired paymentproxy.PaymentRequired
+	if err := json.NewDecoder(resp.Body).Decode(&paymentRequired); err != nil {
+		t.Fatalf("Error decoding payment required response: %v", err)
+	}
+	if resp.StatusCode != http.StatusPaymentRequired || paymentRequired.Credit.Int64() != 15 || paymentRequired.Amount.Int64() != int64(len(testFileContent)) {
+		t.Errorf("Expected a payment required response with 15 credit, but got %d %+v", resp.StatusCode, paymentRequired)
+	}
+
+	// A voucher sent with a request tops up the credit, and the overpayment carries over
+	voucher = createVoucher(t, aliceClient, paymentChannel, 40)
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/file?channelId=%s&amount=%d&signature=%s", meteringProxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
+	expectedCredit := 15 + 40 - len(testFileContent)
+	if resp.Header.Get(paymentproxy.CREDIT_HEADER) != fmt.Sprint(expectedCredit) || resp.Header.Get(paymentproxy.CREDIT_TOKEN_HEADER) != credit.Token {
+		t.Errorf("Expected %d credit to remain, but got %q", expectedCredit, resp.Header.Get(paymentproxy.CREDIT_HEADER))
+	}
+	checkResponse(t, resp, testFileContent, http.StatusOK)
+
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s%s?%s=%s", meteringProxyAddress, paymentproxy.CREDIT_PATH, paymentproxy.CREDIT_TOKEN_PARAM, credit.Token))
+	if credit := decodeCredit(t, resp); credit.Credit.Int64() != int64(expectedCredit) {
+		t.Errorf("Expected %d credit, but got %d", expectedCredit, credit.Credit)
+	}
+
+	// An unknown credit token pays for nothing
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource?%s=unknown", meteringProxyAddress, paymentproxy.CREDIT_TOKEN_PARAM))
+	checkResponse(t, resp, "unknown credit token", http.StatusPaymentRequired)
+
+	// The usage of the channel is reported to requests with the usage token
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", meteringProxyAddress, paymentproxy.USAGE_PATH), nil)
+	if err != nil {
+		t.Fatalf("Error creating request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer usage-token")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error performing request: %v", err)
+	}
+	var usage []paymentproxy.Usage
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		t.Fatalf("Error decoding usage response: %v", err)
+	}
+	foundUsage := false
+	for _, u := range usage {
+		if u.ChannelId != paymentChannel {
+			continue
+		}
+		foundUsage = true
+		if u.Requests != 2 || u.RejectedPayments != 1 || u.Charged.Int64() != int64(len(smallResponse)+len(testFileContent)) {
+			t.Errorf("Expected 2 requests charged %d and 1 rejected payment, but got %+v", len(smallResponse)+len(testFileContent), u)
+		}
+	}
+	if !foundUsage {
+		t.Errorf("Expected usage of channel %s, but got %+v", paymentChannel, usage)
+	}
+
+	// A proxy billing responses as they are sent serves what the credit pays for, and pauses until it is credited again
+	streamProxy := paymentproxy.NewPaymentProxyWithConfig(paymentproxy.Config{
+		ProxyAddress:   streamBillingProxyAddress,
+		NitroEndpoint:  bobRPCUrl,
+		DestinationUrl: destinationServerUrl,
+		Pricing:        paymentproxy.Pricing{Default: paymentproxy.Price{PerByte: 1}},
+		Metering:       true,
+		StreamBilling:  true,
+		PaymentTimeout: 100 * time.Millisecond,
+	})
+	defer func() {
+		err := streamProxy.Stop()
+		if err != nil {
+			t.Fatalf("Error stopping proxy: %v", err)
+		}
+	}()
+	err = streamProxy.Start()
+	if err != nil {
+		t.Fatalf("Error starting proxy: %v", err)
+	}
+	waitForServer(t, fmt.Sprintf("http://%s/", streamBillingProxyAddress), serverReadyMaxWait)
+
+	voucher = createVoucher(t, aliceClient, paymentChannel, uint64(len(testFileContent)))
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/file?channelId=%s&amount=%d&signature=%s", streamBillingProxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
+	checkResponse(t, resp, testFileContent, http.StatusOK)
+	streamToken := resp.Header.Get(paymentproxy.CREDIT_TOKEN_HEADER)
+
+	// With no credit left the response is ended once the payment timeout elapses
+	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/file?%s=%s", streamBillingProxyAddress, paymentproxy.CREDIT_TOKEN_PARAM, streamToken))
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Errorf("Expected the response to end before it was sent")
+	}
+}
+
+// decodeCredit decodes the body of a response from the credit endpoint of a payment proxy
+// If any error occurs it will fail the test
+func decodeCredit(t *testing.T, resp *http.Response) paymentproxy.Credit {
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, but got %d", http.StatusOK, resp.StatusCode)
+	}
+	var credit paymentproxy.Credit
+	if err := json.NewDecoder(resp.Body).Decode(&credit); err != nil {
+		t.Fatalf("Error decoding credit response: %v", err)
+	}
+	return credit
 }
 
 // createVoucher creates a voucher for the given channel and amount	using the given client
@@ -321,7 +565,7 @@ func runDestinationServer(t *testing.T, port uint) (destUrl string, cleanup func
 
 		// Always check that the voucher params were stripped out of every request
 		for p := range params {
-			if p == paymentproxy.AMOUNT_VOUCHER_PARAM || p == paymentproxy.CHANNEL_ID_VOUCHER_PARAM || p == paymentproxy.SIGNATURE_VOUCHER_PARAM {
+			if p == paymentproxy.AMOUNT_VOUCHER_PARAM || p == paymentproxy.CHANNEL_ID_VOUCHER_PARAM || p == paymentproxy.SIGNATURE_VOUCHER_PARAM || p == paymentproxy.CREDIT_TOKEN_PARAM {
 				t.Fatalf("Expected no voucher information to be passed along, but got %s", p)
 			}
 		}
@@ -3,9 +3,11 @@ package node_test
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -25,12 +27,17 @@ import (
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
 	"github.com/statechannels/go-nitro/rpc/transport"
+	grpctrans "github.com/statechannels/go-nitro/rpc/transport/grpc"
 	"github.com/statechannels/go-nitro/rpc/transport/http"
 	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/rpc/transport/unix"
 	"github.com/statechannels/go-nitro/types"
 
 	"github.com/statechannels/go-nitro/crypto"
@@ -52,9 +59,22 @@ func TestRpcWithHttp(t *testing.T) {
 	}
 }
 
+func TestRpcWithGrpc(t *testing.T) {
+	for _, n := range []int{2, 3, 4} {
+		executeNRpcTestWrapper(t, transport.Grpc, n, false)
+	}
+}
+
+func TestRpcWithUnixSocket(t *testing.T) {
+	for _, n := range []int{2, 3, 4} {
+		executeNRpcTestWrapper(t, transport.Unix, n, false)
+	}
+}
+
 func TestRPCWithManualVoucherExchange(t *testing.T) {
 	executeNRpcTestWrapper(t, transport.Http, 4, true)
 	executeNRpcTestWrapper(t, transport.Nats, 4, true)
+	executeNRpcTestWrapper(t, transport.Grpc, 4, true)
 }
 
 func executeNRpcTestWrapper(t *testing.T, connectionType transport.TransportType, n int, manualVoucherExchange bool) {
@@ -144,6 +164,13 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		}
 	}
 
+	slog.Info("Verify that the rpc server serves its OpenRPC document")
+	document, err := clients[0].Discover()
+	checkError(t, err, "client.Discover")
+	if document.OpenRpc != openrpc.Version || len(document.Methods) == 0 {
+		t.Fatalf("expected an OpenRPC document listing the api methods, got %+v", document.Info)
+	}
+
 	waitForPeerInfoExchange(msgServices...)
 	slog.Info("Peer exchange complete")
 
@@ -202,6 +229,37 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		}
 	}
 
+	// assert pagination & filtering of ledger channel queries
+	for i, client := range clients {
+		expectedLedgers := 2
+		if i == 0 || i == n-1 {
+			expectedLedgers = 1
+		}
+
+		seen := []types.Destination{}
+		cursor := ""
+		for {
+			page, err := client.GetLedgerChannels(query.ChannelFilter{}, cursor, 1)
+			checkError(t, err, "client.GetLedgerChannels")
+			for _, lc := range page.Channels {
+				seen = append(seen, lc.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(seen) != expectedLedgers {
+			t.Errorf("expected to page through %d ledger channels, got %d", expectedLedgers, len(seen))
+		}
+
+		page, err := client.GetLedgerChannels(query.ChannelFilter{CreatedAfter: time.Now()}, "", 0)
+		checkError(t, err, "client.GetLedgerChannels")
+		if len(page.Channels) != 0 {
+			t.Errorf("expected no ledger channels created in the future, got %d", len(page.Channels))
+		}
+	}
+
 	t.Log("Ledger channels queried")
 
 	//////////////////////////////////////////////////////////////////
@@ -261,6 +319,19 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 
 	t.Log("Payment channels queried")
 
+	// assert filtering of payment channel queries
+	{
+		page, err := bobClient.GetPaymentChannels(query.ChannelFilter{Status: query.Open, Counterparty: alice.Address()}, "", 0)
+		checkError(t, err, "client.GetPaymentChannels")
+		checkQueryInfoCollection(t, expectedVirtualChannel, 1, page.Channels)
+
+		page, err = bobClient.GetPaymentChannels(query.ChannelFilter{Status: query.Closing}, "", 0)
+		checkError(t, err, "client.GetPaymentChannels")
+		if len(page.Channels) != 0 || page.NextCursor != "" {
+			t.Errorf("expected no closing payment channels, got %+v", page)
+		}
+	}
+
 	if !virtualfund.IsVirtualFundObjective(vabCreateResponse.Id) {
 		t.Errorf("expected virtual fund objective, got %s", vabCreateResponse.Id)
 	}
@@ -285,9 +356,34 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		if rxVoucher.Delta.Cmp(big.NewInt(0)) != 0 {
 			t.Errorf("adding the same voucher should result in a delta of 0, got %d", rxVoucher.Delta)
 		}
+
+		for client, direction := range map[rpc.RpcClientApi]payments.PaymentDirection{aliceClient: payments.Sent, bobClient: payments.Received} {
+			history, err := client.GetPaymentHistory(vabCreateResponse.ChannelId, 0, 0)
+			checkError(t, err, "client.GetPaymentHistory")
+			if len(history.Payments) != 1 || history.HasMore {
+				t.Fatalf("expected a single payment in the history, got %+v", history)
+			}
+			if got := history.Payments[0]; got.Direction != direction || got.Total.Cmp(big.NewInt(1)) != 0 || got.Delta.Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("unexpected payment record %+v", got)
+			}
+		}
 	} else {
 		_, err = aliceClient.Pay(vabCreateResponse.ChannelId, 1)
 		checkError(t, err, "aliceClient.Pay")
+
+		select {
+		case v := <-bobClient.ReceivedVouchersChan():
+			if v.ChannelId != vabCreateResponse.ChannelId || v.Payer != alice.Address() || v.Delta.Cmp(big.NewInt(1)) != 0 || v.Total.Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("unexpected received voucher %+v", v)
+			}
+		case <-time.After(defaultTimeout):
+			t.Fatalf("timed out waiting for bob to be notified of the voucher")
+		}
+	}
+
+	// A payment the channel cannot cover fails, rather than being dropped by the node
+	if _, err := aliceClient.Pay(vabCreateResponse.ChannelId, virtualChannelDeposit); err == nil {
+		t.Errorf("expected a payment exceeding the channel's funds to fail")
 	}
 
 	t.Log("Vouchers sent/received")
@@ -385,6 +481,43 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 	checkNotifications(t, "aliceVirtual", requiredVCNotifs, optionalVCNotifs, aliceVirtualNotifs, defaultTimeout)
 	bobVirtualNotifs := bobClient.PaymentChannelUpdatesChan(vabCreateResponse.ChannelId)
 	checkNotifications(t, "bobVirtual", requiredVCNotifs, optionalVCNotifs, bobVirtualNotifs, defaultTimeout)
+
+	// Every notified ledger update is also recorded as a balance snapshot
+	aliceLedgerHistory, err := aliceClient.GetBalanceHistory(aliceLedger.ChannelId, time.Time{}, time.Time{})
+	checkError(t, err, "aliceClient.GetBalanceHistory")
+	if len(aliceLedgerHistory) < len(expectedAliceLedgerNotifs) {
+		t.Fatalf("expected at least %d balance snapshots for alice's ledger channel, got %d", len(expectedAliceLedgerNotifs), len(aliceLedgerHistory))
+	}
+	latest := aliceLedgerHistory[len(aliceLedgerHistory)-1]
+	if latest.Status != query.Complete || latest.MyBalance.ToInt().Cmp(big.NewInt(99)) != 0 || latest.TheirBalance.ToInt().Cmp(big.NewInt(101)) != 0 {
+		t.Errorf("unexpected latest balance snapshot for alice's ledger channel: %+v", latest)
+	}
+
+	// Every notification is recorded by the node, so a client can replay those it missed
+	if aliceClient.LastEventSeq() == 0 {
+		t.Fatalf("expected alice's notifications to have sequence numbers")
+	}
+	pendingLedgerNotifs := len(aliceLedgerNotifs)
+	err = aliceClient.ReplayEvents(0)
+	checkError(t, err, "aliceClient.ReplayEvents")
+	if len(aliceLedgerNotifs) != pendingLedgerNotifs {
+		t.Errorf("expected replayed notifications that were already received to be skipped, got %d more ledger updates", len(aliceLedgerNotifs)-pendingLedgerNotifs)
+	}
+
+	// Subscriptions select the notifications sent over a connection, which the server cannot tell apart over NATS
+	subscriptionId, err := aliceClient.Subscribe(serde.EventFilter{ChannelIds: []types.Destination{aliceLedger.ChannelId}})
+	if connectionType == transport.Nats {
+		if !errors.Is(err, rpc.ErrSubscriptionsUnsupported) {
+			t.Errorf("expected subscribing over nats to fail with %v, got %v", rpc.ErrSubscriptionsUnsupported, err)
+		}
+	} else {
+		checkError(t, err, "aliceClient.Subscribe")
+		err = aliceClient.Unsubscribe(subscriptionId)
+		checkError(t, err, "aliceClient.Unsubscribe")
+		if err := aliceClient.Unsubscribe(subscriptionId); !errors.Is(err, rpc.ErrSubscriptionNotFound) {
+			t.Errorf("expected unsubscribing twice to fail with %v, got %v", rpc.ErrSubscriptionNotFound, err)
+		}
+	}
 }
 
 // setupNitroNodeWithRPCClient is a helper function that spins up a Nitro Node RPC Server and returns an RPC client connected to it.
@@ -422,23 +555,17 @@ func setupNitroNodeWithRPCClient(
 		ourStore,
 		&engine.PermissivePolicy{})
 
-	var useNats bool
-	switch connectionType {
-	case transport.Nats:
-		useNats = true
-	case transport.Http:
-		useNats = false
-	default:
-		err = fmt.Errorf("unknown connection type %v", connectionType)
-		panic(err)
-	}
-
 	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
 	if err != nil {
 		panic(err)
 	}
 
-	rpcServer, err := interRpc.InitializeRpcServer(&node, rpcPort, useNats, &cert)
+	var rpcServer *rpc.RpcServer
+	if connectionType == transport.Unix {
+		rpcServer, err = interRpc.InitializeUnixRpcServer(&node, filepath.Join(t.TempDir(), "nitro.sock"), unix.DefaultSocketMode, rpc.AuthConfig{})
+	} else {
+		rpcServer, err = interRpc.InitializeRpcServer(&node, rpcPort, connectionType, &cert, rpc.AuthConfig{Insecure: true})
+	}
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -457,6 +584,18 @@ func setupNitroNodeWithRPCClient(
 		if err != nil {
 			panic(err)
 		}
+	case transport.Grpc:
+
+		clientConnection, err = grpctrans.NewGrpcTransportAsClient(rpcServer.Url(), &tls.Config{})
+		if err != nil {
+			panic(err)
+		}
+	case transport.Unix:
+
+		clientConnection, err = unix.NewUnixTransportAsClient(rpcServer.Url())
+		if err != nil {
+			panic(err)
+		}
 	default:
 		err = fmt.Errorf("unknown connection type %v", connectionType)
 		panic(err)
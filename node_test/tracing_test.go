@@ -0,0 +1,74 @@
+package node_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/tracing"
+	"github.com/statechannels/go-nitro/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracePropagation(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	}()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+	chainServiceA := chainservice.NewMockChainService(chain, testactors.Alice.Address())
+	chainServiceB := chainservice.NewMockChainService(chain, testactors.Bob.Address())
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainServiceA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainServiceB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ctx, root := tracing.Tracer().Start(context.Background(), "test")
+	response, err := nodeA.CreateLedgerChannel(ctx, *nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-nodeA.ObjectiveCompleteChan(response.Id)
+	<-nodeB.ObjectiveCompleteChan(response.Id)
+	root.End()
+
+	// Alice's engine handles the request and sends the proposal to Bob, whose engine continues the trace
+	found := map[string]bool{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == "nitro.address" {
+				found[span.Name()+" by "+attr.Value.AsString()] = true
+			}
+		}
+	}
+	for _, expected := range []string{
+		"engine.handleObjectiveRequest by " + nodeA.Address.String(),
+		"engine.sendMessage by " + nodeA.Address.String(),
+		"engine.sendTransaction by " + nodeA.Address.String(),
+		"engine.handleMessage by " + nodeB.Address.String(),
+	} {
+		if !found[expected] {
+			t.Errorf("expected a span %s in the trace of the request, got %v", expected, found)
+		}
+	}
+}
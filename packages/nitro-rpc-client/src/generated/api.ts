@@ -0,0 +1,593 @@
+// Code generated by go-nitro/cmd/generate-rpc-client from the OpenRPC document of go-nitro v1. DO NOT EDIT.
+
+export type Allocation = {
+  AllocationType: number;
+  Amount: number;
+  Destination: string;
+  Metadata: string;
+};
+
+export type AssetBalance = {
+  AssetAddress: string;
+  Channels: number;
+  MyBalance: string;
+  TheirBalance: string;
+  Timestamp: string;
+};
+
+export type AssetMetadata = {
+  AssetType: number;
+  Metadata: string;
+};
+
+export type AuthRequest = {
+  ApiKey: string;
+  Id: string;
+};
+
+export type BalanceSnapshot = {
+  AssetAddress: string;
+  ChannelId: string;
+  Kind: string;
+  MyBalance: string;
+  Status: string;
+  TheirBalance: string;
+  Timestamp: string;
+};
+
+export type ChannelFilter = {
+  AssetAddress: string;
+  Counterparty: string;
+  CreatedAfter: string;
+  MinBalance: string;
+  Status: string;
+};
+
+export type DirectdefundObjectiveRequest = {
+  ChannelId: string;
+};
+
+export type DirectfundObjectiveRequest = {
+  AppData: string;
+  AppDefinition: string;
+  ChallengeDuration: number;
+  CounterParty: string;
+  Nonce: number;
+  Outcome: SingleAssetExit[];
+};
+
+export type DirectfundObjectiveResponse = {
+  ChannelId: string;
+  Id: string;
+};
+
+export type Event = {
+  Method: string;
+  Payload: unknown;
+  Seq: number;
+  Timestamp: string;
+};
+
+export type EventFilter = {
+  ChannelIds?: string[];
+  Counterparties?: string[];
+  Methods?: string[];
+  ObjectiveIds?: string[];
+};
+
+export type EventsPage = {
+  Events: Event[];
+  HasMore: boolean;
+  LastSeq: number;
+  Missed: boolean;
+};
+
+export type GetAssetBalanceHistoryRequest = {
+  From: string;
+  IntervalSeconds: number;
+  To: string;
+};
+
+export type GetBalanceHistoryRequest = {
+  From: string;
+  Id: string;
+  To: string;
+};
+
+export type GetChannelsRequest = {
+  Cursor: string;
+  Filter: ChannelFilter;
+  Limit: number;
+};
+
+export type GetEventsRequest = {
+  Filter: EventFilter;
+  Limit: number;
+  Since: number;
+};
+
+export type GetLedgerChannelRequest = {
+  Id: string;
+};
+
+export type GetPaymentChannelRequest = {
+  Id: string;
+};
+
+export type GetPaymentChannelsByLedgerRequest = {
+  LedgerId: string;
+};
+
+export type GetPaymentHistoryRequest = {
+  Id: string;
+  Limit: number;
+  Offset: number;
+};
+
+export type LedgerChannelBalance = {
+  AssetAddress: string;
+  Me: string;
+  MyBalance: string;
+  TheirBalance: string;
+  Them: string;
+};
+
+export type LedgerChannelInfo = {
+  Balance: LedgerChannelBalance;
+  ID: string;
+  Status: string;
+};
+
+export type LedgerChannelsPage = {
+  Channels: LedgerChannelInfo[];
+  NextCursor: string;
+};
+
+export type PaymentChannelBalance = {
+  AssetAddress: string;
+  PaidSoFar: string;
+  Payee: string;
+  Payer: string;
+  RemainingFunds: string;
+};
+
+export type PaymentChannelInfo = {
+  Balance: PaymentChannelBalance;
+  ID: string;
+  Status: string;
+};
+
+export type PaymentChannelsPage = {
+  Channels: PaymentChannelInfo[];
+  NextCursor: string;
+};
+
+export type PaymentHistory = {
+  HasMore: boolean;
+  ID: string;
+  NextOffset: number;
+  Payments: PaymentRecord[];
+};
+
+export type PaymentRecord = {
+  ChannelId: string;
+  Delta: number;
+  Direction: string;
+  Index: number;
+  Signature: string;
+  Timestamp: string;
+  Total: number;
+};
+
+export type PaymentRequest = {
+  Amount: number;
+  Channel: string;
+};
+
+export type ReceiveVoucherSummary = {
+  Delta: number;
+  Total: number;
+};
+
+export type RevokeAuthTokenRequest = {
+  Token: string;
+};
+
+export type RevokeClientTokensRequest = {
+  ClientId: string;
+};
+
+export type SingleAssetExit = {
+  Allocations: Allocation[];
+  Asset: string;
+  AssetMetadata: AssetMetadata;
+};
+
+export type SubscribeRequest = {
+  Filter: EventFilter;
+  Limit: number;
+  Since: number;
+};
+
+export type SubscribeResponse = {
+  Events: Event[];
+  HasMore: boolean;
+  LastSeq: number;
+  Missed: boolean;
+  SubscriptionId: string;
+};
+
+export type UnsubscribeRequest = {
+  SubscriptionId: string;
+};
+
+export type VirtualdefundObjectiveRequest = {
+  ChannelId: string;
+};
+
+export type VirtualfundObjectiveRequest = {
+  AppDefinition: string;
+  ChallengeDuration: number;
+  CounterParty: string;
+  Intermediaries: string[];
+  Nonce: number;
+  Outcome: SingleAssetExit[];
+};
+
+export type VirtualfundObjectiveResponse = {
+  ChannelId: string;
+  Id: string;
+};
+
+export type Voucher = {
+  Amount: number;
+  ChannelId: string;
+  Signature: string;
+};
+
+export type RequestMethods = {
+  get_auth_token: {
+    params: AuthRequest;
+    result: string;
+  };
+  revoke_auth_token: {
+    params: RevokeAuthTokenRequest;
+    result: string;
+  };
+  revoke_client_tokens: {
+    params: RevokeClientTokensRequest;
+    result: string;
+  };
+  get_address: {
+    params: Record<string, never>;
+    result: string;
+  };
+  version: {
+    params: Record<string, never>;
+    result: string;
+  };
+  create_ledger_channel: {
+    params: DirectfundObjectiveRequest;
+    result: DirectfundObjectiveResponse;
+  };
+  close_ledger_channel: {
+    params: DirectdefundObjectiveRequest;
+    result: string;
+  };
+  create_payment_channel: {
+    params: VirtualfundObjectiveRequest;
+    result: VirtualfundObjectiveResponse;
+  };
+  close_payment_channel: {
+    params: VirtualdefundObjectiveRequest;
+    result: string;
+  };
+  pay: {
+    params: PaymentRequest;
+    result: PaymentRequest;
+  };
+  create_voucher: {
+    params: PaymentRequest;
+    result: Voucher;
+  };
+  receive_voucher: {
+    params: Voucher;
+    result: ReceiveVoucherSummary;
+  };
+  get_payment_channel: {
+    params: GetPaymentChannelRequest;
+    result: PaymentChannelInfo;
+  };
+  get_ledger_channel: {
+    params: GetLedgerChannelRequest;
+    result: LedgerChannelInfo;
+  };
+  get_all_ledger_channels: {
+    params: Record<string, never>;
+    result: LedgerChannelInfo[];
+  };
+  get_payment_channels_by_ledger: {
+    params: GetPaymentChannelsByLedgerRequest;
+    result: PaymentChannelInfo[];
+  };
+  get_ledger_channels: {
+    params: GetChannelsRequest;
+    result: LedgerChannelsPage;
+  };
+  get_payment_channels: {
+    params: GetChannelsRequest;
+    result: PaymentChannelsPage;
+  };
+  get_payment_history: {
+    params: GetPaymentHistoryRequest;
+    result: PaymentHistory;
+  };
+  get_balance_history: {
+    params: GetBalanceHistoryRequest;
+    result: BalanceSnapshot[];
+  };
+  get_asset_balance_history: {
+    params: GetAssetBalanceHistoryRequest;
+    result: AssetBalance[];
+  };
+  get_events: {
+    params: GetEventsRequest;
+    result: EventsPage;
+  };
+  subscribe: {
+    params: SubscribeRequest;
+    result: SubscribeResponse;
+  };
+  unsubscribe: {
+    params: UnsubscribeRequest;
+    result: string;
+  };
+};
+
+export type RequestMethod = keyof RequestMethods;
+
+/**
+ * The codes of the application errors returned by the rpc server
+ */
+export const ErrorCodes = {
+  InvalidAuthToken: -32008,
+  InvalidClientCredentials: -32011,
+  SpendingLimitExceeded: -32012,
+  ChannelNotFound: -32013,
+  InsufficientFunds: -32014,
+  PermissionDenied: -32015,
+  ChannelAlreadyExists: -32016,
+  SubscriptionsUnsupported: -32017,
+  SubscriptionNotFound: -32018,
+} as const;
+
+/**
+ * Sends a request to the rpc server, and resolves to the result of the request
+ */
+export type RequestSender = <M extends RequestMethod>(
+  method: M,
+  payload: RequestMethods[M]["params"]
+) => Promise<RequestMethods[M]["result"]>;
+
+/**
+ * A client of the rpc api, with a method for each method of the api
+ */
+export class RpcApi {
+  constructor(private readonly send: RequestSender) {}
+
+  /**
+   * Returns an auth token granting the permissions of the client
+   */
+  getAuthToken(
+    payload: RequestMethods["get_auth_token"]["params"]
+  ): Promise<RequestMethods["get_auth_token"]["result"]> {
+    return this.send("get_auth_token", payload);
+  }
+
+  /**
+   * Revokes an auth token, so that it can no longer be used
+   */
+  revokeAuthToken(
+    payload: RequestMethods["revoke_auth_token"]["params"]
+  ): Promise<RequestMethods["revoke_auth_token"]["result"]> {
+    return this.send("revoke_auth_token", payload);
+  }
+
+  /**
+   * Revokes every auth token issued to a client so far
+   */
+  revokeClientTokens(
+    payload: RequestMethods["revoke_client_tokens"]["params"]
+  ): Promise<RequestMethods["revoke_client_tokens"]["result"]> {
+    return this.send("revoke_client_tokens", payload);
+  }
+
+  /**
+   * Returns the address of the node
+   */
+  getAddress(
+    payload: RequestMethods["get_address"]["params"]
+  ): Promise<RequestMethods["get_address"]["result"]> {
+    return this.send("get_address", payload);
+  }
+
+  /**
+   * Returns the version of the node
+   */
+  version(
+    payload: RequestMethods["version"]["params"]
+  ): Promise<RequestMethods["version"]["result"]> {
+    return this.send("version", payload);
+  }
+
+  /**
+   * Creates a ledger channel with a counterparty
+   */
+  createLedgerChannel(
+    payload: RequestMethods["create_ledger_channel"]["params"]
+  ): Promise<RequestMethods["create_ledger_channel"]["result"]> {
+    return this.send("create_ledger_channel", payload);
+  }
+
+  /**
+   * Closes a ledger channel
+   */
+  closeLedgerChannel(
+    payload: RequestMethods["close_ledger_channel"]["params"]
+  ): Promise<RequestMethods["close_ledger_channel"]["result"]> {
+    return this.send("close_ledger_channel", payload);
+  }
+
+  /**
+   * Creates a payment channel funded by ledger channels with the intermediaries
+   */
+  createPaymentChannel(
+    payload: RequestMethods["create_payment_channel"]["params"]
+  ): Promise<RequestMethods["create_payment_channel"]["result"]> {
+    return this.send("create_payment_channel", payload);
+  }
+
+  /**
+   * Closes a payment channel
+   */
+  closePaymentChannel(
+    payload: RequestMethods["close_payment_channel"]["params"]
+  ): Promise<RequestMethods["close_payment_channel"]["result"]> {
+    return this.send("close_payment_channel", payload);
+  }
+
+  /**
+   * Pays an amount to the counterparty of a payment channel
+   */
+  pay(
+    payload: RequestMethods["pay"]["params"]
+  ): Promise<RequestMethods["pay"]["result"]> {
+    return this.send("pay", payload);
+  }
+
+  /**
+   * Creates a voucher paying an amount on a payment channel, for the caller to send to the payee
+   */
+  createVoucher(
+    payload: RequestMethods["create_voucher"]["params"]
+  ): Promise<RequestMethods["create_voucher"]["result"]> {
+    return this.send("create_voucher", payload);
+  }
+
+  /**
+   * Receives a voucher sent outside of the node
+   */
+  receiveVoucher(
+    payload: RequestMethods["receive_voucher"]["params"]
+  ): Promise<RequestMethods["receive_voucher"]["result"]> {
+    return this.send("receive_voucher", payload);
+  }
+
+  /**
+   * Returns a payment channel
+   */
+  getPaymentChannel(
+    payload: RequestMethods["get_payment_channel"]["params"]
+  ): Promise<RequestMethods["get_payment_channel"]["result"]> {
+    return this.send("get_payment_channel", payload);
+  }
+
+  /**
+   * Returns a ledger channel
+   */
+  getLedgerChannel(
+    payload: RequestMethods["get_ledger_channel"]["params"]
+  ): Promise<RequestMethods["get_ledger_channel"]["result"]> {
+    return this.send("get_ledger_channel", payload);
+  }
+
+  /**
+   * Returns every ledger channel
+   */
+  getAllLedgerChannels(
+    payload: RequestMethods["get_all_ledger_channels"]["params"]
+  ): Promise<RequestMethods["get_all_ledger_channels"]["result"]> {
+    return this.send("get_all_ledger_channels", payload);
+  }
+
+  /**
+   * Returns the active payment channels funded by a ledger channel
+   */
+  getPaymentChannelsByLedger(
+    payload: RequestMethods["get_payment_channels_by_ledger"]["params"]
+  ): Promise<RequestMethods["get_payment_channels_by_ledger"]["result"]> {
+    return this.send("get_payment_channels_by_ledger", payload);
+  }
+
+  /**
+   * Returns a page of the ledger channels matching a filter
+   */
+  getLedgerChannels(
+    payload: RequestMethods["get_ledger_channels"]["params"]
+  ): Promise<RequestMethods["get_ledger_channels"]["result"]> {
+    return this.send("get_ledger_channels", payload);
+  }
+
+  /**
+   * Returns a page of the payment channels matching a filter
+   */
+  getPaymentChannels(
+    payload: RequestMethods["get_payment_channels"]["params"]
+  ): Promise<RequestMethods["get_payment_channels"]["result"]> {
+    return this.send("get_payment_channels", payload);
+  }
+
+  /**
+   * Returns a page of the payments made on a payment channel
+   */
+  getPaymentHistory(
+    payload: RequestMethods["get_payment_history"]["params"]
+  ): Promise<RequestMethods["get_payment_history"]["result"]> {
+    return this.send("get_payment_history", payload);
+  }
+
+  /**
+   * Returns the balance snapshots of a channel taken in a period
+   */
+  getBalanceHistory(
+    payload: RequestMethods["get_balance_history"]["params"]
+  ): Promise<RequestMethods["get_balance_history"]["result"]> {
+    return this.send("get_balance_history", payload);
+  }
+
+  /**
+   * Returns the total balance of each asset at intervals over a period
+   */
+  getAssetBalanceHistory(
+    payload: RequestMethods["get_asset_balance_history"]["params"]
+  ): Promise<RequestMethods["get_asset_balance_history"]["result"]> {
+    return this.send("get_asset_balance_history", payload);
+  }
+
+  /**
+   * Returns a page of the notifications matching a filter sent after a sequence number, so that a client can replay the notifications it missed
+   */
+  getEvents(
+    payload: RequestMethods["get_events"]["params"]
+  ): Promise<RequestMethods["get_events"]["result"]> {
+    return this.send("get_events", payload);
+  }
+
+  /**
+   * Subscribes the connection to the notifications matching a filter, after which it is only sent the notifications matching one of its subscriptions
+   */
+  subscribe(
+    payload: RequestMethods["subscribe"]["params"]
+  ): Promise<RequestMethods["subscribe"]["result"]> {
+    return this.send("subscribe", payload);
+  }
+
+  /**
+   * Ends a subscription of the connection
+   */
+  unsubscribe(
+    payload: RequestMethods["unsubscribe"]["params"]
+  ): Promise<RequestMethods["unsubscribe"]["result"]> {
+    return this.send("unsubscribe", payload);
+  }
+}
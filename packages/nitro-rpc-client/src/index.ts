@@ -1 +1,2 @@
 export { NitroRpcClient } from "./rpc-client";
+export * as api from "./generated/api";
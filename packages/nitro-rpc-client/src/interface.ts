@@ -3,6 +3,7 @@ import {
   LedgerChannelInfo,
   ObjectiveResponse,
   PaymentChannelInfo,
+  ReceivedVoucher,
   PaymentPayload,
   ReceiveVoucherResult,
   Voucher,
@@ -130,6 +131,11 @@ interface syncAPI {
     channelId: string,
     callback: (info: PaymentChannelInfo) => void
   ): () => void;
+  /**
+   * onVoucherReceived attaches a callback which is triggered whenever the node receives a voucher from a payer.
+   * Returns a cleanup function which can be used to remove the subscription.
+   */
+  onVoucherReceived(callback: (voucher: ReceivedVoucher) => void): () => void;
 }
 
 export interface RpcClientApi
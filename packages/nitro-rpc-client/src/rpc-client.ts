@@ -3,6 +3,7 @@ import {
   DirectFundPayload,
   LedgerChannelInfo,
   PaymentChannelInfo,
+  ReceivedVoucher,
   PaymentPayload,
   VirtualFundPayload,
   RequestMethod,
@@ -117,6 +118,15 @@ export class NitroRpcClient implements RpcClientApi {
     };
   }
 
+  public onVoucherReceived(
+    callback: (voucher: ReceivedVoucher) => void
+  ): () => void {
+    this.transport.Notifications.on("voucher_received", callback);
+    return () => {
+      this.transport.Notifications.off("voucher_received", callback);
+    };
+  }
+
   public async CreateLedgerChannel(
     counterParty: string,
     amount: number
@@ -119,6 +119,17 @@ const receiveVoucherSchema = {
 
 type ReceiveVoucherSchemaType = JTDDataType<typeof receiveVoucherSchema>;
 
+const receivedVoucherSchema = {
+  properties: {
+    ChannelId: { type: "string" },
+    Payer: { type: "string" },
+    Delta: { type: "float64" },
+    Total: { type: "float64" },
+  },
+} as const;
+
+type ReceivedVoucherSchemaType = JTDDataType<typeof receivedVoucherSchema>;
+
 type ResponseSchema =
   | typeof objectiveSchema
   | typeof stringSchema
@@ -244,6 +255,23 @@ export function getAndValidateNotification<T extends RPCNotification["method"]>(
       );
     case "objective_completed":
       return data as string;
+    case "voucher_received": {
+      const validate = ajv.compile<ReceivedVoucherSchemaType>(
+        receivedVoucherSchema
+      );
+      if (!validate(data)) {
+        throw new Error(
+          `Error parsing json rpc notification: ${JSON.stringify(
+            validate.errors
+          )}`
+        );
+      }
+      return {
+        ...data,
+        Delta: BigInt(data.Delta),
+        Total: BigInt(data.Total),
+      };
+    }
     default:
       throw new Error(`Unknown method: ${method}`);
   }
@@ -16,7 +16,7 @@ export type JsonRpcResponse<ResultType> = {
 export type JsonRpcNotification<NotificationName, NotificationPayload> = {
   jsonrpc: "2.0";
   method: NotificationName;
-  params: { payload: NotificationPayload };
+  params: { payload: NotificationPayload; seq?: number };
 };
 
 export type JsonRpcError<Code, Message, Data = undefined> = {
@@ -77,6 +77,12 @@ export type ReceiveVoucherResult = {
   Total: bigint;
   Delta: bigint;
 };
+export type ReceivedVoucher = {
+  ChannelId: string;
+  Payer: string;
+  Delta: bigint;
+  Total: bigint;
+};
 
 /**
  * RPC Requests
@@ -191,7 +197,8 @@ export type RPCResponse =
 export type RPCNotification =
   | ObjectiveCompleteNotification
   | PaymentChannelUpdatedNotification
-  | LedgerChannelUpdatedNotification;
+  | LedgerChannelUpdatedNotification
+  | VoucherReceivedNotification;
 export type NotificationMethod = RPCNotification["method"];
 export type NotificationParams = RPCNotification["params"];
 export type PaymentChannelUpdatedNotification = JsonRpcNotification<
@@ -209,6 +216,11 @@ export type ObjectiveCompleteNotification = JsonRpcNotification<
   string
 >;
 
+export type VoucherReceivedNotification = JsonRpcNotification<
+  "voucher_received",
+  ReceivedVoucher
+>;
+
 /**
  * Outcome related types
  */
@@ -3,6 +3,7 @@ import {
   LedgerChannelInfo,
   Outcome,
   PaymentChannelInfo,
+  ReceivedVoucher,
   RequestMethod,
   RPCRequestAndResponses,
 } from "./types";
@@ -107,6 +108,12 @@ export async function logOutChannelUpdates(rpcClient: NitroRpcClient) {
       );
     }
   );
+  rpcClient.Notifications.on(
+    "voucher_received",
+    (voucher: ReceivedVoucher) => {
+      console.log(`${shortAddress}: Voucher received\n${prettyJson(voucher)}`);
+    }
+  );
 }
 
 function prettyJson(obj: unknown): string {
@@ -0,0 +1,227 @@
+package paymentproxy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// CREDIT_PATH is the endpoint of the proxy for buying credit ahead of requests, when it meters requests. Upstreams with a path prefix have the endpoint under their prefix.
+	// A POST with a voucher credits the channel of the voucher. A GET with a credit token returns the credit remaining.
+	CREDIT_PATH = "/nitro/credit"
+
+	// CREDIT_TOKEN_PARAM is the query param, or VOUCHER_AUTH_SCHEME auth param, that carries a credit token, such as
+	//
+	//	Authorization: Nitro credit="..."
+	CREDIT_TOKEN_PARAM = "credit"
+
+	// CREDIT_TOKEN_HEADER carries the credit token of a channel in responses to requests that credit it
+	CREDIT_TOKEN_HEADER = "Nitro-Credit-Token"
+	// CREDIT_HEADER carries the credit remaining after a metered request has been paid for
+	CREDIT_HEADER = "Nitro-Credit"
+)
+
+// Credit is the JSON body of responses from the CREDIT_PATH endpoint
+type Credit struct {
+	ChannelId types.Destination
+	// Token identifies the channel in requests paid for from its credit
+	Token string
+	// Credit is the amount that has been paid on the channel but not yet spent
+	Credit *big.Int
+}
+
+// creditAccounts holds the credit of each payment channel that has paid the proxy ahead of its requests.
+// Credit is held in memory, so it does not survive a restart of the proxy.
+type creditAccounts struct {
+	mu       sync.Mutex
+	balances map[types.Destination]*big.Int
+	// tokens identify the channel to debit for each request. Channel ids are known to the intermediaries of a channel, so cannot be used instead.
+	tokens        map[string]types.Destination
+	channelTokens map[types.Destination]string
+	// credited holds a chan for each channel that a metered response is waiting to be credited, which is closed when it is
+	credited map[types.Destination]chan struct{}
+}
+
+func newCreditAccounts() *creditAccounts {
+	return &creditAccounts{
+		balances:      make(map[types.Destination]*big.Int),
+		tokens:        make(map[string]types.Destination),
+		channelTokens: make(map[types.Destination]string),
+		credited:      make(map[types.Destination]chan struct{}),
+	}
+}
+
+// credit adds an amount to the credit of a channel, and returns the token of the channel and its new balance
+func (c *creditAccounts) credit(channelId types.Destination, amount *big.Int) (string, *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.channelTokens[channelId]
+	if !ok {
+		token = newCreditToken()
+		c.channelTokens[channelId] = token
+		c.tokens[token] = channelId
+		c.balances[channelId] = new(big.Int)
+	}
+	balance := c.balances[channelId].Add(c.balances[channelId], amount)
+	if credited, ok := c.credited[channelId]; ok {
+		close(credited)
+		delete(c.credited, channelId)
+	}
+	return token, new(big.Int).Set(balance)
+}
+
+// account returns the token and the credit of a channel
+func (c *creditAccounts) account(channelId types.Destination) (string, *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if balance, ok := c.balances[channelId]; ok {
+		return c.channelTokens[channelId], new(big.Int).Set(balance)
+	}
+	return "", new(big.Int)
+}
+
+// channel returns the channel identified by a credit token
+func (c *creditAccounts) channel(token string) (types.Destination, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	channelId, ok := c.tokens[token]
+	return channelId, ok
+}
+
+// debit spends an amount of the credit of a channel, and returns its new balance.
+// If the channel has less credit than the amount, nothing is spent and it returns false.
+func (c *creditAccounts) debit(channelId types.Destination, amount *big.Int) (*big.Int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.debitLocked(channelId, amount)
+}
+
+// debitOrWait spends an amount of the credit of a channel, like debit.
+// If the channel has less credit than the amount, it also returns a chan that is closed the next time the channel is credited.
+func (c *creditAccounts) debitOrWait(channelId types.Destination, amount *big.Int) (*big.Int, bool, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	balance, ok := c.debitLocked(channelId, amount)
+	if ok {
+		return balance, true, nil
+	}
+	credited, waiting := c.credited[channelId]
+	if !waiting {
+		credited = make(chan struct{})
+		c.credited[channelId] = credited
+	}
+	return balance, false, credited
+}
+
+func (c *creditAccounts) debitLocked(channelId types.Destination, amount *big.Int) (*big.Int, bool) {
+	balance, ok := c.balances[channelId]
+	if !ok {
+		return new(big.Int), false
+	}
+	if balance.Cmp(amount) < 0 {
+		return new(big.Int).Set(balance), false
+	}
+	balance.Sub(balance, amount)
+	return new(big.Int).Set(balance), true
+}
+
+func newCreditToken() string {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(token)
+}
+
+// creditVoucher receives the voucher in the params, if there is one, and credits its channel with the payment.
+// It returns the channel, or false if the request has no voucher.
+func (b *backend) creditVoucher(w http.ResponseWriter, r *http.Request) (types.Destination, bool, error) {
+	params := voucherParams(r)
+	if !params.Has(SIGNATURE_VOUCHER_PARAM) {
+		return types.Destination{}, false, nil
+	}
+	v, err := parseVoucher(params)
+	if err != nil {
+		return types.Destination{}, false, createPaymentError(fmt.Errorf("could not parse voucher: %w", err))
+	}
+	s, err := b.vouchers.ReceiveVoucher(v)
+	if err != nil {
+		return types.Destination{}, false, createPaymentError(fmt.Errorf("error processing voucher %w", err))
+	}
+	// A voucher that pays nothing may have been replayed by someone other than the payer, so it does not reveal the credit token
+	if s.Delta.Sign() <= 0 {
+		return types.Destination{}, false, createPaymentError(errors.New("the voucher did not result in a payment"))
+	}
+	token, balance := b.credits.credit(v.ChannelId, s.Delta)
+	slog.Debug("Credited voucher", "channelId", v.ChannelId, "delta", s.Delta, "credit", balance)
+
+	w.Header().Set(CREDIT_TOKEN_HEADER, token)
+	return v.ChannelId, true, nil
+}
+
+// meteredChannel returns the channel a metered request is paid for from.
+// A voucher in the request is credited to its channel first, so that a request can pay for itself and buy credit for later requests.
+func (b *backend) meteredChannel(w http.ResponseWriter, r *http.Request) (types.Destination, error) {
+	channelId, ok, err := b.creditVoucher(w, r)
+	if err != nil || ok {
+		return channelId, err
+	}
+
+	token := voucherParams(r).Get(CREDIT_TOKEN_PARAM)
+	if token == "" {
+		return types.Destination{}, createPaymentError(errors.New("could not parse voucher or credit token"))
+	}
+	channelId, ok = b.credits.channel(token)
+	if !ok {
+		return types.Destination{}, createPaymentError(errors.New("unknown credit token"))
+	}
+	return channelId, nil
+}
+
+// handleCredit serves the CREDIT_PATH endpoint of a backend
+func (p *PaymentProxy) handleCredit(w http.ResponseWriter, r *http.Request, b *backend) {
+	enableCors(w.Header())
+
+	var channelId types.Destination
+	switch r.Method {
+	case http.MethodPost:
+		var ok bool
+		var err error
+		channelId, ok, err = b.creditVoucher(w, r)
+		if err == nil && !ok {
+			err = createPaymentError(errors.New("could not parse voucher: missing signature"))
+		}
+		if err != nil {
+			p.handleError(w, r, err)
+			return
+		}
+	case http.MethodGet:
+		var ok bool
+		channelId, ok = b.credits.channel(voucherParams(r).Get(CREDIT_TOKEN_PARAM))
+		if !ok {
+			http.Error(w, "unknown credit token", http.StatusNotFound)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, balance := b.credits.account(channelId)
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(Credit{ChannelId: channelId, Token: token, Credit: balance})
+	if err != nil {
+		slog.Error("Could not write credit response", "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package paymentproxy
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreditAccounts(t *testing.T) {
+	credits := newCreditAccounts()
+	channelId := types.Destination{1}
+
+	_, ok := credits.debit(channelId, big.NewInt(1))
+	assert.False(t, ok)
+
+	token, balance := credits.credit(channelId, big.NewInt(10))
+	require.NotEmpty(t, token)
+	assert.Equal(t, big.NewInt(10), balance)
+
+	found, ok := credits.channel(token)
+	require.True(t, ok)
+	assert.Equal(t, channelId, found)
+	_, ok = credits.channel("unknown")
+	assert.False(t, ok)
+
+	// Overpayments carry over to later requests
+	balance, ok = credits.debit(channelId, big.NewInt(4))
+	require.True(t, ok)
+	assert.Equal(t, big.NewInt(6), balance)
+
+	// A debit of more than the credit spends nothing
+	balance, ok = credits.debit(channelId, big.NewInt(7))
+	assert.False(t, ok)
+	assert.Equal(t, big.NewInt(6), balance)
+
+	// Topping up keeps the token of the channel
+	sameToken, balance := credits.credit(channelId, big.NewInt(5))
+	assert.Equal(t, token, sameToken)
+	assert.Equal(t, big.NewInt(11), balance)
+
+	// Balances returned are copies
+	balance.SetInt64(1000)
+	_, balance = credits.account(channelId)
+	assert.Equal(t, big.NewInt(11), balance)
+
+	otherToken, _ := credits.credit(types.Destination{2}, big.NewInt(1))
+	assert.NotEqual(t, token, otherToken)
+}
+
+func TestStreamingCost(t *testing.T) {
+	streaming := loadTestPricing(t).Routes[2].Price
+	credit := big.NewInt(26)
+
+	// The cost reserved for a stream never exceeds the credit that paid for it
+	duration := streaming.StreamingDuration(credit)
+	assert.Equal(t, big.NewInt(25), streaming.streamingCost(duration))
+}
@@ -0,0 +1,118 @@
+package paymentproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// DEFAULT_PAYMENT_TIMEOUT is how long a response billed as it is sent waits for credit, once the credit of its channel has run out
+	DEFAULT_PAYMENT_TIMEOUT = 30 * time.Second
+
+	// METERED_CHUNK_SIZE is the most bytes of a response billed as it is sent that are read from the destination before they are paid for
+	METERED_CHUNK_SIZE = 32 * 1024
+
+	ErrCreditExhausted = types.ConstError("the channel ran out of credit")
+)
+
+// chargeAsSent charges for a metered response as its body is sent. The fee of the request is debited upfront, and each chunk of the body is debited before it is sent.
+func (p *PaymentProxy) chargeAsSent(r *http.Response, b *backend, price Price) error {
+	channelId, ok := r.Request.Context().Value(CHANNEL_CONTEXT_ARG).(types.Destination)
+	if !ok {
+		return createPaymentError(fmt.Errorf("could not fetch channel from context"))
+	}
+
+	cost := price.Cost(0)
+	credit, ok := b.credits.debit(channelId, cost)
+	if !ok {
+		err := fmt.Errorf("payment of %d attoFIL required, the channel only has %d attoFIL of credit", cost, credit)
+		return costError{error: createPaymentError(err), cost: cost, credit: credit}
+	}
+	slog.Debug("Billing response as it is sent", "channelId", channelId, "cost", cost, "credit", credit)
+	p.usage.charged(r.Request, cost)
+	request := r.Request
+
+	r.Body = &meteredBody{
+		body:      r.Body,
+		ctx:       r.Request.Context(),
+		credits:   b.credits,
+		channelId: channelId,
+		price:     price,
+		timeout:   p.paymentTimeout,
+		charged:   cost,
+		onCharge:  func(amount *big.Int) { p.usage.charged(request, amount) },
+	}
+	return nil
+}
+
+// meteredBody is a response body that debits the credit of a channel for the bytes read from it.
+// When the credit runs out, reads wait until the channel is credited, the timeout elapses or the request is cancelled.
+type meteredBody struct {
+	body      io.ReadCloser
+	ctx       context.Context
+	credits   *creditAccounts
+	channelId types.Destination
+	price     Price
+	timeout   time.Duration
+
+	// sent is the number of bytes read from the body, and charged is what they have been charged
+	sent    uint64
+	charged *big.Int
+	// onCharge, if set, is called with each amount debited for the body
+	onCharge func(amount *big.Int)
+}
+
+func (b *meteredBody) Read(data []byte) (int, error) {
+	if len(data) > METERED_CHUNK_SIZE {
+		data = data[:METERED_CHUNK_SIZE]
+	}
+	n, err := b.body.Read(data)
+	if n > 0 {
+		if payErr := b.pay(uint64(n)); payErr != nil {
+			return 0, payErr
+		}
+	}
+	return n, err
+}
+
+// pay debits the cost of sending n more bytes
+func (b *meteredBody) pay(n uint64) error {
+	cost := b.price.Cost(b.sent + n)
+	// Tiered prices may cost less for a larger response, in which case nothing more is owed
+	owed := new(big.Int).Sub(cost, b.charged)
+	for owed.Sign() > 0 {
+		credit, ok, credited := b.credits.debitOrWait(b.channelId, owed)
+		if ok {
+			b.charged = cost
+			if b.onCharge != nil {
+				b.onCharge(owed)
+			}
+			break
+		}
+
+		slog.Debug("Pausing response until the channel is credited", "channelId", b.channelId, "owed", owed, "credit", credit)
+		timer := time.NewTimer(b.timeout)
+		select {
+		case <-credited:
+			timer.Stop()
+		case <-timer.C:
+			return fmt.Errorf("%w: %d attoFIL was owed after %d bytes, but only %d attoFIL of credit was paid within %v", ErrCreditExhausted, owed, b.sent, credit, b.timeout)
+		case <-b.ctx.Done():
+			timer.Stop()
+			return b.ctx.Err()
+		}
+	}
+	b.sent += n
+	return nil
+}
+
+func (b *meteredBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,80 @@
+package paymentproxy
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const meteredContent = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+func newTestMeteredBody(credits *creditAccounts, channelId types.Destination, timeout time.Duration) *meteredBody {
+	return &meteredBody{
+		// Read a byte at a time, so that the bytes paid for are sent before the body pauses
+		body:      io.NopCloser(iotest.OneByteReader(strings.NewReader(meteredContent))),
+		ctx:       context.Background(),
+		credits:   credits,
+		channelId: channelId,
+		price:     Price{PerByte: 1},
+		timeout:   timeout,
+		charged:   new(big.Int),
+	}
+}
+
+func TestMeteredBodyResumesWhenCredited(t *testing.T) {
+	credits := newCreditAccounts()
+	channelId := types.Destination{1}
+	credits.credit(channelId, big.NewInt(10))
+	body := newTestMeteredBody(credits, channelId, time.Minute)
+
+	received := make(chan []byte)
+	go func() {
+		data, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		received <- data
+	}()
+
+	// The body pauses once the credit is spent
+	require.Eventually(t, func() bool {
+		credits.mu.Lock()
+		defer credits.mu.Unlock()
+		_, waiting := credits.credited[channelId]
+		return waiting
+	}, time.Second, time.Millisecond)
+	select {
+	case <-received:
+		t.Fatal("expected the body to pause until the channel is credited")
+	default:
+	}
+
+	credits.credit(channelId, big.NewInt(int64(len(meteredContent))))
+	select {
+	case data := <-received:
+		assert.Equal(t, meteredContent, string(data))
+	case <-time.After(time.Second):
+		t.Fatal("expected the body to resume once the channel is credited")
+	}
+
+	// Each byte is charged once, and the remaining credit carries over
+	_, credit := credits.account(channelId)
+	assert.Equal(t, big.NewInt(10), credit)
+}
+
+func TestMeteredBodyEndsWithoutCredit(t *testing.T) {
+	credits := newCreditAccounts()
+	channelId := types.Destination{1}
+	credits.credit(channelId, big.NewInt(10))
+	body := newTestMeteredBody(credits, channelId, 10*time.Millisecond)
+
+	data, err := io.ReadAll(body)
+	assert.ErrorIs(t, err, ErrCreditExhausted)
+	assert.Equal(t, meteredContent[:10], string(data))
+}
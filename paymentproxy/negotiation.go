@@ -0,0 +1,168 @@
+package paymentproxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/payments"
+)
+
+const (
+	// VOUCHER_AUTH_SCHEME is the scheme of Authorization headers carrying a voucher, such as
+	//
+	//	Authorization: Nitro channelId="0x...", amount="5", signature="0x..."
+	//
+	// It is also the scheme of the WWW-Authenticate challenge of 402 Payment Required responses.
+	VOUCHER_AUTH_SCHEME = "Nitro"
+
+	PAYEE_CHALLENGE_PARAM  = "payee"
+	ASSETS_CHALLENGE_PARAM = "assets"
+)
+
+// PaymentRequired is the JSON body of a 402 Payment Required response. It tells the client how to pay for the request it made.
+type PaymentRequired struct {
+	// Error describes why the request was not paid for
+	Error string
+	// Amount is the payment the request requires, if the proxy knows it.
+	// It is not known before the proxy has fetched a response that is priced by its size.
+	Amount *big.Int `json:",omitempty"`
+	// Price is the price of the request, from which a client can work out the cost of a response
+	Price Price
+	// Payee is the address of the nitro node that the upstream of the request is paid through. Vouchers must be for a payment channel with it.
+	Payee common.Address
+	// Assets are the assets the proxy accepts payment in. If empty, the proxy accepts any asset.
+	Assets []common.Address `json:",omitempty"`
+	// Intermediaries are nodes through which the client can open a payment channel with the payee
+	Intermediaries []common.Address `json:",omitempty"`
+	// Credit is the credit remaining on the client's channel, if the proxy meters requests
+	Credit *big.Int `json:",omitempty"`
+}
+
+// costError is a payment error for a request whose cost is known
+type costError struct {
+	error
+	cost *big.Int
+	// credit is the credit remaining on the channel of a metered request
+	credit *big.Int
+}
+
+func (e costError) Unwrap() error {
+	return e.error
+}
+
+// createCostError wraps an error with ErrPayment, recording the cost of the request.
+func createCostError(err error, cost *big.Int) error {
+	return costError{error: createPaymentError(err), cost: cost}
+}
+
+// knownCost returns the cost of any response to a request with the price, if it does not depend on the response
+func (p Price) knownCost() *big.Int {
+	switch {
+	case p.IsStreaming():
+		return p.MinimumStreamingCost()
+	case p.PerByte == 0 && len(p.Tiers) == 0:
+		return new(big.Int).SetUint64(p.PerRequest)
+	default:
+		return nil
+	}
+}
+
+// writePaymentRequired responds with a 402 Payment Required, describing how to pay for the request.
+// The payment terms are sent both as the JSON body and as a WWW-Authenticate challenge.
+func (p *PaymentProxy) writePaymentRequired(w http.ResponseWriter, r *http.Request, err error) {
+	b := p.backendOf(r)
+	price, ok := r.Context().Value(PRICE_CONTEXT_ARG).(Price)
+	if !ok {
+		price = b.pricing.PriceOf(r)
+	}
+	body := PaymentRequired{
+		Error:          err.Error(),
+		Amount:         price.knownCost(),
+		Price:          price,
+		Payee:          b.payee,
+		Assets:         b.pricing.Assets,
+		Intermediaries: b.pricing.Intermediaries,
+	}
+	var ce costError
+	if errors.As(err, &ce) {
+		body.Amount = ce.cost
+		body.Credit = ce.credit
+	}
+
+	challenge := fmt.Sprintf("%s %s=%q", VOUCHER_AUTH_SCHEME, PAYEE_CHALLENGE_PARAM, body.Payee.Hex())
+	if body.Amount != nil {
+		challenge += fmt.Sprintf(", %s=%q", AMOUNT_VOUCHER_PARAM, body.Amount.String())
+	}
+	if len(body.Assets) > 0 {
+		assets := make([]string, len(body.Assets))
+		for i, a := range body.Assets {
+			assets[i] = a.Hex()
+		}
+		challenge += fmt.Sprintf(", %s=%q", ASSETS_CHALLENGE_PARAM, strings.Join(assets, ","))
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusPaymentRequired)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Could not write payment required response", "error", err)
+	}
+}
+
+// voucherParams returns the voucher parameters of a request, from its Authorization header if it has the VOUCHER_AUTH_SCHEME, or otherwise from its query params.
+func voucherParams(r *http.Request) url.Values {
+	scheme, params, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, VOUCHER_AUTH_SCHEME) {
+		return r.URL.Query()
+	}
+	return parseAuthParams(params)
+}
+
+// parseAuthParams parses comma separated auth params, such as `channelId="0x...", amount=5`. Values may be quoted, in which case they may contain commas.
+func parseAuthParams(s string) url.Values {
+	params := url.Values{}
+	var param strings.Builder
+	inQuotes := false
+	add := func() {
+		key, value, ok := strings.Cut(strings.TrimSpace(param.String()), "=")
+		if ok {
+			params.Set(strings.TrimSpace(key), strings.Trim(strings.TrimSpace(value), `"`))
+		}
+		param.Reset()
+	}
+	for _, c := range s {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			add()
+			continue
+		}
+		param.WriteRune(c)
+	}
+	add()
+	return params
+}
+
+// ParsePaymentChallenge parses the WWW-Authenticate header of a 402 Payment Required response from the proxy.
+// It returns false if the header is not a challenge to pay with a voucher.
+func ParsePaymentChallenge(header string) (url.Values, bool) {
+	scheme, params, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, VOUCHER_AUTH_SCHEME) {
+		return nil, false
+	}
+	return parseAuthParams(params), true
+}
+
+// VoucherAuthorization returns the value of an Authorization header carrying the voucher
+func VoucherAuthorization(v payments.Voucher) string {
+	return fmt.Sprintf("%s %s=%q, %s=%q, %s=%q", VOUCHER_AUTH_SCHEME,
+		CHANNEL_ID_VOUCHER_PARAM, v.ChannelId.String(), AMOUNT_VOUCHER_PARAM, v.Amount.String(), SIGNATURE_VOUCHER_PARAM, v.Signature.ToHexString())
+}
@@ -0,0 +1,115 @@
+package paymentproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoucherAuthorization(t *testing.T) {
+	v := payments.Voucher{
+		ChannelId: types.Destination{1},
+		Amount:    big.NewInt(5),
+		Signature: state.Signature{R: []byte{1}, S: []byte{2}, V: 27},
+	}
+	v.Signature.R = common.LeftPadBytes(v.Signature.R, 32)
+	v.Signature.S = common.LeftPadBytes(v.Signature.S, 32)
+
+	r := httptest.NewRequest(http.MethodGet, "/resource?other=1", nil)
+	r.Header.Set("Authorization", VoucherAuthorization(v))
+	parsed, err := parseVoucher(voucherParams(r))
+	require.NoError(t, err)
+	assert.True(t, v.Equal(&parsed))
+
+	removeVoucher(r)
+	assert.Empty(t, r.Header.Get("Authorization"))
+	assert.Equal(t, "other=1", r.URL.RawQuery)
+
+	// Other authorization schemes are left for the destination server
+	r.Header.Set("Authorization", "Bearer token")
+	_, err = parseVoucher(voucherParams(r))
+	assert.Error(t, err)
+	removeVoucher(r)
+	assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+}
+
+func TestMalformedVoucher(t *testing.T) {
+	channelId := types.Destination{1}.String()
+	signature := "0x" + strings.Repeat("01", 65)
+	for name, params := range map[string]string{
+		"short signature":   fmt.Sprintf(`channelId=%s, amount=5, signature="0x0102"`, channelId),
+		"invalid signature": fmt.Sprintf(`channelId=%s, amount=5, signature="not-hex"`, channelId),
+		"invalid amount":    fmt.Sprintf(`channelId=%s, amount=five, signature=%s`, channelId, signature),
+		"negative amount":   fmt.Sprintf(`channelId=%s, amount=-5, signature=%s`, channelId, signature),
+		"invalid channel":   fmt.Sprintf(`channelId=0x01, amount=5, signature=%s`, signature),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			r.Header.Set("Authorization", VOUCHER_AUTH_SCHEME+" "+params)
+
+			// The voucher is rejected before it reaches the node, with a payment error that is answered with a 402
+			_, _, err := (&backend{}).creditVoucher(httptest.NewRecorder(), r)
+			assert.ErrorIs(t, err, ErrPayment)
+		})
+	}
+}
+
+func TestWritePaymentRequired(t *testing.T) {
+	payee := common.HexToAddress("0x01")
+	assets := []common.Address{common.HexToAddress("0x02"), common.HexToAddress("0x03")}
+	b := &backend{
+		payee: payee,
+		pricing: Pricing{
+			Default: Price{PerByte: 1},
+			Routes:  []Route{{Path: "/api/*", Price: Price{PerRequest: 10}}},
+			Assets:  assets,
+		},
+	}
+	p := &PaymentProxy{backends: []*backend{b}, usage: newUsageRecorder()}
+
+	testCases := []struct {
+		path           string
+		err            error
+		expectedAmount *big.Int
+	}{
+		{"/api/search", createPaymentError(fmt.Errorf("could not parse voucher")), big.NewInt(10)},
+		{"/file", createPaymentError(fmt.Errorf("could not parse voucher")), nil},
+		{"/file", createCostError(fmt.Errorf("payment too small"), big.NewInt(42)), big.NewInt(42)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			p.handleError(w, httptest.NewRequest(http.MethodGet, tc.path, nil), tc.err)
+
+			assert.Equal(t, http.StatusPaymentRequired, w.Code)
+			var body PaymentRequired
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.err.Error(), body.Error)
+			assert.Equal(t, tc.expectedAmount, body.Amount)
+			assert.Equal(t, payee, body.Payee)
+			assert.Equal(t, assets, body.Assets)
+			assert.Equal(t, b.pricing.PriceOf(httptest.NewRequest(http.MethodGet, tc.path, nil)), body.Price)
+
+			challenge, ok := ParsePaymentChallenge(w.Header().Get("WWW-Authenticate"))
+			require.True(t, ok)
+			assert.Equal(t, payee.Hex(), challenge.Get(PAYEE_CHALLENGE_PARAM))
+			assert.Equal(t, assets[0].Hex()+","+assets[1].Hex(), challenge.Get(ASSETS_CHALLENGE_PARAM))
+			if tc.expectedAmount != nil {
+				assert.Equal(t, tc.expectedAmount.String(), challenge.Get(AMOUNT_VOUCHER_PARAM))
+			} else {
+				assert.False(t, challenge.Has(AMOUNT_VOUCHER_PARAM))
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// Package paymentclient provides an http.RoundTripper that pays for requests to a paymentproxy.PaymentProxy with vouchers.
+package paymentclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/paymentproxy"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Transport is an http.RoundTripper that pays for requests with vouchers on a payment channel.
+//
+// By default, each request is first sent without a voucher. The proxy rejects it with 402 Payment Required before forwarding it
+// to the upstream, along with the amount the request costs if that does not depend on the response. The request is then sent once
+// more with a voucher for that amount, so the upstream only handles it once.
+//
+// If the cost depends on the response, only the upstream can tell it. Requests with an idempotent method are then sent with a
+// voucher that pays nothing, which the proxy forwards to learn the cost, before they are paid for. Other requests are not
+// repeated, and the 402 is returned to the caller.
+type Transport struct {
+	// Base sends the requests. If it is nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+	// InitialPayment is the amount paid with the first attempt of each request. If it is zero, the first attempt has no voucher.
+	// The proxy forwards a request with a voucher, so a request with a method that is not idempotent is not repeated if the payment is too small.
+	// A proxy that meters requests credits the payment to the channel, so that a repeated request only pays the shortfall.
+	// Other proxies keep a payment that is too small without crediting it.
+	InitialPayment uint64
+	// MaxPayment is the most that is paid for a request. If it is zero, there is no limit.
+	MaxPayment uint64
+
+	nitroClient rpc.RpcClientApi
+	channelId   types.Destination
+}
+
+// NewTransport creates a Transport that pays for requests with vouchers on the payment channel, created by the nitro node of the client
+func NewTransport(nitroClient rpc.RpcClientApi, channelId types.Destination) *Transport {
+	return &Transport{nitroClient: nitroClient, channelId: channelId}
+}
+
+// NewClient creates an http.Client that pays for requests with vouchers on the payment channel
+func NewClient(nitroClient rpc.RpcClientApi, channelId types.Destination) *http.Client {
+	return &http.Client{Transport: NewTransport(nitroClient, channelId)}
+}
+
+// RoundTrip sends the request, and sends it once more with a voucher for the amount the proxy requires if the first attempt did not pay enough.
+// A request that may have reached the upstream is only sent again if its method is idempotent.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	getBody, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A request with a voucher is forwarded to the upstream, even if the voucher pays too little
+	forwarded := t.InitialPayment > 0
+	resp, err := t.send(req, getBody, t.InitialPayment)
+	if err != nil || resp.StatusCode != http.StatusPaymentRequired {
+		return resp, err
+	}
+	challenge, err := readChallenge(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge.amount == nil && !forwarded && isIdempotent(req.Method) {
+		// The cost depends on the response, so the proxy must forward the request to learn it
+		forwarded = true
+		resp, err = t.sendWithVoucher(req, getBody, 0)
+		if err != nil || resp.StatusCode != http.StatusPaymentRequired {
+			return resp, err
+		}
+		if challenge, err = readChallenge(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if forwarded && !isIdempotent(req.Method) {
+		slog.Debug("Not repeating request that may have reached the upstream", "url", req.URL, "method", req.Method)
+		return resp, nil
+	}
+	amount, err := t.requiredPayment(challenge)
+	if err != nil {
+		// The 402 is returned to the caller to handle, as the payment cannot be made
+		slog.Debug("Not paying for request", "url", req.URL, "error", err)
+		return resp, nil
+	}
+	return t.sendWithVoucher(req, getBody, amount)
+}
+
+// send sends a copy of the request with a voucher paying amount, or without a voucher if amount is zero
+func (t *Transport) send(req *http.Request, getBody func() (io.ReadCloser, error), amount uint64) (*http.Response, error) {
+	if amount == 0 {
+		return t.roundTrip(req, getBody, "")
+	}
+	return t.sendWithVoucher(req, getBody, amount)
+}
+
+// sendWithVoucher sends a copy of the request with a voucher paying amount
+func (t *Transport) sendWithVoucher(req *http.Request, getBody func() (io.ReadCloser, error), amount uint64) (*http.Response, error) {
+	voucher, err := t.nitroClient.CreateVoucher(t.channelId, amount)
+	if err != nil {
+		return nil, fmt.Errorf("could not create voucher: %w", err)
+	}
+	return t.roundTrip(req, getBody, paymentproxy.VoucherAuthorization(voucher))
+}
+
+// roundTrip sends a copy of the request with the Authorization header, if it is not empty
+func (t *Transport) roundTrip(req *http.Request, getBody func() (io.ReadCloser, error), authorization string) (*http.Response, error) {
+	sent := req.Clone(req.Context())
+	if getBody != nil {
+		var err error
+		if sent.Body, err = getBody(); err != nil {
+			return nil, err
+		}
+	}
+	if authorization != "" {
+		sent.Header.Set("Authorization", authorization)
+	}
+	return t.base().RoundTrip(sent)
+}
+
+// paymentChallenge is what a 402 Payment Required response asks the transport to pay
+type paymentChallenge struct {
+	params url.Values
+	// amount is the cost of the request, or nil if the proxy did not say
+	amount *big.Int
+	// credit is the credit remaining on the channel, if the proxy meters requests
+	credit *big.Int
+	err    error
+}
+
+// readChallenge reads the challenge of a 402 Payment Required response, leaving its body to be read again
+func readChallenge(resp *http.Response) (paymentChallenge, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return paymentChallenge{}, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	params, ok := paymentproxy.ParsePaymentChallenge(resp.Header.Get("WWW-Authenticate"))
+	if !ok {
+		return paymentChallenge{err: fmt.Errorf("the response is not a challenge to pay with a voucher")}, nil
+	}
+	challenge := paymentChallenge{params: params}
+
+	var paymentRequired paymentproxy.PaymentRequired
+	if json.Unmarshal(body, &paymentRequired) == nil {
+		challenge.amount, challenge.credit = paymentRequired.Amount, paymentRequired.Credit
+	}
+	if raw := params.Get(paymentproxy.AMOUNT_VOUCHER_PARAM); raw != "" {
+		amount, ok := new(big.Int).SetString(raw, 10)
+		if !ok {
+			challenge.err = fmt.Errorf("invalid amount %q", raw)
+		}
+		challenge.amount = amount
+	}
+	return challenge, nil
+}
+
+// requiredPayment returns the amount that a payment challenge asks for, if the transport can pay it.
+// Any credit on the channel counts towards the payment.
+func (t *Transport) requiredPayment(challenge paymentChallenge) (uint64, error) {
+	if challenge.err != nil {
+		return 0, challenge.err
+	}
+	amount := challenge.amount
+	if amount == nil {
+		return 0, fmt.Errorf("the proxy did not say how much the request costs")
+	}
+	if !amount.IsUint64() || amount.Sign() == 0 {
+		return 0, fmt.Errorf("cannot pay %s", amount)
+	}
+	if t.MaxPayment != 0 && amount.Uint64() > t.MaxPayment {
+		return 0, fmt.Errorf("the payment of %s is more than the maximum of %d", amount, t.MaxPayment)
+	}
+	if challenge.credit != nil && challenge.credit.Sign() > 0 {
+		amount = new(big.Int).Sub(amount, challenge.credit)
+		if amount.Sign() <= 0 {
+			return 0, fmt.Errorf("the channel has enough credit to pay for the request")
+		}
+	}
+
+	// The proxy must be paid through the payee of the channel, in the asset of the channel
+	channel, err := t.nitroClient.GetPaymentChannel(t.channelId)
+	if err != nil {
+		return 0, fmt.Errorf("could not get payment channel: %w", err)
+	}
+	if payee := common.HexToAddress(challenge.params.Get(paymentproxy.PAYEE_CHALLENGE_PARAM)); payee != channel.Balance.Payee {
+		return 0, fmt.Errorf("the proxy is paid through %s, not the payee of the channel %s", payee, channel.Balance.Payee)
+	}
+	if assets := challenge.params.Get(paymentproxy.ASSETS_CHALLENGE_PARAM); assets != "" && !acceptsAsset(assets, channel.Balance.AssetAddress) {
+		return 0, fmt.Errorf("the proxy does not accept the asset of the channel %s", channel.Balance.AssetAddress)
+	}
+	return amount.Uint64(), nil
+}
+
+func acceptsAsset(assets string, asset common.Address) bool {
+	for _, a := range strings.Split(assets, ",") {
+		if common.HexToAddress(strings.TrimSpace(a)) == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent returns true if sending a request with the method more than once has the same effect as sending it once (RFC 9110, section 9.2.2)
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// replayableBody returns a function that returns a copy of the body of the request, so that it can be sent more than once.
+// It returns nil if the request has no body.
+func replayableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		req.Body.Close()
+		return req.GetBody, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}, nil
+}
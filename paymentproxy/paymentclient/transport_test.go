@@ -0,0 +1,185 @@
+package paymentclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/paymentproxy"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const requestCost = 10
+
+var payee = common.HexToAddress("0x01")
+
+// fakeNitroClient creates vouchers for a channel with the payee, recording the amount of each
+type fakeNitroClient struct {
+	rpc.RpcClientApi
+	payments []uint64
+}
+
+func (c *fakeNitroClient) CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error) {
+	c.payments = append(c.payments, amount)
+	return payments.Voucher{
+		ChannelId: chId,
+		Amount:    new(big.Int).SetUint64(amount),
+		Signature: state.Signature{R: make([]byte, 32), S: make([]byte, 32)},
+	}, nil
+}
+
+func (c *fakeNitroClient) GetPaymentChannel(chId types.Destination) (query.PaymentChannelInfo, error) {
+	return query.PaymentChannelInfo{ID: chId, Balance: query.PaymentChannelBalance{Payee: payee}}, nil
+}
+
+// paidServer mimics a payment proxy that charges requestCost for each request, and echoes the request body.
+// Like the proxy, it rejects a request without a voucher before its upstream handles it, and charges for the request once the upstream has handled it.
+type paidServer struct {
+	*httptest.Server
+	// upstreamHits counts the requests handled by the upstream
+	upstreamHits int
+	// costDependsOnResponse is true if the cost is only known once the upstream has handled the request
+	costDependsOnResponse bool
+	// metered is true if payments are credited to the channel, and requests are paid for from its credit
+	metered bool
+	credit  int
+}
+
+func runPaidServer(t *testing.T, challengePayee common.Address) *paidServer {
+	s := &paidServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paymentRequired := func(cost *int) {
+			challenge := fmt.Sprintf(`Nitro payee="%s"`, challengePayee.Hex())
+			body := paymentproxy.PaymentRequired{Error: "payment required", Payee: challengePayee}
+			if cost != nil {
+				challenge += fmt.Sprintf(`, amount="%d"`, *cost)
+				body.Amount = big.NewInt(int64(*cost))
+			}
+			if s.metered {
+				body.Credit = big.NewInt(int64(s.credit))
+			}
+			w.Header().Set("WWW-Authenticate", challenge)
+			w.WriteHeader(http.StatusPaymentRequired)
+			require.NoError(t, json.NewEncoder(w).Encode(body))
+		}
+
+		cost := requestCost
+		// The Authorization header has the same syntax as the challenge
+		voucher, ok := paymentproxy.ParsePaymentChallenge(r.Header.Get("Authorization"))
+		if !ok {
+			if s.costDependsOnResponse {
+				paymentRequired(nil)
+			} else {
+				paymentRequired(&cost)
+			}
+			return
+		}
+		amount, err := strconv.Atoi(voucher.Get(paymentproxy.AMOUNT_VOUCHER_PARAM))
+		require.NoError(t, err)
+
+		s.upstreamHits++
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if s.metered {
+			s.credit += amount
+			amount = s.credit
+		}
+		if amount < cost {
+			paymentRequired(&cost)
+			return
+		}
+		if s.metered {
+			s.credit -= cost
+		}
+		_, err = w.Write(body)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestTransportPaysRequiredAmount(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		method                string
+		costDependsOnResponse bool
+		metered               bool
+		initialPayment        uint64
+		expectedPayments      []uint64
+		expectedUpstreamHits  int
+		expectedStatus        int
+	}{
+		{"known cost", http.MethodPost, false, false, 0, []uint64{requestCost}, 1, http.StatusOK},
+		{"cost depends on the response", http.MethodGet, true, false, 0, []uint64{0, requestCost}, 2, http.StatusOK},
+		{"cost depends on the response, not idempotent", http.MethodPost, true, false, 0, nil, 0, http.StatusPaymentRequired},
+		{"initial payment too small, not idempotent", http.MethodPost, false, false, 4, []uint64{4}, 1, http.StatusPaymentRequired},
+		{"initial payment too small", http.MethodPut, false, false, 4, []uint64{4, requestCost}, 2, http.StatusOK},
+		{"initial payment too small, metered", http.MethodPut, false, true, 4, []uint64{4, requestCost - 4}, 2, http.StatusOK},
+		{"initial payment enough", http.MethodPost, false, false, requestCost, []uint64{requestCost}, 1, http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := runPaidServer(t, payee)
+			server.costDependsOnResponse = tc.costDependsOnResponse
+			server.metered = tc.metered
+			nitroClient := &fakeNitroClient{}
+			transport := NewTransport(nitroClient, types.Destination{1})
+			transport.InitialPayment = tc.initialPayment
+
+			req, err := http.NewRequest(tc.method, server.URL, strings.NewReader("request body"))
+			require.NoError(t, err)
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, "request body", string(body))
+			}
+			assert.Equal(t, tc.expectedPayments, nitroClient.payments)
+			assert.Equal(t, tc.expectedUpstreamHits, server.upstreamHits)
+		})
+	}
+}
+
+func TestTransportDoesNotPay(t *testing.T) {
+	testCases := []struct {
+		name           string
+		challengePayee common.Address
+		maxPayment     uint64
+	}{
+		{"another payee", common.HexToAddress("0x02"), 0},
+		{"more than the maximum", payee, requestCost - 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := runPaidServer(t, tc.challengePayee)
+			nitroClient := &fakeNitroClient{}
+			transport := NewTransport(nitroClient, types.Destination{1})
+			transport.MaxPayment = tc.maxPayment
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+			assert.Empty(t, nitroClient.payments)
+			assert.Equal(t, 0, server.upstreamHits)
+		})
+	}
+}
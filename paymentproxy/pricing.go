@@ -0,0 +1,222 @@
+package paymentproxy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Pricing determines what the proxy charges for each request
+type Pricing struct {
+	// Default is the price of requests that match none of the routes
+	Default Price `toml:"default"`
+	// Routes are matched against each request in order. The first route that matches sets the price of the request.
+	Routes []Route `toml:"routes"`
+
+	// Assets are the assets the proxy accepts payment in, which it advertises to clients that have not paid. If empty, any asset is accepted.
+	Assets []common.Address `toml:"assets"`
+	// Intermediaries are nodes through which clients can open a payment channel with the proxy's node, which it advertises to clients that have not paid
+	Intermediaries []common.Address `toml:"intermediaries"`
+}
+
+// Route prices the requests matching a method and path pattern
+type Route struct {
+	// Method is the http method of the requests the route matches. If it is empty, the route matches every method.
+	Method string `toml:"method"`
+	// Path is a pattern matched against the request path, with the syntax of path.Match.
+	// A pattern ending in "/**" matches every path under the prefix before it.
+	Path string `toml:"path"`
+	Price
+}
+
+// Price is what the proxy charges for a request. Its charges are added together.
+//
+// A price with a PerSecond charge is for streaming responses, which are paid for upfront by the second: the response ends
+// once the time paid for by the voucher, after PerRequest, has elapsed. It cannot also charge by response size.
+type Price struct {
+	// PerRequest is a flat fee charged for every request
+	PerRequest uint64 `toml:"perrequest"`
+	// PerByte is charged for each byte of the response body, unless one of the Tiers applies
+	PerByte uint64 `toml:"perbyte"`
+	// Tiers price responses by their size. The first tier the response fits in applies.
+	Tiers []Tier `toml:"tiers"`
+	// PerSecond is charged for each second a streaming response is sent for
+	PerSecond uint64 `toml:"persecond"`
+}
+
+// Tier prices the responses of up to a size
+type Tier struct {
+	// UpTo is the largest response size, in bytes, that the tier applies to. If it is zero, the tier applies to responses of any size.
+	UpTo uint64 `toml:"upto"`
+	// Fee is a flat fee charged for responses in the tier
+	Fee uint64 `toml:"fee"`
+	// PerByte is charged for each byte of responses in the tier
+	PerByte uint64 `toml:"perbyte"`
+}
+
+// LoadPricing loads the pricing from a TOML file, such as
+//
+//	assets = ["0x0000000000000000000000000000000000000000"]
+//	intermediaries = ["0x111A00868581f73AB42FEEF67D235Ca09ca1E8db"]
+//
+//	[default]
+//	perbyte = 1
+//
+//	[[routes]]
+//	method = "GET"
+//	path = "/api/premium/**"
+//	perrequest = 100
+//
+//	[[routes]]
+//	path = "/files/*"
+//	tiers = [{ upto = 1024, fee = 10 }, { upto = 1048576, fee = 500 }, { perbyte = 1 }]
+//
+//	[[routes]]
+//	path = "/live/**"
+//	perrequest = 5
+//	persecond = 2
+func LoadPricing(filepath string) (Pricing, error) {
+	var pricing Pricing
+	_, err := toml.DecodeFile(filepath, &pricing)
+	if err != nil {
+		return Pricing{}, fmt.Errorf("could not load pricing from %s: %w", filepath, err)
+	}
+	if err := pricing.Validate(); err != nil {
+		return Pricing{}, fmt.Errorf("invalid pricing in %s: %w", filepath, err)
+	}
+	return pricing, nil
+}
+
+// Validate checks that the route patterns are well formed and that each price is consistent
+func (p Pricing) Validate() error {
+	if err := p.Default.validate(); err != nil {
+		return fmt.Errorf("default price: %w", err)
+	}
+	for i, r := range p.Routes {
+		if r.Path == "" {
+			return fmt.Errorf("route %d has no path", i)
+		}
+		if _, err := path.Match(strings.TrimSuffix(r.Path, "/**"), ""); err != nil {
+			return fmt.Errorf("route %d has an invalid path pattern %q: %w", i, r.Path, err)
+		}
+		if err := r.Price.validate(); err != nil {
+			return fmt.Errorf("route %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (p Price) validate() error {
+	if p.PerSecond > 0 && (p.PerByte > 0 || len(p.Tiers) > 0) {
+		return errors.New("a price cannot charge both per second and by response size")
+	}
+	for i, t := range p.Tiers {
+		if t.UpTo == 0 && i != len(p.Tiers)-1 {
+			return errors.New("only the last tier can apply to responses of any size")
+		}
+		if i > 0 && t.UpTo != 0 && t.UpTo <= p.Tiers[i-1].UpTo {
+			return errors.New("tiers must be in increasing order of size")
+		}
+	}
+	return nil
+}
+
+// PriceOf returns the price of a request
+func (p Pricing) PriceOf(r *http.Request) Price {
+	_, price := p.routeOf(r)
+	return price
+}
+
+// routeOf returns the label of the route that prices a request, and its price. Requests that match none of the routes have the DEFAULT_ROUTE.
+func (p Pricing) routeOf(r *http.Request) (string, Price) {
+	for _, route := range p.Routes {
+		if route.matches(r) {
+			return route.label(), route.Price
+		}
+	}
+	return DEFAULT_ROUTE, p.Default
+}
+
+// label identifies the route by its method and path pattern
+func (r Route) label() string {
+	if r.Method == "" {
+		return r.Path
+	}
+	return strings.ToUpper(r.Method) + " " + r.Path
+}
+
+func (r Route) matches(req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "/**"); ok {
+		if matched, _ := path.Match(prefix, req.URL.Path); matched {
+			return true
+		}
+		// Match the prefix against as many leading segments of the path as it has
+		segments := strings.Split(req.URL.Path, "/")
+		n := strings.Count(prefix, "/") + 1
+		if len(segments) <= n {
+			return false
+		}
+		matched, _ := path.Match(prefix, strings.Join(segments[:n], "/"))
+		return matched
+	}
+	matched, _ := path.Match(r.Path, req.URL.Path)
+	return matched
+}
+
+// IsStreaming returns true if the price is for streaming responses, which are charged by the second
+func (p Price) IsStreaming() bool {
+	return p.PerSecond > 0
+}
+
+// Cost returns the cost of a response with a body of size bytes
+func (p Price) Cost(size uint64) *big.Int {
+	perByte, fee := p.PerByte, uint64(0)
+	for _, t := range p.Tiers {
+		if t.UpTo == 0 || size <= t.UpTo {
+			perByte, fee = t.PerByte, t.Fee
+			break
+		}
+	}
+
+	cost := new(big.Int).SetUint64(perByte)
+	cost.Mul(cost, new(big.Int).SetUint64(size))
+	cost.Add(cost, new(big.Int).SetUint64(fee))
+	return cost.Add(cost, new(big.Int).SetUint64(p.PerRequest))
+}
+
+// MinimumStreamingCost returns the cost of a streaming response sent for one second, the least that a streaming request can be paid
+func (p Price) MinimumStreamingCost() *big.Int {
+	cost := new(big.Int).SetUint64(p.PerRequest)
+	return cost.Add(cost, new(big.Int).SetUint64(p.PerSecond))
+}
+
+// streamingCost returns the cost of a streaming response sent for a duration, in whole seconds
+func (p Price) streamingCost(duration time.Duration) *big.Int {
+	cost := new(big.Int).SetUint64(p.PerSecond)
+	cost.Mul(cost, big.NewInt(int64(duration/time.Second)))
+	return cost.Add(cost, new(big.Int).SetUint64(p.PerRequest))
+}
+
+// StreamingDuration returns how long a streaming response can be sent for, given the amount paid for it
+func (p Price) StreamingDuration(paid *big.Int) time.Duration {
+	remaining := new(big.Int).Sub(paid, new(big.Int).SetUint64(p.PerRequest))
+	if remaining.Sign() <= 0 || p.PerSecond == 0 {
+		return 0
+	}
+	seconds := remaining.Div(remaining, new(big.Int).SetUint64(p.PerSecond))
+	if !seconds.IsInt64() || seconds.Int64() > math.MaxInt64/int64(time.Second) {
+		return math.MaxInt64
+	}
+	return time.Duration(seconds.Int64()) * time.Second
+}
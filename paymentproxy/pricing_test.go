@@ -0,0 +1,99 @@
+package paymentproxy
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPricing = `
+[default]
+perbyte = 1
+
+[[routes]]
+method = "POST"
+path = "/api/*"
+perrequest = 100
+
+[[routes]]
+path = "/files/**"
+tiers = [{ upto = 10, fee = 5 }, { upto = 100, fee = 50 }, { perbyte = 2 }]
+
+[[routes]]
+path = "/live/**"
+perrequest = 5
+persecond = 2
+`
+
+func loadTestPricing(t *testing.T) Pricing {
+	file := filepath.Join(t.TempDir(), "pricing.toml")
+	require.NoError(t, os.WriteFile(file, []byte(testPricing), 0o600))
+	pricing, err := LoadPricing(file)
+	require.NoError(t, err)
+	return pricing
+}
+
+func TestPriceOf(t *testing.T) {
+	pricing := loadTestPricing(t)
+
+	testCases := []struct {
+		method, path string
+		expected     Price
+	}{
+		{"POST", "/api/search", pricing.Routes[0].Price},
+		{"GET", "/api/search", pricing.Default},
+		{"POST", "/api/search/more", pricing.Default},
+		{"GET", "/files", pricing.Routes[1].Price},
+		{"GET", "/files/a/b.txt", pricing.Routes[1].Price},
+		{"GET", "/filesystem", pricing.Default},
+		{"GET", "/live/channel", pricing.Routes[2].Price},
+		{"GET", "/", pricing.Default},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, pricing.PriceOf(httptest.NewRequest(tc.method, tc.path, nil)))
+		})
+	}
+}
+
+func TestCost(t *testing.T) {
+	pricing := loadTestPricing(t)
+
+	assert.Equal(t, big.NewInt(42), pricing.Default.Cost(42))
+	assert.Equal(t, big.NewInt(100), pricing.Routes[0].Cost(0))
+
+	tiered := pricing.Routes[1].Price
+	assert.Equal(t, big.NewInt(5), tiered.Cost(10))
+	assert.Equal(t, big.NewInt(50), tiered.Cost(11))
+	assert.Equal(t, big.NewInt(202), tiered.Cost(101))
+}
+
+func TestStreamingDuration(t *testing.T) {
+	streaming := loadTestPricing(t).Routes[2].Price
+
+	assert.True(t, streaming.IsStreaming())
+	assert.Equal(t, big.NewInt(7), streaming.MinimumStreamingCost())
+	assert.Equal(t, time.Duration(0), streaming.StreamingDuration(big.NewInt(5)))
+	assert.Equal(t, time.Second, streaming.StreamingDuration(big.NewInt(8)))
+	assert.Equal(t, 10*time.Second, streaming.StreamingDuration(big.NewInt(25)))
+}
+
+func TestValidatePricing(t *testing.T) {
+	for name, pricing := range map[string]Pricing{
+		"missing path":          {Routes: []Route{{Method: "GET"}}},
+		"invalid pattern":       {Routes: []Route{{Path: "/[a"}}},
+		"streaming and size":    {Default: Price{PerByte: 1, PerSecond: 1}},
+		"unbounded middle tier": {Default: Price{Tiers: []Tier{{Fee: 1}, {UpTo: 10, Fee: 2}}}},
+		"unordered tiers":       {Default: Price{Tiers: []Tier{{UpTo: 10}, {UpTo: 5}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, pricing.Validate())
+		})
+	}
+}
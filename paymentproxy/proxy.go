@@ -1,6 +1,7 @@
 package paymentproxy
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,8 +11,11 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/payments"
@@ -26,7 +30,11 @@ const (
 	CHANNEL_ID_VOUCHER_PARAM = "channelId"
 	SIGNATURE_VOUCHER_PARAM  = "signature"
 
-	VOUCHER_CONTEXT_ARG contextKey = "voucher"
+	VOUCHER_CONTEXT_ARG  contextKey = "voucher"
+	PRICE_CONTEXT_ARG    contextKey = "price"
+	CHANNEL_CONTEXT_ARG  contextKey = "channel"
+	UPSTREAM_CONTEXT_ARG contextKey = "upstream"
+	REPLICA_CONTEXT_ARG  contextKey = "replica"
 
 	ErrPayment = types.ConstError("payment error")
 )
@@ -39,38 +47,178 @@ func createPaymentError(err error) error {
 // PaymentProxy is an HTTP proxy that charges for HTTP requests.
 type PaymentProxy struct {
 	server       *http.Server
-	nitroClient  rpc.RpcClientApi
-	costPerByte  uint64
 	reverseProxy *httputil.ReverseProxy
+	// backends are the upstreams that requests are forwarded to, in the order they are routed
+	backends []*backend
+	// nitroClients are the clients of the nitro nodes the backends are paid through, one for each endpoint
+	nitroClients []rpc.RpcClientApi
+	// verifiers receive vouchers for the nitro nodes, if the proxy verifies vouchers itself
+	verifiers []*localVerifier
+	// streamBilling charges for metered responses as they are sent, rather than before
+	streamBilling  bool
+	paymentTimeout time.Duration
+
+	healthCheckInterval time.Duration
+
+	usage               *usageRecorder
+	usageToken          string
+	usageReportPath     string
+	usageReportInterval time.Duration
+
+	// stop is closed when the proxy is stopped, to stop its background goroutines
+	stop chan struct{}
+	// background waits for the goroutines that must finish before the proxy has stopped
+	background sync.WaitGroup
 
-	destinationUrl            *url.URL
 	certFilePath, certKeyPath string
 }
 
-// NewPaymentProxy creates a new PaymentProxy.
+// Config configures a PaymentProxy
+type Config struct {
+	// ProxyAddress is the TCP address the proxy listens on, in the form "host:port"
+	ProxyAddress string
+	// NitroEndpoint is the endpoint of the rpc server of the nitro node the proxy is paid through
+	NitroEndpoint string
+	// DestinationUrl is the url requests are forwarded to, if they are not routed to one of the Upstreams.
+	// If it is empty, requests that match none of the Upstreams are not found.
+	DestinationUrl string
+	// Pricing determines what the proxy charges for each request forwarded to the DestinationUrl
+	Pricing Pricing
+	// Upstreams are services that requests are routed to by their host and path, each with its own pricing and nitro node.
+	// A request is forwarded to the first upstream it matches.
+	Upstreams []Upstream
+	// HealthCheckInterval is how often the replicas of upstreams with a health path are checked. It defaults to DEFAULT_HEALTH_CHECK_INTERVAL.
+	HealthCheckInterval time.Duration
+	// Metering makes the proxy charge requests to credit bought ahead of them, rather than requiring each request to carry a voucher paying for it.
+	// Vouchers are credited to their channel, either on the CREDIT_PATH endpoint or along with a request, and any payment left over is kept as credit.
+	Metering bool
+	// StreamBilling makes a metering proxy charge for the bytes of a response as they are sent, rather than buffering the response to charge for it upfront.
+	// When the credit of the channel runs out, the response is paused until the channel is credited again, for example by a voucher sent to the CREDIT_PATH endpoint.
+	// It does not apply to streaming prices, which charge by the second.
+	StreamBilling bool
+	// LocalVerification makes the proxy verify vouchers itself, against a cache of the state of each channel, rather than waiting for the nitro node to receive them.
+	// Vouchers are forwarded to the node in batches, every VoucherBatchInterval. It requires the proxy to be the only receiver of vouchers on the channels of the node.
+	LocalVerification bool
+	// VoucherBatchInterval is how often vouchers verified by the proxy are forwarded to the nitro node. It defaults to DEFAULT_VOUCHER_BATCH_INTERVAL.
+	VoucherBatchInterval time.Duration
+	// PaymentTimeout is how long a paused response waits for credit before it is ended. It defaults to DEFAULT_PAYMENT_TIMEOUT.
+	PaymentTimeout time.Duration
+	// UsageToken enables the USAGE_PATH endpoint, which reports the usage of each channel to requests with the token as a bearer token
+	UsageToken string
+	// UsageReportPath is a file that the usage of each channel is written to every UsageReportInterval, and when the proxy is stopped.
+	// It is written as CSV if it has a .csv extension, and otherwise as JSON. If it is empty, no report is written.
+	UsageReportPath string
+	// UsageReportInterval defaults to DEFAULT_USAGE_REPORT_INTERVAL
+	UsageReportInterval time.Duration
+	// CertFilePath and CertKeyPath are the TLS certificate and private key of the proxy. If either is empty, TLS is not used.
+	CertFilePath, CertKeyPath string
+}
+
+// NewPaymentProxy creates a new PaymentProxy that charges costPerByte for each byte of every response.
 func NewPaymentProxy(proxyAddress string, nitroEndpoint string, destinationURL string, costPerByte uint64, certFilePath, certKeyPath string) *PaymentProxy {
-	server := &http.Server{Addr: proxyAddress}
+	return NewPaymentProxyWithConfig(Config{
+		ProxyAddress:   proxyAddress,
+		NitroEndpoint:  nitroEndpoint,
+		DestinationUrl: destinationURL,
+		Pricing:        Pricing{Default: Price{PerByte: costPerByte}},
+		CertFilePath:   certFilePath,
+		CertKeyPath:    certKeyPath,
+	})
+}
 
-	nitroClient, err := rpc.NewHttpRpcClient(nitroEndpoint)
-	if err != nil {
-		panic(err)
+// NewPaymentProxyWithConfig creates a new PaymentProxy that forwards requests to the upstreams of the config, charging for each according to the pricing of its upstream.
+func NewPaymentProxyWithConfig(config Config) *PaymentProxy {
+	upstreams := config.Upstreams
+	if config.DestinationUrl != "" {
+		upstreams = append(upstreams[:len(upstreams):len(upstreams)], Upstream{
+			Name:    "default",
+			Urls:    []string{config.DestinationUrl},
+			Pricing: config.Pricing,
+		})
 	}
-	destinationUrl, err := url.Parse(destinationURL)
-	if err != nil {
-		panic(err)
+	for _, u := range upstreams {
+		if err := u.Validate(); err != nil {
+			panic(err)
+		}
+	}
+	if config.StreamBilling && !config.Metering {
+		panic("stream billing requires metering")
+	}
+	if config.PaymentTimeout == 0 {
+		config.PaymentTimeout = DEFAULT_PAYMENT_TIMEOUT
 	}
+	if config.VoucherBatchInterval == 0 {
+		config.VoucherBatchInterval = DEFAULT_VOUCHER_BATCH_INTERVAL
+	}
+	if config.HealthCheckInterval == 0 {
+		config.HealthCheckInterval = DEFAULT_HEALTH_CHECK_INTERVAL
+	}
+	if config.UsageReportInterval == 0 {
+		config.UsageReportInterval = DEFAULT_USAGE_REPORT_INTERVAL
+	}
+	server := &http.Server{Addr: config.ProxyAddress}
 
 	p := &PaymentProxy{
-		server:         server,
-		nitroClient:    nitroClient,
-		costPerByte:    costPerByte,
-		destinationUrl: destinationUrl,
-		reverseProxy:   &httputil.ReverseProxy{},
-		certFilePath:   certFilePath,
-		certKeyPath:    certKeyPath,
+		server:              server,
+		reverseProxy:        &httputil.ReverseProxy{},
+		healthCheckInterval: config.HealthCheckInterval,
+		usage:               newUsageRecorder(),
+		usageToken:          config.UsageToken,
+		usageReportPath:     config.UsageReportPath,
+		usageReportInterval: config.UsageReportInterval,
+		stop:                make(chan struct{}),
+		certFilePath:        config.CertFilePath,
+		certKeyPath:         config.CertKeyPath,
+	}
+
+	// Upstreams paid through the same nitro node share a client, and a verifier
+	nitroClients := make(map[string]rpc.RpcClientApi)
+	verifiers := make(map[string]*localVerifier)
+	for _, u := range upstreams {
+		endpoint := u.NitroEndpoint
+		if endpoint == "" {
+			endpoint = config.NitroEndpoint
+		}
+		nitroClient, ok := nitroClients[endpoint]
+		if !ok {
+			var err error
+			nitroClient, err = rpc.NewHttpRpcClient(endpoint)
+			if err != nil {
+				panic(err)
+			}
+			nitroClients[endpoint] = nitroClient
+			p.nitroClients = append(p.nitroClients, nitroClient)
+		}
+
+		b, err := newBackend(u, config.Metering)
+		if err != nil {
+			panic(err)
+		}
+		if err := b.setNitroClient(nitroClient); err != nil {
+			panic(err)
+		}
+		if config.LocalVerification {
+			verifier, ok := verifiers[endpoint]
+			if !ok {
+				verifier = newLocalVerifier(nitroClient, b.payee, config.VoucherBatchInterval)
+				verifiers[endpoint] = verifier
+				p.verifiers = append(p.verifiers, verifier)
+			}
+			b.vouchers = verifier
+		}
+		p.backends = append(p.backends, b)
+	}
+
+	if config.StreamBilling {
+		p.streamBilling = true
+		p.paymentTimeout = config.PaymentTimeout
+		// Bytes are sent on as soon as they are paid for, so that a paused response has delivered everything paid for
+		p.reverseProxy.FlushInterval = -1
 	}
 	// Wire up our handlers to the reverse proxy
-	p.reverseProxy.Rewrite = func(pr *httputil.ProxyRequest) { pr.SetURL(p.destinationUrl) }
+	p.reverseProxy.Rewrite = func(pr *httputil.ProxyRequest) {
+		pr.SetURL(pr.In.Context().Value(REPLICA_CONTEXT_ARG).(*replica).url)
+	}
 	p.reverseProxy.ModifyResponse = p.handleDestinationResponse
 	p.reverseProxy.ErrorHandler = p.handleError
 	// Wire up our handler to the server
@@ -80,8 +228,8 @@ func NewPaymentProxy(proxyAddress string, nitroEndpoint string, destinationURL s
 }
 
 // ServeHTTP is the main entry point for the payment proxy server.
-// It is responsible for parsing the voucher from the query params and moving it to the request header
-// It then delegates to the reverse proxy to handle rewriting the request and sending it to the destination
+// It is responsible for routing the request to an upstream, and for parsing the voucher from the Authorization header or the query params and moving it to the request context
+// It then delegates to the reverse proxy to handle rewriting the request and sending it to a replica of the upstream
 func (p *PaymentProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// If the request is a health check, return a 200 OK
 	if r.URL.Path == "/health" {
@@ -100,24 +248,67 @@ func (p *PaymentProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	v, err := parseVoucher(r.URL.Query())
-	if err != nil {
-		p.handleError(w, r, createPaymentError(fmt.Errorf("could not parse voucher: %w", err)))
+	if p.usageToken != "" && r.URL.Path == USAGE_PATH {
+		p.handleUsage(w, r)
+		return
+	}
+
+	b := p.route(r)
+	if b == nil {
+		enableCors(w.Header())
+		http.Error(w, "no upstream for the request", http.StatusNotFound)
+		return
+	}
+	// We add the upstream to the request context so we can charge for the request through its nitro node
+	r = r.WithContext(context.WithValue(r.Context(), UPSTREAM_CONTEXT_ARG, b))
+
+	if b.credits != nil && r.URL.Path == b.creditPath() {
+		p.handleCredit(w, r, b)
 		return
 	}
 
+	// We add the price of the request to the request context so we can access it in the response handler.
+	// The request is priced before it is rewritten, so that routes match the path requested from the proxy.
+	// The route is added too, to record the usage of the request against it.
+	route, price := b.pricing.routeOf(r)
+	r = r.WithContext(context.WithValue(r.Context(), PRICE_CONTEXT_ARG, price))
+	r = r.WithContext(context.WithValue(r.Context(), ROUTE_CONTEXT_ARG, route))
+
+	if b.credits != nil {
+		channelId, err := b.meteredChannel(w, r)
+		if err != nil {
+			p.handleError(w, r, err)
+			return
+		}
+		// We add the channel to the request context so we can debit its credit in the response handler
+		r = r.WithContext(context.WithValue(r.Context(), CHANNEL_CONTEXT_ARG, channelId))
+	} else {
+		v, err := parseVoucher(voucherParams(r))
+		if err != nil {
+			p.handleError(w, r, createPaymentError(fmt.Errorf("could not parse voucher: %w", err)))
+			return
+		}
+		// We add the voucher to the request context so we can access it in the response handler
+		r = r.WithContext(context.WithValue(r.Context(), VOUCHER_CONTEXT_ARG, v))
+	}
+
 	removeVoucher(r)
 
-	// We add the voucher to the request context so we can access it in the response handler
-	r = r.WithContext(context.WithValue(r.Context(), VOUCHER_CONTEXT_ARG, v))
+	replica := b.pick()
+	if replica == nil {
+		enableCors(w.Header())
+		http.Error(w, fmt.Sprintf("upstream %s has no healthy replicas", b.name), http.StatusServiceUnavailable)
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), REPLICA_CONTEXT_ARG, replica))
 
 	p.reverseProxy.ServeHTTP(w, r)
 }
 
 // handleDestinationResponse modifies the response before it is sent back to the client
-// It is responsible for parsing the voucher from the request header and redeeming it with the Nitro client
-// It will check the voucher amount against the cost (response size * cost per byte)
-// If the voucher amount is less than the cost, it will return a 402 Payment Required error instead of serving the content
+// It is responsible for charging for the response, either by redeeming the voucher of the request with the Nitro client of its upstream or, if the proxy meters requests, from the credit of the channel
+// It will check the payment against the cost of the response, as set by the price of the request
+// If the payment is less than the cost, it will return a 402 Payment Required error instead of serving the content
 func (p *PaymentProxy) handleDestinationResponse(r *http.Response) error {
 	enableCors(r.Header)
 	// Ignore OPTIONS requests as they are preflight requests
@@ -125,40 +316,126 @@ func (p *PaymentProxy) handleDestinationResponse(r *http.Response) error {
 		return nil
 	}
 
-	contentLength := uint64(0)
-	// If the Content-Length header is set, use that
-	// Otherwise, read the body to get the length
-	if r.ContentLength != -1 {
-		contentLength = uint64(r.ContentLength)
-	} else {
-		var err error
-		contentLength, err = readBodyLength(r.Body)
-		if err != nil {
-			return createPaymentError(err)
-		}
+	if err := p.chargeResponse(r); err != nil {
+		return err
 	}
+	p.usage.served(r)
+	return nil
+}
 
-	v, ok := r.Request.Context().Value(VOUCHER_CONTEXT_ARG).(payments.Voucher)
+// chargeResponse charges for the response to a request
+func (p *PaymentProxy) chargeResponse(r *http.Response) error {
+	b, ok := r.Request.Context().Value(UPSTREAM_CONTEXT_ARG).(*backend)
 	if !ok {
-		return createPaymentError(fmt.Errorf("could not fetch voucher from context"))
+		return createPaymentError(fmt.Errorf("could not fetch upstream from context"))
+	}
+	price, ok := r.Request.Context().Value(PRICE_CONTEXT_ARG).(Price)
+	if !ok {
+		return createPaymentError(fmt.Errorf("could not fetch price from context"))
+	}
+	if p.streamBilling && !price.IsStreaming() {
+		return p.chargeAsSent(r, b, price)
+	}
+	cost, err := responseCost(r, price)
+	if err != nil {
+		return err
 	}
-	cost := p.costPerByte * contentLength
 
-	slog.Debug("Request cost", "cost-per-byte", p.costPerByte, "response-length", contentLength, "cost", cost)
+	if b.credits != nil {
+		return p.chargeCredit(r, b, price, cost)
+	}
 
-	s, err := p.nitroClient.ReceiveVoucher(v)
+	v, ok := r.Request.Context().Value(VOUCHER_CONTEXT_ARG).(payments.Voucher)
+	if !ok {
+		return createPaymentError(fmt.Errorf("could not fetch voucher from context"))
+	}
+	s, err := b.vouchers.ReceiveVoucher(v)
 	if err != nil {
 		return createPaymentError(fmt.Errorf("error processing voucher %w", err))
 	}
-	slog.Debug("Received voucher", "delta", s.Delta.Uint64())
+	slog.Debug("Received voucher", "upstream", b.name, "delta", s.Delta)
+	// The payment is received even if it is too small to serve the response
+	p.usage.charged(r.Request, s.Delta)
 
 	// s.Delta is amount our balance increases by adding this voucher
 	// AKA the payment amount we received in the request for this file
-	if cost > s.Delta.Uint64() {
-		return createPaymentError(fmt.Errorf("payment of %d attoFIL required, the voucher only resulted in a payment of %d attoFIL", cost, s.Delta.Uint64()))
+	if cost.Cmp(s.Delta) > 0 {
+		return createCostError(fmt.Errorf("payment of %d attoFIL required, the voucher only resulted in a payment of %d attoFIL", cost, s.Delta), cost)
 	}
 	slog.Debug("Destination request", "url", r.Request.URL.String())
 
+	if price.IsStreaming() {
+		duration := price.StreamingDuration(s.Delta)
+		slog.Debug("Streaming response", "paid", s.Delta, "duration", duration)
+		r.Body = newTimeLimitedBody(r.Body, duration, nil)
+	}
+
+	return nil
+}
+
+// responseCost returns the least that must be paid for the response. That is the cost of the response body or, for a streaming response, of one second of it.
+func responseCost(r *http.Response, price Price) (*big.Int, error) {
+	if price.IsStreaming() {
+		// Streaming responses are paid for by the second, so at least one second must be paid for
+		return price.MinimumStreamingCost(), nil
+	}
+
+	contentLength := uint64(0)
+	// If the Content-Length header is set, use that
+	// Otherwise, read the body to get the length, keeping it to send on to the client
+	if r.ContentLength != -1 {
+		contentLength = uint64(r.ContentLength)
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, createPaymentError(err)
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		contentLength = uint64(len(body))
+	}
+	cost := price.Cost(contentLength)
+	slog.Debug("Request cost", "price", price, "response-length", contentLength, "cost", cost)
+	return cost, nil
+}
+
+// chargeCredit pays for a metered response from the credit of its channel.
+// A streaming response is sent for as long as the credit pays for. The credit is reserved upfront, and the seconds that are not sent are refunded once the response ends.
+func (p *PaymentProxy) chargeCredit(r *http.Response, b *backend, price Price, cost *big.Int) error {
+	channelId, ok := r.Request.Context().Value(CHANNEL_CONTEXT_ARG).(types.Destination)
+	if !ok {
+		return createPaymentError(fmt.Errorf("could not fetch channel from context"))
+	}
+
+	var duration time.Duration
+	if price.IsStreaming() {
+		_, credit := b.credits.account(channelId)
+		duration = price.StreamingDuration(credit)
+		if duration > 0 {
+			cost = price.streamingCost(duration)
+		}
+	}
+
+	credit, ok := b.credits.debit(channelId, cost)
+	if !ok {
+		err := fmt.Errorf("payment of %d attoFIL required, the channel only has %d attoFIL of credit", cost, credit)
+		return costError{error: createPaymentError(err), cost: cost, credit: credit}
+	}
+	r.Header.Set(CREDIT_HEADER, credit.String())
+	slog.Debug("Debited credit", "channelId", channelId, "cost", cost, "credit", credit)
+	p.usage.charged(r.Request, cost)
+
+	if price.IsStreaming() {
+		r.Body = newTimeLimitedBody(r.Body, duration, func(elapsed time.Duration) {
+			unused := duration - elapsed.Truncate(time.Second) - time.Second
+			if unused > 0 {
+				refund := new(big.Int).Mul(new(big.Int).SetUint64(price.PerSecond), big.NewInt(int64(unused/time.Second)))
+				_, credit := b.credits.credit(channelId, refund)
+				p.usage.charged(r.Request, new(big.Int).Neg(refund))
+				slog.Debug("Refunded unused streaming credit", "channelId", channelId, "refund", refund, "credit", credit)
+			}
+		})
+	}
 	return nil
 }
 
@@ -166,7 +443,8 @@ func (p *PaymentProxy) handleDestinationResponse(r *http.Response) error {
 func (p *PaymentProxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
 	enableCors(w.Header())
 	if errors.Is(err, ErrPayment) {
-		http.Error(w, err.Error(), http.StatusPaymentRequired)
+		p.usage.rejected(r)
+		p.writePaymentRequired(w, r, err)
 	} else {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -174,8 +452,13 @@ func (p *PaymentProxy) handleError(w http.ResponseWriter, r *http.Request, err e
 	slog.Error("Error processing request", "error", err)
 }
 
-// Start starts the proxy server in a goroutine.
+// Start starts the proxy server, the health checks of its upstreams and its usage reports, in goroutines.
 func (p *PaymentProxy) Start() error {
+	go p.runHealthChecks()
+	if p.usageReportPath != "" {
+		p.background.Add(1)
+		go p.runUsageReports()
+	}
 	go func() {
 		if p.certFilePath != "" && p.certKeyPath != "" {
 			if err := p.server.ListenAndServeTLS(p.certFilePath, p.certKeyPath); err != http.ErrServerClosed {
@@ -200,8 +483,20 @@ func (p *PaymentProxy) Stop() error {
 	if err != nil {
 		return err
 	}
+	// The final usage report is written once every request has been served
+	close(p.stop)
+	p.background.Wait()
 
-	return p.nitroClient.Close()
+	// Vouchers verified by the proxy are forwarded to the nodes before their clients are closed
+	for _, verifier := range p.verifiers {
+		verifier.close()
+	}
+	for _, nitroClient := range p.nitroClients {
+		if err := nitroClient.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // parseVoucher takes in an a collection of query params and parses out a voucher.
@@ -219,45 +514,85 @@ func parseVoucher(params url.Values) (payments.Voucher, error) {
 		return payments.Voucher{}, fmt.Errorf("missing signature")
 	}
 
-	amount := big.NewInt(0)
-	amount.SetString(rawAmt, 10)
+	chId, err := hexutil.Decode(rawChId)
+	if err != nil || len(chId) != len(types.Destination{}) {
+		return payments.Voucher{}, fmt.Errorf("invalid channel ID %q", rawChId)
+	}
+	amount, ok := new(big.Int).SetString(rawAmt, 10)
+	if !ok || amount.Sign() < 0 {
+		return payments.Voucher{}, fmt.Errorf("invalid amount %q", rawAmt)
+	}
+	signature, err := hexutil.Decode(rawSignature)
+	// A signature is the concatenation of R, S and V
+	if err != nil || len(signature) != 65 {
+		return payments.Voucher{}, fmt.Errorf("invalid signature %q", rawSignature)
+	}
 
 	v := payments.Voucher{
-		ChannelId: types.Destination(common.HexToHash(rawChId)),
+		ChannelId: types.Destination(chId),
 		Amount:    amount,
-		Signature: crypto.SplitSignature(hexutil.MustDecode(rawSignature)),
+		Signature: crypto.SplitSignature(signature),
 	}
 	return v, nil
 }
 
-// removeVoucherParams removes the voucher parameters from the request URL
+// removeVoucher removes the voucher parameters from the request URL, and the Authorization header if it carries a voucher
 func removeVoucher(r *http.Request) {
+	if scheme, _, _ := strings.Cut(r.Header.Get("Authorization"), " "); strings.EqualFold(scheme, VOUCHER_AUTH_SCHEME) {
+		r.Header.Del("Authorization")
+	}
+
 	queryParams := r.URL.Query()
 
 	queryParams.Del(CHANNEL_ID_VOUCHER_PARAM)
 	queryParams.Del(AMOUNT_VOUCHER_PARAM)
 	queryParams.Del(SIGNATURE_VOUCHER_PARAM)
+	queryParams.Del(CREDIT_TOKEN_PARAM)
 
 	r.URL.RawQuery = queryParams.Encode()
 }
 
-func readBodyLength(b io.ReadCloser) (uint64, error) {
-	var byteCount uint64
-	buffer := make([]byte, 1024)
+// timeLimitedBody is a response body that ends once a duration has elapsed
+type timeLimitedBody struct {
+	body    io.ReadCloser
+	timer   *time.Timer
+	expired atomic.Bool
+	start   time.Time
+	// onClose, if set, is called with how long the body was read for when it is closed
+	onClose func(elapsed time.Duration)
+	closed  sync.Once
+}
 
-	// Read the response body and count the bytes
-	for {
-		n, err := b.Read(buffer)
-		if err != nil {
-			if err == io.EOF {
-				break // Reached the end of the response body
-			}
-			return 0, err
-		}
-		byteCount += uint64(n)
+func newTimeLimitedBody(body io.ReadCloser, duration time.Duration, onClose func(elapsed time.Duration)) *timeLimitedBody {
+	b := &timeLimitedBody{body: body, start: time.Now(), onClose: onClose}
+	b.timer = time.AfterFunc(duration, func() {
+		b.expired.Store(true)
+		// Closing the body unblocks a read that is waiting for the destination server
+		b.body.Close()
+	})
+	return b
+}
+
+// Read reads from the body until the duration has elapsed, after which it reports the end of the body
+func (b *timeLimitedBody) Read(data []byte) (int, error) {
+	if b.expired.Load() {
+		return 0, io.EOF
 	}
+	n, err := b.body.Read(data)
+	if err != nil && b.expired.Load() {
+		return n, io.EOF
+	}
+	return n, err
+}
 
-	return byteCount, nil
+func (b *timeLimitedBody) Close() error {
+	b.timer.Stop()
+	b.closed.Do(func() {
+		if b.onClose != nil {
+			b.onClose(time.Since(b.start))
+		}
+	})
+	return b.body.Close()
 }
 
 // enableCors sets the CORS headers if they are not already set
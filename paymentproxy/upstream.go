@@ -0,0 +1,263 @@
+package paymentproxy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/rpc"
+)
+
+// DEFAULT_HEALTH_CHECK_INTERVAL is how often the replicas of upstreams with a health path are checked
+const DEFAULT_HEALTH_CHECK_INTERVAL = 10 * time.Second
+
+// Upstream is a service that the proxy forwards requests to, paid for through its own nitro node
+type Upstream struct {
+	// Name identifies the upstream in logs
+	Name string `toml:"name"`
+	// Host is the host of the requests routed to the upstream, ignoring any port. If it is empty, requests for any host are routed to the upstream.
+	Host string `toml:"host"`
+	// PathPrefix is the path of the requests routed to the upstream, which is matched against whole path segments.
+	// If it is empty, requests for any path are routed to the upstream. The path is forwarded unchanged.
+	PathPrefix string `toml:"pathprefix"`
+	// Urls are the replicas of the upstream. Requests are balanced across the healthy replicas in turn.
+	Urls []string `toml:"urls"`
+	// NitroEndpoint is the endpoint of the rpc server of the nitro node that the upstream is paid through.
+	// If it is empty, the NitroEndpoint of the proxy config is used.
+	NitroEndpoint string `toml:"nitroendpoint"`
+	// Pricing determines what the proxy charges for requests to the upstream
+	Pricing Pricing `toml:"pricing"`
+	// HealthPath is the path of each replica that is requested to check its health. If it is empty, the replicas are not checked.
+	HealthPath string `toml:"healthpath"`
+}
+
+// LoadUpstreams loads upstreams from a TOML file, such as
+//
+//	[[upstreams]]
+//	name = "video"
+//	host = "video.example.com"
+//	urls = ["http://10.0.0.1:8080", "http://10.0.0.2:8080"]
+//	nitroendpoint = "localhost:4007/api/v1"
+//	healthpath = "/health"
+//
+//	[upstreams.pricing.default]
+//	perbyte = 1
+//
+//	[[upstreams]]
+//	name = "search"
+//	pathprefix = "/search"
+//	urls = ["http://10.0.0.3:8080"]
+//
+//	[upstreams.pricing.default]
+//	perrequest = 100
+func LoadUpstreams(filepath string) ([]Upstream, error) {
+	var file struct {
+		Upstreams []Upstream `toml:"upstreams"`
+	}
+	_, err := toml.DecodeFile(filepath, &file)
+	if err != nil {
+		return nil, fmt.Errorf("could not load upstreams from %s: %w", filepath, err)
+	}
+	for _, u := range file.Upstreams {
+		if err := u.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid upstream in %s: %w", filepath, err)
+		}
+	}
+	return file.Upstreams, nil
+}
+
+// Validate checks that the upstream has well formed urls and a valid pricing
+func (u Upstream) Validate() error {
+	if len(u.Urls) == 0 {
+		return fmt.Errorf("upstream %q has no urls", u.Name)
+	}
+	for _, raw := range u.Urls {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("upstream %q has an invalid url %q: %w", u.Name, raw, err)
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("upstream %q has a url %q that is not fully qualified", u.Name, raw)
+		}
+	}
+	if u.PathPrefix != "" && !strings.HasPrefix(u.PathPrefix, "/") {
+		return fmt.Errorf("upstream %q has a path prefix %q that does not start with /", u.Name, u.PathPrefix)
+	}
+	if err := u.Pricing.Validate(); err != nil {
+		return fmt.Errorf("upstream %q: %w", u.Name, err)
+	}
+	return nil
+}
+
+// backend is an upstream that the proxy is forwarding requests to
+type backend struct {
+	name       string
+	host       string
+	pathPrefix string
+	pricing    Pricing
+	healthPath string
+
+	// vouchers receives the vouchers paying for requests to the backend, for its payee
+	vouchers voucherReceiver
+	payee    common.Address
+	// credits holds the credit of each channel with the payee, if the proxy meters requests
+	credits *creditAccounts
+
+	replicas []*replica
+	// next is the turn of the replica that the next request is balanced to
+	next atomic.Uint64
+}
+
+// replica is one of the urls of an upstream
+type replica struct {
+	url     *url.URL
+	healthy atomic.Bool
+}
+
+// newBackend creates a backend for an upstream. Its nitro client must be set before it is used.
+func newBackend(u Upstream, metering bool) (*backend, error) {
+	b := &backend{
+		name:       u.Name,
+		host:       u.Host,
+		pathPrefix: strings.TrimSuffix(u.PathPrefix, "/"),
+		pricing:    u.Pricing,
+		healthPath: u.HealthPath,
+	}
+	if metering {
+		b.credits = newCreditAccounts()
+	}
+	for _, raw := range u.Urls {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		r := &replica{url: parsed}
+		// Replicas are assumed to be healthy until they are checked
+		r.healthy.Store(true)
+		b.replicas = append(b.replicas, r)
+	}
+	return b, nil
+}
+
+// setNitroClient sets the client of the nitro node that the backend is paid through, which receives its vouchers
+func (b *backend) setNitroClient(nitroClient rpc.RpcClientApi) error {
+	payee, err := nitroClient.Address()
+	if err != nil {
+		return err
+	}
+	b.vouchers = nitroClient
+	b.payee = payee
+	return nil
+}
+
+// matches returns true if the request is routed to the backend
+func (b *backend) matches(r *http.Request) bool {
+	if b.host != "" {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if !strings.EqualFold(host, b.host) {
+			return false
+		}
+	}
+	return b.pathPrefix == "" || r.URL.Path == b.pathPrefix || strings.HasPrefix(r.URL.Path, b.pathPrefix+"/")
+}
+
+// creditPath is the path of the CREDIT_PATH endpoint of the backend, under its path prefix
+func (b *backend) creditPath() string {
+	return b.pathPrefix + CREDIT_PATH
+}
+
+// pick returns the next healthy replica in turn, or nil if none of the replicas are healthy
+func (b *backend) pick() *replica {
+	n := uint64(len(b.replicas))
+	start := b.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := b.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// checkHealth requests the health path of each replica, and records whether it responded successfully
+func (b *backend) checkHealth(client *http.Client) {
+	for _, r := range b.replicas {
+		err := checkReplica(client, r.url.JoinPath(b.healthPath).String())
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.Info("Upstream replica is healthy", "upstream", b.name, "url", r.url)
+			} else {
+				slog.Warn("Upstream replica is unhealthy", "upstream", b.name, "url", r.url, "error", err)
+			}
+		}
+	}
+}
+
+func checkReplica(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// route returns the first backend that the request is routed to, or nil if it matches none of them
+func (p *PaymentProxy) route(r *http.Request) *backend {
+	for _, b := range p.backends {
+		if b.matches(r) {
+			return b
+		}
+	}
+	return nil
+}
+
+// backendOf returns the backend that a request has been routed to
+func (p *PaymentProxy) backendOf(r *http.Request) *backend {
+	if b, ok := r.Context().Value(UPSTREAM_CONTEXT_ARG).(*backend); ok {
+		return b
+	}
+	return p.route(r)
+}
+
+// runHealthChecks checks the health of the replicas of each backend with a health path, until the proxy is stopped
+func (p *PaymentProxy) runHealthChecks() {
+	checked := false
+	for _, b := range p.backends {
+		checked = checked || b.healthPath != ""
+	}
+	if !checked {
+		return
+	}
+
+	client := &http.Client{Timeout: p.healthCheckInterval}
+	ticker := time.NewTicker(p.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		for _, b := range p.backends {
+			if b.healthPath != "" {
+				b.checkHealth(client)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package paymentproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUpstreams = `
+[[upstreams]]
+name = "video"
+host = "video.example.com"
+urls = ["http://10.0.0.1:8080", "http://10.0.0.2:8080"]
+
+[upstreams.pricing.default]
+perbyte = 1
+
+[[upstreams]]
+name = "search"
+pathprefix = "/search/"
+urls = ["http://10.0.0.3:8080"]
+
+[upstreams.pricing.default]
+perrequest = 100
+
+[[upstreams]]
+name = "other"
+urls = ["http://10.0.0.4:8080"]
+`
+
+func loadTestBackends(t *testing.T) []*backend {
+	file := filepath.Join(t.TempDir(), "upstreams.toml")
+	require.NoError(t, os.WriteFile(file, []byte(testUpstreams), 0o600))
+	upstreams, err := LoadUpstreams(file)
+	require.NoError(t, err)
+	require.Len(t, upstreams, 3)
+	assert.Equal(t, Price{PerRequest: 100}, upstreams[1].Pricing.Default)
+
+	backends := make([]*backend, len(upstreams))
+	for i, u := range upstreams {
+		backends[i], err = newBackend(u, false)
+		require.NoError(t, err)
+	}
+	return backends
+}
+
+func TestRoute(t *testing.T) {
+	p := &PaymentProxy{backends: loadTestBackends(t)}
+
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"http://video.example.com/films/1", "video"},
+		{"http://VIDEO.example.com:5511/", "video"},
+		{"http://video.example.com/search", "video"},
+		{"http://proxy/search", "search"},
+		{"http://proxy/search/query?q=1", "search"},
+		{"http://proxy/searches", "other"},
+		{"http://proxy/", "other"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			b := p.route(httptest.NewRequest(http.MethodGet, tc.url, nil))
+			require.NotNil(t, b)
+			assert.Equal(t, tc.expected, b.name)
+		})
+	}
+
+	p.backends = p.backends[:2]
+	assert.Nil(t, p.route(httptest.NewRequest(http.MethodGet, "http://proxy/", nil)))
+}
+
+func TestPickBalancesHealthyReplicas(t *testing.T) {
+	b := loadTestBackends(t)[0]
+	first, second := b.pick(), b.pick()
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, first, b.pick())
+
+	second.healthy.Store(false)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, first, b.pick())
+	}
+
+	first.healthy.Store(false)
+	assert.Nil(t, b.pick())
+}
+
+func TestCheckHealth(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/status/health", r.URL.Path)
+	}))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	b, err := newBackend(Upstream{Urls: []string{healthy.URL + "/status", unhealthy.URL}, HealthPath: "/health"}, false)
+	require.NoError(t, err)
+
+	b.checkHealth(healthy.Client())
+	assert.True(t, b.replicas[0].healthy.Load())
+	assert.False(t, b.replicas[1].healthy.Load())
+}
+
+func TestValidateUpstream(t *testing.T) {
+	for name, u := range map[string]Upstream{
+		"no urls":         {},
+		"relative url":    {Urls: []string{"localhost:8080"}},
+		"relative prefix": {Urls: []string{"http://localhost"}, PathPrefix: "api"},
+		"invalid pricing": {Urls: []string{"http://localhost"}, Pricing: Pricing{Default: Price{PerByte: 1, PerSecond: 1}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, u.Validate())
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package paymentproxy
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// USAGE_PATH is the endpoint of the proxy that reports the usage of each channel, if the proxy has a usage token.
+	// It responds with JSON, or with CSV if the format query param is "csv".
+	USAGE_PATH = "/nitro/usage"
+
+	// DEFAULT_ROUTE labels the usage of requests that match none of the routes of their pricing
+	DEFAULT_ROUTE = "default"
+
+	// DEFAULT_USAGE_REPORT_INTERVAL is how often the usage report is written
+	DEFAULT_USAGE_REPORT_INTERVAL = time.Hour
+
+	ROUTE_CONTEXT_ARG contextKey = "route"
+)
+
+// Usage is the usage of a route of an upstream by a payment channel
+type Usage struct {
+	// ChannelId is the channel that paid for the requests. It is zero for rejected requests that did not identify a channel that paid before.
+	ChannelId types.Destination
+	Upstream  string
+	Route     string
+	// Requests is the number of requests that were paid for and served
+	Requests uint64
+	// BytesServed is the number of bytes of the response bodies sent to the client
+	BytesServed uint64
+	// Charged is the amount received for the requests, or debited from the credit of the channel if the proxy meters requests
+	Charged *big.Int
+	// RejectedPayments is the number of requests that were refused for not being paid for
+	RejectedPayments uint64
+}
+
+type usageKey struct {
+	channelId types.Destination
+	upstream  string
+	route     string
+}
+
+// usageRecorder records the usage of each route of each upstream by each channel, since the proxy started
+type usageRecorder struct {
+	mu    sync.Mutex
+	usage map[usageKey]*Usage
+}
+
+func newUsageRecorder() *usageRecorder {
+	return &usageRecorder{usage: make(map[usageKey]*Usage)}
+}
+
+// usageKeyOf returns the key of the usage of a request, from what is known of it in its context.
+// It also returns whether the channel of the key is only known from the voucher of the request, which may not have been accepted.
+func usageKeyOf(r *http.Request) (key usageKey, fromVoucher bool) {
+	if b, ok := r.Context().Value(UPSTREAM_CONTEXT_ARG).(*backend); ok {
+		key.upstream = b.name
+	}
+	key.route, _ = r.Context().Value(ROUTE_CONTEXT_ARG).(string)
+	if channelId, ok := r.Context().Value(CHANNEL_CONTEXT_ARG).(types.Destination); ok {
+		key.channelId = channelId
+	} else if v, ok := r.Context().Value(VOUCHER_CONTEXT_ARG).(payments.Voucher); ok {
+		key.channelId = v.ChannelId
+		fromVoucher = true
+	}
+	return key, fromVoucher
+}
+
+func (u *usageRecorder) update(r *http.Request, update func(*Usage)) {
+	key, _ := usageKeyOf(r)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.updateLocked(key, update)
+}
+
+// updateLocked applies update to the usage of key, which it adds if there is none. The caller must hold mu.
+func (u *usageRecorder) updateLocked(key usageKey, update func(*Usage)) {
+	usage, ok := u.usage[key]
+	if !ok {
+		usage = &Usage{ChannelId: key.channelId, Upstream: key.upstream, Route: key.route, Charged: new(big.Int)}
+		u.usage[key] = usage
+	}
+	update(usage)
+}
+
+// served records a request that was paid for, counting the bytes of the response body as it is sent
+func (u *usageRecorder) served(r *http.Response) {
+	request := r.Request
+	u.update(request, func(usage *Usage) { usage.Requests++ })
+	r.Body = &countingBody{ReadCloser: r.Body, onClose: func(n uint64) {
+		u.update(request, func(usage *Usage) { usage.BytesServed += n })
+	}}
+}
+
+// charged records an amount charged for a request. A negative amount refunds part of a charge.
+func (u *usageRecorder) charged(r *http.Request, amount *big.Int) {
+	u.update(r, func(usage *Usage) { usage.Charged.Add(usage.Charged, amount) })
+}
+
+// rejected records a request that was refused for not being paid for.
+// A voucher that was never accepted may name any channel, so its rejection is recorded against the channel only if the channel already has usage of the route.
+// Otherwise it is recorded against the zero channel id, so that vouchers for made up channels cannot add usage without bound.
+func (u *usageRecorder) rejected(r *http.Request) {
+	key, fromVoucher := usageKeyOf(r)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.usage[key]; !ok && fromVoucher {
+		key.channelId = types.Destination{}
+	}
+	u.updateLocked(key, func(usage *Usage) { usage.RejectedPayments++ })
+}
+
+// snapshot returns a copy of the usage, ordered by channel, upstream and route
+func (u *usageRecorder) snapshot() []Usage {
+	u.mu.Lock()
+	usage := make([]Usage, 0, len(u.usage))
+	for _, record := range u.usage {
+		copied := *record
+		copied.Charged = new(big.Int).Set(record.Charged)
+		usage = append(usage, copied)
+	}
+	u.mu.Unlock()
+
+	sort.Slice(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if c := bytes.Compare(a.ChannelId[:], b.ChannelId[:]); c != 0 {
+			return c < 0
+		}
+		if a.Upstream != b.Upstream {
+			return a.Upstream < b.Upstream
+		}
+		return a.Route < b.Route
+	})
+	return usage
+}
+
+// writeUsageCSV writes usage as CSV, with a header row
+func writeUsageCSV(w io.Writer, usage []Usage) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"channelId", "upstream", "route", "requests", "bytesServed", "charged", "rejectedPayments"}); err != nil {
+		return err
+	}
+	for _, u := range usage {
+		err := writer.Write([]string{
+			u.ChannelId.String(), u.Upstream, u.Route,
+			strconv.FormatUint(u.Requests, 10), strconv.FormatUint(u.BytesServed, 10), u.Charged.String(), strconv.FormatUint(u.RejectedPayments, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// handleUsage serves the USAGE_PATH endpoint to requests with the usage token as a bearer token
+func (p *PaymentProxy) handleUsage(w http.ResponseWriter, r *http.Request) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare([]byte(token), []byte(p.usageToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "invalid usage token", http.StatusUnauthorized)
+		return
+	}
+
+	usage := p.usage.snapshot()
+	var err error
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		err = writeUsageCSV(w, usage)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(usage)
+	}
+	if err != nil {
+		slog.Error("Could not write usage response", "error", err)
+	}
+}
+
+// writeUsageReport writes the usage to the report file, as CSV if the file has a .csv extension and otherwise as JSON.
+// The report is written to a temporary file which then replaces the report, so that the report is never partly written.
+func (p *PaymentProxy) writeUsageReport() error {
+	usage := p.usage.snapshot()
+	var report bytes.Buffer
+	if strings.EqualFold(filepath.Ext(p.usageReportPath), ".csv") {
+		if err := writeUsageCSV(&report, usage); err != nil {
+			return err
+		}
+	} else {
+		encoder := json.NewEncoder(&report)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(usage); err != nil {
+			return err
+		}
+	}
+
+	tmp := p.usageReportPath + ".tmp"
+	if err := os.WriteFile(tmp, report.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("could not write usage report: %w", err)
+	}
+	if err := os.Rename(tmp, p.usageReportPath); err != nil {
+		return fmt.Errorf("could not write usage report: %w", err)
+	}
+	return nil
+}
+
+// runUsageReports writes the usage report every interval, and once more when the proxy is stopped
+func (p *PaymentProxy) runUsageReports() {
+	defer p.background.Done()
+	ticker := time.NewTicker(p.usageReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			if err := p.writeUsageReport(); err != nil {
+				slog.Error("Could not write the usage report", "error", err)
+			}
+			return
+		}
+		if err := p.writeUsageReport(); err != nil {
+			slog.Error("Could not write the usage report", "error", err)
+		}
+	}
+}
+
+// countingBody is a response body that counts the bytes read from it
+type countingBody struct {
+	io.ReadCloser
+	read    atomic.Uint64
+	onClose func(read uint64)
+	closed  sync.Once
+}
+
+func (b *countingBody) Read(data []byte) (int, error) {
+	n, err := b.ReadCloser.Read(data)
+	b.read.Add(uint64(n))
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.closed.Do(func() { b.onClose(b.read.Load()) })
+	return b.ReadCloser.Close()
+}
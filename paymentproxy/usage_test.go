@@ -0,0 +1,127 @@
+package paymentproxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func usageRequest(channelId types.Destination, route string) *http.Request {
+	ctx := context.WithValue(context.Background(), UPSTREAM_CONTEXT_ARG, &backend{name: "upstream"})
+	ctx = context.WithValue(ctx, ROUTE_CONTEXT_ARG, route)
+	if (channelId != types.Destination{}) {
+		ctx = context.WithValue(ctx, CHANNEL_CONTEXT_ARG, channelId)
+	}
+	return httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+}
+
+func recordTestUsage(t *testing.T) *usageRecorder {
+	usage := newUsageRecorder()
+	serve := func(r *http.Request, body string) {
+		resp := &http.Response{Request: r, Body: io.NopCloser(strings.NewReader(body))}
+		usage.served(resp)
+		_, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	files, api := usageRequest(types.Destination{2}, "/files/**"), usageRequest(types.Destination{1}, DEFAULT_ROUTE)
+	usage.charged(files, big.NewInt(10))
+	serve(files, "hello")
+	usage.charged(files, big.NewInt(7))
+	serve(files, "world!")
+	usage.charged(files, big.NewInt(-2))
+	usage.rejected(files)
+	usage.charged(api, big.NewInt(1))
+	serve(api, "a")
+	usage.rejected(usageRequest(types.Destination{}, DEFAULT_ROUTE))
+	return usage
+}
+
+func TestUsageRecorder(t *testing.T) {
+	usage := recordTestUsage(t).snapshot()
+
+	require.Len(t, usage, 3)
+	assert.Equal(t, Usage{ChannelId: types.Destination{}, Upstream: "upstream", Route: DEFAULT_ROUTE, Charged: big.NewInt(0), RejectedPayments: 1}, usage[0])
+	assert.Equal(t, Usage{ChannelId: types.Destination{1}, Upstream: "upstream", Route: DEFAULT_ROUTE, Requests: 1, BytesServed: 1, Charged: big.NewInt(1)}, usage[1])
+	assert.Equal(t, Usage{ChannelId: types.Destination{2}, Upstream: "upstream", Route: "/files/**", Requests: 2, BytesServed: 11, Charged: big.NewInt(15), RejectedPayments: 1}, usage[2])
+}
+
+func TestUsageRecorderRejectedVouchers(t *testing.T) {
+	usage := newUsageRecorder()
+	voucherRequest := func(channelId types.Destination) *http.Request {
+		r := usageRequest(types.Destination{}, DEFAULT_ROUTE)
+		return r.WithContext(context.WithValue(r.Context(), VOUCHER_CONTEXT_ARG, payments.Voucher{ChannelId: channelId}))
+	}
+
+	// Vouchers that were never accepted are recorded against the zero channel id, however many channels they name
+	for i := byte(1); i <= 100; i++ {
+		usage.rejected(voucherRequest(types.Destination{i}))
+	}
+	// A voucher that was accepted, but paid too little, is recorded against its channel
+	paid := voucherRequest(types.Destination{1})
+	usage.charged(paid, big.NewInt(1))
+	usage.rejected(paid)
+
+	snapshot := usage.snapshot()
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, Usage{ChannelId: types.Destination{}, Upstream: "upstream", Route: DEFAULT_ROUTE, Charged: big.NewInt(0), RejectedPayments: 100}, snapshot[0])
+	assert.Equal(t, Usage{ChannelId: types.Destination{1}, Upstream: "upstream", Route: DEFAULT_ROUTE, Charged: big.NewInt(1), RejectedPayments: 1}, snapshot[1])
+}
+
+func TestHandleUsage(t *testing.T) {
+	p := &PaymentProxy{usage: recordTestUsage(t), usageToken: "secret"}
+
+	w := httptest.NewRecorder()
+	p.handleUsage(w, httptest.NewRequest(http.MethodGet, USAGE_PATH, nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r := httptest.NewRequest(http.MethodGet, USAGE_PATH, nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	p.handleUsage(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	var usage []Usage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+	assert.Equal(t, p.usage.snapshot(), usage)
+
+	r = httptest.NewRequest(http.MethodGet, USAGE_PATH+"?format=csv", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	p.handleUsage(w, r)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "channelId,upstream,route,requests,bytesServed,charged,rejectedPayments", lines[0])
+	assert.Equal(t, types.Destination{2}.String()+",upstream,/files/**,2,11,15,1", lines[3])
+}
+
+func TestWriteUsageReport(t *testing.T) {
+	for _, name := range []string{"usage.json", "usage.csv"} {
+		t.Run(name, func(t *testing.T) {
+			p := &PaymentProxy{usage: recordTestUsage(t), usageReportPath: filepath.Join(t.TempDir(), name)}
+			require.NoError(t, p.writeUsageReport())
+
+			report, err := os.ReadFile(p.usageReportPath)
+			require.NoError(t, err)
+			if filepath.Ext(name) == ".csv" {
+				assert.Len(t, strings.Split(strings.TrimSpace(string(report)), "\n"), 4)
+			} else {
+				var usage []Usage
+				require.NoError(t, json.Unmarshal(report, &usage))
+				assert.Equal(t, p.usage.snapshot(), usage)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package paymentproxy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// DEFAULT_VOUCHER_BATCH_INTERVAL is how often vouchers verified by the proxy are forwarded to the nitro node
+const DEFAULT_VOUCHER_BATCH_INTERVAL = time.Second
+
+// channelInfoTTL is how long the verifier trusts the cached status and balance of a channel before fetching them from the node again
+const channelInfoTTL = time.Minute
+
+// maxForwardAttempts is how many times the verifier tries to forward a voucher that the node fails to receive, before dropping it
+const maxForwardAttempts = 5
+
+// voucherReceiver receives the vouchers that pay for requests. The rpc client of a nitro node is one, which receives them with the node.
+type voucherReceiver interface {
+	ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummary, error)
+}
+
+// localVerifier receives vouchers by verifying them itself, against a cache of the VoucherInfo of each channel, rather than waiting for the nitro node.
+// Vouchers are forwarded to the node in batches, which hold the largest voucher received on each channel since the last batch.
+//
+// The cache is only correct while the proxy is the only receiver of vouchers on the channels, as vouchers received by the node
+// some other way are not known to it. Cached channels expire after infoTTL, so that channels that closed stop being paid,
+// and are evicted when the node rejects one of their vouchers, so that the next voucher is verified against the node's own view.
+type localVerifier struct {
+	nitroClient rpc.RpcClientApi
+	payee       common.Address
+	infoTTL     time.Duration
+
+	mu    sync.Mutex
+	infos map[types.Destination]*cachedVoucherInfo
+	// pending holds the largest voucher received on each channel that is yet to be forwarded to the node
+	pending map[types.Destination]payments.Voucher
+	// attempts counts the failed attempts to forward the pending voucher of each channel
+	attempts map[types.Destination]int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newLocalVerifier creates a localVerifier for the vouchers paying payee, the nitro node of the client, and starts forwarding them to it every interval
+func newLocalVerifier(nitroClient rpc.RpcClientApi, payee common.Address, interval time.Duration) *localVerifier {
+	l := &localVerifier{
+		nitroClient: nitroClient,
+		payee:       payee,
+		infoTTL:     channelInfoTTL,
+		infos:       make(map[types.Destination]*cachedVoucherInfo),
+		pending:     make(map[types.Destination]payments.Voucher),
+		attempts:    make(map[types.Destination]int),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go l.run(interval)
+	return l
+}
+
+// cachedVoucherInfo is the VoucherInfo of a channel, and when its status and balance were fetched from the node
+type cachedVoucherInfo struct {
+	info    *payments.VoucherInfo
+	fetched time.Time
+}
+
+// ReceiveVoucher verifies the voucher, in the same way as the nitro node, and queues it to be forwarded to the node.
+// It returns the total amount received on the channel so far and the amount received from the voucher.
+func (l *localVerifier) ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummary, error) {
+	info, err := l.voucherInfo(v.ChannelId)
+	if err != nil {
+		return payments.ReceiveVoucherSummary{}, err
+	}
+	signer, err := v.RecoverSigner()
+	if err != nil {
+		return payments.ReceiveVoucherSummary{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if types.Gt(v.Amount, info.StartingBalance) {
+		return payments.ReceiveVoucherSummary{}, fmt.Errorf("channel has %w", payments.ErrInsufficientFunds)
+	}
+	total := info.LargestVoucher.Amount
+	if !types.Gt(v.Amount, total) {
+		return payments.ReceiveVoucherSummary{Total: new(big.Int).Set(total), Delta: big.NewInt(0)}, nil
+	}
+	if signer != info.ChannelPayer {
+		return payments.ReceiveVoucherSummary{}, fmt.Errorf("wrong signer: %+v, %+v", signer, info.ChannelPayer)
+	}
+
+	delta := new(big.Int).Sub(v.Amount, total)
+	info.LargestVoucher = v
+	l.pending[v.ChannelId] = v
+	return payments.ReceiveVoucherSummary{Total: new(big.Int).Set(v.Amount), Delta: delta}, nil
+}
+
+// voucherInfo returns the cached VoucherInfo of a channel, fetching it from the node the first time the channel pays and whenever it has expired
+func (l *localVerifier) voucherInfo(channelId types.Destination) (*payments.VoucherInfo, error) {
+	l.mu.Lock()
+	cached, ok := l.infos[channelId]
+	l.mu.Unlock()
+	if ok && time.Since(cached.fetched) < l.infoTTL {
+		return cached.info, nil
+	}
+
+	info, err := l.fetchVoucherInfo(channelId)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		// The channel may have closed since it was cached
+		delete(l.infos, channelId)
+		return nil, err
+	}
+	// Vouchers received by the verifier may not have been forwarded to the node yet
+	largest := info.LargestVoucher
+	if pending, ok := l.pending[channelId]; ok && types.Gt(pending.Amount, largest.Amount) {
+		largest = pending
+	}
+	// Another voucher on the channel may have cached its info first, and been received against it
+	if cached, ok := l.infos[channelId]; ok {
+		if types.Gt(cached.info.LargestVoucher.Amount, largest.Amount) {
+			largest = cached.info.LargestVoucher
+		}
+		info.LargestVoucher = largest
+		*cached.info = *info
+		cached.fetched = time.Now()
+		return cached.info, nil
+	}
+	info.LargestVoucher = largest
+	l.infos[channelId] = &cachedVoucherInfo{info: info, fetched: time.Now()}
+	return info, nil
+}
+
+// fetchVoucherInfo returns the VoucherInfo of an open channel paying the payee, according to the node
+func (l *localVerifier) fetchVoucherInfo(channelId types.Destination) (*payments.VoucherInfo, error) {
+	channel, err := l.nitroClient.GetPaymentChannel(channelId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", payments.ErrChannelNotRegistered, err)
+	}
+	if channel.Status != query.Open {
+		return nil, fmt.Errorf("channel is %s", channel.Status)
+	}
+	if channel.Balance.Payee != l.payee {
+		return nil, fmt.Errorf("can only receive vouchers if we're the payee")
+	}
+	paid := channel.Balance.PaidSoFar.ToInt()
+	return &payments.VoucherInfo{
+		ChannelPayer:    channel.Balance.Payer,
+		ChannelPayee:    channel.Balance.Payee,
+		StartingBalance: new(big.Int).Add(paid, channel.Balance.RemainingFunds.ToInt()),
+		LargestVoucher:  payments.Voucher{ChannelId: channelId, Amount: new(big.Int).Set(paid)},
+	}, nil
+}
+
+func (l *localVerifier) run(interval time.Duration) {
+	defer close(l.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.forward()
+		case <-l.stop:
+			l.forward()
+			return
+		}
+	}
+}
+
+// forward sends the pending vouchers to the node. Vouchers the node fails to receive are retried with the next batch,
+// unless a larger voucher has replaced them, up to maxForwardAttempts times. Vouchers the node rejects are dropped,
+// and their channel is evicted from the cache.
+func (l *localVerifier) forward() {
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = make(map[types.Destination]payments.Voucher)
+	l.mu.Unlock()
+
+	for channelId, v := range batch {
+		s, err := l.nitroClient.ReceiveVoucher(v)
+		l.mu.Lock()
+		switch {
+		case err == nil:
+			delete(l.attempts, channelId)
+			slog.Debug("Forwarded voucher to the nitro node", "channelId", channelId, "amount", v.Amount, "delta", s.Delta)
+		case isRejected(err) || l.attempts[channelId]+1 >= maxForwardAttempts:
+			slog.Error("Dropped voucher the nitro node did not receive", "channelId", channelId, "amount", v.Amount, "error", err)
+			delete(l.attempts, channelId)
+			delete(l.infos, channelId)
+		default:
+			slog.Error("Could not forward voucher to the nitro node", "channelId", channelId, "amount", v.Amount, "error", err)
+			if _, replaced := l.pending[channelId]; !replaced {
+				l.pending[channelId] = v
+				l.attempts[channelId]++
+			} else {
+				delete(l.attempts, channelId)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// isRejected returns true if the node received the voucher but refused it, so forwarding it again would fail again
+func isRejected(err error) bool {
+	return errors.Is(err, rpc.ErrChannelNotFound) || errors.Is(err, rpc.ErrInsufficientFunds) || errors.Is(err, rpc.ErrInvalidParams)
+}
+
+// close forwards the pending vouchers to the node, and stops forwarding them
+func (l *localVerifier) close() {
+	close(l.stop)
+	<-l.done
+}
@@ -0,0 +1,185 @@
+package paymentproxy
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNitroNode is the payee of payment channels from Alice, each with a balance of 100 of which 10 has been paid
+type fakeNitroNode struct {
+	rpc.RpcClientApi
+	mu       sync.Mutex
+	fetched  int
+	received []payments.Voucher
+	failing  bool
+	// rejecting makes the node refuse vouchers, as it does for channels it does not know
+	rejecting bool
+	// closed makes the channels complete, rather than open
+	closed bool
+}
+
+func (n *fakeNitroNode) GetPaymentChannel(chId types.Destination) (query.PaymentChannelInfo, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fetched++
+	status := query.Open
+	if n.closed {
+		status = query.Complete
+	}
+	return query.PaymentChannelInfo{
+		ID:     chId,
+		Status: status,
+		Balance: query.PaymentChannelBalance{
+			Payer:          ta.Alice.Address(),
+			Payee:          ta.Bob.Address(),
+			PaidSoFar:      (*hexutil.Big)(big.NewInt(10)),
+			RemainingFunds: (*hexutil.Big)(big.NewInt(90)),
+		},
+	}, nil
+}
+
+func (n *fakeNitroNode) ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummary, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.failing {
+		return payments.ReceiveVoucherSummary{}, errors.New("node unavailable")
+	}
+	if n.rejecting {
+		return payments.ReceiveVoucherSummary{}, rpc.ErrChannelNotFound
+	}
+	n.received = append(n.received, v)
+	return payments.ReceiveVoucherSummary{}, nil
+}
+
+func signedVoucher(t *testing.T, channelId types.Destination, amount int64, pk []byte) payments.Voucher {
+	v := payments.Voucher{ChannelId: channelId, Amount: big.NewInt(amount)}
+	require.NoError(t, v.Sign(pk))
+	return v
+}
+
+func TestLocalVerifierReceiveVoucher(t *testing.T) {
+	node := &fakeNitroNode{}
+	verifier := newLocalVerifier(node, ta.Bob.Address(), time.Hour)
+	defer verifier.close()
+	channelId := types.Destination{1}
+
+	s, err := verifier.ReceiveVoucher(signedVoucher(t, channelId, 15, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), s.Delta)
+	assert.Equal(t, big.NewInt(15), s.Total)
+
+	// A voucher that is no larger pays nothing
+	s, err = verifier.ReceiveVoucher(signedVoucher(t, channelId, 15, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), s.Delta)
+
+	_, err = verifier.ReceiveVoucher(signedVoucher(t, channelId, 20, ta.Irene.PrivateKey))
+	assert.ErrorContains(t, err, "wrong signer")
+
+	_, err = verifier.ReceiveVoucher(signedVoucher(t, channelId, 101, ta.Alice.PrivateKey))
+	assert.ErrorIs(t, err, payments.ErrInsufficientFunds)
+
+	// The channel is only fetched from the node once
+	assert.Equal(t, 1, node.fetched)
+	assert.Empty(t, node.received)
+
+	otherPayee := newLocalVerifier(node, ta.Irene.Address(), time.Hour)
+	defer otherPayee.close()
+	_, err = otherPayee.ReceiveVoucher(signedVoucher(t, channelId, 15, ta.Alice.PrivateKey))
+	assert.ErrorContains(t, err, "payee")
+}
+
+func TestLocalVerifierForwardsLargestVouchers(t *testing.T) {
+	node := &fakeNitroNode{failing: true}
+	verifier := newLocalVerifier(node, ta.Bob.Address(), time.Hour)
+
+	channelId, otherChannelId := types.Destination{1}, types.Destination{2}
+	for _, amount := range []int64{11, 12, 13} {
+		_, err := verifier.ReceiveVoucher(signedVoucher(t, channelId, amount, ta.Alice.PrivateKey))
+		require.NoError(t, err)
+	}
+	largest := signedVoucher(t, otherChannelId, 50, ta.Alice.PrivateKey)
+	_, err := verifier.ReceiveVoucher(largest)
+	require.NoError(t, err)
+
+	// Vouchers the node fails to receive are kept for the next batch
+	verifier.forward()
+	node.failing = false
+
+	verifier.close()
+	require.Len(t, node.received, 2)
+	for _, v := range node.received {
+		switch v.ChannelId {
+		case channelId:
+			assert.Equal(t, big.NewInt(13), v.Amount)
+		case otherChannelId:
+			assert.True(t, largest.Equal(&v))
+		}
+	}
+}
+
+func TestLocalVerifierDropsRejectedVouchers(t *testing.T) {
+	node := &fakeNitroNode{rejecting: true}
+	verifier := newLocalVerifier(node, ta.Bob.Address(), time.Hour)
+	defer verifier.close()
+	channelId := types.Destination{1}
+
+	_, err := verifier.ReceiveVoucher(signedVoucher(t, channelId, 15, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+	verifier.forward()
+	assert.Empty(t, verifier.pending, "expected a rejected voucher not to be retried")
+
+	// The channel is fetched again, so the rejected voucher is no longer counted as received
+	node.rejecting = false
+	s, err := verifier.ReceiveVoucher(signedVoucher(t, channelId, 15, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), s.Delta)
+	assert.Equal(t, 2, node.fetched)
+}
+
+func TestLocalVerifierGivesUpForwarding(t *testing.T) {
+	node := &fakeNitroNode{failing: true}
+	verifier := newLocalVerifier(node, ta.Bob.Address(), time.Hour)
+	defer verifier.close()
+
+	_, err := verifier.ReceiveVoucher(signedVoucher(t, types.Destination{1}, 15, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+	for i := 0; i < maxForwardAttempts; i++ {
+		require.Len(t, verifier.pending, 1)
+		verifier.forward()
+	}
+	assert.Empty(t, verifier.pending)
+}
+
+func TestLocalVerifierRefreshesChannels(t *testing.T) {
+	node := &fakeNitroNode{failing: true}
+	verifier := newLocalVerifier(node, ta.Bob.Address(), time.Hour)
+	defer verifier.close()
+	verifier.infoTTL = 0
+	channelId := types.Destination{1}
+
+	_, err := verifier.ReceiveVoucher(signedVoucher(t, channelId, 15, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+
+	// Vouchers that have not reached the node are still counted when the channel is fetched again
+	s, err := verifier.ReceiveVoucher(signedVoucher(t, channelId, 20, ta.Alice.PrivateKey))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), s.Delta)
+	assert.Equal(t, 2, node.fetched)
+
+	node.closed = true
+	_, err = verifier.ReceiveVoucher(signedVoucher(t, channelId, 25, ta.Alice.PrivateKey))
+	assert.ErrorContains(t, err, "channel is Complete")
+}
@@ -0,0 +1,61 @@
+package payments
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/types"
+)
+
+type PaymentDirection string
+
+const (
+	Sent     PaymentDirection = "Sent"
+	Received PaymentDirection = "Received"
+)
+
+// PaymentRecord is an entry in the append-only payment history of a payment channel.
+// A record is written each time the voucher manager signs a voucher (as payer) or accepts a larger voucher (as payee).
+type PaymentRecord struct {
+	ChannelId types.Destination
+	// Index is the position of the record in the channel's payment history, starting at 0. It is assigned by the store.
+	Index     uint64
+	Timestamp time.Time
+	Direction PaymentDirection
+	// Delta is the amount paid by this payment
+	Delta *big.Int
+	// Total is the cumulative amount paid on the channel, including this payment. It is the amount of the voucher.
+	Total     *big.Int
+	Signature state.Signature
+}
+
+// newPaymentRecord constructs a PaymentRecord for the given voucher.
+func newPaymentRecord(v Voucher, delta *big.Int, direction PaymentDirection) PaymentRecord {
+	return PaymentRecord{
+		ChannelId: v.ChannelId,
+		Timestamp: time.Now(),
+		Direction: direction,
+		Delta:     big.NewInt(0).Set(delta),
+		Total:     big.NewInt(0).Set(v.Amount),
+		Signature: v.Signature,
+	}
+}
+
+// IsSameVoucher returns true if the record is for the same voucher as other, which it is if it has the same direction and total.
+// The total of a channel's vouchers only increases, so no two payments in the same direction have the same total.
+func (r PaymentRecord) IsSameVoucher(other PaymentRecord) bool {
+	return r.Direction == other.Direction && r.Total != nil && other.Total != nil && r.Total.Cmp(other.Total) == 0
+}
+
+// Clone returns a deep copy of the record.
+func (r PaymentRecord) Clone() PaymentRecord {
+	clone := r
+	if r.Delta != nil {
+		clone.Delta = big.NewInt(0).Set(r.Delta)
+	}
+	if r.Total != nil {
+		clone.Total = big.NewInt(0).Set(r.Total)
+	}
+	return clone
+}
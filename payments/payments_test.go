@@ -27,11 +27,12 @@ type manager interface {
 // Since the store package already imports the payments package if we tried to use the mem or persist store
 // we get import cycles. So we create a simple store that implements the VoucherStore interface for testing.
 func newSimpleVoucherStore() VoucherStore {
-	return &simpleVoucherStore{vouchers: safesync.Map[*VoucherInfo]{}}
+	return &simpleVoucherStore{vouchers: safesync.Map[*VoucherInfo]{}, history: safesync.Map[[]PaymentRecord]{}}
 }
 
 type simpleVoucherStore struct {
 	vouchers safesync.Map[*VoucherInfo]
+	history  safesync.Map[[]PaymentRecord]
 }
 
 func (svs *simpleVoucherStore) SetVoucherInfo(channelId types.Destination, v VoucherInfo) error {
@@ -44,7 +45,9 @@ func (svs *simpleVoucherStore) GetVoucherInfo(channelId types.Destination) (*Vou
 	if !ok {
 		return v, fmt.Errorf("could not load vouchers from store for channelId %s", channelId.String())
 	}
-	return v, nil
+	// Return a copy, as the stores do, so that changes are only stored by SetVoucherInfo
+	copied := *v
+	return &copied, nil
 }
 
 func (svs *simpleVoucherStore) RemoveVoucherInfo(channelId types.Destination) error {
@@ -52,6 +55,28 @@ func (svs *simpleVoucherStore) RemoveVoucherInfo(channelId types.Destination) er
 	return nil
 }
 
+func (svs *simpleVoucherStore) AppendPaymentRecord(r PaymentRecord) error {
+	history, _ := svs.history.Load(r.ChannelId.String())
+	if len(history) > 0 && history[len(history)-1].IsSameVoucher(r) {
+		return nil
+	}
+	r.Index = uint64(len(history))
+	svs.history.Store(r.ChannelId.String(), append(history, r))
+	return nil
+}
+
+func (svs *simpleVoucherStore) GetPaymentHistory(channelId types.Destination, offset, limit uint64) ([]PaymentRecord, error) {
+	history, _ := svs.history.Load(channelId.String())
+	if offset >= uint64(len(history)) {
+		return []PaymentRecord{}, nil
+	}
+	history = history[offset:]
+	if limit != 0 && limit < uint64(len(history)) {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
 func TestPaymentManager(t *testing.T) {
 	testVoucher := func(cId types.Destination, amount *big.Int, actor testactors.Actor) Voucher {
 		payment := &big.Int{}
@@ -173,6 +198,102 @@ func TestPaymentManager(t *testing.T) {
 	_, _, err = receiptMgr.Receive(voucher)
 	Assert(t, err != nil, "expected an error")
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
+
+	// Each payment is recorded in the payment history of both parties
+	for _, tc := range []struct {
+		mgr       *VoucherManager
+		direction PaymentDirection
+	}{{paymentMgr, Sent}, {receiptMgr, Received}} {
+		history, err := tc.mgr.PaymentHistory(channelId, 0, 0)
+		Ok(t, err)
+		Equals(t, 2, len(history))
+		for i, want := range []Voucher{firstVoucher, secondVoucher} {
+			Equals(t, uint64(i), history[i].Index)
+			Equals(t, tc.direction, history[i].Direction)
+			Equals(t, payment, history[i].Delta)
+			Equals(t, want.Amount, history[i].Total)
+			Equals(t, want.Signature, history[i].Signature)
+		}
+
+		page, err := tc.mgr.PaymentHistory(channelId, 1, 1)
+		Ok(t, err)
+		Equals(t, history[1:], page)
+	}
+}
+
+// failingVoucherStore fails to store vouchers while failVouchers is true, and to record payments while failRecords is true
+type failingVoucherStore struct {
+	VoucherStore
+	failVouchers, failRecords bool
+}
+
+func (fvs *failingVoucherStore) SetVoucherInfo(channelId types.Destination, v VoucherInfo) error {
+	if fvs.failVouchers {
+		return fmt.Errorf("could not store voucher for channelId %s", channelId.String())
+	}
+	return fvs.VoucherStore.SetVoucherInfo(channelId, v)
+}
+
+func (fvs *failingVoucherStore) AppendPaymentRecord(r PaymentRecord) error {
+	if fvs.failRecords {
+		return fmt.Errorf("could not record payment for channelId %s", r.ChannelId.String())
+	}
+	return fvs.VoucherStore.AppendPaymentRecord(r)
+}
+
+func TestPaymentRecordedOnceWhenStoreFails(t *testing.T) {
+	var (
+		channelId = types.Destination{1}
+		deposit   = big.NewInt(1000)
+		payment   = big.NewInt(20)
+	)
+	payerStore := &failingVoucherStore{VoucherStore: newSimpleVoucherStore()}
+	payeeStore := &failingVoucherStore{VoucherStore: newSimpleVoucherStore()}
+	paymentMgr := NewVoucherManager(testactors.Alice.Address(), payerStore)
+	receiptMgr := NewVoucherManager(testactors.Bob.Address(), payeeStore)
+	Ok(t, paymentMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+	Ok(t, receiptMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+
+	// A payment that cannot be recorded does not store its voucher
+	payerStore.failRecords = true
+	_, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	Assert(t, err != nil, "expected an error")
+	paid, err := paymentMgr.Paid(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(0), paid)
+	payerStore.failRecords = false
+
+	// A payment whose voucher cannot be stored is recorded once when it is retried
+	payerStore.failVouchers = true
+	_, err = paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	Assert(t, err != nil, "expected an error")
+	payerStore.failVouchers = false
+	voucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	Ok(t, err)
+	Equals(t, payment, voucher.Amount)
+
+	payeeStore.failRecords = true
+	_, _, err = receiptMgr.Receive(voucher)
+	Assert(t, err != nil, "expected an error")
+	received, err := receiptMgr.Paid(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(0), received)
+	payeeStore.failRecords = false
+
+	payeeStore.failVouchers = true
+	_, _, err = receiptMgr.Receive(voucher)
+	Assert(t, err != nil, "expected an error")
+	payeeStore.failVouchers = false
+	_, delta, err := receiptMgr.Receive(voucher)
+	Ok(t, err)
+	Equals(t, payment, delta)
+
+	for _, mgr := range []*VoucherManager{paymentMgr, receiptMgr} {
+		history, err := mgr.PaymentHistory(channelId, 0, 0)
+		Ok(t, err)
+		Equals(t, 1, len(history))
+		Equals(t, payment, history[0].Total)
+	}
 }
 
 // TODO: This is a copy of the test helpers from github.com/statechannels/go-nitro/internal/testactors
@@ -1,6 +1,7 @@
 package payments
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -8,12 +9,25 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+var (
+	// ErrChannelNotRegistered is returned when the voucher manager has no record of a channel
+	ErrChannelNotRegistered = errors.New("channel not registered")
+	// ErrInsufficientFunds is returned when a payment is larger than the funds remaining in a channel
+	ErrInsufficientFunds = errors.New("insufficient funds")
+)
+
 // VoucherStore is an interface for storing voucher information that the voucher manager expects.
 // To avoid import cycles, this interface is defined in the payments package, but implemented in the store package.
 type VoucherStore interface {
 	SetVoucherInfo(channelId types.Destination, v VoucherInfo) error
 	GetVoucherInfo(channelId types.Destination) (v *VoucherInfo, err error)
 	RemoveVoucherInfo(channelId types.Destination) error
+	// AppendPaymentRecord adds the record to the end of the channel's payment history, assigning it the next index.
+	// A record with the same direction and total as the last record of the channel is for the same voucher, so it is not appended again.
+	AppendPaymentRecord(r PaymentRecord) error
+	// GetPaymentHistory returns at most limit records from the channel's payment history, starting at index offset.
+	// A limit of 0 returns every record from offset onwards.
+	GetPaymentHistory(channelId types.Destination, offset, limit uint64) ([]PaymentRecord, error)
 }
 
 // VoucherInfo stores the status of payments for a given payment channel.
@@ -51,13 +65,16 @@ func (vm *VoucherManager) Remove(channelId types.Destination) error {
 // Pay will deduct amount from balance and add it to paid, returning a signed voucher for the
 // total amount paid.
 func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk []byte) (Voucher, error) {
+	if amount == nil || amount.Sign() < 0 {
+		return Voucher{}, fmt.Errorf("unable to pay negative amount %v", amount)
+	}
 	vInfo, err := vm.store.GetVoucherInfo(channelId)
 	if err != nil {
-		return Voucher{}, fmt.Errorf("channel not registered: %w", err)
+		return Voucher{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 
 	if types.Gt(amount, vInfo.Remaining()) {
-		return Voucher{}, fmt.Errorf("unable to pay amount: insufficient funds")
+		return Voucher{}, fmt.Errorf("unable to pay amount: %w", ErrInsufficientFunds)
 	}
 
 	if vInfo.ChannelPayer != vm.me {
@@ -72,6 +89,13 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 		return voucher, err
 	}
 
+	// The payment is recorded before the voucher is stored, so that it is in the history of every stored voucher.
+	// If the voucher cannot be stored, paying again appends nothing, as the record is for the same voucher.
+	err = vm.store.AppendPaymentRecord(newPaymentRecord(voucher, amount, Sent))
+	if err != nil {
+		return Voucher{}, fmt.Errorf("could not record payment: %w", err)
+	}
+
 	err = vm.store.SetVoucherInfo(channelId, *vInfo)
 	if err != nil {
 		return Voucher{}, err
@@ -83,7 +107,7 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.Int, err error) {
 	vInfo, err := vm.store.GetVoucherInfo(voucher.ChannelId)
 	if err != nil {
-		return &big.Int{}, &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+		return &big.Int{}, &big.Int{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 
 	// We only care about vouchers when we are the recipient of the payment
@@ -92,7 +116,7 @@ func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.I
 	}
 
 	if types.Gt(voucher.Amount, vInfo.StartingBalance) {
-		return &big.Int{}, &big.Int{}, fmt.Errorf("channel has insufficient funds")
+		return &big.Int{}, &big.Int{}, fmt.Errorf("channel has %w", ErrInsufficientFunds)
 	}
 
 	total = vInfo.LargestVoucher.Amount
@@ -113,6 +137,12 @@ func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.I
 	total = voucher.Amount
 	vInfo.LargestVoucher = voucher
 
+	// As in Pay, the payment is recorded first, so that receiving the voucher again records it once
+	err = vm.store.AppendPaymentRecord(newPaymentRecord(voucher, delta, Received))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not record payment: %w", err)
+	}
+
 	err = vm.store.SetVoucherInfo(voucher.ChannelId, *vInfo)
 	if err != nil {
 		return nil, nil, err
@@ -130,16 +160,22 @@ func (vm *VoucherManager) ChannelRegistered(channelId types.Destination) bool {
 func (vm *VoucherManager) Paid(chanId types.Destination) (*big.Int, error) {
 	v, err := vm.store.GetVoucherInfo(chanId)
 	if err != nil {
-		return &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+		return &big.Int{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 	return v.LargestVoucher.Amount, nil
 }
 
+// PaymentHistory returns at most limit records from the payment history of the channel, starting at index offset.
+// A limit of 0 returns every record from offset onwards.
+func (vm *VoucherManager) PaymentHistory(chanId types.Destination, offset, limit uint64) ([]PaymentRecord, error) {
+	return vm.store.GetPaymentHistory(chanId, offset, limit)
+}
+
 // Remaining returns the remaining amount of funds in the channel
 func (vm *VoucherManager) Remaining(chanId types.Destination) (*big.Int, error) {
 	v, err := vm.store.GetVoucherInfo(chanId)
 	if err != nil {
-		return &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+		return &big.Int{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 	remaining := big.NewInt(0).Sub(v.StartingBalance, v.LargestVoucher.Amount)
 	return remaining, nil
@@ -42,6 +42,16 @@ type ReceiveVoucherSummary struct {
 	Delta *big.Int
 }
 
+// ReceivedVoucher describes a voucher received from the payer of a payment channel
+type ReceivedVoucher struct {
+	ChannelId types.Destination
+	Payer     common.Address
+	// Delta is the amount paid by the voucher. It is zero if the voucher is no larger than one received before.
+	Delta *big.Int
+	// Total is the amount paid on the channel so far, including this voucher
+	Total *big.Int
+}
+
 func (v *Voucher) Hash() (types.Bytes32, error) {
 	encoded, err := abi.Arguments{
 		{Type: nitroAbi.Destination},
@@ -49,6 +49,8 @@ type Message struct {
 	Payments []payments.Voucher
 	// RejectedObjectives is a collection of objectives that have been rejected.
 	RejectedObjectives []ObjectiveId
+	// Metadata carries information about the message that is not part of any protocol, such as the trace context of the operation that sent it.
+	Metadata map[string]string `json:",omitempty"`
 }
 
 // Serialize serializes the message into a string.
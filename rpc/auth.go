@@ -1,101 +1,358 @@
 package rpc
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
-// todo: the private key should not be hardcoded
-var rpcPK = []byte("rpcPK")
-
-type permission string
+// Permission is a capability granted to the holder of an auth token
+type Permission string
 
 const permissionKey = "perm"
+
 const (
-	permNone permission = "none"
-	permRead permission = "read"
-	permSign permission = "sign"
+	permNone         Permission = "none"
+	PermRead         Permission = "read"
+	PermPay          Permission = "pay"
+	PermOpenChannel  Permission = "open-channel"
+	PermCloseChannel Permission = "close-channel"
+	// PermAdmin grants every other permission, as well as the ability to revoke tokens
+	PermAdmin Permission = "admin"
 )
 
-var allPermissions = []permission{permRead, permSign}
+var AllPermissions = []Permission{PermRead, PermPay, PermOpenChannel, PermCloseChannel, PermAdmin}
+
+// DefaultTokenExpiry is how long an auth token is valid for if AuthConfig does not specify an expiry
+const DefaultTokenExpiry = 7 * 24 * time.Hour
+
+const signingSecretLength = 32
+
+// localClientId is the id of a client that connects over a local socket without supplying credentials
+const localClientId = "local"
 
 var (
 	errInvalidSigningMethod = errors.New("invalid signing method")
 	errInvalidToken         = errors.New("invalid token")
 	errExpiredToken         = errors.New("token has expired")
+	errRevokedToken         = errors.New("token has been revoked")
+	errUnknownClient        = errors.New("token was issued to an unknown client")
 	errInvalidPermissions   = errors.New("token has invalid permissions")
 	errInvalidPermission    = errors.New("token has an invalid permission")
 	errMissingPermission    = errors.New("token is missing permission")
+	errMissingTokenId       = errors.New("token has no id")
+	errInvalidCredentials   = errors.New("invalid client credentials")
 )
 
 var invalidIAtFormat = "invalid issued at: %w"
 
+// ClientCredentials registers a client that is allowed to request auth tokens.
+// A client authenticates with its api key, or by presenting a TLS client certificate with the given fingerprint.
+type ClientCredentials struct {
+	Id     string `toml:"id"`
+	ApiKey string `toml:"apikey"`
+	// CertFingerprint is the hex encoded SHA-256 hash of the client's DER encoded TLS certificate. Colons are ignored.
+	CertFingerprint string          `toml:"certfingerprint"`
+	Permissions     []Permission    `toml:"permissions"`
+	SpendingLimits  []SpendingLimit `toml:"spendinglimits"`
+}
+
+// AuthConfig configures how the rpc server authenticates clients
+type AuthConfig struct {
+	// SigningSecret is used to sign and verify auth tokens.
+	// If it is empty, a random secret is generated, so tokens do not survive a restart of the server.
+	SigningSecret []byte
+	// TokenExpiry is how long an auth token is valid for. If it is zero, DefaultTokenExpiry is used.
+	TokenExpiry time.Duration
+	// Clients are the clients allowed to request auth tokens.
+	// If there are none, only callers connected over a local socket can request a token, unless Insecure is set.
+	Clients []ClientCredentials
+	// Insecure allows any caller to request a token with every permission when no clients are registered.
+	// It is meant for development, where the rpc server is only reachable from the local machine.
+	Insecure bool
+}
+
+// RequiresClientCerts returns true if any client authenticates with a TLS client certificate
+func (c AuthConfig) RequiresClientCerts() bool {
+	for _, client := range c.Clients {
+		if client.CertFingerprint != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticator issues auth tokens to registered clients, and verifies the tokens presented with requests
+type authenticator struct {
+	signingSecret []byte
+	tokenExpiry   time.Duration
+	clients       map[string]ClientCredentials
+	clientCerts   map[string]string // fingerprint -> client id
+	insecure      bool
+
+	revokedMu      sync.Mutex
+	revokedTokens  map[string]time.Time // token id -> issued at
+	revokedClients map[string]time.Time // client id -> tokens issued at or before this time are revoked
+}
+
+// newAuthenticator validates the config and returns an authenticator that uses it
+func newAuthenticator(config AuthConfig) (*authenticator, error) {
+	a := &authenticator{
+		signingSecret:  config.SigningSecret,
+		tokenExpiry:    config.TokenExpiry,
+		clients:        make(map[string]ClientCredentials),
+		clientCerts:    make(map[string]string),
+		insecure:       config.Insecure,
+		revokedTokens:  make(map[string]time.Time),
+		revokedClients: make(map[string]time.Time),
+	}
+
+	if len(a.signingSecret) == 0 {
+		a.signingSecret = make([]byte, signingSecretLength)
+		if _, err := rand.Read(a.signingSecret); err != nil {
+			return nil, fmt.Errorf("could not generate signing secret: %w", err)
+		}
+	}
+
+	if a.tokenExpiry < 0 {
+		return nil, fmt.Errorf("token expiry must not be negative")
+	}
+	if a.tokenExpiry == 0 {
+		a.tokenExpiry = DefaultTokenExpiry
+	}
+
+	for _, c := range config.Clients {
+		if c.Id == "" {
+			return nil, fmt.Errorf("client has no id")
+		}
+		if c.Id == localClientId {
+			return nil, fmt.Errorf("client id %s is reserved for clients connecting over a local socket", localClientId)
+		}
+		if _, ok := a.clients[c.Id]; ok {
+			return nil, fmt.Errorf("client %s is registered more than once", c.Id)
+		}
+		if c.ApiKey == "" && c.CertFingerprint == "" {
+			return nil, fmt.Errorf("client %s has neither an api key nor a certificate fingerprint", c.Id)
+		}
+		for _, p := range c.Permissions {
+			if !slices.Contains(AllPermissions, p) {
+				return nil, fmt.Errorf("client %s has unknown permission %q", c.Id, p)
+			}
+		}
+		if c.CertFingerprint != "" {
+			fingerprint := normalizeFingerprint(c.CertFingerprint)
+			if _, err := hex.DecodeString(fingerprint); err != nil || len(fingerprint) != 64 {
+				return nil, fmt.Errorf("client %s has an invalid certificate fingerprint", c.Id)
+			}
+			if id, ok := a.clientCerts[fingerprint]; ok {
+				return nil, fmt.Errorf("clients %s and %s have the same certificate fingerprint", id, c.Id)
+			}
+			a.clientCerts[fingerprint] = c.Id
+		}
+		a.clients[c.Id] = c
+	}
+
+	return a, nil
+}
+
+// normalizeFingerprint lower-cases the fingerprint and removes any colon separators
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// isOpen returns true if no clients are registered and the authenticator is insecure, in which case any caller can request a token
+func (a *authenticator) isOpen() bool {
+	return len(a.clients) == 0 && a.insecure
+}
+
+// authenticate checks the credentials supplied by the peer, and returns the id and permissions of the client they belong to.
+// A verified TLS client certificate takes precedence over an api key.
+// A peer connected over a local socket has already passed the socket's file permission check, so it is granted every permission unless it supplies credentials.
+// Any other peer must supply the credentials of a registered client, unless the authenticator is open.
+func (a *authenticator) authenticate(req serde.AuthRequest, peer transport.Peer) (string, []Permission, error) {
+	if peer.Local && req.Id == "" {
+		return localClientId, AllPermissions, nil
+	}
+
+	if a.isOpen() {
+		return req.Id, AllPermissions, nil
+	}
+
+	if peer.CertificateFingerprint != "" {
+		if id, ok := a.clientCerts[normalizeFingerprint(peer.CertificateFingerprint)]; ok {
+			return id, a.clients[id].Permissions, nil
+		}
+	}
+
+	client, ok := a.clients[req.Id]
+	if !ok || client.ApiKey == "" || subtle.ConstantTimeCompare([]byte(client.ApiKey), []byte(req.ApiKey)) != 1 {
+		return "", nil, errInvalidCredentials
+	}
+	return client.Id, client.Permissions, nil
+}
+
 // generateAuthToken generates a JWT token that a client uses to authenticate with the server for restricted endpoints
 // subject is the identifier of the client for which the token is generated
-func generateAuthToken(subject string, p []permission) (string, error) {
+func (a *authenticator) generateAuthToken(subject string, p []Permission) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
 	claims[permissionKey] = p
 	// the keys are defined by https://datatracker.ietf.org/doc/html/rfc7519
 	claims["iat"] = time.Now().Unix()
 	claims["sub"] = subject
-	return token.SignedString(rpcPK)
+	claims["jti"] = hex.EncodeToString(id)
+	return token.SignedString(a.signingSecret)
 }
 
-// checkTokenValidity takes a JWT token, verifies that the token is valid and that the token contains the required permission
-func checkTokenValidity(tokenString string, requiredPermission permission, validDuration time.Duration) error {
-	if requiredPermission == permNone {
-		return nil
-	}
-
+// parseToken verifies the signature of a JWT token and returns its claims. It does not check expiry or revocation.
+func (a *authenticator) parseToken(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
 			return nil, errInvalidSigningMethod
 		}
-		return rpcPK, nil
+		return a.signingSecret, nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return errInvalidToken
+		return nil, errInvalidToken
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
+	return token.Claims.(jwt.MapClaims), nil
+}
+
+// checkTokenValidity takes a JWT token, verifies that the token is valid and that the token contains the required permission.
+// It returns the id of the client the token was issued to, or an empty string if no permission is required.
+func (a *authenticator) checkTokenValidity(tokenString string, requiredPermission Permission) (string, error) {
+	if requiredPermission == permNone {
+		return "", nil
+	}
+
+	claims, err := a.parseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
 
 	// Check expiration
 	iAt, err := claims.GetIssuedAt()
 	if err != nil {
-		return fmt.Errorf(invalidIAtFormat, err)
+		return "", fmt.Errorf(invalidIAtFormat, err)
 	}
 
-	if time.Now().After(iAt.Add(validDuration)) {
-		return errExpiredToken
+	if time.Now().After(iAt.Add(a.tokenExpiry)) {
+		return "", errExpiredToken
+	}
+
+	// Check revocation
+	subject, err := claims.GetSubject()
+	if err != nil {
+		return "", err
+	}
+	if !a.isOpen() {
+		if _, ok := a.clients[subject]; !ok && subject != localClientId {
+			return "", errUnknownClient
+		}
+	}
+	id, _ := claims["jti"].(string)
+	if a.isRevoked(id, subject, iAt.Time) {
+		return "", errRevokedToken
 	}
 
 	// Check permissions
 	permissions, ok := claims[permissionKey].([]interface{})
 	if !ok {
-		return errInvalidPermissions
+		return "", errInvalidPermissions
 	}
 
 	for _, p := range permissions {
 		sp, ok := p.(string)
 		if !ok {
-			return errInvalidPermission
+			return "", errInvalidPermission
 		}
 
-		pp := permission(sp)
+		pp := Permission(sp)
 
-		if pp == requiredPermission {
-			return nil
+		if pp == requiredPermission || pp == PermAdmin {
+			return subject, nil
 		}
 	}
 
-	return errMissingPermission
+	return "", errMissingPermission
+}
+
+// isRevoked returns true if the token with the given id, issued to subject at issuedAt, has been revoked
+func (a *authenticator) isRevoked(id, subject string, issuedAt time.Time) bool {
+	a.revokedMu.Lock()
+	defer a.revokedMu.Unlock()
+
+	if _, ok := a.revokedTokens[id]; ok {
+		return true
+	}
+	revokedAt, ok := a.revokedClients[subject]
+	return ok && !issuedAt.After(revokedAt)
+}
+
+// revokeToken revokes a single token, which must have been signed by this authenticator. It returns the id of the token.
+func (a *authenticator) revokeToken(tokenString string) (string, error) {
+	claims, err := a.parseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	iAt, err := claims.GetIssuedAt()
+	if err != nil {
+		return "", fmt.Errorf(invalidIAtFormat, err)
+	}
+	id, _ := claims["jti"].(string)
+	if id == "" {
+		return "", errMissingTokenId
+	}
+
+	a.revokedMu.Lock()
+	defer a.revokedMu.Unlock()
+	a.pruneRevocations()
+	a.revokedTokens[id] = iAt.Time
+	return id, nil
+}
+
+// revokeClientTokens revokes every token issued to the client so far.
+// The client can still request new tokens; to prevent that, remove the client from the config.
+// Tokens have a granularity of one second, so a token issued within the same second as the revocation is also revoked.
+func (a *authenticator) revokeClientTokens(clientId string) {
+	a.revokedMu.Lock()
+	defer a.revokedMu.Unlock()
+	a.pruneRevocations()
+	a.revokedClients[clientId] = time.Now()
+}
+
+// pruneRevocations forgets revocations of tokens that have since expired. The caller must hold revokedMu.
+func (a *authenticator) pruneRevocations() {
+	cutoff := time.Now().Add(-a.tokenExpiry)
+	for id, issuedAt := range a.revokedTokens {
+		if issuedAt.Before(cutoff) {
+			delete(a.revokedTokens, id)
+		}
+	}
+	for id, revokedAt := range a.revokedClients {
+		if revokedAt.Before(cutoff) {
+			delete(a.revokedClients, id)
+		}
+	}
 }
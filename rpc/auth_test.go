@@ -3,41 +3,251 @@ package rpc
 import (
 	"errors"
 	"testing"
-	"time"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
+const testFingerprint = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func newTestAuthenticator(t *testing.T, config AuthConfig) *authenticator {
+	a, err := newAuthenticator(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
 func TestValidAuthToken(t *testing.T) {
-	token, err := generateAuthToken("1", allPermissions)
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+	token, err := a.generateAuthToken("1", AllPermissions)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = checkTokenValidity(token, permSign, time.Hour)
+	_, err = a.checkTokenValidity(token, PermPay)
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
 func TestAuthTokenMissingPermission(t *testing.T) {
-	token, err := generateAuthToken("1", []permission{permRead})
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+	token, err := a.generateAuthToken("1", []Permission{PermRead})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = checkTokenValidity(token, permSign, time.Hour)
+	_, err = a.checkTokenValidity(token, PermPay)
 	if !errors.Is(err, errMissingPermission) {
 		t.Fatal("expected errMissingPermission, got", err)
 	}
 }
 
+func TestAdminGrantsEveryPermission(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+	token, err := a.generateAuthToken("1", []Permission{PermAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range AllPermissions {
+		if _, err := a.checkTokenValidity(token, p); err != nil {
+			t.Fatalf("expected admin token to grant %s, got %v", p, err)
+		}
+	}
+}
+
 func TestExpiredAuthToken(t *testing.T) {
-	token, err := generateAuthToken("1", allPermissions)
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+	token, err := a.generateAuthToken("1", AllPermissions)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = checkTokenValidity(token, permSign, time.Duration(0))
+	a.tokenExpiry = 0
+	_, err = a.checkTokenValidity(token, PermPay)
 	if !errors.Is(err, errExpiredToken) {
 		t.Fatal("expected errExpiredToken, got", err)
 	}
 }
+
+func TestAuthTokenFromAnotherSecret(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{SigningSecret: []byte("a")})
+	b := newTestAuthenticator(t, AuthConfig{SigningSecret: []byte("b")})
+	token, err := a.generateAuthToken("1", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.checkTokenValidity(token, PermRead); err == nil {
+		t.Fatal("expected a token signed with another secret to be rejected")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Clients: []ClientCredentials{
+		{Id: "reader", ApiKey: "reader-key", Permissions: []Permission{PermRead}},
+		{Id: "operator", CertFingerprint: testFingerprint, Permissions: []Permission{PermAdmin}},
+	}})
+
+	id, permissions, err := a.authenticate(serde.AuthRequest{Id: "reader", ApiKey: "reader-key"}, transport.Peer{})
+	if err != nil || id != "reader" || len(permissions) != 1 || permissions[0] != PermRead {
+		t.Fatalf("expected reader to authenticate with its api key, got %s %v %v", id, permissions, err)
+	}
+
+	_, _, err = a.authenticate(serde.AuthRequest{Id: "reader", ApiKey: "wrong-key"}, transport.Peer{})
+	if !errors.Is(err, errInvalidCredentials) {
+		t.Fatal("expected errInvalidCredentials, got", err)
+	}
+
+	// The operator has no api key, so it can only authenticate with its certificate
+	_, _, err = a.authenticate(serde.AuthRequest{Id: "operator"}, transport.Peer{})
+	if !errors.Is(err, errInvalidCredentials) {
+		t.Fatal("expected errInvalidCredentials, got", err)
+	}
+
+	id, _, err = a.authenticate(serde.AuthRequest{}, transport.Peer{CertificateFingerprint: testFingerprint})
+	if err != nil || id != "operator" {
+		t.Fatalf("expected operator to authenticate with its certificate, got %s %v", id, err)
+	}
+}
+
+func TestAuthenticateLocalPeer(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Clients: []ClientCredentials{{Id: "reader", ApiKey: "reader-key", Permissions: []Permission{PermRead}}}})
+	local := transport.Peer{Local: true}
+
+	id, permissions, err := a.authenticate(serde.AuthRequest{}, local)
+	if err != nil || id != localClientId || len(permissions) != len(AllPermissions) {
+		t.Fatalf("expected a local peer without credentials to be granted every permission, got %s %v %v", id, permissions, err)
+	}
+	token, err := a.generateAuthToken(id, permissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.checkTokenValidity(token, PermPay); err != nil {
+		t.Fatal("expected the token of a local peer to be valid, got", err)
+	}
+
+	// A local peer that supplies credentials is authenticated as the client they belong to
+	id, permissions, err = a.authenticate(serde.AuthRequest{Id: "reader", ApiKey: "reader-key"}, local)
+	if err != nil || id != "reader" || len(permissions) != 1 {
+		t.Fatalf("expected the local peer to authenticate as reader, got %s %v %v", id, permissions, err)
+	}
+
+	// Remote peers cannot claim to be local
+	if _, _, err := a.authenticate(serde.AuthRequest{}, transport.Peer{}); !errors.Is(err, errInvalidCredentials) {
+		t.Fatal("expected errInvalidCredentials, got", err)
+	}
+}
+
+func TestOpenAuthenticator(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+
+	id, permissions, err := a.authenticate(serde.AuthRequest{Id: "anyone"}, transport.Peer{})
+	if err != nil || id != "anyone" || len(permissions) != len(AllPermissions) {
+		t.Fatalf("expected any caller to be granted every permission, got %s %v %v", id, permissions, err)
+	}
+}
+
+func TestAuthenticatorWithoutClients(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{})
+
+	// Without registered clients, remote peers cannot request a token unless the authenticator is insecure
+	if _, _, err := a.authenticate(serde.AuthRequest{Id: "anyone"}, transport.Peer{}); !errors.Is(err, errInvalidCredentials) {
+		t.Fatal("expected errInvalidCredentials, got", err)
+	}
+	if _, _, err := a.authenticate(serde.AuthRequest{}, transport.Peer{CertificateFingerprint: testFingerprint}); !errors.Is(err, errInvalidCredentials) {
+		t.Fatal("expected errInvalidCredentials, got", err)
+	}
+
+	// Local peers can
+	id, permissions, err := a.authenticate(serde.AuthRequest{}, transport.Peer{Local: true})
+	if err != nil || id != localClientId || len(permissions) != len(AllPermissions) {
+		t.Fatalf("expected a local peer to be granted every permission, got %s %v %v", id, permissions, err)
+	}
+
+	// Tokens issued to anyone else, such as by an insecure server sharing the signing secret, are rejected
+	token, err := a.generateAuthToken("anyone", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.checkTokenValidity(token, PermRead); !errors.Is(err, errUnknownClient) {
+		t.Fatal("expected errUnknownClient, got", err)
+	}
+}
+
+func TestAuthTokenForUnknownClient(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Clients: []ClientCredentials{{Id: "reader", ApiKey: "key", Permissions: []Permission{PermRead}}}})
+	token, err := a.generateAuthToken("removed", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = a.checkTokenValidity(token, PermRead)
+	if !errors.Is(err, errUnknownClient) {
+		t.Fatal("expected errUnknownClient, got", err)
+	}
+}
+
+func TestRevokeAuthToken(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+	revoked, err := a.generateAuthToken("1", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := a.generateAuthToken("1", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = a.revokeToken(revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.checkTokenValidity(revoked, PermRead); !errors.Is(err, errRevokedToken) {
+		t.Fatal("expected errRevokedToken, got", err)
+	}
+	if _, err := a.checkTokenValidity(other, PermRead); err != nil {
+		t.Fatal("expected other token to remain valid, got", err)
+	}
+}
+
+func TestRevokeClientTokens(t *testing.T) {
+	a := newTestAuthenticator(t, AuthConfig{Insecure: true})
+	revoked, err := a.generateAuthToken("1", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := a.generateAuthToken("2", AllPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.revokeClientTokens("1")
+
+	if _, err := a.checkTokenValidity(revoked, PermRead); !errors.Is(err, errRevokedToken) {
+		t.Fatal("expected errRevokedToken, got", err)
+	}
+	if _, err := a.checkTokenValidity(other, PermRead); err != nil {
+		t.Fatal("expected token of another client to remain valid, got", err)
+	}
+}
+
+func TestInvalidAuthConfig(t *testing.T) {
+	configs := map[string]AuthConfig{
+		"negative expiry":     {TokenExpiry: -1},
+		"missing id":          {Clients: []ClientCredentials{{ApiKey: "key"}}},
+		"missing credentials": {Clients: []ClientCredentials{{Id: "1"}}},
+		"duplicate id":        {Clients: []ClientCredentials{{Id: "1", ApiKey: "a"}, {Id: "1", ApiKey: "b"}}},
+		"reserved id":         {Clients: []ClientCredentials{{Id: localClientId, ApiKey: "key"}}},
+		"unknown permission":  {Clients: []ClientCredentials{{Id: "1", ApiKey: "key", Permissions: []Permission{"sign"}}}},
+		"invalid fingerprint": {Clients: []ClientCredentials{{Id: "1", CertFingerprint: "abc"}}},
+	}
+	for name, config := range configs {
+		if _, err := newAuthenticator(config); err == nil {
+			t.Errorf("%s: expected config to be rejected", name)
+		}
+	}
+}
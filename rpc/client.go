@@ -3,8 +3,9 @@ package rpc
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log/slog"
+	"math"
 	"sync"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/node/tracing"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
@@ -21,10 +23,14 @@ import (
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
 	"github.com/statechannels/go-nitro/rpc/serde"
 	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/statechannels/go-nitro/rpc/transport/unix"
 	"github.com/statechannels/go-nitro/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RpcClientApi provides various functions to make RPC API calls to a nitro RPC server
@@ -32,6 +38,9 @@ type RpcClientApi interface {
 	// Address returns the address of the nitro node
 	Address() (common.Address, error)
 
+	// Discover returns the OpenRPC document describing the api of the rpc server
+	Discover() (openrpc.Document, error)
+
 	// CreateVoucher creates a voucher for the given channelId and amount and returns it.
 	// It is the responsibility of the caller to send the voucher to the payee.
 	CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error)
@@ -59,6 +68,28 @@ type RpcClientApi interface {
 	// GetPaymentChannelsByLedger returns all active payment channels for a given ledger channel
 	GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error)
 
+	// GetLedgerChannels returns a page of at most limit ledger channels matching the filter, ordered by channel id.
+	// The page starts after the channel identified by cursor (the NextCursor of the previous page), or at the first channel if cursor is empty.
+	// A limit of 0 requests the largest page the server supports.
+	GetLedgerChannels(filter query.ChannelFilter, cursor string, limit uint64) (query.LedgerChannelsPage, error)
+
+	// GetPaymentChannels returns a page of at most limit payment channels matching the filter, ordered by channel id.
+	// The page starts after the channel identified by cursor (the NextCursor of the previous page), or at the first channel if cursor is empty.
+	// A limit of 0 requests the largest page the server supports.
+	GetPaymentChannels(filter query.ChannelFilter, cursor string, limit uint64) (query.PaymentChannelsPage, error)
+
+	// GetBalanceHistory returns the balance snapshots of the given channel taken in [from, to), ordered by time.
+	// A zero to requests snapshots up to the current time.
+	GetBalanceHistory(chId types.Destination, from, to time.Time) ([]query.BalanceSnapshot, error)
+
+	// GetAssetBalanceHistory returns the total balance of each asset held in the node's channels, at each interval after from up to and including to.
+	// A zero to requests balances up to the current time. An interval of 0 returns only the balances at to.
+	GetAssetBalanceHistory(from, to time.Time, interval time.Duration) ([]query.AssetBalance, error)
+
+	// GetPaymentHistory returns a page of at most limit payments made on the given payment channel, starting at index offset.
+	// A limit of 0 requests the largest page the server supports.
+	GetPaymentHistory(chId types.Destination, offset, limit uint64) (query.PaymentHistory, error)
+
 	// CreateLedgerChannel creates a new ledger channel with the specified counterparty, ChallengeDuration, and outcome
 	CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error)
 
@@ -68,6 +99,12 @@ type RpcClientApi interface {
 	// Pay uses the specified channel to pay the specified amount
 	Pay(id types.Destination, amount uint64) (serde.PaymentRequest, error)
 
+	// RevokeAuthToken revokes the given auth token, so that it can no longer be used. It requires the admin permission.
+	RevokeAuthToken(token string) error
+
+	// RevokeClientTokens revokes every auth token issued to the given client so far. It requires the admin permission.
+	RevokeClientTokens(clientId string) error
+
 	// Close shuts down the RpcClient and closes the underlying transport
 	Close() error
 
@@ -79,19 +116,48 @@ type RpcClientApi interface {
 
 	// PaymentChannelUpdatesChan returns a channel that receives payment channel updates for the given payment channel id
 	PaymentChannelUpdatesChan(paymentChannelId types.Destination) <-chan query.PaymentChannelInfo
+
+	// ReceivedVouchersChan returns a channel that receives the details of every voucher the node receives from a payer.
+	// Vouchers are dropped if the channel is full, so that a client that does not read it is not held up.
+	ReceivedVouchersChan() <-chan payments.ReceivedVoucher
+
+	// LastEventSeq returns the sequence number of the last notification the client has received, or 0 if it has received none.
+	// A client that replaces this one, for instance after the connection drops, can pass it to ReplayEvents to receive the notifications it missed.
+	LastEventSeq() uint64
+
+	// ReplayEvents delivers the notifications sent after the notification with sequence number since to the client's notification channels,
+	// skipping any the client has already received. It returns ErrEventsMissed if some of them have been dropped from the node's event log.
+	ReplayEvents(since uint64) error
+
+	// Subscribe asks the server to send the client only the notifications matching the filter, or those of its other subscriptions.
+	// It returns the id of the subscription, which can be passed to Unsubscribe.
+	// Clients that have not subscribed are sent every notification. Subscribing requires a transport over which the server can tell clients apart.
+	Subscribe(filter serde.EventFilter) (string, error)
+
+	// Unsubscribe ends a subscription. The client is still only sent the notifications matching its remaining subscriptions.
+	Unsubscribe(subscriptionId string) error
 }
 
+// ErrEventsMissed is returned by ReplayEvents if some of the notifications to replay have been dropped from the node's bounded event log
+var ErrEventsMissed = errors.New("notifications were dropped from the node's event log before they could be replayed")
+
 // rpcClient is the implementation
 type rpcClient struct {
 	transport             transport.Requester
 	completedObjectives   *safesync.Map[chan struct{}]
 	ledgerChannelUpdates  *safesync.Map[chan query.LedgerChannelInfo]
 	paymentChannelUpdates *safesync.Map[chan query.PaymentChannelInfo]
+	receivedVouchers      chan payments.ReceivedVoucher
 	cancel                context.CancelFunc
 	routineTracker        *sync.WaitGroup
 	nodeAddress           common.Address
 	logger                *slog.Logger
 	authToken             string
+
+	// The notifications delivered so far have the contiguous sequence numbers firstEventSeq to lastEventSeq
+	firstEventSeq uint64
+	lastEventSeq  uint64
+	eventsMu      sync.Mutex // guards firstEventSeq and lastEventSeq, and serializes the delivery of notifications
 }
 
 // response includes a payload or an error.
@@ -100,14 +166,25 @@ type response[T serde.ResponsePayload] struct {
 	Error   error
 }
 
-// NewRpcClient creates a new RpcClient
+// NewRpcClient creates a new RpcClient.
+// The client is authenticated by the transport (e.g. with a TLS client certificate), or not at all if the server has no registered clients.
 func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
+	return newRpcClient(trans, serde.AuthRequest{})
+}
+
+// NewRpcClientWithApiKey creates a new RpcClient that authenticates with the api key registered for clientId on the server
+func NewRpcClientWithApiKey(trans transport.Requester, clientId, apiKey string) (RpcClientApi, error) {
+	return newRpcClient(trans, serde.AuthRequest{Id: clientId, ApiKey: apiKey})
+}
+
+func newRpcClient(trans transport.Requester, credentials serde.AuthRequest) (RpcClientApi, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &rpcClient{
 		transport:             trans,
 		completedObjectives:   &safesync.Map[chan struct{}]{},
 		ledgerChannelUpdates:  &safesync.Map[chan query.LedgerChannelInfo]{},
 		paymentChannelUpdates: &safesync.Map[chan query.PaymentChannelInfo]{},
+		receivedVouchers:      make(chan payments.ReceivedVoucher, 1000),
 		cancel:                cancel,
 		routineTracker:        &sync.WaitGroup{},
 		nodeAddress:           common.Address{},
@@ -131,10 +208,16 @@ func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
 	c.routineTracker.Add(1)
 	go c.subscribeToNotifications(ctx, notificationChan)
 
-	authToken, err := WaitForRequestNoAuth[serde.NoPayloadRequest, string](c, serde.GetAuthTokenMethod, serde.NoPayloadRequest{})
+	authToken, err := WaitForRequestNoAuth[serde.AuthRequest, string](c, serde.GetAuthTokenMethod, credentials)
+	if err != nil {
+		// Stop listening for notifications, but leave the transport for the caller to close
+		c.cancel()
+		c.routineTracker.Wait()
+		return nil, err
+	}
 	c.authToken = authToken
 
-	return c, err
+	return c, nil
 }
 
 // NewHttpRpcClient creates a new rpcClient using an http transport
@@ -143,7 +226,27 @@ func NewHttpRpcClient(rpcServerUrl string) (RpcClientApi, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewRpcClient(transport)
+	client, err := NewRpcClient(transport)
+	if err != nil {
+		transport.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewUnixRpcClient creates a new rpcClient connected to a server listening on the unix domain socket at socketPath.
+// The socket's file permissions control access, so the client does not need credentials.
+func NewUnixRpcClient(socketPath string) (RpcClientApi, error) {
+	transport, err := unix.NewUnixTransportAsClient(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewRpcClient(transport)
+	if err != nil {
+		transport.Close()
+		return nil, err
+	}
+	return client, nil
 }
 
 // Address returns the address of the the nitro node
@@ -151,6 +254,11 @@ func (rc *rpcClient) Address() (common.Address, error) {
 	return rc.nodeAddress, nil
 }
 
+// Discover returns the OpenRPC document describing the api of the rpc server
+func (rc *rpcClient) Discover() (openrpc.Document, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, openrpc.Document](rc, serde.DiscoverMethod, serde.NoPayloadRequest{})
+}
+
 // CreateVoucher creates a voucher for the given channelId and amount and returns it.
 // It is the responsibility of the caller to send the voucher to the payee.
 func (rc *rpcClient) CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error) {
@@ -208,6 +316,36 @@ func (rc *rpcClient) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]q
 	return waitForAuthorizedRequest[serde.GetPaymentChannelsByLedgerRequest, []query.PaymentChannelInfo](rc, serde.GetPaymentChannelsByLedgerMethod, serde.GetPaymentChannelsByLedgerRequest{LedgerId: ledgerId})
 }
 
+// GetLedgerChannels returns a page of ledger channels matching the filter
+func (rc *rpcClient) GetLedgerChannels(filter query.ChannelFilter, cursor string, limit uint64) (query.LedgerChannelsPage, error) {
+	req := serde.GetChannelsRequest{Filter: filter, Cursor: cursor, Limit: limit}
+	return waitForAuthorizedRequest[serde.GetChannelsRequest, query.LedgerChannelsPage](rc, serde.GetLedgerChannelsMethod, req)
+}
+
+// GetPaymentChannels returns a page of payment channels matching the filter
+func (rc *rpcClient) GetPaymentChannels(filter query.ChannelFilter, cursor string, limit uint64) (query.PaymentChannelsPage, error) {
+	req := serde.GetChannelsRequest{Filter: filter, Cursor: cursor, Limit: limit}
+	return waitForAuthorizedRequest[serde.GetChannelsRequest, query.PaymentChannelsPage](rc, serde.GetPaymentChannelsMethod, req)
+}
+
+// GetBalanceHistory returns the balance snapshots of the given channel taken in [from, to)
+func (rc *rpcClient) GetBalanceHistory(chId types.Destination, from, to time.Time) ([]query.BalanceSnapshot, error) {
+	req := serde.GetBalanceHistoryRequest{Id: chId, From: from, To: to}
+	return waitForAuthorizedRequest[serde.GetBalanceHistoryRequest, []query.BalanceSnapshot](rc, serde.GetBalanceHistoryMethod, req)
+}
+
+// GetAssetBalanceHistory returns the total balance of each asset held in the node's channels at regular intervals
+func (rc *rpcClient) GetAssetBalanceHistory(from, to time.Time, interval time.Duration) ([]query.AssetBalance, error) {
+	req := serde.GetAssetBalanceHistoryRequest{From: from, To: to, IntervalSeconds: uint64(interval / time.Second)}
+	return waitForAuthorizedRequest[serde.GetAssetBalanceHistoryRequest, []query.AssetBalance](rc, serde.GetAssetBalanceHistoryMethod, req)
+}
+
+// GetPaymentHistory returns a page of at most limit payments made on the given payment channel, starting at index offset
+func (rc *rpcClient) GetPaymentHistory(chId types.Destination, offset, limit uint64) (query.PaymentHistory, error) {
+	req := serde.GetPaymentHistoryRequest{Id: chId, Offset: offset, Limit: limit}
+	return waitForAuthorizedRequest[serde.GetPaymentHistoryRequest, query.PaymentHistory](rc, serde.GetPaymentHistoryMethod, req)
+}
+
 // CreateLedger creates a new ledger channel
 func (rc *rpcClient) CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
 	objReq := directfund.NewObjectiveRequest(
@@ -233,6 +371,36 @@ func (rc *rpcClient) Pay(id types.Destination, amount uint64) (serde.PaymentRequ
 	return waitForAuthorizedRequest[serde.PaymentRequest, serde.PaymentRequest](rc, serde.PayRequestMethod, pReq)
 }
 
+// RevokeAuthToken revokes the given auth token, so that it can no longer be used
+func (rc *rpcClient) RevokeAuthToken(token string) error {
+	req := serde.RevokeAuthTokenRequest{Token: token}
+	_, err := waitForAuthorizedRequest[serde.RevokeAuthTokenRequest, string](rc, serde.RevokeAuthTokenMethod, req)
+	return err
+}
+
+// RevokeClientTokens revokes every auth token issued to the given client so far
+func (rc *rpcClient) RevokeClientTokens(clientId string) error {
+	req := serde.RevokeClientTokensRequest{ClientId: clientId}
+	_, err := waitForAuthorizedRequest[serde.RevokeClientTokensRequest, string](rc, serde.RevokeClientTokensMethod, req)
+	return err
+}
+
+func (rc *rpcClient) Subscribe(filter serde.EventFilter) (string, error) {
+	// Notifications are replayed by ReplayEvents, so none are requested here
+	req := serde.SubscribeRequest{Since: math.MaxUint64, Filter: filter}
+	res, err := waitForAuthorizedRequest[serde.SubscribeRequest, serde.SubscribeResponse](rc, serde.SubscribeMethod, req)
+	if err != nil {
+		return "", err
+	}
+	return res.SubscriptionId, nil
+}
+
+func (rc *rpcClient) Unsubscribe(subscriptionId string) error {
+	req := serde.UnsubscribeRequest{SubscriptionId: subscriptionId}
+	_, err := waitForAuthorizedRequest[serde.UnsubscribeRequest, string](rc, serde.UnsubscribeMethod, req)
+	return err
+}
+
 func (rc *rpcClient) Close() error {
 	rc.cancel()
 	rc.routineTracker.Wait()
@@ -247,44 +415,130 @@ func (rc *rpcClient) subscribeToNotifications(ctx context.Context, notificationC
 			rc.routineTracker.Done()
 			return
 		case data := <-notificationChan:
-
-			method, err := getNotificationMethod(data)
+			var notification struct {
+				Method serde.NotificationMethod `json:"method"`
+				Params struct {
+					Payload json.RawMessage `json:"payload"`
+					Seq     uint64          `json:"seq"`
+				} `json:"params"`
+			}
+			err := json.Unmarshal(data, &notification)
 			if err != nil {
 				panic(err)
 			}
-			switch method {
-			case serde.ObjectiveCompleted:
-				rpcRequest := serde.JsonRpcSpecificRequest[protocols.ObjectiveId]{}
-				err := json.Unmarshal(data, &rpcRequest)
-				rc.logger.Debug("Received notification", "method", method, "data", rpcRequest)
-				if err != nil {
-					panic(err)
-				}
-				c, _ := rc.completedObjectives.LoadOrStore(string(rpcRequest.Params.Payload), make(chan struct{}))
-				close(c)
-			case serde.LedgerChannelUpdated:
-				rpcRequest := serde.JsonRpcSpecificRequest[query.LedgerChannelInfo]{}
-				err := json.Unmarshal(data, &rpcRequest)
-				rc.logger.Debug("Received notification", "method", method, "data", rpcRequest)
-				if err != nil {
-					panic(err)
-				}
-				c, _ := rc.ledgerChannelUpdates.LoadOrStore(string(rpcRequest.Params.Payload.ID.String()), make(chan query.LedgerChannelInfo, 100))
-				c <- rpcRequest.Params.Payload
-
-			case serde.PaymentChannelUpdated:
-				rpcRequest := serde.JsonRpcSpecificRequest[query.PaymentChannelInfo]{}
-				err := json.Unmarshal(data, &rpcRequest)
-				rc.logger.Debug("Received notification", "method", method, "data", rpcRequest)
-				if err != nil {
-					panic(err)
-				}
-				c, _ := rc.paymentChannelUpdates.LoadOrStore(string(rpcRequest.Params.Payload.ID.String()), make(chan query.PaymentChannelInfo, 100))
-				c <- rpcRequest.Params.Payload
+			err = rc.deliverNotification(notification.Method, notification.Params.Payload, notification.Params.Seq, false)
+			if err != nil {
+				panic(err)
 			}
+		}
+	}
+}
+
+// deliverNotification sends the payload of a notification to the notification channels it concerns, unless the notification has already been delivered.
+// Notifications without a sequence number were not recorded by the node, so cannot be replayed and are always delivered.
+func (rc *rpcClient) deliverNotification(method serde.NotificationMethod, payload json.RawMessage, seq uint64, replayed bool) error {
+	rc.eventsMu.Lock()
+	defer rc.eventsMu.Unlock()
+
+	if seq != 0 {
+		if rc.firstEventSeq != 0 && seq >= rc.firstEventSeq && seq <= rc.lastEventSeq {
+			return nil
+		}
+		// Received notifications follow on from those delivered so far, whereas replayed notifications may precede them
+		if rc.firstEventSeq == 0 {
+			rc.firstEventSeq = seq
+		}
+		if !replayed || seq > rc.lastEventSeq {
+			rc.lastEventSeq = seq
+		}
+	}
+
+	rc.logger.Debug("Received notification", "method", method, "seq", seq, "replayed", replayed, "payload", string(payload))
+	switch method {
+	case serde.ObjectiveCompleted:
+		var id protocols.ObjectiveId
+		if err := json.Unmarshal(payload, &id); err != nil {
+			return err
+		}
+		c, _ := rc.completedObjectives.LoadOrStore(string(id), make(chan struct{}))
+		select {
+		case <-c:
+			// The objective has already been reported as complete
+		default:
+			close(c)
+		}
+	case serde.LedgerChannelUpdated:
+		var info query.LedgerChannelInfo
+		if err := json.Unmarshal(payload, &info); err != nil {
+			return err
+		}
+		c, _ := rc.ledgerChannelUpdates.LoadOrStore(string(info.ID.String()), make(chan query.LedgerChannelInfo, 100))
+		c <- info
+	case serde.PaymentChannelUpdated:
+		var info query.PaymentChannelInfo
+		if err := json.Unmarshal(payload, &info); err != nil {
+			return err
+		}
+		c, _ := rc.paymentChannelUpdates.LoadOrStore(string(info.ID.String()), make(chan query.PaymentChannelInfo, 100))
+		c <- info
+	case serde.VoucherReceived:
+		var v payments.ReceivedVoucher
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return err
+		}
+		select {
+		case rc.receivedVouchers <- v:
+		default:
+			rc.logger.Warn("Dropped received voucher notification as the channel is full", "channelId", v.ChannelId)
+		}
+	}
+	return nil
+}
+
+// LastEventSeq returns the sequence number of the last notification the client has received
+func (rc *rpcClient) LastEventSeq() uint64 {
+	rc.eventsMu.Lock()
+	defer rc.eventsMu.Unlock()
+	return rc.lastEventSeq
+}
 
+// ReplayEvents delivers the notifications sent after the notification with sequence number since, which the client has not already received
+func (rc *rpcClient) ReplayEvents(since uint64) error {
+	missed := false
+	next := since
+	for {
+		// Pages are requested without holding eventsMu, so that notifications received meanwhile are not held up
+		page, err := waitForAuthorizedRequest[serde.GetEventsRequest, query.EventsPage](rc, serde.GetEventsMethod, serde.GetEventsRequest{Since: next})
+		if err != nil {
+			return err
+		}
+		if next == since {
+			missed = page.Missed
 		}
+
+		for _, e := range page.Events {
+			err := rc.deliverNotification(serde.NotificationMethod(e.Method), e.Payload, e.Seq, true)
+			if err != nil {
+				return err
+			}
+			next = e.Seq
+		}
+		if !page.HasMore {
+			break
+		}
+	}
+
+	// The replayed notifications lead up to those received since the client connected, so together they are contiguous
+	rc.eventsMu.Lock()
+	if !missed && rc.firstEventSeq > since+1 && next+1 >= rc.firstEventSeq {
+		rc.firstEventSeq = since + 1
 	}
+	rc.eventsMu.Unlock()
+
+	if missed {
+		return ErrEventsMissed
+	}
+	return nil
 }
 
 // ObjectiveCompleteChan returns a chan that receives an empty struct when the objective with given id is completed
@@ -305,6 +559,10 @@ func (rc *rpcClient) PaymentChannelUpdatesChan(paymentChannelId types.Destinatio
 	return c
 }
 
+func (rc *rpcClient) ReceivedVouchersChan() <-chan payments.ReceivedVoucher {
+	return rc.receivedVouchers
+}
+
 // WaitForRequestNoAuth calls waitForRequest with an empty auth token
 func WaitForRequestNoAuth[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClient, method serde.RequestMethod, requestData T) (U, error) {
 	return waitForRequest[T, U](rc, method, requestData, "")
@@ -331,12 +589,20 @@ func waitForRequest[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClie
 //   - Returns an error if:
 //     [1] the request fails to send
 //     [2] the response cannot be parsed
-//   - Otherwise, returns the JSONRPC server's response
+//   - Otherwise, returns the JSONRPC server's response, with any error it contains converted to a *ServerError
+//
+// The request is traced by a client span, whose trace context is sent with the request for the server to continue.
 func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transport.Requester, method serde.RequestMethod, reqPayload T,
 	authToken string, logger *slog.Logger, wg *sync.WaitGroup,
-) (response[U], error) {
+) (res response[U], err error) {
+	ctx, span := tracing.Tracer().Start(context.Background(), "rpc."+string(method),
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("rpc.method", string(method))))
+	// The span records both errors sending the request and errors returned by the server
+	defer func() { tracing.End(span, errors.Join(err, res.Error)) }()
+
 	requestId := rand.Uint64()
 	message := serde.NewJsonRpcSpecificRequest(requestId, method, reqPayload, authToken)
+	message.Params.TraceContext = tracing.Inject(ctx)
 	data, err := json.Marshal(message)
 	if err != nil {
 		return response[U]{}, err
@@ -354,8 +620,8 @@ func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transpor
 	err = json.Unmarshal(responseData, &jsonResponse)
 	if err != nil {
 		return response[U]{}, err
-	} else if jsonResponse.Error != (serde.JsonRpcError{}) {
-		return response[U]{Error: jsonResponse.Error}, nil
+	} else if jsonResponse.Error.Code != 0 {
+		return response[U]{Error: newServerError(jsonResponse.Error)}, nil
 	}
 
 	// Now convert response.Result into the specific type for this request, and return that
@@ -366,19 +632,3 @@ func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transpor
 	}
 	return response[U]{Payload: successResponse.Result}, nil
 }
-
-// getNotificationMethod parses the raw notification and returns the notification method
-func getNotificationMethod(raw []byte) (serde.NotificationMethod, error) {
-	var notif map[string]interface{}
-
-	err := json.Unmarshal(raw, &notif)
-	if err != nil {
-		return "", err
-	}
-
-	method, ok := notif["method"].(string)
-	if !ok {
-		return "", fmt.Errorf("method not found in notification")
-	}
-	return serde.NotificationMethod(method), nil
-}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliverNotificationSkipsDuplicates(t *testing.T) {
+	rc := &rpcClient{
+		completedObjectives:   &safesync.Map[chan struct{}]{},
+		ledgerChannelUpdates:  &safesync.Map[chan query.LedgerChannelInfo]{},
+		paymentChannelUpdates: &safesync.Map[chan query.PaymentChannelInfo]{},
+		logger:                slog.Default(),
+	}
+	channelId := types.Destination{1}
+	deliver := func(seq uint64, replayed bool) {
+		payload, err := json.Marshal(query.LedgerChannelInfo{ID: channelId, Status: query.ChannelStatus(fmt.Sprint(seq))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rc.deliverNotification(serde.LedgerChannelUpdated, payload, seq, replayed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The client connects after notification 4 was sent, and replays the notifications since 2 while notification 7 is sent
+	deliver(5, false)
+	deliver(6, false)
+	for seq := uint64(3); seq <= 7; seq++ {
+		deliver(seq, true)
+	}
+	deliver(7, false)
+	deliver(8, false)
+	assert.Equal(t, uint64(8), rc.LastEventSeq())
+
+	updates := rc.LedgerChannelUpdatesChan(channelId)
+	for _, seq := range []uint64{5, 6, 3, 4, 7, 8} {
+		info := <-updates
+		assert.Equal(t, query.ChannelStatus(fmt.Sprint(seq)), info.Status)
+	}
+	assert.Empty(t, updates)
+
+	// A notification that was not recorded by the node is always delivered
+	deliver(0, false)
+	assert.Len(t, updates, 1)
+	assert.Equal(t, uint64(8), rc.LastEventSeq())
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+)
+
+// Errors that a request to the rpc server can fail with. Errors returned by RpcClientApi methods can be matched against them using errors.Is:
+//
+//	if errors.Is(err, rpc.ErrInsufficientFunds) { ... }
+var (
+	ErrParse                    = errors.New("parse error")
+	ErrInvalidRequest           = errors.New("invalid request")
+	ErrMethodNotFound           = errors.New("method not found")
+	ErrInvalidParams            = errors.New("invalid params")
+	ErrInternal                 = errors.New("internal error")
+	ErrInvalidAuthToken         = errors.New("invalid auth token")
+	ErrInvalidCredentials       = errors.New("invalid client credentials")
+	ErrPermissionDenied         = errors.New("permission denied")
+	ErrSpendingLimitExceeded    = errors.New("spending limit exceeded")
+	ErrChannelNotFound          = errors.New("channel not found")
+	ErrInsufficientFunds        = errors.New("insufficient funds")
+	ErrChannelExists            = errors.New("channel already exists")
+	ErrSubscriptionsUnsupported = errors.New("subscriptions unsupported")
+	ErrSubscriptionNotFound     = errors.New("subscription not found")
+	// ErrUnauthorized matches any error caused by the client not being allowed to make the request: an invalid auth token, invalid credentials or a missing permission
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// errorCodes maps each error to the jsonrpc error codes it matches
+var errorCodes = map[error][]int64{
+	ErrParse:                    {serde.ParseError.Code},
+	ErrInvalidRequest:           {serde.InvalidRequestError.Code, serde.RequestUnmarshalError.Code},
+	ErrMethodNotFound:           {serde.MethodNotFoundError.Code},
+	ErrInvalidParams:            {serde.InvalidParamsError.Code, serde.ParamsUnmarshalError.Code},
+	ErrInternal:                 {serde.InternalServerError.Code},
+	ErrInvalidAuthToken:         {serde.InvalidAuthTokenError.Code},
+	ErrInvalidCredentials:       {serde.InvalidCredentialsError.Code},
+	ErrPermissionDenied:         {serde.PermissionDeniedError.Code},
+	ErrSpendingLimitExceeded:    {serde.SpendingLimitExceededError.Code},
+	ErrChannelNotFound:          {serde.ChannelNotFoundError.Code},
+	ErrInsufficientFunds:        {serde.InsufficientFundsError.Code},
+	ErrChannelExists:            {serde.ChannelExistsError.Code},
+	ErrSubscriptionsUnsupported: {serde.SubscriptionsUnsupportedError.Code},
+	ErrSubscriptionNotFound:     {serde.SubscriptionNotFoundError.Code},
+	ErrUnauthorized:             {serde.InvalidAuthTokenError.Code, serde.InvalidCredentialsError.Code, serde.PermissionDeniedError.Code},
+}
+
+// ServerError is an error response returned by the rpc server
+type ServerError struct {
+	Code    int64
+	Message string
+	Data    any
+}
+
+func newServerError(e serde.JsonRpcError) *ServerError {
+	return &ServerError{Code: e.Code, Message: e.Message, Data: e.Data}
+}
+
+// Error returns the message sent by the server
+func (e *ServerError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is one of the errors of this package that matches the code of e
+func (e *ServerError) Is(target error) bool {
+	// The map is not indexed by target, as that panics if target is not comparable
+	for err, codes := range errorCodes {
+		if err == target {
+			return slices.Contains(codes, e.Code)
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+)
+
+func TestServerErrorIs(t *testing.T) {
+	testCases := []struct {
+		jsonErr  serde.JsonRpcError
+		matches  []error
+		excludes []error
+	}{
+		{serde.InsufficientFundsError, []error{ErrInsufficientFunds}, []error{ErrChannelNotFound, ErrUnauthorized}},
+		{serde.ChannelNotFoundError, []error{ErrChannelNotFound}, []error{ErrInsufficientFunds, ErrInternal}},
+		{serde.PermissionDeniedError, []error{ErrPermissionDenied, ErrUnauthorized}, []error{ErrInvalidAuthToken}},
+		{serde.InvalidAuthTokenError, []error{ErrInvalidAuthToken, ErrUnauthorized}, []error{ErrPermissionDenied}},
+		{serde.ParamsUnmarshalError, []error{ErrInvalidParams}, []error{ErrInvalidRequest}},
+		{serde.InternalServerError, []error{ErrInternal}, []error{ErrChannelNotFound}},
+	}
+
+	for _, tc := range testCases {
+		// Errors are wrapped to check that matching does not depend on the error being returned directly
+		err := fmt.Errorf("request failed: %w", newServerError(tc.jsonErr))
+		for _, target := range tc.matches {
+			if !errors.Is(err, target) {
+				t.Errorf("expected %q to match %q", err, target)
+			}
+		}
+		for _, target := range tc.excludes {
+			if errors.Is(err, target) {
+				t.Errorf("expected %q not to match %q", err, target)
+			}
+		}
+
+		var serverErr *ServerError
+		if !errors.As(err, &serverErr) || serverErr.Code != tc.jsonErr.Code {
+			t.Errorf("expected %q to contain a server error with code %d", err, tc.jsonErr.Code)
+		}
+	}
+
+	// An uncomparable target must not cause a panic
+	if errors.Is(newServerError(serde.InternalServerError), serde.JsonRpcError{Data: map[string]string{}}) {
+		t.Error("expected a jsonrpc error not to match")
+	}
+}
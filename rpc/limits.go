@@ -0,0 +1,396 @@
+package rpc
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// SpendingLimit caps the amount of an asset that a client can spend.
+// Payments and vouchers count as spending. Funding a channel does not, but a client cannot fund a channel with more than it is still allowed to spend.
+// The funding of the channels a client opens is committed against the limit until they close or fail to open, so that it cannot
+// open any number of channels that are each within the limit. Payment channels are funded from ledger channels, so the funding of
+// ledger channels and of payment channels are committed separately. Payments on a payment channel are paid from its funding,
+// so they reduce the funding committed to it.
+type SpendingLimit struct {
+	Asset  types.Address `toml:"asset"` // The zero address is the chain's native token
+	Amount uint64        `toml:"amount"`
+	// Period is the length of the window the limit applies to. Each window starts with the first payment after the previous one ended.
+	// A period of zero applies the limit for as long as the server is running.
+	Period time.Duration `toml:"period"`
+	// PerChannel applies the limit to each channel separately, rather than to the total spent across all channels
+	PerChannel bool `toml:"perchannel"`
+}
+
+// spendingLimiter tracks how much each client has spent against its spending limits.
+// Usage is kept in memory, so it is reset when the server restarts.
+type spendingLimiter struct {
+	limits map[string][]SpendingLimit // client id -> limits
+
+	mu    sync.Mutex
+	usage map[usageKey]*usage
+
+	// committed is the funding that each client has committed to the channels it opened, less what it has paid on them
+	committed   map[commitmentKey]*big.Int
+	commitments map[types.Destination]*commitment
+	objectives  map[protocols.ObjectiveId]types.Destination
+	// opening counts the channels being opened, whose objectives are not yet known.
+	// Objectives that fail while channels are being opened are kept in failedEarly, in case they are among them.
+	opening     int
+	failedEarly map[protocols.ObjectiveId]bool
+}
+
+// commitmentKey identifies the funding of an asset that a client committed to channels of one kind
+type commitmentKey struct {
+	client string
+	asset  types.Address
+	ledger bool
+}
+
+// commitment is the funding a client committed to a channel it opened
+type commitment struct {
+	client  string
+	ledger  bool
+	funding map[types.Address]*big.Int
+	// paid is the amount of each asset paid on the channel so far
+	paid map[types.Address]*big.Int
+}
+
+// outstanding returns the funding of the asset that has not been paid on the channel
+func (c *commitment) outstanding(asset types.Address) *big.Int {
+	outstanding := new(big.Int).Set(c.funding[asset])
+	if paid, ok := c.paid[asset]; ok {
+		outstanding.Sub(outstanding, paid)
+	}
+	if outstanding.Sign() < 0 {
+		outstanding.SetInt64(0)
+	}
+	return outstanding
+}
+
+// usageKey identifies the usage of one of a client's limits. The channel is only set for per-channel limits.
+type usageKey struct {
+	client  string
+	limit   int
+	channel types.Destination
+}
+
+type usage struct {
+	windowStart time.Time
+	spent       *big.Int
+}
+
+// newSpendingLimiter validates the spending limits of each client and returns a limiter that enforces them
+func newSpendingLimiter(clients []ClientCredentials) (*spendingLimiter, error) {
+	sl := &spendingLimiter{
+		limits:      make(map[string][]SpendingLimit),
+		usage:       make(map[usageKey]*usage),
+		committed:   make(map[commitmentKey]*big.Int),
+		commitments: make(map[types.Destination]*commitment),
+		objectives:  make(map[protocols.ObjectiveId]types.Destination),
+		failedEarly: make(map[protocols.ObjectiveId]bool),
+	}
+	for _, c := range clients {
+		for _, l := range c.SpendingLimits {
+			if l.Period < 0 {
+				return nil, fmt.Errorf("client %s has a spending limit with a negative period", c.Id)
+			}
+		}
+		if len(c.SpendingLimits) > 0 {
+			sl.limits[c.Id] = c.SpendingLimits
+		}
+	}
+	return sl, nil
+}
+
+// hasLimits returns true if any spending limits apply to the client
+func (sl *spendingLimiter) hasLimits(client string) bool {
+	return len(sl.limits[client]) > 0
+}
+
+// remaining returns how much more the client can spend of the asset on the channel under the given limit.
+// The caller must hold mu.
+func (sl *spendingLimiter) remaining(key usageKey, l SpendingLimit, now time.Time) *big.Int {
+	remaining := new(big.Int).SetUint64(l.Amount)
+	u, ok := sl.usage[key]
+	if !ok || (l.Period > 0 && !now.Before(u.windowStart.Add(l.Period))) {
+		return remaining
+	}
+	return remaining.Sub(remaining, u.spent)
+}
+
+// applicable returns the usage keys of the client's limits that apply to spending the asset on the channel
+func (sl *spendingLimiter) applicable(client string, asset types.Address, channel types.Destination) ([]usageKey, []SpendingLimit) {
+	keys := []usageKey{}
+	limits := []SpendingLimit{}
+	for i, l := range sl.limits[client] {
+		if l.Asset != asset {
+			continue
+		}
+		key := usageKey{client: client, limit: i}
+		if l.PerChannel {
+			key.channel = channel
+		}
+		keys = append(keys, key)
+		limits = append(limits, l)
+	}
+	return keys, limits
+}
+
+// checkLocked returns an error if spending amount of the asset on the channel would exceed any of the client's limits.
+// The caller must hold mu.
+func (sl *spendingLimiter) checkLocked(client string, asset types.Address, channel types.Destination, amount *big.Int, now time.Time) error {
+	keys, limits := sl.applicable(client, asset, channel)
+	for i, key := range keys {
+		remaining := sl.remaining(key, limits[i], now)
+		if !limits[i].PerChannel {
+			remaining.Sub(remaining, sl.committedElsewhere(client, asset, channel))
+		}
+		if amount.Cmp(remaining) > 0 {
+			return fmt.Errorf("client %s can spend at most %s more of asset %s", client, remaining, asset)
+		}
+	}
+	return nil
+}
+
+// committedElsewhere returns the funding of the asset that the client committed to payment channels other than channel,
+// which it can still spend by paying on them. The caller must hold mu.
+func (sl *spendingLimiter) committedElsewhere(client string, asset types.Address, channel types.Destination) *big.Int {
+	elsewhere := new(big.Int)
+	if committed, ok := sl.committed[commitmentKey{client, asset, false}]; ok {
+		elsewhere.Set(committed)
+	}
+	if c, ok := sl.commitments[channel]; ok && c.client == client && !c.ledger {
+		if _, ok := c.funding[asset]; ok {
+			elsewhere.Sub(elsewhere, c.outstanding(asset))
+		}
+	}
+	return elsewhere
+}
+
+// spend records that the client spent amount of the asset on the channel, or returns an error without recording anything if that would exceed any of its limits.
+// The amount must be positive, as spending a negative amount would lower the recorded spend.
+func (sl *spendingLimiter) spend(client string, asset types.Address, channel types.Destination, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return fmt.Errorf("client %s cannot spend a non-positive amount %v of asset %s", client, amount, asset)
+	}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	now := time.Now()
+	if err := sl.checkLocked(client, asset, channel, amount, now); err != nil {
+		return err
+	}
+
+	keys, limits := sl.applicable(client, asset, channel)
+	for i, key := range keys {
+		u, ok := sl.usage[key]
+		if !ok || (limits[i].Period > 0 && !now.Before(u.windowStart.Add(limits[i].Period))) {
+			u = &usage{windowStart: now, spent: big.NewInt(0)}
+			sl.usage[key] = u
+		}
+		u.spent.Add(u.spent, amount)
+	}
+	sl.payOnCommitment(client, asset, channel, amount)
+	return nil
+}
+
+// refund reverses a previous call to spend, e.g. because the payment failed
+func (sl *spendingLimiter) refund(client string, asset types.Address, channel types.Destination, amount *big.Int) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	sl.payOnCommitment(client, asset, channel, new(big.Int).Neg(amount))
+
+	keys, _ := sl.applicable(client, asset, channel)
+	for _, key := range keys {
+		if u, ok := sl.usage[key]; ok {
+			u.spent.Sub(u.spent, amount)
+			if u.spent.Sign() < 0 {
+				u.spent.SetInt64(0)
+			}
+		}
+	}
+}
+
+// payOnCommitment records a payment of amount, which is negative for a refund, on a channel the client opened.
+// The caller must hold mu.
+func (sl *spendingLimiter) payOnCommitment(client string, asset types.Address, channel types.Destination, amount *big.Int) {
+	c, ok := sl.commitments[channel]
+	if !ok || c.client != client || c.ledger {
+		return
+	}
+	if _, ok := c.funding[asset]; !ok {
+		return
+	}
+	before := c.outstanding(asset)
+	if _, ok := c.paid[asset]; !ok {
+		c.paid[asset] = new(big.Int)
+	}
+	c.paid[asset].Add(c.paid[asset], amount)
+	if c.paid[asset].Sign() < 0 {
+		c.paid[asset].SetInt64(0)
+	}
+	key := commitmentKey{client, asset, c.ledger}
+	if _, ok := sl.committed[key]; !ok {
+		sl.committed[key] = new(big.Int)
+	}
+	sl.committed[key].Sub(sl.committed[key], before).Add(sl.committed[key], c.outstanding(asset))
+}
+
+// commit reserves the funding of a channel that the client is opening, or returns an error without reserving anything if the
+// funding of any asset is more than the client is still allowed to spend, less the funding it has committed to other channels of the same kind.
+// The caller must call opened or abandon once it knows whether the channel is being opened.
+func (sl *spendingLimiter) commit(client string, ledger bool, funding map[types.Address]*big.Int) error {
+	for asset, amount := range funding {
+		if amount == nil || amount.Sign() <= 0 {
+			return fmt.Errorf("client %s cannot fund a channel with a non-positive amount %v of asset %s", client, amount, asset)
+		}
+	}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	now := time.Now()
+	for asset, amount := range funding {
+		keys, limits := sl.applicable(client, asset, types.Destination{})
+		for i, key := range keys {
+			remaining := sl.remaining(key, limits[i], now)
+			// Each channel has its own per-channel limit, so only the other limits are shared with other channels
+			if committed, ok := sl.committed[commitmentKey{client, asset, ledger}]; ok && !limits[i].PerChannel {
+				remaining.Sub(remaining, committed)
+			}
+			if amount.Cmp(remaining) > 0 {
+				return fmt.Errorf("client %s can fund channels with at most %s more of asset %s", client, remaining, asset)
+			}
+		}
+	}
+
+	for asset, amount := range funding {
+		key := commitmentKey{client, asset, ledger}
+		if _, ok := sl.committed[key]; !ok {
+			sl.committed[key] = new(big.Int)
+		}
+		sl.committed[key].Add(sl.committed[key], amount)
+	}
+	sl.opening++
+	return nil
+}
+
+// opened records that the channel whose funding was committed is being opened by the objective.
+// The funding stays committed until the channel closes or the objective fails.
+func (sl *spendingLimiter) opened(client string, ledger bool, funding map[types.Address]*big.Int, channel types.Destination, objective protocols.ObjectiveId) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	c := &commitment{client: client, ledger: ledger, funding: funding, paid: make(map[types.Address]*big.Int)}
+	failed := sl.failedEarly[objective]
+	delete(sl.failedEarly, objective)
+	sl.doneOpening()
+	if failed {
+		sl.uncommit(c)
+		return
+	}
+	sl.commitments[channel] = c
+	sl.objectives[objective] = channel
+}
+
+// abandon releases the funding that was committed to a channel that is not being opened after all
+func (sl *spendingLimiter) abandon(client string, ledger bool, funding map[types.Address]*big.Int) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	sl.doneOpening()
+	sl.uncommit(&commitment{client: client, ledger: ledger, funding: funding})
+}
+
+// doneOpening records that a channel is no longer being opened. The caller must hold mu.
+func (sl *spendingLimiter) doneOpening() {
+	sl.opening--
+	if sl.opening == 0 {
+		clear(sl.failedEarly)
+	}
+}
+
+// uncommit releases the outstanding funding of a commitment. The caller must hold mu.
+func (sl *spendingLimiter) uncommit(c *commitment) {
+	for asset := range c.funding {
+		key := commitmentKey{c.client, asset, c.ledger}
+		committed, ok := sl.committed[key]
+		if !ok {
+			continue
+		}
+		committed.Sub(committed, c.outstanding(asset))
+		if committed.Sign() <= 0 {
+			delete(sl.committed, key)
+		}
+	}
+}
+
+// release releases the funding committed to the channel, if any. The caller must hold mu.
+func (sl *spendingLimiter) release(channel types.Destination) {
+	c, ok := sl.commitments[channel]
+	if !ok {
+		return
+	}
+	delete(sl.commitments, channel)
+	for objective, ch := range sl.objectives {
+		if ch == channel {
+			delete(sl.objectives, objective)
+		}
+	}
+	sl.uncommit(c)
+}
+
+// handleEngineEvent releases the funding committed to channels that have closed, or whose objectives have failed.
+// It does not block, so it can be registered with Node.AddEngineEventHandler.
+func (sl *spendingLimiter) handleEngineEvent(event engine.EngineEvent) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	for _, objective := range event.FailedObjectives {
+		if channel, ok := sl.objectives[objective]; ok {
+			sl.release(channel)
+		} else if sl.opening > 0 {
+			sl.failedEarly[objective] = true
+		}
+	}
+	for _, info := range event.LedgerChannelUpdates {
+		if info.Status == query.Complete {
+			sl.release(info.ID)
+		}
+	}
+	for _, info := range event.PaymentChannelUpdates {
+		if info.Status == query.Complete {
+			sl.release(info.ID)
+		}
+	}
+}
+
+// fundingByAsset returns the total amount allocated to destination in the outcome, for each asset it is allocated any of
+func fundingByAsset(o outcome.Exit, destination types.Destination) map[types.Address]*big.Int {
+	funding := make(map[types.Address]*big.Int)
+	for _, sae := range o {
+		for _, a := range sae.Allocations {
+			if a.Destination != destination {
+				continue
+			}
+			if _, ok := funding[sae.Asset]; !ok {
+				funding[sae.Asset] = big.NewInt(0)
+			}
+			funding[sae.Asset].Add(funding[sae.Asset], a.Amount)
+		}
+	}
+	for asset, amount := range funding {
+		if amount.Sign() == 0 {
+			delete(funding, asset)
+		}
+	}
+	return funding
+}
@@ -0,0 +1,204 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var (
+	testAsset    = common.HexToAddress("0xa5")
+	otherAsset   = common.HexToAddress("0xb6")
+	testChannelA = types.Destination(common.HexToHash("0x0a"))
+	testChannelB = types.Destination(common.HexToHash("0x0b"))
+)
+
+func newTestLimiter(t *testing.T, limits ...SpendingLimit) *spendingLimiter {
+	sl, err := newSpendingLimiter([]ClientCredentials{{Id: "client", ApiKey: "key", SpendingLimits: limits}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sl
+}
+
+func TestSpendingLimitAcrossChannels(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10})
+
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(6)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.spend("client", testAsset, testChannelB, big.NewInt(5)); err == nil {
+		t.Fatal("expected the limit to apply to the total spent across channels")
+	}
+	if err := sl.spend("client", testAsset, testChannelB, big.NewInt(4)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other assets and other clients are not limited
+	if err := sl.spend("client", otherAsset, testChannelA, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if sl.hasLimits("other-client") {
+		t.Fatal("expected no limits for a client without spending limits")
+	}
+}
+
+func TestSpendingLimitPerChannel(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10, PerChannel: true})
+
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(1)); err == nil {
+		t.Fatal("expected the limit of channel A to be exhausted")
+	}
+	if err := sl.spend("client", testAsset, testChannelB, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.commit("client", false, map[types.Address]*big.Int{testAsset: big.NewInt(11)}); err == nil {
+		t.Fatal("expected a new channel to be limited")
+	}
+}
+
+func TestSpendingLimitPeriod(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10, Period: 50 * time.Millisecond})
+
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(1)); err == nil {
+		t.Fatal("expected the limit to be exhausted")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(10)); err != nil {
+		t.Fatal("expected the limit to reset after the period, got", err)
+	}
+}
+
+func TestSpendingLimitRefund(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10})
+
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	sl.refund("client", testAsset, testChannelA, big.NewInt(4))
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(4)); err != nil {
+		t.Fatal("expected a refund to restore the limit, got", err)
+	}
+}
+
+func TestSpendingLimitCommittedFunding(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10})
+	funding := func(amount int64) map[types.Address]*big.Int {
+		return map[types.Address]*big.Int{testAsset: big.NewInt(amount)}
+	}
+
+	if err := sl.commit("client", false, funding(6)); err != nil {
+		t.Fatal(err)
+	}
+	sl.opened("client", false, funding(6), testChannelA, "objective-a")
+	if err := sl.commit("client", false, funding(5)); err == nil {
+		t.Fatal("expected the funding of channel A to count against the limit")
+	}
+	if err := sl.spend("client", testAsset, testChannelB, big.NewInt(5)); err == nil {
+		t.Fatal("expected the funding of channel A to count against payments on other channels")
+	}
+
+	// Paying on channel A spends its funding, rather than adding to it
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(6)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.commit("client", false, funding(5)); err == nil {
+		t.Fatal("expected the payments on channel A to count against the limit")
+	}
+	sl.refund("client", testAsset, testChannelA, big.NewInt(2))
+	if err := sl.commit("client", false, funding(5)); err == nil {
+		t.Fatal("expected a refund to commit the funding of channel A again")
+	}
+
+	// Ledger channels fund payment channels, so their funding is committed separately
+	if err := sl.commit("client", true, funding(10)); err == nil {
+		t.Fatal("expected the payments on channel A to count against the funding of ledger channels")
+	}
+	if err := sl.commit("client", true, funding(4)); err != nil {
+		t.Fatal(err)
+	}
+	sl.abandon("client", true, funding(4))
+
+	// Closing channel A releases what was not paid on it
+	sl.handleEngineEvent(engine.EngineEvent{PaymentChannelUpdates: []query.PaymentChannelInfo{{ID: testChannelA, Status: query.Complete}}})
+	if err := sl.commit("client", false, funding(7)); err == nil {
+		t.Fatal("expected the payments on channel A to still count against the limit")
+	}
+	if err := sl.commit("client", false, funding(6)); err != nil {
+		t.Fatal("expected closing channel A to release its funding, got", err)
+	}
+}
+
+func TestSpendingLimitFailedObjective(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10})
+	funding := map[types.Address]*big.Int{testAsset: big.NewInt(10)}
+
+	if err := sl.commit("client", true, funding); err != nil {
+		t.Fatal(err)
+	}
+	sl.opened("client", true, funding, testChannelA, "objective-a")
+	sl.handleEngineEvent(engine.EngineEvent{FailedObjectives: []protocols.ObjectiveId{"objective-a"}})
+	if err := sl.commit("client", true, funding); err != nil {
+		t.Fatal("expected a failed objective to release its funding, got", err)
+	}
+
+	// The objective can fail before the node returns it, while its funding is committed
+	sl.handleEngineEvent(engine.EngineEvent{FailedObjectives: []protocols.ObjectiveId{"objective-b"}})
+	sl.opened("client", true, funding, testChannelB, "objective-b")
+	if err := sl.commit("client", true, funding); err != nil {
+		t.Fatal("expected an objective that failed early to release its funding, got", err)
+	}
+}
+
+func TestFundingByAsset(t *testing.T) {
+	me := types.Destination(common.HexToHash("0x01"))
+	them := types.Destination(common.HexToHash("0x02"))
+	o := outcome.Exit{
+		{Asset: testAsset, Allocations: outcome.Allocations{{Destination: me, Amount: big.NewInt(5)}, {Destination: them, Amount: big.NewInt(7)}}},
+		{Asset: otherAsset, Allocations: outcome.Allocations{{Destination: them, Amount: big.NewInt(3)}}},
+	}
+
+	funding := fundingByAsset(o, me)
+	if len(funding) != 1 || funding[testAsset].Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("unexpected funding %v", funding)
+	}
+}
+
+func TestSpendingLimitRejectsNonPositiveAmounts(t *testing.T) {
+	sl := newTestLimiter(t, SpendingLimit{Asset: testAsset, Amount: 10})
+
+	// An amount of 2^63 or more must not wrap around to a negative amount that lowers the recorded spend
+	huge := new(big.Int).SetUint64(1 << 63)
+	if err := sl.spend("client", testAsset, testChannelA, huge); err == nil {
+		t.Fatal("expected an amount of 2^63 to exceed the limit")
+	}
+	for _, amount := range []*big.Int{big.NewInt(0), new(big.Int).Neg(huge)} {
+		if err := sl.spend("client", testAsset, testChannelA, amount); err == nil {
+			t.Fatalf("expected spending %s to be rejected", amount)
+		}
+		if err := sl.commit("client", false, map[types.Address]*big.Int{testAsset: amount}); err == nil {
+			t.Fatalf("expected committing %s to be rejected", amount)
+		}
+	}
+
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.spend("client", testAsset, testChannelA, big.NewInt(1)); err == nil {
+		t.Fatal("expected the rejected amounts to leave the recorded spend unchanged")
+	}
+}
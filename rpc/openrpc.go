@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+)
+
+//go:generate go run ../cmd/generate-rpc-client --document openrpc.json --typescript ../packages/nitro-rpc-client/src/generated/api.ts
+
+// apiVersion is the version of the api served by the rpc server
+const apiVersion = "v1"
+
+// apiMethod describes a method of the api served by the rpc server
+type apiMethod struct {
+	name       serde.RequestMethod
+	summary    string
+	permission Permission
+	params     reflect.Type
+	result     reflect.Type
+	errors     []serde.JsonRpcError
+}
+
+// describe describes a method with a payload of type T and a result of type U.
+// The errors are the application errors the method can return, other than those caused by an invalid auth token.
+func describe[T serde.RequestPayload, U serde.ResponsePayload](name serde.RequestMethod, permission Permission, summary string, errors ...serde.JsonRpcError) apiMethod {
+	return apiMethod{
+		name:       name,
+		summary:    summary,
+		permission: permission,
+		params:     reflect.TypeOf((*T)(nil)).Elem(),
+		result:     reflect.TypeOf((*U)(nil)).Elem(),
+		errors:     errors,
+	}
+}
+
+// apiMethods describes every method of the api, in the order they are listed in the OpenRPC document
+var apiMethods = []apiMethod{
+	describe[serde.AuthRequest, string](serde.GetAuthTokenMethod, permNone, "Returns an auth token granting the permissions of the client", serde.InvalidCredentialsError),
+	describe[serde.RevokeAuthTokenRequest, string](serde.RevokeAuthTokenMethod, PermAdmin, "Revokes an auth token, so that it can no longer be used"),
+	describe[serde.RevokeClientTokensRequest, string](serde.RevokeClientTokensMethod, PermAdmin, "Revokes every auth token issued to a client so far"),
+	describe[serde.NoPayloadRequest, string](serde.GetAddressMethod, permNone, "Returns the address of the node"),
+	describe[serde.NoPayloadRequest, string](serde.VersionMethod, permNone, "Returns the version of the node"),
+	describe[directfund.ObjectiveRequest, directfund.ObjectiveResponse](serde.CreateLedgerChannelRequestMethod, PermOpenChannel, "Creates a ledger channel with a counterparty", serde.ChannelExistsError),
+	describe[directdefund.ObjectiveRequest, protocols.ObjectiveId](serde.CloseLedgerChannelRequestMethod, PermCloseChannel, "Closes a ledger channel"),
+	describe[virtualfund.ObjectiveRequest, virtualfund.ObjectiveResponse](serde.CreatePaymentChannelRequestMethod, PermOpenChannel, "Creates a payment channel funded by ledger channels with the intermediaries", serde.SpendingLimitExceededError),
+	describe[virtualdefund.ObjectiveRequest, protocols.ObjectiveId](serde.ClosePaymentChannelRequestMethod, PermCloseChannel, "Closes a payment channel"),
+	describe[serde.PaymentRequest, serde.PaymentRequest](serde.PayRequestMethod, PermPay, "Pays an amount to the counterparty of a payment channel", serde.SpendingLimitExceededError),
+	describe[serde.PaymentRequest, payments.Voucher](serde.CreateVoucherRequestMethod, PermPay, "Creates a voucher paying an amount on a payment channel, for the caller to send to the payee", serde.SpendingLimitExceededError, serde.ChannelNotFoundError, serde.InsufficientFundsError),
+	describe[payments.Voucher, payments.ReceiveVoucherSummary](serde.ReceiveVoucherRequestMethod, PermRead, "Receives a voucher sent outside of the node", serde.ChannelNotFoundError, serde.InsufficientFundsError),
+	describe[serde.GetPaymentChannelRequest, query.PaymentChannelInfo](serde.GetPaymentChannelRequestMethod, PermRead, "Returns a payment channel", serde.ChannelNotFoundError),
+	describe[serde.GetLedgerChannelRequest, query.LedgerChannelInfo](serde.GetLedgerChannelRequestMethod, PermRead, "Returns a ledger channel", serde.ChannelNotFoundError),
+	describe[serde.NoPayloadRequest, serde.GetAllLedgersResponse](serde.GetAllLedgerChannelsMethod, PermRead, "Returns every ledger channel"),
+	describe[serde.GetPaymentChannelsByLedgerRequest, serde.GetPaymentChannelsByLedgerResponse](serde.GetPaymentChannelsByLedgerMethod, PermRead, "Returns the active payment channels funded by a ledger channel"),
+	describe[serde.GetChannelsRequest, query.LedgerChannelsPage](serde.GetLedgerChannelsMethod, PermRead, "Returns a page of the ledger channels matching a filter"),
+	describe[serde.GetChannelsRequest, query.PaymentChannelsPage](serde.GetPaymentChannelsMethod, PermRead, "Returns a page of the payment channels matching a filter"),
+	describe[serde.GetPaymentHistoryRequest, query.PaymentHistory](serde.GetPaymentHistoryMethod, PermRead, "Returns a page of the payments made on a payment channel"),
+	describe[serde.GetBalanceHistoryRequest, serde.GetBalanceHistoryResponse](serde.GetBalanceHistoryMethod, PermRead, "Returns the balance snapshots of a channel taken in a period"),
+	describe[serde.GetAssetBalanceHistoryRequest, serde.GetAssetBalanceHistoryResponse](serde.GetAssetBalanceHistoryMethod, PermRead, "Returns the total balance of each asset at intervals over a period"),
+	describe[serde.GetEventsRequest, query.EventsPage](serde.GetEventsMethod, PermRead, "Returns a page of the notifications matching a filter sent after a sequence number, so that a client can replay the notifications it missed"),
+	describe[serde.SubscribeRequest, serde.SubscribeResponse](serde.SubscribeMethod, PermRead, "Subscribes the connection to the notifications matching a filter, after which it is only sent the notifications matching one of its subscriptions", serde.SubscriptionsUnsupportedError),
+	describe[serde.UnsubscribeRequest, string](serde.UnsubscribeMethod, PermRead, "Ends a subscription of the connection", serde.SubscriptionNotFoundError),
+}
+
+// newSchemaGenerator returns a generator of the schemas of api types, with overrides for the types that have a custom JSON encoding
+func newSchemaGenerator() *openrpc.Generator {
+	g := openrpc.NewGenerator()
+	g.Override(reflect.TypeOf(common.Address{}), openrpc.Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]{40}$"})
+	g.Override(reflect.TypeOf(common.Hash{}), openrpc.Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]{64}$"})
+	g.Override(reflect.TypeOf(types.Destination{}), openrpc.Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]{64}$"})
+	g.Override(reflect.TypeOf(crypto.Signature{}), openrpc.Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]{130}$"})
+	g.Override(reflect.TypeOf(hexutil.Big{}), openrpc.Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]+$", Description: "A hex encoded integer"})
+	g.Override(reflect.TypeOf(big.Int{}), openrpc.Schema{Type: "integer"})
+	g.Override(reflect.TypeOf(time.Time{}), openrpc.Schema{Type: "string", Format: "date-time"})
+	g.Override(reflect.TypeOf(json.RawMessage{}), openrpc.Schema{Description: "The JSON payload of the notification"})
+	return g
+}
+
+// OpenRpcDocument returns the OpenRPC document describing the api of the rpc server, which the server serves from the rpc.discover method.
+// It is generated from the types of the payloads and results of the api methods.
+func OpenRpcDocument() (openrpc.Document, error) {
+	g := newSchemaGenerator()
+
+	methods := make([]openrpc.Method, len(apiMethods))
+	for i, m := range apiMethods {
+		payload, err := g.Schema(m.params)
+		if err != nil {
+			return openrpc.Document{}, err
+		}
+		result, err := g.Schema(m.result)
+		if err != nil {
+			return openrpc.Document{}, err
+		}
+
+		errors := m.errors
+		permission := ""
+		if m.permission != permNone {
+			errors = append([]serde.JsonRpcError{serde.InvalidAuthTokenError, serde.PermissionDeniedError}, errors...)
+			permission = string(m.permission)
+		}
+
+		methods[i] = openrpc.Method{
+			Name:           string(m.name),
+			Summary:        m.summary,
+			ParamStructure: "by-name",
+			Params: []openrpc.ContentDescriptor{
+				{Name: "authtoken", Description: "An auth token returned by get_auth_token", Required: m.permission != permNone, Schema: &openrpc.Schema{Type: "string"}},
+				{Name: "payload", Required: true, Schema: payload},
+				{
+					Name:        "tracecontext",
+					Description: "The W3C trace context of the request, such as its traceparent, which the server continues the trace of",
+					Schema:      &openrpc.Schema{Type: "object", AdditionalProperties: &openrpc.Schema{Type: "string"}},
+				},
+			},
+			Result:     openrpc.ContentDescriptor{Name: "result", Schema: result},
+			Errors:     openRpcErrors(errors),
+			Permission: permission,
+		}
+	}
+
+	return openrpc.Document{
+		OpenRpc: openrpc.Version,
+		Info: openrpc.Info{
+			Title:       "go-nitro",
+			Description: "The JSON-RPC api of a go-nitro node",
+			Version:     apiVersion,
+		},
+		Methods:    methods,
+		Components: g.Components(),
+	}, nil
+}
+
+// openRpcDocument returns the document generated by OpenRpcDocument, which is generated once
+var openRpcDocument = sync.OnceValues(OpenRpcDocument)
+
+func openRpcErrors(errs []serde.JsonRpcError) []openrpc.Error {
+	var errors []openrpc.Error
+	for _, e := range errs {
+		errors = append(errors, openrpc.Error{Code: e.Code, Message: e.Message})
+	}
+	return errors
+}
@@ -0,0 +1,69 @@
+// Package openrpc describes a JSON-RPC api as an OpenRPC document (https://spec.open-rpc.org).
+// The JSON schemas of the params and results of methods are generated from their Go types, so that the document cannot drift from the api it describes.
+package openrpc
+
+// Version is the version of the OpenRPC specification that documents conform to
+const Version = "1.2.6"
+
+// Document is an OpenRPC document
+type Document struct {
+	OpenRpc    string     `json:"openrpc"`
+	Info       Info       `json:"info"`
+	Methods    []Method   `json:"methods"`
+	Components Components `json:"components"`
+}
+
+// Info is the metadata of the api
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Method describes a method of the api
+type Method struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary,omitempty"`
+	// ParamStructure is "by-name" if the params are sent as an object, or "by-position" if they are sent as an array
+	ParamStructure string              `json:"paramStructure,omitempty"`
+	Params         []ContentDescriptor `json:"params"`
+	Result         ContentDescriptor   `json:"result"`
+	Errors         []Error             `json:"errors,omitempty"`
+	// Permission is the permission an auth token must grant to call the method, or empty if the method can be called without an auth token
+	Permission string `json:"x-permission,omitempty"`
+}
+
+// ContentDescriptor describes a param or result of a method
+type ContentDescriptor struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Error is an application error that a method can return
+type Error struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// Components holds the schemas that other schemas of the document refer to
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of JSON schema needed to describe the JSON encoding of Go types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// RefPrefix is the prefix of references to the schemas of a document's components
+const RefPrefix = "#/components/schemas/"
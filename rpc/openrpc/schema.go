@@ -0,0 +1,242 @@
+package openrpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generator generates the schemas of Go types, following the rules encoding/json uses to encode values of those types.
+// Named struct types are added to the components of the document, and referred to by reference.
+type Generator struct {
+	overrides map[reflect.Type]Schema
+	// defined holds the schemas of the named struct types that have been referred to
+	defined map[reflect.Type]*Schema
+	// refs holds the references to each named struct type. Their Ref is set once the components are named.
+	refs map[reflect.Type][]*Schema
+}
+
+// NewGenerator creates a generator with no overrides
+func NewGenerator() *Generator {
+	return &Generator{
+		overrides: make(map[reflect.Type]Schema),
+		defined:   make(map[reflect.Type]*Schema),
+		refs:      make(map[reflect.Type][]*Schema),
+	}
+}
+
+// Override sets the schema of values of type t. Types with a custom JSON encoding must be given a schema this way.
+func (g *Generator) Override(t reflect.Type, s Schema) {
+	g.overrides[t] = s
+}
+
+// Schema returns the schema of the JSON encoding of values of type t
+func (g *Generator) Schema(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s, ok := g.overrides[t]; ok {
+		return &s, nil
+	}
+	if hasCustomEncoding(t) {
+		return nil, fmt.Errorf("%s has a custom JSON encoding, so its schema must be overridden", t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		// Any value can be encoded
+		return &Schema{}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !hasCustomEncoding(t.Elem()) {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		return g.arraySchema(t)
+	case reflect.Array:
+		return g.arraySchema(t)
+	case reflect.Map:
+		return g.mapSchema(t)
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.reference(t)
+	default:
+		return nil, fmt.Errorf("%s cannot be encoded as JSON", t)
+	}
+}
+
+// hasCustomEncoding returns true if values of type t, or pointers to them, implement json.Marshaler or encoding.TextMarshaler
+func hasCustomEncoding(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || pt.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+func (g *Generator) arraySchema(t reflect.Type) (*Schema, error) {
+	items, err := g.Schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Type: "array", Items: items}, nil
+}
+
+func (g *Generator) mapSchema(t reflect.Type) (*Schema, error) {
+	switch t.Key().Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !t.Key().Implements(textMarshalerType) {
+			return nil, fmt.Errorf("%s cannot be encoded as JSON: unsupported key type", t)
+		}
+	}
+
+	values, err := g.Schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Type: "object", AdditionalProperties: values}, nil
+}
+
+// reference returns a reference to the schema of the named struct type t, generating the schema the first time t is referred to
+func (g *Generator) reference(t reflect.Type) (*Schema, error) {
+	if strings.ContainsAny(t.Name(), "[]") {
+		return nil, fmt.Errorf("%s is an instance of a generic type, which cannot be named", t)
+	}
+
+	ref := &Schema{}
+	g.refs[t] = append(g.refs[t], ref)
+	if _, ok := g.defined[t]; ok {
+		return ref, nil
+	}
+
+	// The type is defined before its schema is generated, so that recursive types refer to themselves rather than recursing forever
+	g.defined[t] = &Schema{}
+	s, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	*g.defined[t] = *s
+	return ref, nil
+}
+
+// structSchema returns the schema of the struct type t, whose fields are encoded as the properties of an object
+func (g *Generator) structSchema(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := g.addFields(s, t); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addFields adds the encoded fields of the struct type t to s.
+// As with encoding/json, the fields of embedded structs are promoted, unless a field of the same name is less deeply nested.
+func (g *Generator) addFields(s *Schema, t reflect.Type) error {
+	var embedded []reflect.Type
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			embedded = append(embedded, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := s.Properties[name]; ok {
+			continue
+		}
+
+		fieldSchema, err := g.Schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %w", field.Name, t, err)
+		}
+		if hasOption(options, "string") {
+			fieldSchema = &Schema{Type: "string"}
+		}
+		s.Properties[name] = fieldSchema
+		if !hasOption(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	for _, e := range embedded {
+		if err := g.addFields(s, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasOption(options string, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Components names the schemas of the named struct types that have been referred to, and sets the references to them.
+// Types are named by their Go type name, prefixed by their package name if types of several packages share that name.
+func (g *Generator) Components() Components {
+	count := make(map[string]int)
+	for t := range g.defined {
+		count[t.Name()]++
+	}
+
+	schemas := make(map[string]*Schema)
+	for t, s := range g.defined {
+		name := t.Name()
+		if count[name] > 1 {
+			name = pascalCase(path.Base(t.PkgPath())) + name
+		}
+		schemas[name] = s
+		for _, ref := range g.refs[t] {
+			ref.Ref = RefPrefix + name
+		}
+	}
+	return Components{Schemas: schemas}
+}
+
+// pascalCase converts a package name such as consensus_channel to ConsensusChannel
+func pascalCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' })
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, "")
+}
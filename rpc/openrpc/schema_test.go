@@ -0,0 +1,122 @@
+package openrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type inner struct {
+	Shadowed string
+	Promoted int
+}
+
+type outer struct {
+	inner
+	Shadowed bool
+	Renamed  string  `json:"renamed"`
+	Optional *uint64 `json:"optional,omitempty"`
+	Quoted   int     `json:",string"`
+	Skipped  string  `json:"-"`
+	private  string
+	Bytes    []byte
+	Values   map[string]float64
+	Children []outer
+	Any      interface{}
+}
+
+func TestStructSchema(t *testing.T) {
+	g := NewGenerator()
+	s, err := g.Schema(reflect.TypeOf(outer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	components := g.Components()
+	assert.Equal(t, RefPrefix+"outer", s.Ref)
+
+	outerSchema := components.Schemas["outer"]
+	expected := map[string]*Schema{
+		"Shadowed": {Type: "boolean"},
+		"Promoted": {Type: "integer"},
+		"renamed":  {Type: "string"},
+		"optional": {Type: "integer"},
+		"Quoted":   {Type: "string"},
+		"Bytes":    {Type: "string", Format: "byte"},
+		"Values":   {Type: "object", AdditionalProperties: &Schema{Type: "number"}},
+		"Children": {Type: "array", Items: &Schema{Ref: RefPrefix + "outer"}},
+		"Any":      {},
+	}
+	assert.Equal(t, expected, outerSchema.Properties)
+	assert.ElementsMatch(t, []string{"Shadowed", "Promoted", "renamed", "Quoted", "Bytes", "Values", "Children", "Any"}, outerSchema.Required)
+}
+
+func TestCustomEncoding(t *testing.T) {
+	g := NewGenerator()
+	if _, err := g.Schema(reflect.TypeOf(time.Time{})); err == nil {
+		t.Fatal("expected a type with a custom encoding to require an override")
+	}
+
+	g.Override(reflect.TypeOf(time.Time{}), Schema{Type: "string", Format: "date-time"})
+	s, err := g.Schema(reflect.TypeOf(&time.Time{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, s)
+}
+
+func TestNameCollisions(t *testing.T) {
+	type Filter struct{ Status string }
+	g := NewGenerator()
+	for _, v := range []any{Filter{}, inner{}} {
+		if _, err := g.Schema(reflect.TypeOf(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	components := g.Components()
+	assert.Contains(t, components.Schemas, "Filter")
+	assert.Contains(t, components.Schemas, "inner")
+
+	assert.Equal(t, "ConsensusChannel", pascalCase("consensus_channel"))
+	assert.Equal(t, "getPaymentChannel", camelCase("get_payment_channel"))
+}
+
+func TestTypeScript(t *testing.T) {
+	g := NewGenerator()
+	payload, err := g.Schema(reflect.TypeOf(struct{ Id string }{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := g.Schema(reflect.TypeOf([]outer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Document{
+		Info: Info{Title: "test", Version: "v1"},
+		Methods: []Method{{
+			Name:    "get_things",
+			Summary: "Returns things",
+			Params:  []ContentDescriptor{{Name: "payload", Schema: payload}},
+			Result:  ContentDescriptor{Name: "result", Schema: result},
+			Errors:  []Error{{Code: -32013, Message: "Channel not found"}},
+		}},
+		Components: g.Components(),
+	}
+
+	ts, err := TypeScript(d, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"export type outer = {\n",
+		"  optional?: number;\n",
+		"  Children: outer[];\n",
+		"  Values: Record<string, number>;\n",
+		"  get_things: {\n    params: {\n      Id: string;\n    };\n    result: outer[];\n  };\n",
+		"  ChannelNotFound: -32013,\n",
+		"  getThings(\n",
+	} {
+		assert.Contains(t, string(ts), expected)
+	}
+}
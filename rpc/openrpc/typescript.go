@@ -0,0 +1,192 @@
+package openrpc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScript generates a typed TypeScript client of the api described by the document.
+// It contains a type for each schema of the components, a RequestMethods type mapping each method to the types of its payload and result,
+// the codes of the application errors, and a client class with a method for each method of the api.
+func TypeScript(d Document, generator string) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by %s from the OpenRPC document of %s %s. DO NOT EDIT.\n", generator, d.Info.Title, d.Info.Version)
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t, err := tsType(d.Components.Schemas[name], 0)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		fmt.Fprintf(&b, "\nexport type %s = %s;\n", name, t)
+	}
+
+	type method struct {
+		name, summary, payload, result string
+	}
+	methods := make([]method, len(d.Methods))
+	for i, m := range d.Methods {
+		var payload *Schema
+		for _, p := range m.Params {
+			if p.Name == "payload" {
+				payload = p.Schema
+			}
+		}
+		if payload == nil {
+			return nil, fmt.Errorf("method %s has no payload param", m.Name)
+		}
+		payloadType, err := tsType(payload, 4)
+		if err != nil {
+			return nil, fmt.Errorf("payload of method %s: %w", m.Name, err)
+		}
+		resultType, err := tsType(m.Result.Schema, 4)
+		if err != nil {
+			return nil, fmt.Errorf("result of method %s: %w", m.Name, err)
+		}
+		methods[i] = method{name: m.Name, summary: m.Summary, payload: payloadType, result: resultType}
+	}
+
+	b.WriteString("\nexport type RequestMethods = {\n")
+	for _, m := range methods {
+		fmt.Fprintf(&b, "  %s: {\n    params: %s;\n    result: %s;\n  };\n", tsKey(m.name), m.payload, m.result)
+	}
+	b.WriteString("};\n\nexport type RequestMethod = keyof RequestMethods;\n")
+
+	var errors []Error
+	for _, m := range d.Methods {
+		for _, e := range m.Errors {
+			if !slices.Contains(errors, e) {
+				errors = append(errors, e)
+			}
+		}
+	}
+	sort.Slice(errors, func(i, j int) bool { return errors[i].Code > errors[j].Code })
+	b.WriteString("\n/**\n * The codes of the application errors returned by the rpc server\n */\nexport const ErrorCodes = {\n")
+	for _, e := range errors {
+		fmt.Fprintf(&b, "  %s: %d,\n", pascalCase(strings.ReplaceAll(strings.ToLower(e.Message), " ", "_")), e.Code)
+	}
+	b.WriteString("} as const;\n")
+
+	b.WriteString(`
+/**
+ * Sends a request to the rpc server, and resolves to the result of the request
+ */
+export type RequestSender = <M extends RequestMethod>(
+  method: M,
+  payload: RequestMethods[M]["params"]
+) => Promise<RequestMethods[M]["result"]>;
+
+/**
+ * A client of the rpc api, with a method for each method of the api
+ */
+export class RpcApi {
+  constructor(private readonly send: RequestSender) {}
+`)
+	for _, m := range methods {
+		b.WriteString("\n")
+		if m.summary != "" {
+			fmt.Fprintf(&b, "  /**\n   * %s\n   */\n", m.summary)
+		}
+		fmt.Fprintf(&b, "  %s(\n    payload: RequestMethods[%q][\"params\"]\n  ): Promise<RequestMethods[%q][\"result\"]> {\n    return this.send(%q, payload);\n  }\n", camelCase(m.name), m.name, m.name, m.name)
+	}
+	b.WriteString("}\n")
+
+	return []byte(b.String()), nil
+}
+
+// tsType returns the TypeScript type of values matching the schema. Object types are indented by indent spaces.
+func tsType(s *Schema, indent int) (string, error) {
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, RefPrefix)
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %s", s.Ref)
+		}
+		return name, nil
+	}
+
+	switch s.Type {
+	case "":
+		return "unknown", nil
+	case "string":
+		return "string", nil
+	case "integer", "number":
+		return "number", nil
+	case "boolean":
+		return "boolean", nil
+	case "array":
+		items, err := tsType(s.Items, indent)
+		if err != nil {
+			return "", err
+		}
+		if identifier.MatchString(items) {
+			return items + "[]", nil
+		}
+		return "Array<" + items + ">", nil
+	case "object":
+		if s.AdditionalProperties != nil {
+			values, err := tsType(s.AdditionalProperties, indent)
+			if err != nil {
+				return "", err
+			}
+			return "Record<string, " + values + ">", nil
+		}
+		if len(s.Properties) == 0 {
+			return "Record<string, never>", nil
+		}
+		return tsObject(s, indent)
+	default:
+		return "", fmt.Errorf("unsupported type %s", s.Type)
+	}
+}
+
+// tsObject returns the TypeScript type of an object with the properties of the schema, which are listed in alphabetical order
+func tsObject(s *Schema, indent int) (string, error) {
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	padding := strings.Repeat(" ", indent)
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, key := range keys {
+		t, err := tsType(s.Properties[key], indent+2)
+		if err != nil {
+			return "", fmt.Errorf("property %s: %w", key, err)
+		}
+		optional := ""
+		if !slices.Contains(s.Required, key) {
+			optional = "?"
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", padding, tsKey(key), optional, t)
+	}
+	b.WriteString(padding + "}")
+	return b.String(), nil
+}
+
+// tsKey returns key as a property name, quoting it if it is not an identifier
+func tsKey(key string) string {
+	if identifier.MatchString(key) {
+		return key
+	}
+	return fmt.Sprintf("%q", key)
+}
+
+// camelCase converts a method name such as get_payment_channel to getPaymentChannel
+func camelCase(s string) string {
+	p := pascalCase(s)
+	if p == "" {
+		return p
+	}
+	return strings.ToLower(p[:1]) + p[1:]
+}
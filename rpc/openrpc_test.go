@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOpenRpcDocumentUpToDate checks that the committed OpenRPC document and TypeScript client match the api, so that clients generated from them are compatible with the server
+func TestOpenRpcDocumentUpToDate(t *testing.T) {
+	document, err := OpenRpcDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typescript, err := openrpc.TypeScript(document, "go-nitro/cmd/generate-rpc-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for file, expected := range map[string][]byte{
+		"openrpc.json": append(data, '\n'),
+		"../packages/nitro-rpc-client/src/generated/api.ts": typescript,
+	} {
+		committed, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(committed, expected) {
+			t.Errorf("%s is out of date: run go generate ./rpc", file)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, testAuthConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "rpc.discover"}`)
+	response := serde.JsonRpcSuccessResponse[openrpc.Document]{}
+	if err := json.Unmarshal(mockResponder.Handler(transport.Peer{}, request), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, openrpc.Version, response.Result.OpenRpc)
+	assert.Equal(t, len(apiMethods), len(response.Result.Methods))
+
+	// Every documented method must be served. The requests have no auth token, so they fail before they reach the node.
+	for _, m := range response.Result.Methods {
+		if m.Permission == "" {
+			continue
+		}
+		request, err := json.Marshal(serde.JsonRpcGeneralRequest{Jsonrpc: "2.0", Id: 2, Method: m.Name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		errResponse := serde.JsonRpcErrorResponse{}
+		if err := json.Unmarshal(mockResponder.Handler(transport.Peer{}, request), &errResponse); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, serde.InvalidAuthTokenError, errResponse.Error, m.Name)
+	}
+}
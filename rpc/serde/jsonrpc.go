@@ -1,6 +1,8 @@
 package serde
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/statechannels/go-nitro/node/query"
@@ -10,6 +12,7 @@ import (
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -30,6 +33,18 @@ const (
 	GetAllLedgerChannelsMethod        RequestMethod = "get_all_ledger_channels"
 	CreateVoucherRequestMethod        RequestMethod = "create_voucher"
 	ReceiveVoucherRequestMethod       RequestMethod = "receive_voucher"
+	GetPaymentHistoryMethod           RequestMethod = "get_payment_history"
+	GetLedgerChannelsMethod           RequestMethod = "get_ledger_channels"
+	GetPaymentChannelsMethod          RequestMethod = "get_payment_channels"
+	GetBalanceHistoryMethod           RequestMethod = "get_balance_history"
+	GetAssetBalanceHistoryMethod      RequestMethod = "get_asset_balance_history"
+	RevokeAuthTokenMethod             RequestMethod = "revoke_auth_token"
+	RevokeClientTokensMethod          RequestMethod = "revoke_client_tokens"
+	SubscribeMethod                   RequestMethod = "subscribe"
+	UnsubscribeMethod                 RequestMethod = "unsubscribe"
+	GetEventsMethod                   RequestMethod = "get_events"
+	// DiscoverMethod returns the OpenRPC document of the api. As the OpenRPC specification recommends, it is not listed in the document.
+	DiscoverMethod RequestMethod = "rpc.discover"
 )
 
 type NotificationMethod string
@@ -38,6 +53,7 @@ const (
 	ObjectiveCompleted    NotificationMethod = "objective_completed"
 	LedgerChannelUpdated  NotificationMethod = "ledger_channel_updated"
 	PaymentChannelUpdated NotificationMethod = "payment_channel_updated"
+	VoucherReceived       NotificationMethod = "voucher_received"
 )
 
 type NotificationOrRequest interface {
@@ -47,7 +63,14 @@ type NotificationOrRequest interface {
 const JsonRpcVersion = "2.0"
 
 type AuthRequest struct {
-	Id string
+	Id     string
+	ApiKey string // Not required if the client authenticates with a TLS client certificate
+}
+type RevokeAuthTokenRequest struct {
+	Token string
+}
+type RevokeClientTokensRequest struct {
+	ClientId string
 }
 type PaymentRequest struct {
 	Amount  uint64
@@ -62,6 +85,56 @@ type GetLedgerChannelRequest struct {
 type GetPaymentChannelsByLedgerRequest struct {
 	LedgerId types.Destination
 }
+type GetChannelsRequest struct {
+	Filter query.ChannelFilter
+	Cursor string // The NextCursor of the previous page, or empty for the first page
+	Limit  uint64 // A limit of 0 requests the largest page the server supports
+}
+type GetBalanceHistoryRequest struct {
+	Id   types.Destination
+	From time.Time
+	To   time.Time // A zero value requests snapshots up to the current time
+}
+type GetAssetBalanceHistoryRequest struct {
+	From            time.Time
+	To              time.Time // A zero value requests balances up to the current time
+	IntervalSeconds uint64
+}
+type GetPaymentHistoryRequest struct {
+	Id     types.Destination
+	Offset uint64
+	Limit  uint64 // A limit of 0 requests the largest page the server supports
+}
+
+// EventFilter selects notifications. A notification matches if it matches every non-empty field, and it matches a field if it matches any of its values.
+// ChannelIds and Counterparties only match channel updates, and ObjectiveIds only match completed objectives.
+type EventFilter struct {
+	Methods        []NotificationMethod    `json:",omitempty"`
+	ChannelIds     []types.Destination     `json:",omitempty"`
+	Counterparties []types.Address         `json:",omitempty"`
+	ObjectiveIds   []protocols.ObjectiveId `json:",omitempty"`
+}
+
+// GetEventsRequest requests the notifications matching Filter sent after the notification with sequence number Since,
+// so that a client can replay the notifications it missed while it was disconnected.
+type GetEventsRequest struct {
+	Since  uint64
+	Limit  uint64 // A limit of 0 requests the largest page the server supports
+	Filter EventFilter
+}
+
+// SubscribeRequest subscribes the connection the request is sent over to the notifications matching Filter.
+// Once a connection has subscribed, it is only sent the notifications matching one of its subscriptions.
+// The response replays the first page of the matching notifications sent after Since; a Since of math.MaxUint64 replays none.
+type SubscribeRequest struct {
+	Since  uint64
+	Limit  uint64 // A limit of 0 requests the largest page the server supports
+	Filter EventFilter
+}
+
+type UnsubscribeRequest struct {
+	SubscriptionId string
+}
 
 type (
 	NoPayloadRequest = struct{}
@@ -73,10 +146,19 @@ type RequestPayload interface {
 		virtualfund.ObjectiveRequest |
 		virtualdefund.ObjectiveRequest |
 		AuthRequest |
+		RevokeAuthTokenRequest |
+		RevokeClientTokensRequest |
 		PaymentRequest |
 		GetLedgerChannelRequest |
 		GetPaymentChannelRequest |
 		GetPaymentChannelsByLedgerRequest |
+		GetPaymentHistoryRequest |
+		GetChannelsRequest |
+		GetBalanceHistoryRequest |
+		GetAssetBalanceHistoryRequest |
+		GetEventsRequest |
+		SubscribeRequest |
+		UnsubscribeRequest |
 		NoPayloadRequest |
 		payments.Voucher
 }
@@ -84,12 +166,17 @@ type RequestPayload interface {
 type NotificationPayload interface {
 	protocols.ObjectiveId |
 		query.PaymentChannelInfo |
-		query.LedgerChannelInfo
+		query.LedgerChannelInfo |
+		payments.ReceivedVoucher
 }
 
 type Params[T RequestPayload | NotificationPayload] struct {
 	AuthToken string `json:"authtoken"`
 	Payload   T      `json:"payload"`
+	// Seq is the sequence number of a notification, which a client passes to the subscribe method to replay the notifications sent after it
+	Seq uint64 `json:"seq,omitempty"`
+	// TraceContext is the W3C trace context of the request, such as its traceparent, which the server continues the trace of
+	TraceContext map[string]string `json:"tracecontext,omitempty"`
 }
 
 type JsonRpcSpecificRequest[T RequestPayload | NotificationPayload] struct {
@@ -102,8 +189,16 @@ type JsonRpcSpecificRequest[T RequestPayload | NotificationPayload] struct {
 type (
 	GetAllLedgersResponse              = []query.LedgerChannelInfo
 	GetPaymentChannelsByLedgerResponse = []query.PaymentChannelInfo
+	GetBalanceHistoryResponse          = []query.BalanceSnapshot
+	GetAssetBalanceHistoryResponse     = []query.AssetBalance
 )
 
+// SubscribeResponse identifies the subscription, which is passed to unsubscribe to end it, and replays the notifications requested
+type SubscribeResponse struct {
+	SubscriptionId string
+	query.EventsPage
+}
+
 type ResponsePayload interface {
 	directfund.ObjectiveResponse |
 		protocols.ObjectiveId |
@@ -113,10 +208,18 @@ type ResponsePayload interface {
 		query.LedgerChannelInfo |
 		GetAllLedgersResponse |
 		GetPaymentChannelsByLedgerResponse |
+		GetBalanceHistoryResponse |
+		GetAssetBalanceHistoryResponse |
+		query.PaymentHistory |
+		query.LedgerChannelsPage |
+		query.PaymentChannelsPage |
+		query.EventsPage |
+		SubscribeResponse |
 		payments.Voucher |
 		common.Address |
 		string |
-		payments.ReceiveVoucherSummary
+		payments.ReceiveVoucherSummary |
+		openrpc.Document
 }
 
 type JsonRpcSuccessResponse[T ResponsePayload] struct {
@@ -180,13 +283,35 @@ func NewJsonRpcErrorResponse(requestId uint64, error JsonRpcError) *JsonRpcError
 	}
 }
 
+// Standard errors defined by the JSON-RPC 2.0 specification: https://www.jsonrpc.org/specification#error_object
+var (
+	ParseError          = JsonRpcError{Code: -32700, Message: "Parse error"}
+	InvalidRequestError = JsonRpcError{Code: -32600, Message: "Invalid Request"}
+	MethodNotFoundError = JsonRpcError{Code: -32601, Message: "Method not found"}
+	InvalidParamsError  = JsonRpcError{Code: -32602, Message: "Invalid params"}
+	InternalServerError = JsonRpcError{Code: -32603, Message: "Internal error"}
+)
+
+// Application errors, using codes from the range the JSON-RPC 2.0 specification reserves for implementation-defined server errors
 var (
-	ParseError            = JsonRpcError{Code: -32700, Message: "Parse error"}
-	InvalidRequestError   = JsonRpcError{Code: -32600, Message: "Invalid Request"}
-	MethodNotFoundError   = JsonRpcError{Code: -32601, Message: "Method not found"}
-	InvalidParamsError    = JsonRpcError{Code: -32602, Message: "Invalid params"}
-	InternalServerError   = JsonRpcError{Code: -32603, Message: "Internal error"}
-	RequestUnmarshalError = JsonRpcError{Code: -32010, Message: "Could not unmarshal request object"}
-	ParamsUnmarshalError  = JsonRpcError{Code: -32009, Message: "Could not unmarshal params object"}
 	InvalidAuthTokenError = JsonRpcError{Code: -32008, Message: "Invalid auth token"}
+	// Deprecated: the server returns InvalidParamsError instead
+	ParamsUnmarshalError = JsonRpcError{Code: -32009, Message: "Could not unmarshal params object"}
+	// Deprecated: the server returns InvalidRequestError instead
+	RequestUnmarshalError   = JsonRpcError{Code: -32010, Message: "Could not unmarshal request object"}
+	InvalidCredentialsError = JsonRpcError{Code: -32011, Message: "Invalid client credentials"}
+	// SpendingLimitExceededError is returned when a request would exceed a spending limit of the client. The request can be retried once the limit resets.
+	SpendingLimitExceededError = JsonRpcError{Code: -32012, Message: "Spending limit exceeded"}
+	// ChannelNotFoundError is returned when a request refers to a channel the node does not know about
+	ChannelNotFoundError = JsonRpcError{Code: -32013, Message: "Channel not found"}
+	// InsufficientFundsError is returned when a payment is larger than the funds remaining in a channel
+	InsufficientFundsError = JsonRpcError{Code: -32014, Message: "Insufficient funds"}
+	// PermissionDeniedError is returned when a valid auth token does not grant the permission a request requires
+	PermissionDeniedError = JsonRpcError{Code: -32015, Message: "Permission denied"}
+	// ChannelExistsError is returned when a ledger channel with the requested counterparty already exists
+	ChannelExistsError = JsonRpcError{Code: -32016, Message: "Channel already exists"}
+	// SubscriptionsUnsupportedError is returned when a client subscribes over a connection on which the server cannot tell which notifications to send it
+	SubscriptionsUnsupportedError = JsonRpcError{Code: -32017, Message: "Subscriptions unsupported"}
+	// SubscriptionNotFoundError is returned when a client unsubscribes from a subscription its connection does not have
+	SubscriptionNotFoundError = JsonRpcError{Code: -32018, Message: "Subscription not found"}
 )
@@ -14,6 +14,14 @@ func ValidatePaymentRequest(req PaymentRequest) error {
 	return nil
 }
 
+// ValidateCreateVoucherRequest is like ValidatePaymentRequest, but allows a zero amount, which restates the largest voucher of the channel
+func ValidateCreateVoucherRequest(req PaymentRequest) error {
+	if (req.Channel == types.Destination{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
 func ValidateGetPaymentChannelRequest(req GetPaymentChannelRequest) error {
 	if (req.Id == types.Destination{}) {
 		return InvalidParamsError
@@ -27,3 +35,66 @@ func ValidateGetPaymentChannelsByLedgerRequest(req GetPaymentChannelsByLedgerReq
 	}
 	return nil
 }
+
+func ValidateGetPaymentHistoryRequest(req GetPaymentHistoryRequest) error {
+	if (req.Id == types.Destination{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateGetChannelsRequest(req GetChannelsRequest) error {
+	if req.Filter.MinBalance != nil && req.Filter.MinBalance.ToInt().Sign() < 0 {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateGetBalanceHistoryRequest(req GetBalanceHistoryRequest) error {
+	if (req.Id == types.Destination{}) {
+		return InvalidParamsError
+	}
+	if !req.To.IsZero() && req.To.Before(req.From) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateGetAssetBalanceHistoryRequest(req GetAssetBalanceHistoryRequest) error {
+	if !req.To.IsZero() && req.To.Before(req.From) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateRevokeAuthTokenRequest(req RevokeAuthTokenRequest) error {
+	if req.Token == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateRevokeClientTokensRequest(req RevokeClientTokensRequest) error {
+	if req.ClientId == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateEventFilter(filter EventFilter) error {
+	for _, method := range filter.Methods {
+		switch method {
+		case ObjectiveCompleted, LedgerChannelUpdated, PaymentChannelUpdated, VoucherReceived:
+		default:
+			return InvalidParamsError
+		}
+	}
+	return nil
+}
+
+func ValidateUnsubscribeRequest(req UnsubscribeRequest) error {
+	if req.SubscriptionId == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
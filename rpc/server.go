@@ -1,16 +1,22 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"math/big"
 	"sync"
 	"time"
 
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/logging"
 	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/node/tracing"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
@@ -18,18 +24,25 @@ import (
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
 	"github.com/statechannels/go-nitro/rpc/serde"
 	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/statechannels/go-nitro/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RpcServer handles nitro rpc requests and executes them on the nitro node
 type RpcServer struct {
 	transport transport.Responder
 	node      *nitro.Node
-	logger    *slog.Logger
-	cancel    context.CancelFunc
-	wg        *sync.WaitGroup
+	auth      *authenticator
+	limits    *spendingLimiter
+	// subscriptions select the notifications sent over each connection
+	subscriptions *subscriptions
+	logger        *slog.Logger
+	cancel        context.CancelFunc
+	wg            *sync.WaitGroup
 }
 
 func (rs *RpcServer) Url() string {
@@ -52,20 +65,31 @@ func (rs *RpcServer) Close() error {
 }
 
 // newRpcServerWithoutNotifications creates a new rpc server without notifications enabled
-func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer, error) {
+func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Responder, authConfig AuthConfig) (*RpcServer, error) {
 	logger := slog.Default()
 	if hasNitroAddress := (nitroNode.Address != nil) && (nitroNode.Address != &types.Address{}); hasNitroAddress {
 		logger = logging.LoggerWithAddress(slog.Default(), *nitroNode.Address)
 	}
+	auth, err := newAuthenticator(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := newSpendingLimiter(authConfig.Clients)
+	if err != nil {
+		return nil, err
+	}
 	rs := &RpcServer{
-		transport: trans,
-		node:      nitroNode,
-		cancel:    func() {},
-		wg:        &sync.WaitGroup{},
-		logger:    logger,
+		transport:     trans,
+		node:          nitroNode,
+		auth:          auth,
+		limits:        limits,
+		subscriptions: newSubscriptions(),
+		cancel:        func() {},
+		wg:            &sync.WaitGroup{},
+		logger:        logger,
 	}
 
-	err := rs.registerHandlers()
+	err = rs.registerHandlers()
 	if err != nil {
 		return nil, err
 	}
@@ -73,16 +97,32 @@ func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Res
 	return rs, nil
 }
 
-func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer, error) {
+// NewRpcServer creates an rpc server that executes requests on the nitro node, and authenticates clients as configured by authConfig
+func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder, authConfig AuthConfig) (*RpcServer, error) {
+	auth, err := newAuthenticator(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := newSpendingLimiter(authConfig.Clients)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	rs := &RpcServer{
-		transport: trans,
-		node:      nitroNode,
-		cancel:    cancel,
-		wg:        &sync.WaitGroup{},
-		logger:    logging.LoggerWithAddress(slog.Default(), *nitroNode.Address),
+		transport:     trans,
+		node:          nitroNode,
+		auth:          auth,
+		limits:        limits,
+		subscriptions: newSubscriptions(),
+		cancel:        cancel,
+		wg:            &sync.WaitGroup{},
+		logger:        logging.LoggerWithAddress(slog.Default(), *nitroNode.Address),
 	}
 
+	// Funding committed against spending limits is released when the channel closes or fails to open
+	rs.node.AddEngineEventHandler(rs.limits.handleEngineEvent)
+
 	rs.wg.Add(1)
 
 	// The update channels are initialized syncronously.
@@ -91,9 +131,17 @@ func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer,
 	completedObjChan := rs.node.CompletedObjectives()
 	ledgerUpdateChan := rs.node.LedgerUpdates()
 	paymentUpdateChan := rs.node.PaymentUpdates()
+	receivedVoucherChan := rs.node.ReceivedVouchers()
 
-	go rs.sendNotifications(ctx, completedObjChan, ledgerUpdateChan, paymentUpdateChan)
-	err := rs.registerHandlers()
+	switch {
+	case auth.isOpen():
+		rs.logger.Warn("No rpc clients are registered and auth is insecure, so any caller can request an auth token with every permission")
+	case len(auth.clients) == 0:
+		rs.logger.Warn("No rpc clients are registered, so only callers connected over a local socket can request an auth token")
+	}
+
+	go rs.sendNotifications(ctx, completedObjChan, ledgerUpdateChan, paymentUpdateChan, receivedVoucherChan)
+	err = rs.registerHandlers()
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +151,7 @@ func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer,
 
 // registerHandlers registers the handlers for the rpc server
 func (rs *RpcServer) registerHandlers() (err error) {
-	handlerV1 := func(requestData []byte) []byte {
+	handleRequest := func(peer transport.Peer, requestData []byte) []byte {
 		if !json.Valid(requestData) {
 			rs.logger.Error("request is not valid json")
 			errRes := serde.NewJsonRpcErrorResponse(0, serde.ParseError)
@@ -121,14 +169,46 @@ func (rs *RpcServer) registerHandlers() (err error) {
 		switch serde.RequestMethod(jsonrpcReq.Method) {
 		case serde.GetAuthTokenMethod:
 			return processRequest(rs, permNone, requestData, func(req serde.AuthRequest) (string, error) {
-				return generateAuthToken(req.Id, allPermissions)
+				clientId, permissions, err := rs.auth.authenticate(req, peer)
+				if err != nil {
+					rs.logger.Warn("Rejected auth token request", "client", req.Id, "error", err)
+					return "", serde.InvalidCredentialsError
+				}
+				return rs.auth.generateAuthToken(clientId, permissions)
+			})
+		case serde.RevokeAuthTokenMethod:
+			return processRequest(rs, PermAdmin, requestData, func(req serde.RevokeAuthTokenRequest) (string, error) {
+				if err := serde.ValidateRevokeAuthTokenRequest(req); err != nil {
+					return "", err
+				}
+				return rs.auth.revokeToken(req.Token)
+			})
+		case serde.RevokeClientTokensMethod:
+			return processRequest(rs, PermAdmin, requestData, func(req serde.RevokeClientTokensRequest) (string, error) {
+				if err := serde.ValidateRevokeClientTokensRequest(req); err != nil {
+					return "", err
+				}
+				rs.auth.revokeClientTokens(req.ClientId)
+				return req.ClientId, nil
 			})
 		case serde.CreateVoucherRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req serde.PaymentRequest) (payments.Voucher, error) {
-				return rs.node.CreateVoucher(req.Channel, big.NewInt(int64(req.Amount)))
+			return processRequestAs(rs, PermPay, requestData, func(_ context.Context, clientId string, req serde.PaymentRequest) (payments.Voucher, error) {
+				if err := serde.ValidateCreateVoucherRequest(req); err != nil {
+					return payments.Voucher{}, err
+				}
+				amount := new(big.Int).SetUint64(req.Amount)
+				refund, err := rs.spend(clientId, req.Channel, amount)
+				if err != nil {
+					return payments.Voucher{}, err
+				}
+				voucher, err := rs.node.CreateVoucher(req.Channel, amount)
+				if err != nil && voucher.Amount == nil { // the voucher was not created
+					refund()
+				}
+				return voucher, err
 			})
 		case serde.ReceiveVoucherRequestMethod:
-			return processRequest(rs, permRead, requestData, func(req payments.Voucher) (payments.ReceiveVoucherSummary, error) {
+			return processRequest(rs, PermRead, requestData, func(req payments.Voucher) (payments.ReceiveVoucherSummary, error) {
 				return rs.node.ReceiveVoucher(req)
 			})
 		case serde.GetAddressMethod:
@@ -139,90 +219,258 @@ func (rs *RpcServer) registerHandlers() (err error) {
 			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (string, error) {
 				return rs.node.Version(), nil
 			})
+		case serde.DiscoverMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (openrpc.Document, error) {
+				return openRpcDocument()
+			})
 		case serde.CreateLedgerChannelRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req directfund.ObjectiveRequest) (directfund.ObjectiveResponse, error) {
-				return rs.node.CreateLedgerChannel(req.CounterParty, req.ChallengeDuration, req.Outcome)
+			return processRequestAs(rs, PermOpenChannel, requestData, func(ctx context.Context, clientId string, req directfund.ObjectiveRequest) (directfund.ObjectiveResponse, error) {
+				opened, err := rs.commitFunding(clientId, true, req.Outcome)
+				if err != nil {
+					return directfund.ObjectiveResponse{}, err
+				}
+				response, err := rs.node.CreateLedgerChannel(ctx, req.CounterParty, req.ChallengeDuration, req.Outcome)
+				opened(response.Id, response.ChannelId, err)
+				return response, err
 			})
 		case serde.CloseLedgerChannelRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req directdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
-				return rs.node.CloseLedgerChannel(req.ChannelId)
+			return processRequestAs(rs, PermCloseChannel, requestData, func(ctx context.Context, _ string, req directdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
+				return rs.node.CloseLedgerChannel(ctx, req.ChannelId)
 			})
 		case serde.CreatePaymentChannelRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req virtualfund.ObjectiveRequest) (virtualfund.ObjectiveResponse, error) {
-				return rs.node.CreatePaymentChannel(req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome)
+			return processRequestAs(rs, PermOpenChannel, requestData, func(ctx context.Context, clientId string, req virtualfund.ObjectiveRequest) (virtualfund.ObjectiveResponse, error) {
+				opened, err := rs.commitFunding(clientId, false, req.Outcome)
+				if err != nil {
+					return virtualfund.ObjectiveResponse{}, err
+				}
+				response, err := rs.node.CreatePaymentChannel(ctx, req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome)
+				opened(response.Id, response.ChannelId, err)
+				return response, err
 			})
 		case serde.ClosePaymentChannelRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req virtualdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
-				return rs.node.ClosePaymentChannel(req.ChannelId)
+			return processRequestAs(rs, PermCloseChannel, requestData, func(ctx context.Context, _ string, req virtualdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
+				return rs.node.ClosePaymentChannel(ctx, req.ChannelId)
 			})
 		case serde.PayRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req serde.PaymentRequest) (serde.PaymentRequest, error) {
+			return processRequestAs(rs, PermPay, requestData, func(ctx context.Context, clientId string, req serde.PaymentRequest) (serde.PaymentRequest, error) {
 				if err := serde.ValidatePaymentRequest(req); err != nil {
 					return serde.PaymentRequest{}, err
 				}
-				rs.node.Pay(req.Channel, big.NewInt(int64(req.Amount)))
+				amount := new(big.Int).SetUint64(req.Amount)
+				refund, err := rs.spend(clientId, req.Channel, amount)
+				if err != nil {
+					return serde.PaymentRequest{}, err
+				}
+				if err := rs.node.Pay(ctx, req.Channel, amount); err != nil {
+					refund()
+					return serde.PaymentRequest{}, err
+				}
 				return req, nil
 			})
 		case serde.GetPaymentChannelRequestMethod:
-			return processRequest(rs, permRead, requestData, func(req serde.GetPaymentChannelRequest) (query.PaymentChannelInfo, error) {
+			return processRequest(rs, PermRead, requestData, func(req serde.GetPaymentChannelRequest) (query.PaymentChannelInfo, error) {
 				if err := serde.ValidateGetPaymentChannelRequest(req); err != nil {
 					return query.PaymentChannelInfo{}, err
 				}
 				return rs.node.GetPaymentChannel(req.Id)
 			})
 		case serde.GetLedgerChannelRequestMethod:
-			return processRequest(rs, permRead, requestData, func(req serde.GetLedgerChannelRequest) (query.LedgerChannelInfo, error) {
+			return processRequest(rs, PermRead, requestData, func(req serde.GetLedgerChannelRequest) (query.LedgerChannelInfo, error) {
 				return rs.node.GetLedgerChannel(req.Id)
 			})
 		case serde.GetAllLedgerChannelsMethod:
-			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) ([]query.LedgerChannelInfo, error) {
+			return processRequest(rs, PermRead, requestData, func(req serde.NoPayloadRequest) ([]query.LedgerChannelInfo, error) {
 				return rs.node.GetAllLedgerChannels()
 			})
 		case serde.GetPaymentChannelsByLedgerMethod:
-			return processRequest(rs, permRead, requestData, func(req serde.GetPaymentChannelsByLedgerRequest) ([]query.PaymentChannelInfo, error) {
+			return processRequest(rs, PermRead, requestData, func(req serde.GetPaymentChannelsByLedgerRequest) ([]query.PaymentChannelInfo, error) {
 				if err := serde.ValidateGetPaymentChannelsByLedgerRequest(req); err != nil {
 					return []query.PaymentChannelInfo{}, err
 				}
 				return rs.node.GetPaymentChannelsByLedger(req.LedgerId)
 			})
+		case serde.GetLedgerChannelsMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.GetChannelsRequest) (query.LedgerChannelsPage, error) {
+				if err := serde.ValidateGetChannelsRequest(req); err != nil {
+					return query.LedgerChannelsPage{}, err
+				}
+				return rs.node.GetLedgerChannels(req.Filter, req.Cursor, req.Limit)
+			})
+		case serde.GetPaymentChannelsMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.GetChannelsRequest) (query.PaymentChannelsPage, error) {
+				if err := serde.ValidateGetChannelsRequest(req); err != nil {
+					return query.PaymentChannelsPage{}, err
+				}
+				return rs.node.GetPaymentChannels(req.Filter, req.Cursor, req.Limit)
+			})
+		case serde.GetBalanceHistoryMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.GetBalanceHistoryRequest) ([]query.BalanceSnapshot, error) {
+				if err := serde.ValidateGetBalanceHistoryRequest(req); err != nil {
+					return []query.BalanceSnapshot{}, err
+				}
+				if req.To.IsZero() {
+					req.To = time.Now()
+				}
+				return rs.node.GetBalanceHistory(req.Id, req.From, req.To)
+			})
+		case serde.GetAssetBalanceHistoryMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.GetAssetBalanceHistoryRequest) ([]query.AssetBalance, error) {
+				if err := serde.ValidateGetAssetBalanceHistoryRequest(req); err != nil {
+					return []query.AssetBalance{}, err
+				}
+				if req.To.IsZero() {
+					req.To = time.Now()
+				}
+				return rs.node.GetAssetBalanceHistory(req.From, req.To, time.Duration(req.IntervalSeconds)*time.Second)
+			})
+		case serde.GetPaymentHistoryMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.GetPaymentHistoryRequest) (query.PaymentHistory, error) {
+				if err := serde.ValidateGetPaymentHistoryRequest(req); err != nil {
+					return query.PaymentHistory{}, err
+				}
+				return rs.node.GetPaymentHistory(req.Id, req.Offset, req.Limit)
+			})
+		case serde.GetEventsMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.GetEventsRequest) (query.EventsPage, error) {
+				if err := serde.ValidateEventFilter(req.Filter); err != nil {
+					return query.EventsPage{}, err
+				}
+				return rs.getEvents(peer.ConnectionId, req.Filter, req.Since, req.Limit)
+			})
+		case serde.SubscribeMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.SubscribeRequest) (serde.SubscribeResponse, error) {
+				if err := serde.ValidateEventFilter(req.Filter); err != nil {
+					return serde.SubscribeResponse{}, err
+				}
+				if peer.ConnectionId == "" {
+					return serde.SubscribeResponse{}, serde.SubscriptionsUnsupportedError
+				}
+				id := rs.subscriptions.add(peer.ConnectionId, req.Filter)
+				page, err := rs.getEvents(peer.ConnectionId, req.Filter, req.Since, req.Limit)
+				if err != nil {
+					rs.subscriptions.remove(peer.ConnectionId, id)
+					return serde.SubscribeResponse{}, err
+				}
+				return serde.SubscribeResponse{SubscriptionId: id, EventsPage: page}, nil
+			})
+		case serde.UnsubscribeMethod:
+			return processRequest(rs, PermRead, requestData, func(req serde.UnsubscribeRequest) (string, error) {
+				if err := serde.ValidateUnsubscribeRequest(req); err != nil {
+					return "", err
+				}
+				if !rs.subscriptions.remove(peer.ConnectionId, req.SubscriptionId) {
+					return "", serde.SubscriptionNotFoundError
+				}
+				return req.SubscriptionId, nil
+			})
 		default:
 			errRes := serde.NewJsonRpcErrorResponse(jsonrpcReq.Id, serde.MethodNotFoundError)
 			return marshalResponse(errRes)
 		}
 	}
 
+	handlerV1 := func(peer transport.Peer, requestData []byte) []byte {
+		if !isBatch(requestData) {
+			return handleRequest(peer, requestData)
+		}
+		return processBatch(requestData, func(request []byte) []byte {
+			return handleRequest(peer, request)
+		})
+	}
+
+	rs.transport.RegisterDisconnectHandler(rs.subscriptions.removeConnection)
 	err = rs.transport.RegisterRequestHandler("v1", handlerV1)
 	return err
 }
 
-func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServer, permission permission, requestData []byte, processPayload func(T) (U, error)) []byte {
+// commitFunding commits the node's funding in the outcome of a channel the client is opening against its spending limits.
+// It returns a function to call with the result of opening the channel, which releases the funding if the channel is not being opened.
+func (rs *RpcServer) commitFunding(clientId string, ledger bool, o outcome.Exit) (func(protocols.ObjectiveId, types.Destination, error), error) {
+	if !rs.limits.hasLimits(clientId) {
+		return func(protocols.ObjectiveId, types.Destination, error) {}, nil
+	}
+	funding := fundingByAsset(o, types.AddressToDestination(*rs.node.Address))
+	if err := rs.limits.commit(clientId, ledger, funding); err != nil {
+		rs.logger.Warn("Rejected channel funding exceeding spending limit", "client", clientId, "error", err)
+		return nil, spendingLimitError(err)
+	}
+	return func(objectiveId protocols.ObjectiveId, channelId types.Destination, err error) {
+		if err != nil {
+			rs.limits.abandon(clientId, ledger, funding)
+			return
+		}
+		rs.limits.opened(clientId, ledger, funding, channelId, objectiveId)
+	}, nil
+}
+
+// spend records a payment of amount on the channel against the client's spending limits.
+// It returns a function that reverses the record, to be called if the payment fails.
+func (rs *RpcServer) spend(clientId string, channelId types.Destination, amount *big.Int) (func(), error) {
+	// A voucher for a zero amount restates the largest voucher, so it spends nothing
+	if !rs.limits.hasLimits(clientId) || amount.Sign() == 0 {
+		return func() {}, nil
+	}
+	info, err := rs.node.GetPaymentChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+	asset := info.Balance.AssetAddress
+	if err := rs.limits.spend(clientId, asset, channelId, amount); err != nil {
+		rs.logger.Warn("Rejected payment exceeding spending limit", "client", clientId, "channel", channelId, "error", err)
+		return nil, spendingLimitError(err)
+	}
+	return func() { rs.limits.refund(clientId, asset, channelId, amount) }, nil
+}
+
+// spendingLimitError converts an error from the spending limiter to a jsonrpc error
+func spendingLimitError(err error) serde.JsonRpcError {
+	jsonErr := serde.SpendingLimitExceededError
+	jsonErr.Message = fmt.Sprintf("%s: %s", jsonErr.Message, err)
+	return jsonErr
+}
+
+func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServer, permission Permission, requestData []byte, processPayload func(T) (U, error)) []byte {
+	return processRequestAs(rs, permission, requestData, func(_ context.Context, _ string, req T) (U, error) {
+		return processPayload(req)
+	})
+}
+
+// processRequestAs is like processRequest, but also passes the id of the client that made the request to processPayload,
+// along with a context carrying the span of the request. The span continues the trace of the client, if the request carries one.
+func processRequestAs[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServer, permission Permission, requestData []byte, processPayload func(context.Context, string, T) (U, error)) []byte {
 	rpcRequest := serde.JsonRpcSpecificRequest[T]{}
 	// This unmarshal will fail only when the requestData is not valid json.
 	// Request-specific params validation is optionally performed as part of the processPayload function
 	err := json.Unmarshal(requestData, &rpcRequest)
 	if err != nil {
-		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.ParamsUnmarshalError)
+		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.InvalidParamsError)
 		return marshalResponse(response)
 	}
 
-	err = checkTokenValidity(rpcRequest.Params.AuthToken, permission, 7*24*time.Hour)
+	ctx, span := tracing.Tracer().Start(tracing.Extract(context.Background(), rpcRequest.Params.TraceContext), "rpc."+rpcRequest.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.method", rpcRequest.Method)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	clientId, err := rs.auth.checkTokenValidity(rpcRequest.Params.AuthToken, permission)
+	if errors.Is(err, errMissingPermission) {
+		responseErr := serde.PermissionDeniedError
+		responseErr.Message = fmt.Sprintf("%s: requires %s", responseErr.Message, permission)
+		rs.logger.Warn(responseErr.Message)
+		return marshalResponse(serde.NewJsonRpcErrorResponse(rpcRequest.Id, responseErr))
+	}
 	if err != nil {
 		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.InvalidAuthTokenError)
 		rs.logger.Warn(serde.InvalidAuthTokenError.Message)
 		return marshalResponse(response)
 	}
 
+	span.SetAttributes(attribute.String("rpc.client", clientId))
 	payload := rpcRequest.Params.Payload
-	processedResponse, err := processPayload(payload)
+	processedResponse, err := processPayload(ctx, clientId, payload)
 	if err != nil {
-		responseErr := serde.InternalServerError // default error
-		responseErr.Message = err.Error()
-
-		if jsonErr, ok := err.(serde.JsonRpcError); ok {
-			responseErr.Code = jsonErr.Code // overwrite default if error object is jsonrpc error
-		}
-
-		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, responseErr)
+		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, toJsonRpcError(err))
 		return marshalResponse(response)
 	}
 
@@ -230,6 +478,77 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 	return marshalResponse(response)
 }
 
+// toJsonRpcError converts an error returned while processing a request to a jsonrpc error.
+// Errors the application error code table has a code for are given that code, and anything else is an internal error.
+// The message is the message of the original error, so that no detail is lost.
+func toJsonRpcError(err error) serde.JsonRpcError {
+	var jsonErr serde.JsonRpcError
+	switch {
+	case errors.As(err, &jsonErr):
+		return jsonErr
+	case errors.Is(err, store.ErrNoSuchChannel), errors.Is(err, payments.ErrChannelNotRegistered):
+		jsonErr = serde.ChannelNotFoundError
+	case errors.Is(err, payments.ErrInsufficientFunds):
+		jsonErr = serde.InsufficientFundsError
+	case errors.Is(err, directfund.ErrLedgerChannelExists):
+		jsonErr = serde.ChannelExistsError
+	default:
+		jsonErr = serde.InternalServerError
+	}
+	jsonErr.Message = err.Error()
+	return jsonErr
+}
+
+// MaxBatchSize is the largest number of requests the server processes in a single batch
+const MaxBatchSize = 100
+
+// isBatch returns true if the request data is a JSON array, which the jsonrpc spec uses for a batch of requests
+func isBatch(requestData []byte) bool {
+	trimmed := bytes.TrimLeft(requestData, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// processBatch processes each request of a batch in order, and returns an array of their responses.
+// Notifications, which are requests without an id, are processed but not responded to, so a batch of only notifications returns nothing.
+// See https://www.jsonrpc.org/specification#batch
+func processBatch(requestData []byte, handleRequest func([]byte) []byte) []byte {
+	var requests []json.RawMessage
+	if err := json.Unmarshal(requestData, &requests); err != nil {
+		return marshalResponse(serde.NewJsonRpcErrorResponse(0, serde.ParseError))
+	}
+	if len(requests) == 0 {
+		return marshalResponse(serde.NewJsonRpcErrorResponse(0, serde.InvalidRequestError))
+	}
+	if len(requests) > MaxBatchSize {
+		errRes := serde.InvalidRequestError
+		errRes.Message = fmt.Sprintf("%s: a batch can contain at most %d requests", errRes.Message, MaxBatchSize)
+		return marshalResponse(serde.NewJsonRpcErrorResponse(0, errRes))
+	}
+
+	responses := make([]json.RawMessage, 0, len(requests))
+	for _, request := range requests {
+		response := handleRequest(request)
+		if isNotification(request) {
+			continue
+		}
+		responses = append(responses, response)
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return marshalResponse(responses)
+}
+
+// isNotification returns true if the request is an object without an id, which the jsonrpc spec uses for a notification
+func isNotification(request json.RawMessage) bool {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(request, &members); err != nil {
+		return false
+	}
+	_, hasId := members["id"]
+	return !hasId
+}
+
 // Marshal and return response data
 func marshalResponse(response any) []byte {
 	responseData, err := json.Marshal(response)
@@ -244,20 +563,22 @@ func validateJsonrpcRequest(requestData []byte) (serde.JsonRpcGeneralRequest, []
 	vr := serde.JsonRpcGeneralRequest{}
 	err := json.Unmarshal(requestData, &request)
 	if err != nil {
-		errRes := serde.NewJsonRpcErrorResponse(0, serde.RequestUnmarshalError)
+		errRes := serde.NewJsonRpcErrorResponse(0, serde.InvalidRequestError)
 		return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
 	}
 
 	// jsonrpc spec says id can be a string, number.
 	// We only support numbers: https://github.com/statechannels/go-nitro/issues/1160
 	// When golang unmarshals JSON into an interface value, float64 is used for numbers.
-	requestId := request["id"]
-	fRequestId, ok := requestId.(float64)
-	if !ok || fRequestId != float64(uint64(fRequestId)) {
-		errRes := serde.NewJsonRpcErrorResponse(0, serde.InvalidRequestError)
-		return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
+	// A notification has no id, and is processed like a request with id 0.
+	if requestId, hasId := request["id"]; hasId {
+		fRequestId, ok := requestId.(float64)
+		if !ok || fRequestId != float64(uint64(fRequestId)) {
+			errRes := serde.NewJsonRpcErrorResponse(0, serde.InvalidRequestError)
+			return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
+		}
+		vr.Id = uint64(fRequestId)
 	}
-	vr.Id = uint64(fRequestId)
 
 	sJsonrpc, ok := request["jsonrpc"].(string)
 	if !ok || sJsonrpc != "2.0" {
@@ -280,6 +601,7 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 	completedObjChan <-chan protocols.ObjectiveId,
 	ledgerUpdatesChan <-chan query.LedgerChannelInfo,
 	paymentUpdatesChan <-chan query.PaymentChannelInfo,
+	receivedVouchersChan <-chan payments.ReceivedVoucher,
 ) {
 	defer rs.wg.Done()
 	for {
@@ -314,6 +636,15 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 			if err != nil {
 				panic(err)
 			}
+		case voucher, ok := <-receivedVouchersChan:
+			if !ok {
+				rs.logger.Warn("ReceivedVouchers channel closed, exiting sendNotifications")
+				return
+			}
+			err := sendNotification(rs, serde.VoucherReceived, voucher)
+			if err != nil {
+				panic(err)
+			}
 		}
 	}
 }
@@ -322,9 +653,30 @@ func sendNotification[T serde.NotificationMethod, U serde.NotificationPayload](r
 	rs.logger.Debug("Sending notification", "method", method, "payload", payload)
 
 	request := serde.NewJsonRpcSpecificRequest(rand.Uint64(), method, payload, "")
+
+	// The notification is recorded in the node's event log, so that clients which miss it can replay it.
+	// Failing to record it should not stop it being sent to the clients that are connected.
+	payloadData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	event, err := rs.node.RecordEvent(string(method), payloadData)
+	if err != nil {
+		rs.logger.Error("Could not record notification", "method", method, "error", err)
+	} else {
+		request.Params.Seq = event.Seq
+	}
+
 	data, err := json.Marshal(request)
 	if err != nil {
 		return err
 	}
-	return rs.transport.Notify(data)
+
+	fields, err := newEventFields(serde.NotificationMethod(method), payloadData)
+	if err != nil {
+		return err
+	}
+	return rs.transport.Notify(data, func(connectionId string) bool {
+		return rs.subscriptions.accepts(connectionId, fields)
+	})
 }
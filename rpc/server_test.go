@@ -2,16 +2,26 @@ package rpc
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 
 	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/statechannels/go-nitro/types"
 	"github.com/stretchr/testify/assert"
 )
 
+// testAuthConfig uses a fixed signing secret, so that tokens issued by one test server are accepted by another.
+// It is insecure, so that the tests can request tokens without registering clients.
+var testAuthConfig = AuthConfig{SigningSecret: []byte("test-secret"), Insecure: true}
+
 type mockResponder struct {
-	Handler func([]byte) []byte
+	Handler transport.RequestHandler
 }
 
 func (*mockResponder) Close() error {
@@ -22,27 +32,29 @@ func (*mockResponder) Url() string {
 	return ""
 }
 
-func (m *mockResponder) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
+func (m *mockResponder) RegisterRequestHandler(apiVersion string, handler transport.RequestHandler) error {
 	m.Handler = handler
 	return nil
 }
 
-func (*mockResponder) Notify([]byte) error {
+func (*mockResponder) Notify([]byte, func(string) bool) error {
 	return nil
 }
 
+func (*mockResponder) RegisterDisconnectHandler(func(string)) {}
+
 func sendRequestAndExpectError(t *testing.T, request []byte, expectedError serde.JsonRpcError) {
 	mockNode := &nitro.Node{}
 
 	mockResponder := &mockResponder{}
 	// Since we're using an empty node we want to disable notifications
 	// otherwise the server will try to send notifications to the node and fail
-	_, err := newRpcServerWithoutNotifications(mockNode, mockResponder)
+	_, err := newRpcServerWithoutNotifications(mockNode, mockResponder, testAuthConfig)
 	if err != nil {
 		t.Error(err)
 	}
 
-	response := mockResponder.Handler(request)
+	response := mockResponder.Handler(transport.Peer{}, request)
 
 	jsonResponse := serde.JsonRpcErrorResponse{}
 	err = json.Unmarshal(response, &jsonResponse)
@@ -63,12 +75,12 @@ func getAuthToken(t *testing.T) string {
 	mockResponder := &mockResponder{}
 	// Since we're using an empty node we want to disable notifications
 	// otherwise the server will try to send notifications to the node and fail
-	_, err = newRpcServerWithoutNotifications(mockNode, mockResponder)
+	_, err = newRpcServerWithoutNotifications(mockNode, mockResponder, testAuthConfig)
 	if err != nil {
 		t.Error(err)
 	}
 
-	response := mockResponder.Handler(jsonRequest)
+	response := mockResponder.Handler(transport.Peer{}, jsonRequest)
 
 	jsonResponse := serde.JsonRpcSuccessResponse[string]{}
 	err = json.Unmarshal(response, &jsonResponse)
@@ -179,3 +191,197 @@ func TestRpcPayInvalidParam(t *testing.T) {
 	expectedError := serde.InvalidParamsError
 	sendRequestAndExpectError(t, jsonRequest, expectedError)
 }
+
+func TestRpcSubscribeWithoutConnection(t *testing.T) {
+	authToken := getAuthToken(t)
+	request := serde.JsonRpcSpecificRequest[serde.SubscribeRequest]{
+		Jsonrpc: "2.0", Id: 2, Method: "subscribe", Params: serde.Params[serde.SubscribeRequest]{AuthToken: authToken},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Error(err)
+	}
+	// The mock responder does not identify the connection a request is sent over
+	sendRequestAndExpectError(t, jsonRequest, serde.SubscriptionsUnsupportedError)
+}
+
+func TestRpcSubscribeInvalidFilter(t *testing.T) {
+	authToken := getAuthToken(t)
+	subscribeRequest := serde.SubscribeRequest{Filter: serde.EventFilter{Methods: []serde.NotificationMethod{"unknown_method"}}}
+	request := serde.JsonRpcSpecificRequest[serde.SubscribeRequest]{
+		Jsonrpc: "2.0", Id: 2, Method: "subscribe", Params: serde.Params[serde.SubscribeRequest]{AuthToken: authToken, Payload: subscribeRequest},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Error(err)
+	}
+	sendRequestAndExpectError(t, jsonRequest, serde.InvalidParamsError)
+}
+
+func TestRpcUnsubscribeUnknownSubscription(t *testing.T) {
+	authToken := getAuthToken(t)
+	request := serde.JsonRpcSpecificRequest[serde.UnsubscribeRequest]{
+		Jsonrpc: "2.0", Id: 2, Method: "unsubscribe", Params: serde.Params[serde.UnsubscribeRequest]{AuthToken: authToken, Payload: serde.UnsubscribeRequest{SubscriptionId: "1"}},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Error(err)
+	}
+	sendRequestAndExpectError(t, jsonRequest, serde.SubscriptionNotFoundError)
+}
+
+func TestRpcGetAuthTokenInvalidCredentials(t *testing.T) {
+	request := serde.JsonRpcSpecificRequest[serde.AuthRequest]{
+		Jsonrpc: "2.0", Id: 1, Method: "get_auth_token", Params: serde.Params[serde.AuthRequest]{Payload: serde.AuthRequest{Id: "client", ApiKey: "wrong-key"}},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Error(err)
+	}
+
+	mockResponder := &mockResponder{}
+	authConfig := AuthConfig{Clients: []ClientCredentials{{Id: "client", ApiKey: "key", Permissions: AllPermissions}}}
+	_, err = newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := mockResponder.Handler(transport.Peer{}, jsonRequest)
+
+	jsonResponse := serde.JsonRpcErrorResponse{}
+	err = json.Unmarshal(response, &jsonResponse)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, serde.InvalidCredentialsError, jsonResponse.Error)
+}
+
+func TestRpcBatch(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, testAuthConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := []byte(`[
+		{"jsonrpc": "2.0", "id": 1, "method": "get_auth_token", "params": {"authtoken": "", "payload": {}}},
+		{"jsonrpc": "2.0", "id": 2, "method": "not_a_method"},
+		{"jsonrpc": "2.0", "method": "get_auth_token", "params": {"authtoken": "", "payload": {}}},
+		{"jsonrpc": "2.0", "id": 3},
+		5
+	]`)
+	response := mockResponder.Handler(transport.Peer{}, request)
+
+	responses := []serde.JsonRpcGeneralResponse{}
+	if err := json.Unmarshal(response, &responses); err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 4 {
+		t.Fatalf("expected a response for each of the 4 requests that are not notifications, got %d", len(responses))
+	}
+	assert.Equal(t, uint64(1), responses[0].Id)
+	assert.Equal(t, serde.JsonRpcError{}, responses[0].Error)
+	assert.NotEmpty(t, responses[0].Result)
+	assert.Equal(t, uint64(2), responses[1].Id)
+	assert.Equal(t, serde.MethodNotFoundError, responses[1].Error)
+	assert.Equal(t, uint64(3), responses[2].Id)
+	assert.Equal(t, serde.InvalidRequestError, responses[2].Error)
+	assert.Equal(t, serde.InvalidRequestError, responses[3].Error)
+}
+
+func TestRpcBatchOfNotifications(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, testAuthConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := []byte(`[
+		{"jsonrpc": "2.0", "method": "get_auth_token", "params": {"authtoken": "", "payload": {}}},
+		{"jsonrpc": "2.0", "method": "not_a_method"}
+	]`)
+	assert.Empty(t, mockResponder.Handler(transport.Peer{}, request))
+}
+
+func TestRpcBatchedNotificationIsProcessed(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, testAuthConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authToken := getAuthToken(t)
+
+	// The notification revokes the token, which is only observable through the requests that follow it
+	request := []byte(fmt.Sprintf(`[
+		{"jsonrpc": "2.0", "method": "revoke_auth_token", "params": {"authtoken": %[1]q, "payload": {"Token": %[1]q}}}
+	]`, authToken))
+	assert.Empty(t, mockResponder.Handler(transport.Peer{}, request))
+
+	request = []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "revoke_auth_token", "params": {"authtoken": %[1]q, "payload": {"Token": %[1]q}}}`, authToken))
+	jsonResponse := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(mockResponder.Handler(transport.Peer{}, request), &jsonResponse); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.InvalidAuthTokenError, jsonResponse.Error)
+}
+
+func TestRpcEmptyBatch(t *testing.T) {
+	sendRequestAndExpectError(t, []byte(" []"), serde.InvalidRequestError)
+}
+
+func TestRpcBatchParseError(t *testing.T) {
+	sendRequestAndExpectError(t, []byte(`[{"jsonrpc": "2.0", "id": 1`), serde.ParseError)
+}
+
+func TestRpcPermissionDenied(t *testing.T) {
+	mockResponder := &mockResponder{}
+	authConfig := AuthConfig{Clients: []ClientCredentials{{Id: "reader", ApiKey: "key", Permissions: []Permission{PermRead}}}}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authRequest, err := json.Marshal(serde.JsonRpcSpecificRequest[serde.AuthRequest]{
+		Jsonrpc: "2.0", Id: 1, Method: "get_auth_token", Params: serde.Params[serde.AuthRequest]{Payload: serde.AuthRequest{Id: "reader", ApiKey: "key"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authResponse := serde.JsonRpcSuccessResponse[string]{}
+	if err := json.Unmarshal(mockResponder.Handler(transport.Peer{}, authRequest), &authResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	payRequest, err := json.Marshal(serde.JsonRpcSpecificRequest[serde.PaymentRequest]{
+		Jsonrpc: "2.0", Id: 2, Method: string(serde.PayRequestMethod), Params: serde.Params[serde.PaymentRequest]{AuthToken: authResponse.Result},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonResponse := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(mockResponder.Handler(transport.Peer{}, payRequest), &jsonResponse); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.PermissionDeniedError.Code, jsonResponse.Error.Code)
+	assert.Contains(t, jsonResponse.Error.Message, string(PermPay))
+}
+
+func TestToJsonRpcError(t *testing.T) {
+	testCases := []struct {
+		err          error
+		expectedCode int64
+	}{
+		{fmt.Errorf("could not find channel: %w", store.ErrNoSuchChannel), serde.ChannelNotFoundError.Code},
+		{fmt.Errorf("%w: %w", payments.ErrChannelNotRegistered, errors.New("not in store")), serde.ChannelNotFoundError.Code},
+		{fmt.Errorf("unable to pay amount: %w", payments.ErrInsufficientFunds), serde.InsufficientFundsError.Code},
+		{fmt.Errorf("counterparty 0xabc: %w", directfund.ErrLedgerChannelExists), serde.ChannelExistsError.Code},
+		{serde.InvalidParamsError, serde.InvalidParamsError.Code},
+		{errors.New("something went wrong"), serde.InternalServerError.Code},
+	}
+
+	for _, tc := range testCases {
+		jsonErr := toJsonRpcError(tc.err)
+		assert.Equal(t, tc.expectedCode, jsonErr.Code, tc.err.Error())
+		assert.Equal(t, tc.err.Error(), jsonErr.Message)
+	}
+}
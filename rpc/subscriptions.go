@@ -0,0 +1,188 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// subscriptions holds the notification subscriptions of each connection.
+// A connection that has never subscribed is sent every notification. Once it subscribes, it is only sent the notifications matching one of its subscriptions.
+type subscriptions struct {
+	mu sync.RWMutex
+	// byConnection holds the filters of the subscriptions of each connection that has subscribed, by subscription id
+	byConnection map[string]map[string]serde.EventFilter
+	// lastId is the id of the last subscription. Subscriptions are owned by their connection, so their ids need not be hard to guess.
+	lastId uint64
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{byConnection: make(map[string]map[string]serde.EventFilter)}
+}
+
+// add subscribes the connection to the notifications matching the filter, and returns the id of the subscription
+func (s *subscriptions) add(connectionId string, filter serde.EventFilter) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastId++
+	id := strconv.FormatUint(s.lastId, 10)
+	if s.byConnection[connectionId] == nil {
+		s.byConnection[connectionId] = make(map[string]serde.EventFilter)
+	}
+	s.byConnection[connectionId][id] = filter
+	return id
+}
+
+// remove ends a subscription of the connection. It returns false if the connection has no such subscription.
+// The connection is still only sent the notifications matching its remaining subscriptions, which may be none.
+func (s *subscriptions) remove(connectionId, subscriptionId string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filters, ok := s.byConnection[connectionId]
+	if !ok {
+		return false
+	}
+	if _, ok := filters[subscriptionId]; !ok {
+		return false
+	}
+	delete(filters, subscriptionId)
+	return true
+}
+
+// removeConnection forgets the subscriptions of a connection that has closed
+func (s *subscriptions) removeConnection(connectionId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.byConnection, connectionId)
+}
+
+// accepts returns true if the notification should be sent over the connection
+func (s *subscriptions) accepts(connectionId string, event eventFields) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filters, ok := s.byConnection[connectionId]
+	if !ok {
+		return true
+	}
+	for _, filter := range filters {
+		if event.matches(filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventFields holds the fields of a notification that filters select on
+type eventFields struct {
+	method         serde.NotificationMethod
+	channelId      types.Destination
+	counterparties []types.Address
+	objectiveId    protocols.ObjectiveId
+}
+
+// newEventFields decodes the fields of a notification from its JSON payload
+func newEventFields(method serde.NotificationMethod, payload json.RawMessage) (eventFields, error) {
+	event := eventFields{method: method}
+	switch method {
+	case serde.ObjectiveCompleted:
+		if err := json.Unmarshal(payload, &event.objectiveId); err != nil {
+			return eventFields{}, err
+		}
+	case serde.LedgerChannelUpdated:
+		var info query.LedgerChannelInfo
+		if err := json.Unmarshal(payload, &info); err != nil {
+			return eventFields{}, err
+		}
+		event.channelId = info.ID
+		event.counterparties = []types.Address{info.Balance.Them}
+	case serde.PaymentChannelUpdated:
+		var info query.PaymentChannelInfo
+		if err := json.Unmarshal(payload, &info); err != nil {
+			return eventFields{}, err
+		}
+		event.channelId = info.ID
+		event.counterparties = []types.Address{info.Balance.Payer, info.Balance.Payee}
+	case serde.VoucherReceived:
+		var v payments.ReceivedVoucher
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return eventFields{}, err
+		}
+		event.channelId = v.ChannelId
+		event.counterparties = []types.Address{v.Payer}
+	default:
+		return eventFields{}, fmt.Errorf("unknown notification method %s", method)
+	}
+	return event, nil
+}
+
+// matches returns true if the notification matches every non-empty field of the filter
+func (e eventFields) matches(filter serde.EventFilter) bool {
+	if len(filter.Methods) > 0 && !slices.Contains(filter.Methods, e.method) {
+		return false
+	}
+	isChannelUpdate := e.method == serde.LedgerChannelUpdated || e.method == serde.PaymentChannelUpdated || e.method == serde.VoucherReceived
+	if len(filter.ChannelIds) > 0 && (!isChannelUpdate || !slices.Contains(filter.ChannelIds, e.channelId)) {
+		return false
+	}
+	if len(filter.Counterparties) > 0 && (!isChannelUpdate || !slices.ContainsFunc(e.counterparties, func(a types.Address) bool {
+		return slices.Contains(filter.Counterparties, a)
+	})) {
+		return false
+	}
+	if len(filter.ObjectiveIds) > 0 && (e.method != serde.ObjectiveCompleted || !slices.Contains(filter.ObjectiveIds, e.objectiveId)) {
+		return false
+	}
+	return true
+}
+
+// getEvents returns a page of the events sent after since that match the filter and, if the connection has subscribed, one of its subscriptions.
+// Pages of the event log are read until the page is full or the log is exhausted.
+func (rs *RpcServer) getEvents(connectionId string, filter serde.EventFilter, since, limit uint64) (query.EventsPage, error) {
+	if limit == 0 || limit > query.MaxEventsPageSize {
+		limit = query.MaxEventsPageSize
+	}
+
+	result := query.EventsPage{Events: []query.Event{}}
+	next := since
+	for {
+		page, err := rs.node.GetEvents(next, limit)
+		if err != nil {
+			return query.EventsPage{}, err
+		}
+		if next == since {
+			result.Missed = page.Missed
+		}
+		result.LastSeq = page.LastSeq
+
+		for _, e := range page.Events {
+			if uint64(len(result.Events)) == limit {
+				result.HasMore = true
+				return result, nil
+			}
+			next = e.Seq
+
+			event, err := newEventFields(serde.NotificationMethod(e.Method), e.Payload)
+			if err != nil {
+				rs.logger.Error("Could not decode recorded notification", "seq", e.Seq, "error", err)
+				continue
+			}
+			if event.matches(filter) && rs.subscriptions.accepts(connectionId, event) {
+				result.Events = append(result.Events, e)
+			}
+		}
+		if !page.HasMore {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilterMatches(t *testing.T) {
+	ledgerId, paymentId := types.Destination{1}, types.Destination{2}
+	irene, bob := types.Address{3}, types.Address{4}
+	objectiveId := protocols.ObjectiveId("DirectFunding-0x01")
+
+	fields := func(method serde.NotificationMethod, payload any) eventFields {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event, err := newEventFields(method, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	ledgerUpdate := fields(serde.LedgerChannelUpdated, query.LedgerChannelInfo{ID: ledgerId, Balance: query.LedgerChannelBalance{Them: irene}})
+	paymentUpdate := fields(serde.PaymentChannelUpdated, query.PaymentChannelInfo{ID: paymentId, Balance: query.PaymentChannelBalance{Payee: bob}})
+	completed := fields(serde.ObjectiveCompleted, objectiveId)
+	voucher := fields(serde.VoucherReceived, payments.ReceivedVoucher{ChannelId: paymentId, Payer: irene, Delta: big.NewInt(1), Total: big.NewInt(2)})
+
+	testCases := []struct {
+		name   string
+		filter serde.EventFilter
+		// matches holds whether the ledger update, payment update, completed objective and received voucher match the filter
+		matches [4]bool
+	}{
+		{"empty filter", serde.EventFilter{}, [4]bool{true, true, true, true}},
+		{"method", serde.EventFilter{Methods: []serde.NotificationMethod{serde.PaymentChannelUpdated, serde.ObjectiveCompleted}}, [4]bool{false, true, true, false}},
+		{"channel id", serde.EventFilter{ChannelIds: []types.Destination{ledgerId}}, [4]bool{true, false, false, false}},
+		{"counterparty", serde.EventFilter{Counterparties: []types.Address{irene, bob}}, [4]bool{true, true, false, true}},
+		{"voucher channel id", serde.EventFilter{ChannelIds: []types.Destination{paymentId}}, [4]bool{false, true, false, true}},
+		{"objective id", serde.EventFilter{ObjectiveIds: []protocols.ObjectiveId{objectiveId}}, [4]bool{false, false, true, false}},
+		{"every field must match", serde.EventFilter{ChannelIds: []types.Destination{ledgerId}, Counterparties: []types.Address{bob}}, [4]bool{false, false, false, false}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.matches, [4]bool{ledgerUpdate.matches(tc.filter), paymentUpdate.matches(tc.filter), completed.matches(tc.filter), voucher.matches(tc.filter)})
+		})
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	s := newSubscriptions()
+	ledgerUpdate := eventFields{method: serde.LedgerChannelUpdated, channelId: types.Destination{1}}
+	completed := eventFields{method: serde.ObjectiveCompleted, objectiveId: "DirectFunding-0x01"}
+
+	// Connections that have not subscribed are sent every notification
+	assert.True(t, s.accepts("a", ledgerUpdate))
+	assert.True(t, s.accepts("a", completed))
+
+	id := s.add("a", serde.EventFilter{Methods: []serde.NotificationMethod{serde.ObjectiveCompleted}})
+	assert.False(t, s.accepts("a", ledgerUpdate))
+	assert.True(t, s.accepts("a", completed))
+	assert.True(t, s.accepts("b", ledgerUpdate), "other connections are unaffected")
+
+	// Subscriptions are owned by their connection
+	assert.False(t, s.remove("b", id))
+	assert.True(t, s.remove("a", id))
+	assert.False(t, s.remove("a", id))
+	assert.False(t, s.accepts("a", completed), "a connection with no remaining subscriptions is sent nothing")
+
+	s.removeConnection("a")
+	assert.True(t, s.accepts("a", completed))
+}
@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport/grpc/nitropb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// connectTimeout is how long NewGrpcTransportAsClient waits for the server to accept the notification subscription
+const connectTimeout = 10 * time.Second
+
+type clientGrpcTransport struct {
+	logger           *slog.Logger
+	conn             *grpc.ClientConn
+	client           nitropb.NitroRpcClient
+	notificationChan chan []byte
+	// connectionId identifies the Subscribe stream, and is sent with each request
+	connectionId string
+	cancel       context.CancelFunc
+	wg           *sync.WaitGroup
+}
+
+// NewGrpcTransportAsClient creates a transport that sends requests to a gRPC server, and subscribes to its notifications.
+// The target is the host:port of the server. A nil TLS config connects without TLS.
+// Initialization blocks until the server is ready, or connectTimeout has passed.
+func NewGrpcTransportAsClient(target string, tlsConfig *tls.Config) (*clientGrpcTransport, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &clientGrpcTransport{
+		logger:           slog.Default(),
+		conn:             conn,
+		client:           nitropb.NewNitroRpcClient(conn),
+		notificationChan: make(chan []byte, 10),
+		wg:               &sync.WaitGroup{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	timeout := time.AfterFunc(connectTimeout, cancel)
+
+	stream, err := t.client.Subscribe(ctx, &nitropb.SubscribeRequest{}, grpc.WaitForReady(true))
+	if err == nil {
+		// The server sends the header, carrying the id of the stream, once the subscription is registered
+		var header metadata.MD
+		header, err = stream.Header()
+		if ids := header.Get(connectionIdKey); len(ids) > 0 {
+			t.connectionId = ids[0]
+		}
+	}
+	if !timeout.Stop() || err != nil {
+		cancel()
+		conn.Close()
+		return nil, fmt.Errorf("grpc server %s not ready: %w", target, err)
+	}
+
+	t.wg.Add(1)
+	go t.readNotifications(ctx, stream)
+
+	return t, nil
+}
+
+func (t *clientGrpcTransport) Request(data []byte) ([]byte, error) {
+	request := serde.JsonRpcGeneralRequest{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if t.connectionId != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, connectionIdKey, t.connectionId)
+	}
+
+	switch serde.RequestMethod(request.Method) {
+	case serde.GetAuthTokenMethod:
+		return forward(ctx, data, authRequestToProto, t.client.GetAuthToken, authTokenFromProto)
+	case serde.RevokeAuthTokenMethod:
+		return forward(ctx, data, revokeAuthTokenRequestToProto, t.client.RevokeAuthToken, revokeAuthTokenResponseFromProto)
+	case serde.RevokeClientTokensMethod:
+		return forward(ctx, data, revokeClientTokensRequestToProto, t.client.RevokeClientTokens, revokeClientTokensResponseFromProto)
+	case serde.GetAddressMethod:
+		return forward(ctx, data, getAddressRequestToProto, t.client.GetAddress, getAddressResponseFromProto)
+	case serde.VersionMethod:
+		return forward(ctx, data, versionRequestToProto, t.client.Version, versionResponseFromProto)
+	case serde.DiscoverMethod:
+		return forward(ctx, data, discoverRequestToProto, t.client.Discover, discoverResponseFromProto)
+	case serde.CreateLedgerChannelRequestMethod:
+		return forward(ctx, data, createLedgerChannelRequestToProto, t.client.CreateLedgerChannel, ledgerObjectiveResponseFromProto)
+	case serde.CloseLedgerChannelRequestMethod:
+		return forward(ctx, data, closeLedgerChannelRequestToProto, t.client.CloseLedgerChannel, closeChannelResponseFromProto)
+	case serde.CreatePaymentChannelRequestMethod:
+		return forward(ctx, data, createPaymentChannelRequestToProto, t.client.CreatePaymentChannel, paymentObjectiveResponseFromProto)
+	case serde.ClosePaymentChannelRequestMethod:
+		return forward(ctx, data, closePaymentChannelRequestToProto, t.client.ClosePaymentChannel, closeChannelResponseFromProto)
+	case serde.PayRequestMethod:
+		return forward(ctx, data, paymentRequestToProto, t.client.Pay, paymentRequestFromProto)
+	case serde.CreateVoucherRequestMethod:
+		return forward(ctx, data, paymentRequestToProto, t.client.CreateVoucher, voucherFromProto)
+	case serde.ReceiveVoucherRequestMethod:
+		return forward(ctx, data, voucherToProto, t.client.ReceiveVoucher, receiveVoucherSummaryFromProto)
+	case serde.GetPaymentChannelRequestMethod:
+		return forward(ctx, data, getPaymentChannelRequestToProto, t.client.GetPaymentChannel, paymentChannelInfoFromProto)
+	case serde.GetLedgerChannelRequestMethod:
+		return forward(ctx, data, getLedgerChannelRequestToProto, t.client.GetLedgerChannel, ledgerChannelInfoFromProto)
+	case serde.GetAllLedgerChannelsMethod:
+		return forward(ctx, data, getAllLedgerChannelsRequestToProto, t.client.GetAllLedgerChannels, ledgerChannelListFromProto)
+	case serde.GetPaymentChannelsByLedgerMethod:
+		return forward(ctx, data, getPaymentChannelsByLedgerRequestToProto, t.client.GetPaymentChannelsByLedger, paymentChannelListFromProto)
+	case serde.GetLedgerChannelsMethod:
+		return forward(ctx, data, getChannelsRequestToProto, t.client.GetLedgerChannels, ledgerChannelsPageFromProto)
+	case serde.GetPaymentChannelsMethod:
+		return forward(ctx, data, getChannelsRequestToProto, t.client.GetPaymentChannels, paymentChannelsPageFromProto)
+	case serde.GetPaymentHistoryMethod:
+		return forward(ctx, data, getPaymentHistoryRequestToProto, t.client.GetPaymentHistory, paymentHistoryFromProto)
+	case serde.GetBalanceHistoryMethod:
+		return forward(ctx, data, getBalanceHistoryRequestToProto, t.client.GetBalanceHistory, balanceSnapshotListFromProto)
+	case serde.GetAssetBalanceHistoryMethod:
+		return forward(ctx, data, getAssetBalanceHistoryRequestToProto, t.client.GetAssetBalanceHistory, assetBalanceListFromProto)
+	case serde.GetEventsMethod:
+		return forward(ctx, data, getEventsRequestToProto, t.client.GetEvents, eventsPageFromProto)
+	case serde.SubscribeMethod:
+		return forward(ctx, data, subscribeRequestToProto, t.client.AddSubscription, subscribeResponseFromProto)
+	case serde.UnsubscribeMethod:
+		return forward(ctx, data, unsubscribeRequestToProto, t.client.RemoveSubscription, unsubscribeResponseFromProto)
+	default:
+		return json.Marshal(serde.NewJsonRpcErrorResponse(request.Id, serde.MethodNotFoundError))
+	}
+}
+
+// forward converts a JSON-RPC request to a gRPC request, calls the server, and converts the gRPC response back to a JSON-RPC response.
+// Errors returned by the rpc server are converted to JSON-RPC error responses, while connection errors are returned as errors.
+func forward[T serde.RequestPayload, U serde.ResponsePayload, P any, R any](ctx context.Context, data []byte, toProto func(T) P, call func(context.Context, P, ...grpc.CallOption) (R, error), fromProto func(R) (U, error)) ([]byte, error) {
+	request := serde.JsonRpcSpecificRequest[T]{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return json.Marshal(serde.NewJsonRpcErrorResponse(request.Id, serde.InvalidParamsError))
+	}
+
+	if request.Params.AuthToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, authorizationKey, bearerPrefix+request.Params.AuthToken)
+	}
+	// The trace context is sent as metadata, under the same keys as the trace context headers of other transports
+	for key, value := range request.Params.TraceContext {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+
+	res, err := call(ctx, toProto(request.Params.Payload))
+	if err != nil {
+		if jsonErr, ok := errorFromStatus(err); ok {
+			return json.Marshal(serde.NewJsonRpcErrorResponse(request.Id, jsonErr))
+		}
+		return nil, err
+	}
+
+	result, err := fromProto(res)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(serde.NewJsonRpcResponse(request.Id, result))
+}
+
+func (t *clientGrpcTransport) Subscribe() (<-chan []byte, error) {
+	return t.notificationChan, nil
+}
+
+func (t *clientGrpcTransport) Close() error {
+	// Cancelling the subscription causes the readNotifications go-routine to exit
+	t.cancel()
+	t.wg.Wait()
+
+	close(t.notificationChan)
+	return t.conn.Close()
+}
+
+func (t *clientGrpcTransport) readNotifications(ctx context.Context, stream nitropb.NitroRpc_SubscribeClient) {
+	defer t.wg.Done()
+
+	t.logger.Debug("Starting to read gRPC notifications")
+	for {
+		notification, err := stream.Recv()
+		if err != nil {
+			t.logger.Info("gRPC notification stream ended", "error", err)
+			return
+		}
+
+		data, err := notificationFromProto(notification)
+		if err != nil {
+			t.logger.Error("Could not convert gRPC notification", "error", err)
+			continue
+		}
+		select {
+		case t.notificationChan <- data:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,982 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/openrpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport/grpc/nitropb"
+	"github.com/statechannels/go-nitro/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// This file converts between the protobuf messages of the gRPC api and the payloads of the JSON-RPC api.
+// Functions named xToProto convert a payload to a message, and functions named xFromProto convert a message to a payload.
+
+// parser converts the fields of a protobuf message, keeping the first error it encounters
+type parser struct {
+	err error
+}
+
+func (p *parser) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// address parses a hex encoded address. An empty string is the zero address.
+func (p *parser) address(s string) types.Address {
+	if s == "" {
+		return types.Address{}
+	}
+	if !common.IsHexAddress(s) {
+		p.fail("invalid address %q", s)
+		return types.Address{}
+	}
+	return common.HexToAddress(s)
+}
+
+func (p *parser) addresses(s []string) []types.Address {
+	addresses := make([]types.Address, len(s))
+	for i := range s {
+		addresses[i] = p.address(s[i])
+	}
+	return addresses
+}
+
+// destination parses a hex encoded 32 byte destination. An empty string is the zero destination.
+func (p *parser) destination(s string) types.Destination {
+	if s == "" {
+		return types.Destination{}
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		p.fail("invalid destination %q", s)
+		return types.Destination{}
+	}
+	return types.Destination(common.BytesToHash(b))
+}
+
+// amount parses a decimal amount. An empty string is a nil amount.
+func (p *parser) amount(s string) *big.Int {
+	if s == "" {
+		return nil
+	}
+	a, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		p.fail("invalid amount %q", s)
+		return nil
+	}
+	return a
+}
+
+func (p *parser) big(s string) *hexutil.Big {
+	return (*hexutil.Big)(p.amount(s))
+}
+
+// signature parses a hex encoded 65 byte signature. An empty string is the empty signature.
+func (p *parser) signature(s string) state.Signature {
+	if s == "" {
+		return state.Signature{}
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != 65 {
+		p.fail("invalid signature %q", s)
+		return state.Signature{}
+	}
+	return crypto.SplitSignature(b)
+}
+
+func amountToProto(a *big.Int) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
+
+func bigToProto(b *hexutil.Big) string {
+	return amountToProto(b.ToInt())
+}
+
+func signatureToProto(s state.Signature) string {
+	if len(s.R) == 0 {
+		return ""
+	}
+	return s.ToHexString()
+}
+
+func timeToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeFromProto(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
+
+var channelStatuses = map[query.ChannelStatus]nitropb.ChannelStatus{
+	query.Proposed: nitropb.ChannelStatus_CHANNEL_STATUS_PROPOSED,
+	query.Open:     nitropb.ChannelStatus_CHANNEL_STATUS_OPEN,
+	query.Closing:  nitropb.ChannelStatus_CHANNEL_STATUS_CLOSING,
+	query.Complete: nitropb.ChannelStatus_CHANNEL_STATUS_COMPLETE,
+}
+
+func statusToProto(s query.ChannelStatus) nitropb.ChannelStatus {
+	return channelStatuses[s]
+}
+
+func statusFromProto(s nitropb.ChannelStatus) query.ChannelStatus {
+	for status, pb := range channelStatuses {
+		if pb == s {
+			return status
+		}
+	}
+	return ""
+}
+
+var paymentDirections = map[payments.PaymentDirection]nitropb.PaymentDirection{
+	payments.Sent:     nitropb.PaymentDirection_PAYMENT_DIRECTION_SENT,
+	payments.Received: nitropb.PaymentDirection_PAYMENT_DIRECTION_RECEIVED,
+}
+
+func directionFromProto(d nitropb.PaymentDirection) payments.PaymentDirection {
+	for direction, pb := range paymentDirections {
+		if pb == d {
+			return direction
+		}
+	}
+	return ""
+}
+
+var channelKinds = map[store.ChannelKind]nitropb.ChannelKind{
+	store.LedgerChannel:  nitropb.ChannelKind_CHANNEL_KIND_LEDGER,
+	store.PaymentChannel: nitropb.ChannelKind_CHANNEL_KIND_PAYMENT,
+}
+
+func kindFromProto(k nitropb.ChannelKind) string {
+	for kind, pb := range channelKinds {
+		if pb == k {
+			return string(kind)
+		}
+	}
+	return ""
+}
+
+// Auth
+
+func authRequestToProto(r serde.AuthRequest) *nitropb.AuthRequest {
+	return &nitropb.AuthRequest{Id: r.Id, ApiKey: r.ApiKey}
+}
+
+func authRequestFromProto(r *nitropb.AuthRequest) (serde.AuthRequest, error) {
+	return serde.AuthRequest{Id: r.Id, ApiKey: r.ApiKey}, nil
+}
+
+func authTokenToProto(token string) *nitropb.AuthToken {
+	return &nitropb.AuthToken{Token: token}
+}
+
+func authTokenFromProto(r *nitropb.AuthToken) (string, error) {
+	return r.Token, nil
+}
+
+func revokeAuthTokenRequestToProto(r serde.RevokeAuthTokenRequest) *nitropb.RevokeAuthTokenRequest {
+	return &nitropb.RevokeAuthTokenRequest{Token: r.Token}
+}
+
+func revokeAuthTokenRequestFromProto(r *nitropb.RevokeAuthTokenRequest) (serde.RevokeAuthTokenRequest, error) {
+	return serde.RevokeAuthTokenRequest{Token: r.Token}, nil
+}
+
+func revokeAuthTokenResponseToProto(tokenId string) *nitropb.RevokeAuthTokenResponse {
+	return &nitropb.RevokeAuthTokenResponse{TokenId: tokenId}
+}
+
+func revokeAuthTokenResponseFromProto(r *nitropb.RevokeAuthTokenResponse) (string, error) {
+	return r.TokenId, nil
+}
+
+func revokeClientTokensRequestToProto(r serde.RevokeClientTokensRequest) *nitropb.RevokeClientTokensRequest {
+	return &nitropb.RevokeClientTokensRequest{ClientId: r.ClientId}
+}
+
+func revokeClientTokensRequestFromProto(r *nitropb.RevokeClientTokensRequest) (serde.RevokeClientTokensRequest, error) {
+	return serde.RevokeClientTokensRequest{ClientId: r.ClientId}, nil
+}
+
+func revokeClientTokensResponseToProto(clientId string) *nitropb.RevokeClientTokensResponse {
+	return &nitropb.RevokeClientTokensResponse{ClientId: clientId}
+}
+
+func revokeClientTokensResponseFromProto(r *nitropb.RevokeClientTokensResponse) (string, error) {
+	return r.ClientId, nil
+}
+
+// Node
+
+func getAddressRequestToProto(serde.NoPayloadRequest) *nitropb.GetAddressRequest {
+	return &nitropb.GetAddressRequest{}
+}
+
+func getAddressRequestFromProto(*nitropb.GetAddressRequest) (serde.NoPayloadRequest, error) {
+	return serde.NoPayloadRequest{}, nil
+}
+
+func getAddressResponseToProto(address string) *nitropb.GetAddressResponse {
+	return &nitropb.GetAddressResponse{Address: address}
+}
+
+func getAddressResponseFromProto(r *nitropb.GetAddressResponse) (string, error) {
+	return r.Address, nil
+}
+
+func versionRequestToProto(serde.NoPayloadRequest) *nitropb.VersionRequest {
+	return &nitropb.VersionRequest{}
+}
+
+func versionRequestFromProto(*nitropb.VersionRequest) (serde.NoPayloadRequest, error) {
+	return serde.NoPayloadRequest{}, nil
+}
+
+func versionResponseToProto(version string) *nitropb.VersionResponse {
+	return &nitropb.VersionResponse{Version: version}
+}
+
+func versionResponseFromProto(r *nitropb.VersionResponse) (string, error) {
+	return r.Version, nil
+}
+
+func discoverRequestToProto(serde.NoPayloadRequest) *nitropb.DiscoverRequest {
+	return &nitropb.DiscoverRequest{}
+}
+
+func discoverRequestFromProto(*nitropb.DiscoverRequest) (serde.NoPayloadRequest, error) {
+	return serde.NoPayloadRequest{}, nil
+}
+
+func discoverResponseToProto(document openrpc.Document) *nitropb.DiscoverResponse {
+	// A document only contains strings, numbers, slices and maps with string keys, so it always encodes
+	data, _ := json.Marshal(document)
+	return &nitropb.DiscoverResponse{Document: string(data)}
+}
+
+func discoverResponseFromProto(r *nitropb.DiscoverResponse) (openrpc.Document, error) {
+	var document openrpc.Document
+	err := json.Unmarshal([]byte(r.Document), &document)
+	return document, err
+}
+
+// Objectives
+
+func outcomeToProto(o outcome.Exit) []*nitropb.SingleAssetExit {
+	exits := make([]*nitropb.SingleAssetExit, len(o))
+	for i, sae := range o {
+		allocations := make([]*nitropb.Allocation, len(sae.Allocations))
+		for j, a := range sae.Allocations {
+			allocations[j] = &nitropb.Allocation{
+				Destination:    a.Destination.String(),
+				Amount:         amountToProto(a.Amount),
+				AllocationType: uint32(a.AllocationType),
+				Metadata:       a.Metadata,
+			}
+		}
+		exits[i] = &nitropb.SingleAssetExit{
+			Asset:         sae.Asset.Hex(),
+			AssetType:     uint32(sae.AssetMetadata.AssetType),
+			AssetMetadata: sae.AssetMetadata.Metadata,
+			Allocations:   allocations,
+		}
+	}
+	return exits
+}
+
+func (p *parser) outcome(exits []*nitropb.SingleAssetExit) outcome.Exit {
+	o := make(outcome.Exit, len(exits))
+	for i, sae := range exits {
+		allocations := make(outcome.Allocations, len(sae.Allocations))
+		for j, a := range sae.Allocations {
+			allocations[j] = outcome.Allocation{
+				Destination:    p.destination(a.Destination),
+				Amount:         p.amount(a.Amount),
+				AllocationType: outcome.AllocationType(a.AllocationType),
+				Metadata:       a.Metadata,
+			}
+		}
+		o[i] = outcome.SingleAssetExit{
+			Asset:         p.address(sae.Asset),
+			AssetMetadata: outcome.AssetMetadata{AssetType: outcome.AssetType(sae.AssetType), Metadata: sae.AssetMetadata},
+			Allocations:   allocations,
+		}
+	}
+	return o
+}
+
+func createLedgerChannelRequestToProto(r directfund.ObjectiveRequest) *nitropb.CreateLedgerChannelRequest {
+	return &nitropb.CreateLedgerChannelRequest{
+		Counterparty:      r.CounterParty.Hex(),
+		ChallengeDuration: r.ChallengeDuration,
+		Outcome:           outcomeToProto(r.Outcome),
+		AppDefinition:     r.AppDefinition.Hex(),
+		AppData:           r.AppData,
+		Nonce:             r.Nonce,
+	}
+}
+
+func createLedgerChannelRequestFromProto(r *nitropb.CreateLedgerChannelRequest) (directfund.ObjectiveRequest, error) {
+	p := parser{}
+	req := directfund.NewObjectiveRequest(p.address(r.Counterparty), r.ChallengeDuration, p.outcome(r.Outcome), r.Nonce, p.address(r.AppDefinition))
+	req.AppData = r.AppData
+	return req, p.err
+}
+
+func createPaymentChannelRequestToProto(r virtualfund.ObjectiveRequest) *nitropb.CreatePaymentChannelRequest {
+	intermediaries := make([]string, len(r.Intermediaries))
+	for i, a := range r.Intermediaries {
+		intermediaries[i] = a.Hex()
+	}
+	return &nitropb.CreatePaymentChannelRequest{
+		Intermediaries:    intermediaries,
+		Counterparty:      r.CounterParty.Hex(),
+		ChallengeDuration: r.ChallengeDuration,
+		Outcome:           outcomeToProto(r.Outcome),
+		Nonce:             r.Nonce,
+		AppDefinition:     r.AppDefinition.Hex(),
+	}
+}
+
+func createPaymentChannelRequestFromProto(r *nitropb.CreatePaymentChannelRequest) (virtualfund.ObjectiveRequest, error) {
+	p := parser{}
+	req := virtualfund.NewObjectiveRequest(p.addresses(r.Intermediaries), p.address(r.Counterparty), r.ChallengeDuration, p.outcome(r.Outcome), r.Nonce, p.address(r.AppDefinition))
+	return req, p.err
+}
+
+func ledgerObjectiveResponseToProto(r directfund.ObjectiveResponse) *nitropb.ObjectiveResponse {
+	return &nitropb.ObjectiveResponse{Id: string(r.Id), ChannelId: r.ChannelId.String()}
+}
+
+func ledgerObjectiveResponseFromProto(r *nitropb.ObjectiveResponse) (directfund.ObjectiveResponse, error) {
+	p := parser{}
+	return directfund.ObjectiveResponse{Id: protocols.ObjectiveId(r.Id), ChannelId: p.destination(r.ChannelId)}, p.err
+}
+
+func paymentObjectiveResponseToProto(r virtualfund.ObjectiveResponse) *nitropb.ObjectiveResponse {
+	return &nitropb.ObjectiveResponse{Id: string(r.Id), ChannelId: r.ChannelId.String()}
+}
+
+func paymentObjectiveResponseFromProto(r *nitropb.ObjectiveResponse) (virtualfund.ObjectiveResponse, error) {
+	p := parser{}
+	return virtualfund.ObjectiveResponse{Id: protocols.ObjectiveId(r.Id), ChannelId: p.destination(r.ChannelId)}, p.err
+}
+
+func closeLedgerChannelRequestToProto(r directdefund.ObjectiveRequest) *nitropb.CloseChannelRequest {
+	return &nitropb.CloseChannelRequest{ChannelId: r.ChannelId.String()}
+}
+
+func closeLedgerChannelRequestFromProto(r *nitropb.CloseChannelRequest) (directdefund.ObjectiveRequest, error) {
+	p := parser{}
+	return directdefund.NewObjectiveRequest(p.destination(r.ChannelId)), p.err
+}
+
+func closePaymentChannelRequestToProto(r virtualdefund.ObjectiveRequest) *nitropb.CloseChannelRequest {
+	return &nitropb.CloseChannelRequest{ChannelId: r.ChannelId.String()}
+}
+
+func closePaymentChannelRequestFromProto(r *nitropb.CloseChannelRequest) (virtualdefund.ObjectiveRequest, error) {
+	p := parser{}
+	return virtualdefund.NewObjectiveRequest(p.destination(r.ChannelId)), p.err
+}
+
+func closeChannelResponseToProto(id protocols.ObjectiveId) *nitropb.CloseChannelResponse {
+	return &nitropb.CloseChannelResponse{ObjectiveId: string(id)}
+}
+
+func closeChannelResponseFromProto(r *nitropb.CloseChannelResponse) (protocols.ObjectiveId, error) {
+	return protocols.ObjectiveId(r.ObjectiveId), nil
+}
+
+// Payments
+
+func paymentRequestToProto(r serde.PaymentRequest) *nitropb.PaymentRequest {
+	return &nitropb.PaymentRequest{Channel: r.Channel.String(), Amount: r.Amount}
+}
+
+func paymentRequestFromProto(r *nitropb.PaymentRequest) (serde.PaymentRequest, error) {
+	p := parser{}
+	return serde.PaymentRequest{Channel: p.destination(r.Channel), Amount: r.Amount}, p.err
+}
+
+func voucherToProto(v payments.Voucher) *nitropb.Voucher {
+	return &nitropb.Voucher{ChannelId: v.ChannelId.String(), Amount: amountToProto(v.Amount), Signature: signatureToProto(v.Signature)}
+}
+
+func voucherFromProto(v *nitropb.Voucher) (payments.Voucher, error) {
+	p := parser{}
+	return payments.Voucher{ChannelId: p.destination(v.ChannelId), Amount: p.amount(v.Amount), Signature: p.signature(v.Signature)}, p.err
+}
+
+func receiveVoucherSummaryToProto(s payments.ReceiveVoucherSummary) *nitropb.ReceiveVoucherSummary {
+	return &nitropb.ReceiveVoucherSummary{Total: amountToProto(s.Total), Delta: amountToProto(s.Delta)}
+}
+
+func receiveVoucherSummaryFromProto(s *nitropb.ReceiveVoucherSummary) (payments.ReceiveVoucherSummary, error) {
+	p := parser{}
+	return payments.ReceiveVoucherSummary{Total: p.amount(s.Total), Delta: p.amount(s.Delta)}, p.err
+}
+
+func receivedVoucherToProto(v payments.ReceivedVoucher) *nitropb.ReceivedVoucher {
+	return &nitropb.ReceivedVoucher{ChannelId: v.ChannelId.String(), Payer: v.Payer.Hex(), Delta: amountToProto(v.Delta), Total: amountToProto(v.Total)}
+}
+
+func receivedVoucherFromProto(v *nitropb.ReceivedVoucher) (payments.ReceivedVoucher, error) {
+	p := parser{}
+	return payments.ReceivedVoucher{ChannelId: p.destination(v.ChannelId), Payer: p.address(v.Payer), Delta: p.amount(v.Delta), Total: p.amount(v.Total)}, p.err
+}
+
+// Channels
+
+func paymentChannelInfoToProto(i query.PaymentChannelInfo) *nitropb.PaymentChannelInfo {
+	return &nitropb.PaymentChannelInfo{
+		Id:     i.ID.String(),
+		Status: statusToProto(i.Status),
+		Balance: &nitropb.PaymentChannelBalance{
+			AssetAddress:   i.Balance.AssetAddress.Hex(),
+			Payee:          i.Balance.Payee.Hex(),
+			Payer:          i.Balance.Payer.Hex(),
+			PaidSoFar:      bigToProto(i.Balance.PaidSoFar),
+			RemainingFunds: bigToProto(i.Balance.RemainingFunds),
+		},
+	}
+}
+
+func (p *parser) paymentChannelInfo(i *nitropb.PaymentChannelInfo) query.PaymentChannelInfo {
+	b := i.GetBalance()
+	return query.PaymentChannelInfo{
+		ID:     p.destination(i.Id),
+		Status: statusFromProto(i.Status),
+		Balance: query.PaymentChannelBalance{
+			AssetAddress:   p.address(b.GetAssetAddress()),
+			Payee:          p.address(b.GetPayee()),
+			Payer:          p.address(b.GetPayer()),
+			PaidSoFar:      p.big(b.GetPaidSoFar()),
+			RemainingFunds: p.big(b.GetRemainingFunds()),
+		},
+	}
+}
+
+func paymentChannelInfoFromProto(i *nitropb.PaymentChannelInfo) (query.PaymentChannelInfo, error) {
+	p := parser{}
+	info := p.paymentChannelInfo(i)
+	return info, p.err
+}
+
+func ledgerChannelInfoToProto(i query.LedgerChannelInfo) *nitropb.LedgerChannelInfo {
+	return &nitropb.LedgerChannelInfo{
+		Id:     i.ID.String(),
+		Status: statusToProto(i.Status),
+		Balance: &nitropb.LedgerChannelBalance{
+			AssetAddress: i.Balance.AssetAddress.Hex(),
+			Me:           i.Balance.Me.Hex(),
+			Them:         i.Balance.Them.Hex(),
+			MyBalance:    bigToProto(i.Balance.MyBalance),
+			TheirBalance: bigToProto(i.Balance.TheirBalance),
+		},
+	}
+}
+
+func (p *parser) ledgerChannelInfo(i *nitropb.LedgerChannelInfo) query.LedgerChannelInfo {
+	b := i.GetBalance()
+	return query.LedgerChannelInfo{
+		ID:     p.destination(i.Id),
+		Status: statusFromProto(i.Status),
+		Balance: query.LedgerChannelBalance{
+			AssetAddress: p.address(b.GetAssetAddress()),
+			Me:           p.address(b.GetMe()),
+			Them:         p.address(b.GetThem()),
+			MyBalance:    p.big(b.GetMyBalance()),
+			TheirBalance: p.big(b.GetTheirBalance()),
+		},
+	}
+}
+
+func ledgerChannelInfoFromProto(i *nitropb.LedgerChannelInfo) (query.LedgerChannelInfo, error) {
+	p := parser{}
+	info := p.ledgerChannelInfo(i)
+	return info, p.err
+}
+
+func paymentChannelsToProto(channels []query.PaymentChannelInfo) []*nitropb.PaymentChannelInfo {
+	list := make([]*nitropb.PaymentChannelInfo, len(channels))
+	for i, c := range channels {
+		list[i] = paymentChannelInfoToProto(c)
+	}
+	return list
+}
+
+func (p *parser) paymentChannels(channels []*nitropb.PaymentChannelInfo) []query.PaymentChannelInfo {
+	list := make([]query.PaymentChannelInfo, len(channels))
+	for i, c := range channels {
+		list[i] = p.paymentChannelInfo(c)
+	}
+	return list
+}
+
+func ledgerChannelsToProto(channels []query.LedgerChannelInfo) []*nitropb.LedgerChannelInfo {
+	list := make([]*nitropb.LedgerChannelInfo, len(channels))
+	for i, c := range channels {
+		list[i] = ledgerChannelInfoToProto(c)
+	}
+	return list
+}
+
+func (p *parser) ledgerChannels(channels []*nitropb.LedgerChannelInfo) []query.LedgerChannelInfo {
+	list := make([]query.LedgerChannelInfo, len(channels))
+	for i, c := range channels {
+		list[i] = p.ledgerChannelInfo(c)
+	}
+	return list
+}
+
+func getPaymentChannelRequestToProto(r serde.GetPaymentChannelRequest) *nitropb.GetChannelRequest {
+	return &nitropb.GetChannelRequest{Id: r.Id.String()}
+}
+
+func getPaymentChannelRequestFromProto(r *nitropb.GetChannelRequest) (serde.GetPaymentChannelRequest, error) {
+	p := parser{}
+	return serde.GetPaymentChannelRequest{Id: p.destination(r.Id)}, p.err
+}
+
+func getLedgerChannelRequestToProto(r serde.GetLedgerChannelRequest) *nitropb.GetChannelRequest {
+	return &nitropb.GetChannelRequest{Id: r.Id.String()}
+}
+
+func getLedgerChannelRequestFromProto(r *nitropb.GetChannelRequest) (serde.GetLedgerChannelRequest, error) {
+	p := parser{}
+	return serde.GetLedgerChannelRequest{Id: p.destination(r.Id)}, p.err
+}
+
+func getAllLedgerChannelsRequestToProto(serde.NoPayloadRequest) *nitropb.GetAllLedgerChannelsRequest {
+	return &nitropb.GetAllLedgerChannelsRequest{}
+}
+
+func getAllLedgerChannelsRequestFromProto(*nitropb.GetAllLedgerChannelsRequest) (serde.NoPayloadRequest, error) {
+	return serde.NoPayloadRequest{}, nil
+}
+
+func ledgerChannelListToProto(channels []query.LedgerChannelInfo) *nitropb.LedgerChannelList {
+	return &nitropb.LedgerChannelList{Channels: ledgerChannelsToProto(channels)}
+}
+
+func ledgerChannelListFromProto(r *nitropb.LedgerChannelList) ([]query.LedgerChannelInfo, error) {
+	p := parser{}
+	list := p.ledgerChannels(r.Channels)
+	return list, p.err
+}
+
+func getPaymentChannelsByLedgerRequestToProto(r serde.GetPaymentChannelsByLedgerRequest) *nitropb.GetPaymentChannelsByLedgerRequest {
+	return &nitropb.GetPaymentChannelsByLedgerRequest{LedgerId: r.LedgerId.String()}
+}
+
+func getPaymentChannelsByLedgerRequestFromProto(r *nitropb.GetPaymentChannelsByLedgerRequest) (serde.GetPaymentChannelsByLedgerRequest, error) {
+	p := parser{}
+	return serde.GetPaymentChannelsByLedgerRequest{LedgerId: p.destination(r.LedgerId)}, p.err
+}
+
+func paymentChannelListToProto(channels []query.PaymentChannelInfo) *nitropb.PaymentChannelList {
+	return &nitropb.PaymentChannelList{Channels: paymentChannelsToProto(channels)}
+}
+
+func paymentChannelListFromProto(r *nitropb.PaymentChannelList) ([]query.PaymentChannelInfo, error) {
+	p := parser{}
+	list := p.paymentChannels(r.Channels)
+	return list, p.err
+}
+
+func getChannelsRequestToProto(r serde.GetChannelsRequest) *nitropb.GetChannelsRequest {
+	filter := &nitropb.ChannelFilter{
+		Status:       statusToProto(r.Filter.Status),
+		CreatedAfter: timeToProto(r.Filter.CreatedAfter),
+	}
+	if (r.Filter.Counterparty != types.Address{}) {
+		filter.Counterparty = r.Filter.Counterparty.Hex()
+	}
+	if r.Filter.AssetAddress != nil {
+		asset := r.Filter.AssetAddress.Hex()
+		filter.AssetAddress = &asset
+	}
+	if r.Filter.MinBalance != nil {
+		filter.MinBalance = bigToProto(r.Filter.MinBalance)
+	}
+	return &nitropb.GetChannelsRequest{Filter: filter, Cursor: r.Cursor, Limit: r.Limit}
+}
+
+func getChannelsRequestFromProto(r *nitropb.GetChannelsRequest) (serde.GetChannelsRequest, error) {
+	p := parser{}
+	f := r.GetFilter()
+	filter := query.ChannelFilter{
+		Status:       statusFromProto(f.GetStatus()),
+		Counterparty: p.address(f.GetCounterparty()),
+		MinBalance:   p.big(f.GetMinBalance()),
+		CreatedAfter: timeFromProto(f.GetCreatedAfter()),
+	}
+	if f.AssetAddress != nil {
+		asset := p.address(f.GetAssetAddress())
+		filter.AssetAddress = &asset
+	}
+	return serde.GetChannelsRequest{Filter: filter, Cursor: r.Cursor, Limit: r.Limit}, p.err
+}
+
+func ledgerChannelsPageToProto(page query.LedgerChannelsPage) *nitropb.LedgerChannelsPage {
+	return &nitropb.LedgerChannelsPage{Channels: ledgerChannelsToProto(page.Channels), NextCursor: page.NextCursor}
+}
+
+func ledgerChannelsPageFromProto(page *nitropb.LedgerChannelsPage) (query.LedgerChannelsPage, error) {
+	p := parser{}
+	return query.LedgerChannelsPage{Channels: p.ledgerChannels(page.Channels), NextCursor: page.NextCursor}, p.err
+}
+
+func paymentChannelsPageToProto(page query.PaymentChannelsPage) *nitropb.PaymentChannelsPage {
+	return &nitropb.PaymentChannelsPage{Channels: paymentChannelsToProto(page.Channels), NextCursor: page.NextCursor}
+}
+
+func paymentChannelsPageFromProto(page *nitropb.PaymentChannelsPage) (query.PaymentChannelsPage, error) {
+	p := parser{}
+	return query.PaymentChannelsPage{Channels: p.paymentChannels(page.Channels), NextCursor: page.NextCursor}, p.err
+}
+
+// History
+
+func getPaymentHistoryRequestToProto(r serde.GetPaymentHistoryRequest) *nitropb.GetPaymentHistoryRequest {
+	return &nitropb.GetPaymentHistoryRequest{Id: r.Id.String(), Offset: r.Offset, Limit: r.Limit}
+}
+
+func getPaymentHistoryRequestFromProto(r *nitropb.GetPaymentHistoryRequest) (serde.GetPaymentHistoryRequest, error) {
+	p := parser{}
+	return serde.GetPaymentHistoryRequest{Id: p.destination(r.Id), Offset: r.Offset, Limit: r.Limit}, p.err
+}
+
+func paymentHistoryToProto(h query.PaymentHistory) *nitropb.PaymentHistory {
+	records := make([]*nitropb.PaymentRecord, len(h.Payments))
+	for i, r := range h.Payments {
+		records[i] = &nitropb.PaymentRecord{
+			ChannelId: r.ChannelId.String(),
+			Index:     r.Index,
+			Timestamp: timeToProto(r.Timestamp),
+			Direction: paymentDirections[r.Direction],
+			Delta:     amountToProto(r.Delta),
+			Total:     amountToProto(r.Total),
+			Signature: signatureToProto(r.Signature),
+		}
+	}
+	return &nitropb.PaymentHistory{Id: h.ID.String(), Payments: records, NextOffset: h.NextOffset, HasMore: h.HasMore}
+}
+
+func paymentHistoryFromProto(h *nitropb.PaymentHistory) (query.PaymentHistory, error) {
+	p := parser{}
+	records := make([]payments.PaymentRecord, len(h.Payments))
+	for i, r := range h.Payments {
+		records[i] = payments.PaymentRecord{
+			ChannelId: p.destination(r.ChannelId),
+			Index:     r.Index,
+			Timestamp: timeFromProto(r.Timestamp),
+			Direction: directionFromProto(r.Direction),
+			Delta:     p.amount(r.Delta),
+			Total:     p.amount(r.Total),
+			Signature: p.signature(r.Signature),
+		}
+	}
+	return query.PaymentHistory{ID: p.destination(h.Id), Payments: records, NextOffset: h.NextOffset, HasMore: h.HasMore}, p.err
+}
+
+func getBalanceHistoryRequestToProto(r serde.GetBalanceHistoryRequest) *nitropb.GetBalanceHistoryRequest {
+	return &nitropb.GetBalanceHistoryRequest{Id: r.Id.String(), From: timeToProto(r.From), To: timeToProto(r.To)}
+}
+
+func getBalanceHistoryRequestFromProto(r *nitropb.GetBalanceHistoryRequest) (serde.GetBalanceHistoryRequest, error) {
+	p := parser{}
+	return serde.GetBalanceHistoryRequest{Id: p.destination(r.Id), From: timeFromProto(r.From), To: timeFromProto(r.To)}, p.err
+}
+
+func balanceSnapshotListToProto(snapshots []query.BalanceSnapshot) *nitropb.BalanceSnapshotList {
+	list := make([]*nitropb.BalanceSnapshot, len(snapshots))
+	for i, s := range snapshots {
+		list[i] = &nitropb.BalanceSnapshot{
+			ChannelId:    s.ChannelId.String(),
+			Kind:         channelKinds[store.ChannelKind(s.Kind)],
+			Timestamp:    timeToProto(s.Timestamp),
+			Status:       statusToProto(s.Status),
+			AssetAddress: s.AssetAddress.Hex(),
+			MyBalance:    bigToProto(s.MyBalance),
+			TheirBalance: bigToProto(s.TheirBalance),
+		}
+	}
+	return &nitropb.BalanceSnapshotList{Snapshots: list}
+}
+
+func balanceSnapshotListFromProto(r *nitropb.BalanceSnapshotList) ([]query.BalanceSnapshot, error) {
+	p := parser{}
+	list := make([]query.BalanceSnapshot, len(r.Snapshots))
+	for i, s := range r.Snapshots {
+		list[i] = query.BalanceSnapshot{
+			ChannelId:    p.destination(s.ChannelId),
+			Kind:         kindFromProto(s.Kind),
+			Timestamp:    timeFromProto(s.Timestamp),
+			Status:       statusFromProto(s.Status),
+			AssetAddress: p.address(s.AssetAddress),
+			MyBalance:    p.big(s.MyBalance),
+			TheirBalance: p.big(s.TheirBalance),
+		}
+	}
+	return list, p.err
+}
+
+func getAssetBalanceHistoryRequestToProto(r serde.GetAssetBalanceHistoryRequest) *nitropb.GetAssetBalanceHistoryRequest {
+	return &nitropb.GetAssetBalanceHistoryRequest{From: timeToProto(r.From), To: timeToProto(r.To), IntervalSeconds: r.IntervalSeconds}
+}
+
+func getAssetBalanceHistoryRequestFromProto(r *nitropb.GetAssetBalanceHistoryRequest) (serde.GetAssetBalanceHistoryRequest, error) {
+	return serde.GetAssetBalanceHistoryRequest{From: timeFromProto(r.From), To: timeFromProto(r.To), IntervalSeconds: r.IntervalSeconds}, nil
+}
+
+func assetBalanceListToProto(balances []query.AssetBalance) *nitropb.AssetBalanceList {
+	list := make([]*nitropb.AssetBalance, len(balances))
+	for i, b := range balances {
+		list[i] = &nitropb.AssetBalance{
+			Timestamp:    timeToProto(b.Timestamp),
+			AssetAddress: b.AssetAddress.Hex(),
+			MyBalance:    bigToProto(b.MyBalance),
+			TheirBalance: bigToProto(b.TheirBalance),
+			Channels:     uint64(b.Channels),
+		}
+	}
+	return &nitropb.AssetBalanceList{Balances: list}
+}
+
+func assetBalanceListFromProto(r *nitropb.AssetBalanceList) ([]query.AssetBalance, error) {
+	p := parser{}
+	list := make([]query.AssetBalance, len(r.Balances))
+	for i, b := range r.Balances {
+		list[i] = query.AssetBalance{
+			Timestamp:    timeFromProto(b.Timestamp),
+			AssetAddress: p.address(b.AssetAddress),
+			MyBalance:    p.big(b.MyBalance),
+			TheirBalance: p.big(b.TheirBalance),
+			Channels:     uint(b.Channels),
+		}
+	}
+	return list, p.err
+}
+
+// Notifications
+
+// notificationToProto converts JSON-RPC notification data, as sent by the rpc server, to a protobuf notification
+func notificationToProto(data []byte) (*nitropb.Notification, error) {
+	var n struct {
+		Method serde.NotificationMethod `json:"method"`
+		Params struct {
+			Payload json.RawMessage `json:"payload"`
+			Seq     uint64          `json:"seq"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+
+	notification, err := notificationPayloadToProto(n.Method, n.Params.Payload)
+	if err != nil {
+		return nil, err
+	}
+	notification.Seq = n.Params.Seq
+	return notification, nil
+}
+
+// notificationPayloadToProto converts the JSON payload of a notification to a protobuf notification
+func notificationPayloadToProto(method serde.NotificationMethod, payload json.RawMessage) (*nitropb.Notification, error) {
+	switch method {
+	case serde.ObjectiveCompleted:
+		var id protocols.ObjectiveId
+		err := json.Unmarshal(payload, &id)
+		return &nitropb.Notification{Notification: &nitropb.Notification_ObjectiveCompleted{ObjectiveCompleted: string(id)}}, err
+	case serde.LedgerChannelUpdated:
+		var info query.LedgerChannelInfo
+		err := json.Unmarshal(payload, &info)
+		return &nitropb.Notification{Notification: &nitropb.Notification_LedgerChannelUpdated{LedgerChannelUpdated: ledgerChannelInfoToProto(info)}}, err
+	case serde.PaymentChannelUpdated:
+		var info query.PaymentChannelInfo
+		err := json.Unmarshal(payload, &info)
+		return &nitropb.Notification{Notification: &nitropb.Notification_PaymentChannelUpdated{PaymentChannelUpdated: paymentChannelInfoToProto(info)}}, err
+	case serde.VoucherReceived:
+		var v payments.ReceivedVoucher
+		err := json.Unmarshal(payload, &v)
+		return &nitropb.Notification{Notification: &nitropb.Notification_VoucherReceived{VoucherReceived: receivedVoucherToProto(v)}}, err
+	default:
+		return nil, fmt.Errorf("unknown notification method %q", method)
+	}
+}
+
+// notificationFromProto converts a protobuf notification to JSON-RPC notification data, as expected by the rpc client
+func notificationFromProto(n *nitropb.Notification) ([]byte, error) {
+	switch notification := n.Notification.(type) {
+	case *nitropb.Notification_ObjectiveCompleted:
+		return marshalNotification(serde.ObjectiveCompleted, protocols.ObjectiveId(notification.ObjectiveCompleted), n.Seq)
+	case *nitropb.Notification_LedgerChannelUpdated:
+		info, err := ledgerChannelInfoFromProto(notification.LedgerChannelUpdated)
+		if err != nil {
+			return nil, err
+		}
+		return marshalNotification(serde.LedgerChannelUpdated, info, n.Seq)
+	case *nitropb.Notification_PaymentChannelUpdated:
+		info, err := paymentChannelInfoFromProto(notification.PaymentChannelUpdated)
+		if err != nil {
+			return nil, err
+		}
+		return marshalNotification(serde.PaymentChannelUpdated, info, n.Seq)
+	case *nitropb.Notification_VoucherReceived:
+		v, err := receivedVoucherFromProto(notification.VoucherReceived)
+		if err != nil {
+			return nil, err
+		}
+		return marshalNotification(serde.VoucherReceived, v, n.Seq)
+	default:
+		return nil, fmt.Errorf("unknown notification %T", notification)
+	}
+}
+
+func marshalNotification[T serde.NotificationPayload](method serde.NotificationMethod, payload T, seq uint64) ([]byte, error) {
+	request := serde.NewJsonRpcSpecificRequest(rand.Uint64(), method, payload, "")
+	request.Params.Seq = seq
+	return json.Marshal(request)
+}
+
+func eventFilterToProto(f serde.EventFilter) *nitropb.EventFilter {
+	filter := &nitropb.EventFilter{}
+	for _, method := range f.Methods {
+		filter.Methods = append(filter.Methods, string(method))
+	}
+	for _, id := range f.ChannelIds {
+		filter.ChannelIds = append(filter.ChannelIds, id.String())
+	}
+	for _, counterparty := range f.Counterparties {
+		filter.Counterparties = append(filter.Counterparties, counterparty.Hex())
+	}
+	for _, id := range f.ObjectiveIds {
+		filter.ObjectiveIds = append(filter.ObjectiveIds, string(id))
+	}
+	return filter
+}
+
+func (p *parser) eventFilter(f *nitropb.EventFilter) serde.EventFilter {
+	filter := serde.EventFilter{}
+	for _, method := range f.GetMethods() {
+		filter.Methods = append(filter.Methods, serde.NotificationMethod(method))
+	}
+	for _, id := range f.GetChannelIds() {
+		filter.ChannelIds = append(filter.ChannelIds, p.destination(id))
+	}
+	for _, counterparty := range f.GetCounterparties() {
+		filter.Counterparties = append(filter.Counterparties, p.address(counterparty))
+	}
+	for _, id := range f.GetObjectiveIds() {
+		filter.ObjectiveIds = append(filter.ObjectiveIds, protocols.ObjectiveId(id))
+	}
+	return filter
+}
+
+func getEventsRequestToProto(r serde.GetEventsRequest) *nitropb.GetEventsRequest {
+	return &nitropb.GetEventsRequest{Since: r.Since, Limit: r.Limit, Filter: eventFilterToProto(r.Filter)}
+}
+
+func getEventsRequestFromProto(r *nitropb.GetEventsRequest) (serde.GetEventsRequest, error) {
+	p := parser{}
+	return serde.GetEventsRequest{Since: r.Since, Limit: r.Limit, Filter: p.eventFilter(r.Filter)}, p.err
+}
+
+func subscribeRequestToProto(r serde.SubscribeRequest) *nitropb.AddSubscriptionRequest {
+	return &nitropb.AddSubscriptionRequest{Since: r.Since, Limit: r.Limit, Filter: eventFilterToProto(r.Filter)}
+}
+
+func subscribeRequestFromProto(r *nitropb.AddSubscriptionRequest) (serde.SubscribeRequest, error) {
+	p := parser{}
+	return serde.SubscribeRequest{Since: r.Since, Limit: r.Limit, Filter: p.eventFilter(r.Filter)}, p.err
+}
+
+func subscribeResponseToProto(r serde.SubscribeResponse) *nitropb.AddSubscriptionResponse {
+	return &nitropb.AddSubscriptionResponse{SubscriptionId: r.SubscriptionId, Events: eventsPageToProto(r.EventsPage)}
+}
+
+func subscribeResponseFromProto(r *nitropb.AddSubscriptionResponse) (serde.SubscribeResponse, error) {
+	page, err := eventsPageFromProto(r.GetEvents())
+	return serde.SubscribeResponse{SubscriptionId: r.SubscriptionId, EventsPage: page}, err
+}
+
+func unsubscribeRequestToProto(r serde.UnsubscribeRequest) *nitropb.RemoveSubscriptionRequest {
+	return &nitropb.RemoveSubscriptionRequest{SubscriptionId: r.SubscriptionId}
+}
+
+func unsubscribeRequestFromProto(r *nitropb.RemoveSubscriptionRequest) (serde.UnsubscribeRequest, error) {
+	return serde.UnsubscribeRequest{SubscriptionId: r.SubscriptionId}, nil
+}
+
+func unsubscribeResponseToProto(subscriptionId string) *nitropb.RemoveSubscriptionResponse {
+	return &nitropb.RemoveSubscriptionResponse{SubscriptionId: subscriptionId}
+}
+
+func unsubscribeResponseFromProto(r *nitropb.RemoveSubscriptionResponse) (string, error) {
+	return r.SubscriptionId, nil
+}
+
+func eventsPageToProto(page query.EventsPage) *nitropb.EventsPage {
+	events := make([]*nitropb.Event, len(page.Events))
+	for i, e := range page.Events {
+		notification, err := notificationPayloadToProto(serde.NotificationMethod(e.Method), e.Payload)
+		if err != nil {
+			// The event is left empty, so that the client reports it as unknown rather than it being silently dropped
+			notification = &nitropb.Notification{}
+		}
+		notification.Seq = e.Seq
+		events[i] = &nitropb.Event{Timestamp: timeToProto(e.Timestamp), Notification: notification}
+	}
+	return &nitropb.EventsPage{Events: events, LastSeq: page.LastSeq, HasMore: page.HasMore, Missed: page.Missed}
+}
+
+func eventsPageFromProto(page *nitropb.EventsPage) (query.EventsPage, error) {
+	events := make([]query.Event, len(page.GetEvents()))
+	for i, e := range page.GetEvents() {
+		data, err := notificationFromProto(e.Notification)
+		if err != nil {
+			return query.EventsPage{}, err
+		}
+		var n struct {
+			Method string `json:"method"`
+			Params struct {
+				Payload json.RawMessage `json:"payload"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(data, &n); err != nil {
+			return query.EventsPage{}, err
+		}
+		events[i] = query.Event{Seq: e.Notification.Seq, Method: n.Method, Timestamp: timeFromProto(e.Timestamp), Payload: n.Params.Payload}
+	}
+	return query.EventsPage{Events: events, LastSeq: page.GetLastSeq(), HasMore: page.GetHasMore(), Missed: page.GetMissed()}, nil
+}
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	errorDomain = "go-nitro"
+	errorReason = "JSON_RPC_ERROR"
+)
+
+// statusCodes maps the JSON-RPC error codes returned by the rpc server to gRPC status codes.
+// Other codes map to codes.Unknown.
+var statusCodes = map[int64]codes.Code{
+	serde.ParseError.Code:                    codes.InvalidArgument,
+	serde.InvalidRequestError.Code:           codes.InvalidArgument,
+	serde.InvalidParamsError.Code:            codes.InvalidArgument,
+	serde.RequestUnmarshalError.Code:         codes.InvalidArgument,
+	serde.ParamsUnmarshalError.Code:          codes.InvalidArgument,
+	serde.MethodNotFoundError.Code:           codes.Unimplemented,
+	serde.InvalidAuthTokenError.Code:         codes.Unauthenticated,
+	serde.InvalidCredentialsError.Code:       codes.Unauthenticated,
+	serde.SpendingLimitExceededError.Code:    codes.ResourceExhausted,
+	serde.PermissionDeniedError.Code:         codes.PermissionDenied,
+	serde.ChannelNotFoundError.Code:          codes.NotFound,
+	serde.InsufficientFundsError.Code:        codes.FailedPrecondition,
+	serde.ChannelExistsError.Code:            codes.AlreadyExists,
+	serde.SubscriptionsUnsupportedError.Code: codes.FailedPrecondition,
+	serde.SubscriptionNotFoundError.Code:     codes.NotFound,
+	serde.InternalServerError.Code:           codes.Internal,
+}
+
+// errorToStatus converts a JSON-RPC error to a gRPC status error.
+// The JSON-RPC error code is attached as the "code" metadata of an ErrorInfo detail, so that errorFromStatus can recover it.
+func errorToStatus(jsonErr serde.JsonRpcError) error {
+	code, ok := statusCodes[jsonErr.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   errorReason,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"code": strconv.FormatInt(jsonErr.Code, 10)},
+	}
+	if jsonErr.Data != nil {
+		if data, err := json.Marshal(jsonErr.Data); err == nil {
+			info.Metadata["data"] = string(data)
+		}
+	}
+
+	s, err := status.New(code, jsonErr.Message).WithDetails(info)
+	if err != nil {
+		return status.Error(code, jsonErr.Message)
+	}
+	return s.Err()
+}
+
+// errorFromStatus recovers the JSON-RPC error from a status error created by errorToStatus.
+// It returns false if the error did not originate from the rpc server, e.g. if the connection failed.
+func errorFromStatus(err error) (serde.JsonRpcError, bool) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return serde.JsonRpcError{}, false
+	}
+
+	for _, detail := range s.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != errorDomain || info.Reason != errorReason {
+			continue
+		}
+		code, err := strconv.ParseInt(info.Metadata["code"], 10, 64)
+		if err != nil {
+			return serde.JsonRpcError{}, false
+		}
+		jsonErr := serde.JsonRpcError{Code: code, Message: s.Message()}
+		if data, ok := info.Metadata["data"]; ok {
+			_ = json.Unmarshal([]byte(data), &jsonErr.Data)
+		}
+		return jsonErr, true
+	}
+	return serde.JsonRpcError{}, false
+}
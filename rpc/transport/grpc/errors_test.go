@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorStatusRoundTrip(t *testing.T) {
+	for _, jsonErr := range []serde.JsonRpcError{serde.InvalidAuthTokenError, serde.SpendingLimitExceededError, {Code: serde.ChannelNotFoundError.Code, Message: "could not find channel with id 0x01"}} {
+		got, ok := errorFromStatus(errorToStatus(jsonErr))
+		if !ok || got != jsonErr {
+			t.Errorf("expected %v to survive the round trip, got %v", jsonErr, got)
+		}
+	}
+
+	if code := status.Code(errorToStatus(serde.InvalidAuthTokenError)); code != codes.Unauthenticated {
+		t.Errorf("expected an invalid auth token to be unauthenticated, got %s", code)
+	}
+	if code := status.Code(errorToStatus(serde.InsufficientFundsError)); code != codes.FailedPrecondition {
+		t.Errorf("expected insufficient funds to be a failed precondition, got %s", code)
+	}
+
+	if _, ok := errorFromStatus(status.Error(codes.Unavailable, "connection refused")); ok {
+		t.Error("expected a connection error not to be converted to a JSON-RPC error")
+	}
+	if _, ok := errorFromStatus(errors.New("not a status")); ok {
+		t.Error("expected a plain error not to be converted to a JSON-RPC error")
+	}
+}
@@ -0,0 +1,4 @@
+// Package nitropb contains the protobuf schema of the gRPC api, and the Go code generated from it.
+package nitropb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative nitro.proto
@@ -0,0 +1,1103 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: nitro.proto
+
+package nitropb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NitroRpc_GetAuthToken_FullMethodName               = "/nitro.rpc.v1.NitroRpc/GetAuthToken"
+	NitroRpc_RevokeAuthToken_FullMethodName            = "/nitro.rpc.v1.NitroRpc/RevokeAuthToken"
+	NitroRpc_RevokeClientTokens_FullMethodName         = "/nitro.rpc.v1.NitroRpc/RevokeClientTokens"
+	NitroRpc_GetAddress_FullMethodName                 = "/nitro.rpc.v1.NitroRpc/GetAddress"
+	NitroRpc_Version_FullMethodName                    = "/nitro.rpc.v1.NitroRpc/Version"
+	NitroRpc_Discover_FullMethodName                   = "/nitro.rpc.v1.NitroRpc/Discover"
+	NitroRpc_CreateLedgerChannel_FullMethodName        = "/nitro.rpc.v1.NitroRpc/CreateLedgerChannel"
+	NitroRpc_CloseLedgerChannel_FullMethodName         = "/nitro.rpc.v1.NitroRpc/CloseLedgerChannel"
+	NitroRpc_CreatePaymentChannel_FullMethodName       = "/nitro.rpc.v1.NitroRpc/CreatePaymentChannel"
+	NitroRpc_ClosePaymentChannel_FullMethodName        = "/nitro.rpc.v1.NitroRpc/ClosePaymentChannel"
+	NitroRpc_Pay_FullMethodName                        = "/nitro.rpc.v1.NitroRpc/Pay"
+	NitroRpc_CreateVoucher_FullMethodName              = "/nitro.rpc.v1.NitroRpc/CreateVoucher"
+	NitroRpc_ReceiveVoucher_FullMethodName             = "/nitro.rpc.v1.NitroRpc/ReceiveVoucher"
+	NitroRpc_GetPaymentChannel_FullMethodName          = "/nitro.rpc.v1.NitroRpc/GetPaymentChannel"
+	NitroRpc_GetLedgerChannel_FullMethodName           = "/nitro.rpc.v1.NitroRpc/GetLedgerChannel"
+	NitroRpc_GetAllLedgerChannels_FullMethodName       = "/nitro.rpc.v1.NitroRpc/GetAllLedgerChannels"
+	NitroRpc_GetPaymentChannelsByLedger_FullMethodName = "/nitro.rpc.v1.NitroRpc/GetPaymentChannelsByLedger"
+	NitroRpc_GetLedgerChannels_FullMethodName          = "/nitro.rpc.v1.NitroRpc/GetLedgerChannels"
+	NitroRpc_GetPaymentChannels_FullMethodName         = "/nitro.rpc.v1.NitroRpc/GetPaymentChannels"
+	NitroRpc_GetPaymentHistory_FullMethodName          = "/nitro.rpc.v1.NitroRpc/GetPaymentHistory"
+	NitroRpc_GetBalanceHistory_FullMethodName          = "/nitro.rpc.v1.NitroRpc/GetBalanceHistory"
+	NitroRpc_GetAssetBalanceHistory_FullMethodName     = "/nitro.rpc.v1.NitroRpc/GetAssetBalanceHistory"
+	NitroRpc_Subscribe_FullMethodName                  = "/nitro.rpc.v1.NitroRpc/Subscribe"
+	NitroRpc_GetEvents_FullMethodName                  = "/nitro.rpc.v1.NitroRpc/GetEvents"
+	NitroRpc_AddSubscription_FullMethodName            = "/nitro.rpc.v1.NitroRpc/AddSubscription"
+	NitroRpc_RemoveSubscription_FullMethodName         = "/nitro.rpc.v1.NitroRpc/RemoveSubscription"
+)
+
+// NitroRpcClient is the client API for NitroRpc service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NitroRpc exposes the nitro rpc api over gRPC. Each method corresponds to the JSON-RPC method of the same name in snake case.
+//
+// Methods other than GetAuthToken, GetAddress, Version, Discover and Subscribe require the token returned by GetAuthToken,
+// sent as "authorization: Bearer <token>" metadata.
+//
+// Addresses, channel ids and signatures are 0x-prefixed hex strings. Amounts are decimal strings.
+type NitroRpcClient interface {
+	GetAuthToken(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthToken, error)
+	RevokeAuthToken(ctx context.Context, in *RevokeAuthTokenRequest, opts ...grpc.CallOption) (*RevokeAuthTokenResponse, error)
+	RevokeClientTokens(ctx context.Context, in *RevokeClientTokensRequest, opts ...grpc.CallOption) (*RevokeClientTokensResponse, error)
+	GetAddress(ctx context.Context, in *GetAddressRequest, opts ...grpc.CallOption) (*GetAddressResponse, error)
+	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+	// Discover returns the OpenRPC document of the JSON-RPC api, which is served by the rpc.discover method
+	Discover(ctx context.Context, in *DiscoverRequest, opts ...grpc.CallOption) (*DiscoverResponse, error)
+	CreateLedgerChannel(ctx context.Context, in *CreateLedgerChannelRequest, opts ...grpc.CallOption) (*ObjectiveResponse, error)
+	CloseLedgerChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (*CloseChannelResponse, error)
+	CreatePaymentChannel(ctx context.Context, in *CreatePaymentChannelRequest, opts ...grpc.CallOption) (*ObjectiveResponse, error)
+	ClosePaymentChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (*CloseChannelResponse, error)
+	Pay(ctx context.Context, in *PaymentRequest, opts ...grpc.CallOption) (*PaymentRequest, error)
+	CreateVoucher(ctx context.Context, in *PaymentRequest, opts ...grpc.CallOption) (*Voucher, error)
+	ReceiveVoucher(ctx context.Context, in *Voucher, opts ...grpc.CallOption) (*ReceiveVoucherSummary, error)
+	GetPaymentChannel(ctx context.Context, in *GetChannelRequest, opts ...grpc.CallOption) (*PaymentChannelInfo, error)
+	GetLedgerChannel(ctx context.Context, in *GetChannelRequest, opts ...grpc.CallOption) (*LedgerChannelInfo, error)
+	GetAllLedgerChannels(ctx context.Context, in *GetAllLedgerChannelsRequest, opts ...grpc.CallOption) (*LedgerChannelList, error)
+	GetPaymentChannelsByLedger(ctx context.Context, in *GetPaymentChannelsByLedgerRequest, opts ...grpc.CallOption) (*PaymentChannelList, error)
+	GetLedgerChannels(ctx context.Context, in *GetChannelsRequest, opts ...grpc.CallOption) (*LedgerChannelsPage, error)
+	GetPaymentChannels(ctx context.Context, in *GetChannelsRequest, opts ...grpc.CallOption) (*PaymentChannelsPage, error)
+	GetPaymentHistory(ctx context.Context, in *GetPaymentHistoryRequest, opts ...grpc.CallOption) (*PaymentHistory, error)
+	GetBalanceHistory(ctx context.Context, in *GetBalanceHistoryRequest, opts ...grpc.CallOption) (*BalanceSnapshotList, error)
+	GetAssetBalanceHistory(ctx context.Context, in *GetAssetBalanceHistoryRequest, opts ...grpc.CallOption) (*AssetBalanceList, error)
+	// Subscribe streams notifications of completed objectives and channel updates
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Notification], error)
+	// GetEvents returns the notifications matching a filter sent after a sequence number, so that a subscriber can replay the notifications it missed.
+	// It is served by the JSON-RPC get_events method.
+	GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*EventsPage, error)
+	// AddSubscription subscribes the caller's Subscribe stream to the notifications matching a filter, after which the stream is only sent
+	// the notifications matching one of its subscriptions. It is served by the JSON-RPC subscribe method.
+	AddSubscription(ctx context.Context, in *AddSubscriptionRequest, opts ...grpc.CallOption) (*AddSubscriptionResponse, error)
+	// RemoveSubscription ends a subscription of the caller's Subscribe stream. It is served by the JSON-RPC unsubscribe method.
+	RemoveSubscription(ctx context.Context, in *RemoveSubscriptionRequest, opts ...grpc.CallOption) (*RemoveSubscriptionResponse, error)
+}
+
+type nitroRpcClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNitroRpcClient(cc grpc.ClientConnInterface) NitroRpcClient {
+	return &nitroRpcClient{cc}
+}
+
+func (c *nitroRpcClient) GetAuthToken(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthToken, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthToken)
+	err := c.cc.Invoke(ctx, NitroRpc_GetAuthToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) RevokeAuthToken(ctx context.Context, in *RevokeAuthTokenRequest, opts ...grpc.CallOption) (*RevokeAuthTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeAuthTokenResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_RevokeAuthToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) RevokeClientTokens(ctx context.Context, in *RevokeClientTokensRequest, opts ...grpc.CallOption) (*RevokeClientTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeClientTokensResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_RevokeClientTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetAddress(ctx context.Context, in *GetAddressRequest, opts ...grpc.CallOption) (*GetAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAddressResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_GetAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_Version_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) Discover(ctx context.Context, in *DiscoverRequest, opts ...grpc.CallOption) (*DiscoverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiscoverResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_Discover_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) CreateLedgerChannel(ctx context.Context, in *CreateLedgerChannelRequest, opts ...grpc.CallOption) (*ObjectiveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ObjectiveResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_CreateLedgerChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) CloseLedgerChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (*CloseChannelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloseChannelResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_CloseLedgerChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) CreatePaymentChannel(ctx context.Context, in *CreatePaymentChannelRequest, opts ...grpc.CallOption) (*ObjectiveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ObjectiveResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_CreatePaymentChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) ClosePaymentChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (*CloseChannelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloseChannelResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_ClosePaymentChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) Pay(ctx context.Context, in *PaymentRequest, opts ...grpc.CallOption) (*PaymentRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentRequest)
+	err := c.cc.Invoke(ctx, NitroRpc_Pay_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) CreateVoucher(ctx context.Context, in *PaymentRequest, opts ...grpc.CallOption) (*Voucher, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Voucher)
+	err := c.cc.Invoke(ctx, NitroRpc_CreateVoucher_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) ReceiveVoucher(ctx context.Context, in *Voucher, opts ...grpc.CallOption) (*ReceiveVoucherSummary, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReceiveVoucherSummary)
+	err := c.cc.Invoke(ctx, NitroRpc_ReceiveVoucher_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetPaymentChannel(ctx context.Context, in *GetChannelRequest, opts ...grpc.CallOption) (*PaymentChannelInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentChannelInfo)
+	err := c.cc.Invoke(ctx, NitroRpc_GetPaymentChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetLedgerChannel(ctx context.Context, in *GetChannelRequest, opts ...grpc.CallOption) (*LedgerChannelInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LedgerChannelInfo)
+	err := c.cc.Invoke(ctx, NitroRpc_GetLedgerChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetAllLedgerChannels(ctx context.Context, in *GetAllLedgerChannelsRequest, opts ...grpc.CallOption) (*LedgerChannelList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LedgerChannelList)
+	err := c.cc.Invoke(ctx, NitroRpc_GetAllLedgerChannels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetPaymentChannelsByLedger(ctx context.Context, in *GetPaymentChannelsByLedgerRequest, opts ...grpc.CallOption) (*PaymentChannelList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentChannelList)
+	err := c.cc.Invoke(ctx, NitroRpc_GetPaymentChannelsByLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetLedgerChannels(ctx context.Context, in *GetChannelsRequest, opts ...grpc.CallOption) (*LedgerChannelsPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LedgerChannelsPage)
+	err := c.cc.Invoke(ctx, NitroRpc_GetLedgerChannels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetPaymentChannels(ctx context.Context, in *GetChannelsRequest, opts ...grpc.CallOption) (*PaymentChannelsPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentChannelsPage)
+	err := c.cc.Invoke(ctx, NitroRpc_GetPaymentChannels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetPaymentHistory(ctx context.Context, in *GetPaymentHistoryRequest, opts ...grpc.CallOption) (*PaymentHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentHistory)
+	err := c.cc.Invoke(ctx, NitroRpc_GetPaymentHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetBalanceHistory(ctx context.Context, in *GetBalanceHistoryRequest, opts ...grpc.CallOption) (*BalanceSnapshotList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceSnapshotList)
+	err := c.cc.Invoke(ctx, NitroRpc_GetBalanceHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) GetAssetBalanceHistory(ctx context.Context, in *GetAssetBalanceHistoryRequest, opts ...grpc.CallOption) (*AssetBalanceList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AssetBalanceList)
+	err := c.cc.Invoke(ctx, NitroRpc_GetAssetBalanceHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Notification], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NitroRpc_ServiceDesc.Streams[0], NitroRpc_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Notification]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NitroRpc_SubscribeClient = grpc.ServerStreamingClient[Notification]
+
+func (c *nitroRpcClient) GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*EventsPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EventsPage)
+	err := c.cc.Invoke(ctx, NitroRpc_GetEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) AddSubscription(ctx context.Context, in *AddSubscriptionRequest, opts ...grpc.CallOption) (*AddSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddSubscriptionResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_AddSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroRpcClient) RemoveSubscription(ctx context.Context, in *RemoveSubscriptionRequest, opts ...grpc.CallOption) (*RemoveSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveSubscriptionResponse)
+	err := c.cc.Invoke(ctx, NitroRpc_RemoveSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NitroRpcServer is the server API for NitroRpc service.
+// All implementations must embed UnimplementedNitroRpcServer
+// for forward compatibility.
+//
+// NitroRpc exposes the nitro rpc api over gRPC. Each method corresponds to the JSON-RPC method of the same name in snake case.
+//
+// Methods other than GetAuthToken, GetAddress, Version, Discover and Subscribe require the token returned by GetAuthToken,
+// sent as "authorization: Bearer <token>" metadata.
+//
+// Addresses, channel ids and signatures are 0x-prefixed hex strings. Amounts are decimal strings.
+type NitroRpcServer interface {
+	GetAuthToken(context.Context, *AuthRequest) (*AuthToken, error)
+	RevokeAuthToken(context.Context, *RevokeAuthTokenRequest) (*RevokeAuthTokenResponse, error)
+	RevokeClientTokens(context.Context, *RevokeClientTokensRequest) (*RevokeClientTokensResponse, error)
+	GetAddress(context.Context, *GetAddressRequest) (*GetAddressResponse, error)
+	Version(context.Context, *VersionRequest) (*VersionResponse, error)
+	// Discover returns the OpenRPC document of the JSON-RPC api, which is served by the rpc.discover method
+	Discover(context.Context, *DiscoverRequest) (*DiscoverResponse, error)
+	CreateLedgerChannel(context.Context, *CreateLedgerChannelRequest) (*ObjectiveResponse, error)
+	CloseLedgerChannel(context.Context, *CloseChannelRequest) (*CloseChannelResponse, error)
+	CreatePaymentChannel(context.Context, *CreatePaymentChannelRequest) (*ObjectiveResponse, error)
+	ClosePaymentChannel(context.Context, *CloseChannelRequest) (*CloseChannelResponse, error)
+	Pay(context.Context, *PaymentRequest) (*PaymentRequest, error)
+	CreateVoucher(context.Context, *PaymentRequest) (*Voucher, error)
+	ReceiveVoucher(context.Context, *Voucher) (*ReceiveVoucherSummary, error)
+	GetPaymentChannel(context.Context, *GetChannelRequest) (*PaymentChannelInfo, error)
+	GetLedgerChannel(context.Context, *GetChannelRequest) (*LedgerChannelInfo, error)
+	GetAllLedgerChannels(context.Context, *GetAllLedgerChannelsRequest) (*LedgerChannelList, error)
+	GetPaymentChannelsByLedger(context.Context, *GetPaymentChannelsByLedgerRequest) (*PaymentChannelList, error)
+	GetLedgerChannels(context.Context, *GetChannelsRequest) (*LedgerChannelsPage, error)
+	GetPaymentChannels(context.Context, *GetChannelsRequest) (*PaymentChannelsPage, error)
+	GetPaymentHistory(context.Context, *GetPaymentHistoryRequest) (*PaymentHistory, error)
+	GetBalanceHistory(context.Context, *GetBalanceHistoryRequest) (*BalanceSnapshotList, error)
+	GetAssetBalanceHistory(context.Context, *GetAssetBalanceHistoryRequest) (*AssetBalanceList, error)
+	// Subscribe streams notifications of completed objectives and channel updates
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Notification]) error
+	// GetEvents returns the notifications matching a filter sent after a sequence number, so that a subscriber can replay the notifications it missed.
+	// It is served by the JSON-RPC get_events method.
+	GetEvents(context.Context, *GetEventsRequest) (*EventsPage, error)
+	// AddSubscription subscribes the caller's Subscribe stream to the notifications matching a filter, after which the stream is only sent
+	// the notifications matching one of its subscriptions. It is served by the JSON-RPC subscribe method.
+	AddSubscription(context.Context, *AddSubscriptionRequest) (*AddSubscriptionResponse, error)
+	// RemoveSubscription ends a subscription of the caller's Subscribe stream. It is served by the JSON-RPC unsubscribe method.
+	RemoveSubscription(context.Context, *RemoveSubscriptionRequest) (*RemoveSubscriptionResponse, error)
+	mustEmbedUnimplementedNitroRpcServer()
+}
+
+// UnimplementedNitroRpcServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNitroRpcServer struct{}
+
+func (UnimplementedNitroRpcServer) GetAuthToken(context.Context, *AuthRequest) (*AuthToken, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthToken not implemented")
+}
+func (UnimplementedNitroRpcServer) RevokeAuthToken(context.Context, *RevokeAuthTokenRequest) (*RevokeAuthTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAuthToken not implemented")
+}
+func (UnimplementedNitroRpcServer) RevokeClientTokens(context.Context, *RevokeClientTokensRequest) (*RevokeClientTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeClientTokens not implemented")
+}
+func (UnimplementedNitroRpcServer) GetAddress(context.Context, *GetAddressRequest) (*GetAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAddress not implemented")
+}
+func (UnimplementedNitroRpcServer) Version(context.Context, *VersionRequest) (*VersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedNitroRpcServer) Discover(context.Context, *DiscoverRequest) (*DiscoverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Discover not implemented")
+}
+func (UnimplementedNitroRpcServer) CreateLedgerChannel(context.Context, *CreateLedgerChannelRequest) (*ObjectiveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLedgerChannel not implemented")
+}
+func (UnimplementedNitroRpcServer) CloseLedgerChannel(context.Context, *CloseChannelRequest) (*CloseChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseLedgerChannel not implemented")
+}
+func (UnimplementedNitroRpcServer) CreatePaymentChannel(context.Context, *CreatePaymentChannelRequest) (*ObjectiveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePaymentChannel not implemented")
+}
+func (UnimplementedNitroRpcServer) ClosePaymentChannel(context.Context, *CloseChannelRequest) (*CloseChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePaymentChannel not implemented")
+}
+func (UnimplementedNitroRpcServer) Pay(context.Context, *PaymentRequest) (*PaymentRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pay not implemented")
+}
+func (UnimplementedNitroRpcServer) CreateVoucher(context.Context, *PaymentRequest) (*Voucher, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVoucher not implemented")
+}
+func (UnimplementedNitroRpcServer) ReceiveVoucher(context.Context, *Voucher) (*ReceiveVoucherSummary, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReceiveVoucher not implemented")
+}
+func (UnimplementedNitroRpcServer) GetPaymentChannel(context.Context, *GetChannelRequest) (*PaymentChannelInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentChannel not implemented")
+}
+func (UnimplementedNitroRpcServer) GetLedgerChannel(context.Context, *GetChannelRequest) (*LedgerChannelInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLedgerChannel not implemented")
+}
+func (UnimplementedNitroRpcServer) GetAllLedgerChannels(context.Context, *GetAllLedgerChannelsRequest) (*LedgerChannelList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllLedgerChannels not implemented")
+}
+func (UnimplementedNitroRpcServer) GetPaymentChannelsByLedger(context.Context, *GetPaymentChannelsByLedgerRequest) (*PaymentChannelList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentChannelsByLedger not implemented")
+}
+func (UnimplementedNitroRpcServer) GetLedgerChannels(context.Context, *GetChannelsRequest) (*LedgerChannelsPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLedgerChannels not implemented")
+}
+func (UnimplementedNitroRpcServer) GetPaymentChannels(context.Context, *GetChannelsRequest) (*PaymentChannelsPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentChannels not implemented")
+}
+func (UnimplementedNitroRpcServer) GetPaymentHistory(context.Context, *GetPaymentHistoryRequest) (*PaymentHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentHistory not implemented")
+}
+func (UnimplementedNitroRpcServer) GetBalanceHistory(context.Context, *GetBalanceHistoryRequest) (*BalanceSnapshotList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalanceHistory not implemented")
+}
+func (UnimplementedNitroRpcServer) GetAssetBalanceHistory(context.Context, *GetAssetBalanceHistoryRequest) (*AssetBalanceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAssetBalanceHistory not implemented")
+}
+func (UnimplementedNitroRpcServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Notification]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedNitroRpcServer) GetEvents(context.Context, *GetEventsRequest) (*EventsPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvents not implemented")
+}
+func (UnimplementedNitroRpcServer) AddSubscription(context.Context, *AddSubscriptionRequest) (*AddSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSubscription not implemented")
+}
+func (UnimplementedNitroRpcServer) RemoveSubscription(context.Context, *RemoveSubscriptionRequest) (*RemoveSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveSubscription not implemented")
+}
+func (UnimplementedNitroRpcServer) mustEmbedUnimplementedNitroRpcServer() {}
+func (UnimplementedNitroRpcServer) testEmbeddedByValue()                  {}
+
+// UnsafeNitroRpcServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NitroRpcServer will
+// result in compilation errors.
+type UnsafeNitroRpcServer interface {
+	mustEmbedUnimplementedNitroRpcServer()
+}
+
+func RegisterNitroRpcServer(s grpc.ServiceRegistrar, srv NitroRpcServer) {
+	// If the following call pancis, it indicates UnimplementedNitroRpcServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NitroRpc_ServiceDesc, srv)
+}
+
+func _NitroRpc_GetAuthToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetAuthToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetAuthToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetAuthToken(ctx, req.(*AuthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_RevokeAuthToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAuthTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).RevokeAuthToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_RevokeAuthToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).RevokeAuthToken(ctx, req.(*RevokeAuthTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_RevokeClientTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeClientTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).RevokeClientTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_RevokeClientTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).RevokeClientTokens(ctx, req.(*RevokeClientTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetAddress(ctx, req.(*GetAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_Version_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).Version(ctx, req.(*VersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_Discover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiscoverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).Discover(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_Discover_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).Discover(ctx, req.(*DiscoverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_CreateLedgerChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLedgerChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).CreateLedgerChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_CreateLedgerChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).CreateLedgerChannel(ctx, req.(*CreateLedgerChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_CloseLedgerChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).CloseLedgerChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_CloseLedgerChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).CloseLedgerChannel(ctx, req.(*CloseChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_CreatePaymentChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePaymentChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).CreatePaymentChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_CreatePaymentChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).CreatePaymentChannel(ctx, req.(*CreatePaymentChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_ClosePaymentChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).ClosePaymentChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_ClosePaymentChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).ClosePaymentChannel(ctx, req.(*CloseChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_Pay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).Pay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_Pay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).Pay(ctx, req.(*PaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_CreateVoucher_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).CreateVoucher(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_CreateVoucher_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).CreateVoucher(ctx, req.(*PaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_ReceiveVoucher_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Voucher)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).ReceiveVoucher(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_ReceiveVoucher_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).ReceiveVoucher(ctx, req.(*Voucher))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetPaymentChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetPaymentChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetPaymentChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetPaymentChannel(ctx, req.(*GetChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetLedgerChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetLedgerChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetLedgerChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetLedgerChannel(ctx, req.(*GetChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetAllLedgerChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllLedgerChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetAllLedgerChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetAllLedgerChannels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetAllLedgerChannels(ctx, req.(*GetAllLedgerChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetPaymentChannelsByLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentChannelsByLedgerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetPaymentChannelsByLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetPaymentChannelsByLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetPaymentChannelsByLedger(ctx, req.(*GetPaymentChannelsByLedgerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetLedgerChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetLedgerChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetLedgerChannels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetLedgerChannels(ctx, req.(*GetChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetPaymentChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetPaymentChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetPaymentChannels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetPaymentChannels(ctx, req.(*GetChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetPaymentHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetPaymentHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetPaymentHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetPaymentHistory(ctx, req.(*GetPaymentHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetBalanceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetBalanceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetBalanceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetBalanceHistory(ctx, req.(*GetBalanceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_GetAssetBalanceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAssetBalanceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetAssetBalanceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetAssetBalanceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetAssetBalanceHistory(ctx, req.(*GetAssetBalanceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NitroRpcServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Notification]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NitroRpc_SubscribeServer = grpc.ServerStreamingServer[Notification]
+
+func _NitroRpc_GetEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).GetEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_GetEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).GetEvents(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_AddSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).AddSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_AddSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).AddSubscription(ctx, req.(*AddSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NitroRpc_RemoveSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroRpcServer).RemoveSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NitroRpc_RemoveSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroRpcServer).RemoveSubscription(ctx, req.(*RemoveSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NitroRpc_ServiceDesc is the grpc.ServiceDesc for NitroRpc service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NitroRpc_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nitro.rpc.v1.NitroRpc",
+	HandlerType: (*NitroRpcServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAuthToken",
+			Handler:    _NitroRpc_GetAuthToken_Handler,
+		},
+		{
+			MethodName: "RevokeAuthToken",
+			Handler:    _NitroRpc_RevokeAuthToken_Handler,
+		},
+		{
+			MethodName: "RevokeClientTokens",
+			Handler:    _NitroRpc_RevokeClientTokens_Handler,
+		},
+		{
+			MethodName: "GetAddress",
+			Handler:    _NitroRpc_GetAddress_Handler,
+		},
+		{
+			MethodName: "Version",
+			Handler:    _NitroRpc_Version_Handler,
+		},
+		{
+			MethodName: "Discover",
+			Handler:    _NitroRpc_Discover_Handler,
+		},
+		{
+			MethodName: "CreateLedgerChannel",
+			Handler:    _NitroRpc_CreateLedgerChannel_Handler,
+		},
+		{
+			MethodName: "CloseLedgerChannel",
+			Handler:    _NitroRpc_CloseLedgerChannel_Handler,
+		},
+		{
+			MethodName: "CreatePaymentChannel",
+			Handler:    _NitroRpc_CreatePaymentChannel_Handler,
+		},
+		{
+			MethodName: "ClosePaymentChannel",
+			Handler:    _NitroRpc_ClosePaymentChannel_Handler,
+		},
+		{
+			MethodName: "Pay",
+			Handler:    _NitroRpc_Pay_Handler,
+		},
+		{
+			MethodName: "CreateVoucher",
+			Handler:    _NitroRpc_CreateVoucher_Handler,
+		},
+		{
+			MethodName: "ReceiveVoucher",
+			Handler:    _NitroRpc_ReceiveVoucher_Handler,
+		},
+		{
+			MethodName: "GetPaymentChannel",
+			Handler:    _NitroRpc_GetPaymentChannel_Handler,
+		},
+		{
+			MethodName: "GetLedgerChannel",
+			Handler:    _NitroRpc_GetLedgerChannel_Handler,
+		},
+		{
+			MethodName: "GetAllLedgerChannels",
+			Handler:    _NitroRpc_GetAllLedgerChannels_Handler,
+		},
+		{
+			MethodName: "GetPaymentChannelsByLedger",
+			Handler:    _NitroRpc_GetPaymentChannelsByLedger_Handler,
+		},
+		{
+			MethodName: "GetLedgerChannels",
+			Handler:    _NitroRpc_GetLedgerChannels_Handler,
+		},
+		{
+			MethodName: "GetPaymentChannels",
+			Handler:    _NitroRpc_GetPaymentChannels_Handler,
+		},
+		{
+			MethodName: "GetPaymentHistory",
+			Handler:    _NitroRpc_GetPaymentHistory_Handler,
+		},
+		{
+			MethodName: "GetBalanceHistory",
+			Handler:    _NitroRpc_GetBalanceHistory_Handler,
+		},
+		{
+			MethodName: "GetAssetBalanceHistory",
+			Handler:    _NitroRpc_GetAssetBalanceHistory_Handler,
+		},
+		{
+			MethodName: "GetEvents",
+			Handler:    _NitroRpc_GetEvents_Handler,
+		},
+		{
+			MethodName: "AddSubscription",
+			Handler:    _NitroRpc_AddSubscription_Handler,
+		},
+		{
+			MethodName: "RemoveSubscription",
+			Handler:    _NitroRpc_RemoveSubscription_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _NitroRpc_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "nitro.proto",
+}
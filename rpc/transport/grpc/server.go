@@ -0,0 +1,364 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/node/tracing"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/rpc/transport/grpc/nitropb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	grpcServerAddress = "127.0.0.1:"
+	// apiVersion is the version of the rpc server's request handler that the gRPC api is served by
+	apiVersion = "v1"
+	// authorizationKey is the metadata key that carries the auth token, as "Bearer <token>"
+	authorizationKey = "authorization"
+	bearerPrefix     = "Bearer "
+)
+
+// connectionIdKey is the metadata key that carries the id of a client's Subscribe stream. Metadata keys are lowercase.
+var connectionIdKey = strings.ToLower(transport.ConnectionIdHeader)
+
+// subscriber is a Subscribe stream waiting for notifications
+type subscriber struct {
+	notifications chan *nitropb.Notification
+	done          <-chan struct{}
+}
+
+type serverGrpcTransport struct {
+	nitropb.UnimplementedNitroRpcServer
+
+	grpcServer      *grpc.Server
+	requestHandlers map[string]transport.RequestHandler
+	port            string
+	subscribers     safesync.Map[subscriber]
+	onDisconnect    func(connectionId string)
+	requestId       atomic.Uint64
+	closed          chan struct{}
+	logger          *slog.Logger
+
+	wg *sync.WaitGroup
+}
+
+// NewGrpcTransportAsServer starts a gRPC server that serves the NitroRpc service.
+// If a certificate is supplied, clientAuth determines whether clients are asked for a TLS client certificate.
+func NewGrpcTransportAsServer(port string, cert *tls.Certificate, clientAuth tls.ClientAuthType) (*serverGrpcTransport, error) {
+	t := &serverGrpcTransport{
+		port:            port,
+		requestHandlers: make(map[string]transport.RequestHandler),
+		subscribers:     safesync.Map[subscriber]{},
+		closed:          make(chan struct{}),
+		logger:          slog.Default(),
+		wg:              &sync.WaitGroup{},
+	}
+
+	var opts []grpc.ServerOption
+	if cert != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{*cert},
+			ClientAuth:   clientAuth,
+		})))
+	}
+	t.grpcServer = grpc.NewServer(opts...)
+	nitropb.RegisterNitroRpcServer(t.grpcServer, t)
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return nil, err
+	}
+
+	t.wg.Add(1)
+	go t.serveGrpc(listener)
+	return t, nil
+}
+
+func (t *serverGrpcTransport) serveGrpc(listener net.Listener) {
+	defer t.wg.Done()
+
+	err := t.grpcServer.Serve(listener)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (t *serverGrpcTransport) RegisterRequestHandler(apiVersion string, handler transport.RequestHandler) error {
+	t.requestHandlers[apiVersion] = handler
+	return nil
+}
+
+func (t *serverGrpcTransport) RegisterDisconnectHandler(handler func(connectionId string)) {
+	t.onDisconnect = handler
+}
+
+func (t *serverGrpcTransport) Notify(data []byte, send func(connectionId string) bool) error {
+	notification, err := notificationToProto(data)
+	if err != nil {
+		return err
+	}
+
+	t.subscribers.Range(func(key string, s subscriber) bool {
+		if !send(key) {
+			return true
+		}
+		select {
+		case s.notifications <- notification:
+		case <-s.done:
+		}
+		return true
+	})
+	return nil
+}
+
+func (t *serverGrpcTransport) Close() error {
+	// Subscribe streams only end when the client goes away, so they are ended here to let the server stop gracefully
+	close(t.closed)
+	t.grpcServer.GracefulStop()
+
+	t.wg.Wait()
+	return nil
+}
+
+func (t *serverGrpcTransport) Url() string {
+	return grpcServerAddress + t.port
+}
+
+func (t *serverGrpcTransport) Subscribe(_ *nitropb.SubscribeRequest, stream nitropb.NitroRpc_SubscribeServer) error {
+	s := subscriber{notifications: make(chan *nitropb.Notification), done: stream.Context().Done()}
+	key := transport.NewConnectionId()
+	t.subscribers.Store(key, s)
+	t.logger.Debug("gRPC transport added a notification listener")
+	defer t.removeSubscriber(key)
+
+	// Sending the header lets the client know that it is subscribed.
+	// It carries the id of the stream, which the client sends with its requests so that they can refer to the stream.
+	if err := stream.SendHeader(metadata.Pairs(connectionIdKey, key)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-t.closed:
+			return nil
+		case <-s.done:
+			return nil
+		case notification := <-s.notifications:
+			if err := stream.Send(notification); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// removeSubscriber forgets a Subscribe stream that has ended
+func (t *serverGrpcTransport) removeSubscriber(key string) {
+	t.subscribers.Delete(key)
+	if t.onDisconnect != nil {
+		t.onDisconnect(key)
+	}
+}
+
+// handle converts a gRPC request to a JSON-RPC request, passes it to the registered request handler, and converts the response back
+func handle[P any, T serde.RequestPayload, U serde.ResponsePayload, R any](ctx context.Context, t *serverGrpcTransport, method serde.RequestMethod, req P, fromProto func(P) (T, error), toProto func(U) R) (R, error) {
+	var empty R
+
+	handler, ok := t.requestHandlers[apiVersion]
+	if !ok {
+		return empty, status.Error(codes.Unavailable, "rpc server is not ready")
+	}
+
+	payload, err := fromProto(req)
+	if err != nil {
+		return empty, errorToStatus(serde.JsonRpcError{Code: serde.InvalidParamsError.Code, Message: err.Error()})
+	}
+
+	request := serde.NewJsonRpcSpecificRequest(t.requestId.Add(1), method, payload, authToken(ctx))
+	request.Params.TraceContext = traceContext(ctx)
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return empty, status.Error(codes.Internal, err.Error())
+	}
+
+	responseData := handler(t.requestPeer(ctx), requestData)
+
+	jsonResponse := serde.JsonRpcGeneralResponse{}
+	if err := json.Unmarshal(responseData, &jsonResponse); err != nil {
+		return empty, status.Error(codes.Internal, err.Error())
+	} else if jsonResponse.Error.Code != 0 {
+		return empty, errorToStatus(jsonResponse.Error)
+	}
+
+	successResponse := serde.JsonRpcSuccessResponse[U]{}
+	if err := json.Unmarshal(responseData, &successResponse); err != nil {
+		return empty, status.Error(codes.Internal, err.Error())
+	}
+	return toProto(successResponse.Result), nil
+}
+
+// authToken returns the bearer token sent in the request metadata, if any
+func authToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, v := range md.Get(authorizationKey) {
+		if token, ok := strings.CutPrefix(v, bearerPrefix); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// traceContext returns the trace context fields sent in the request metadata, if any
+func traceContext(ctx context.Context) map[string]string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	var fields map[string]string
+	for _, field := range tracing.Fields() {
+		if values := md.Get(field); len(values) > 0 {
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[field] = values[0]
+		}
+	}
+	return fields
+}
+
+// requestPeer returns the peer that sent the request, identified by its TLS client certificate if it presented one.
+// The peer's connection id is set if the request metadata names an open Subscribe stream.
+func (t *serverGrpcTransport) requestPeer(ctx context.Context) transport.Peer {
+	result := transport.Peer{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range md.Get(connectionIdKey) {
+			if _, ok := t.subscribers.Load(key); ok {
+				result.ConnectionId = key
+			}
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return result
+	}
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		result.CertificateFingerprint = transport.CertificateFingerprint(tlsInfo.State.PeerCertificates[0])
+	}
+	return result
+}
+
+func (t *serverGrpcTransport) GetAuthToken(ctx context.Context, req *nitropb.AuthRequest) (*nitropb.AuthToken, error) {
+	return handle(ctx, t, serde.GetAuthTokenMethod, req, authRequestFromProto, authTokenToProto)
+}
+
+func (t *serverGrpcTransport) RevokeAuthToken(ctx context.Context, req *nitropb.RevokeAuthTokenRequest) (*nitropb.RevokeAuthTokenResponse, error) {
+	return handle(ctx, t, serde.RevokeAuthTokenMethod, req, revokeAuthTokenRequestFromProto, revokeAuthTokenResponseToProto)
+}
+
+func (t *serverGrpcTransport) RevokeClientTokens(ctx context.Context, req *nitropb.RevokeClientTokensRequest) (*nitropb.RevokeClientTokensResponse, error) {
+	return handle(ctx, t, serde.RevokeClientTokensMethod, req, revokeClientTokensRequestFromProto, revokeClientTokensResponseToProto)
+}
+
+func (t *serverGrpcTransport) GetAddress(ctx context.Context, req *nitropb.GetAddressRequest) (*nitropb.GetAddressResponse, error) {
+	return handle(ctx, t, serde.GetAddressMethod, req, getAddressRequestFromProto, getAddressResponseToProto)
+}
+
+func (t *serverGrpcTransport) Version(ctx context.Context, req *nitropb.VersionRequest) (*nitropb.VersionResponse, error) {
+	return handle(ctx, t, serde.VersionMethod, req, versionRequestFromProto, versionResponseToProto)
+}
+
+func (t *serverGrpcTransport) Discover(ctx context.Context, req *nitropb.DiscoverRequest) (*nitropb.DiscoverResponse, error) {
+	return handle(ctx, t, serde.DiscoverMethod, req, discoverRequestFromProto, discoverResponseToProto)
+}
+
+func (t *serverGrpcTransport) CreateLedgerChannel(ctx context.Context, req *nitropb.CreateLedgerChannelRequest) (*nitropb.ObjectiveResponse, error) {
+	return handle(ctx, t, serde.CreateLedgerChannelRequestMethod, req, createLedgerChannelRequestFromProto, ledgerObjectiveResponseToProto)
+}
+
+func (t *serverGrpcTransport) CloseLedgerChannel(ctx context.Context, req *nitropb.CloseChannelRequest) (*nitropb.CloseChannelResponse, error) {
+	return handle(ctx, t, serde.CloseLedgerChannelRequestMethod, req, closeLedgerChannelRequestFromProto, closeChannelResponseToProto)
+}
+
+func (t *serverGrpcTransport) CreatePaymentChannel(ctx context.Context, req *nitropb.CreatePaymentChannelRequest) (*nitropb.ObjectiveResponse, error) {
+	return handle(ctx, t, serde.CreatePaymentChannelRequestMethod, req, createPaymentChannelRequestFromProto, paymentObjectiveResponseToProto)
+}
+
+func (t *serverGrpcTransport) ClosePaymentChannel(ctx context.Context, req *nitropb.CloseChannelRequest) (*nitropb.CloseChannelResponse, error) {
+	return handle(ctx, t, serde.ClosePaymentChannelRequestMethod, req, closePaymentChannelRequestFromProto, closeChannelResponseToProto)
+}
+
+func (t *serverGrpcTransport) Pay(ctx context.Context, req *nitropb.PaymentRequest) (*nitropb.PaymentRequest, error) {
+	return handle(ctx, t, serde.PayRequestMethod, req, paymentRequestFromProto, paymentRequestToProto)
+}
+
+func (t *serverGrpcTransport) CreateVoucher(ctx context.Context, req *nitropb.PaymentRequest) (*nitropb.Voucher, error) {
+	return handle(ctx, t, serde.CreateVoucherRequestMethod, req, paymentRequestFromProto, voucherToProto)
+}
+
+func (t *serverGrpcTransport) ReceiveVoucher(ctx context.Context, req *nitropb.Voucher) (*nitropb.ReceiveVoucherSummary, error) {
+	return handle(ctx, t, serde.ReceiveVoucherRequestMethod, req, voucherFromProto, receiveVoucherSummaryToProto)
+}
+
+func (t *serverGrpcTransport) GetPaymentChannel(ctx context.Context, req *nitropb.GetChannelRequest) (*nitropb.PaymentChannelInfo, error) {
+	return handle(ctx, t, serde.GetPaymentChannelRequestMethod, req, getPaymentChannelRequestFromProto, paymentChannelInfoToProto)
+}
+
+func (t *serverGrpcTransport) GetLedgerChannel(ctx context.Context, req *nitropb.GetChannelRequest) (*nitropb.LedgerChannelInfo, error) {
+	return handle(ctx, t, serde.GetLedgerChannelRequestMethod, req, getLedgerChannelRequestFromProto, ledgerChannelInfoToProto)
+}
+
+func (t *serverGrpcTransport) GetAllLedgerChannels(ctx context.Context, req *nitropb.GetAllLedgerChannelsRequest) (*nitropb.LedgerChannelList, error) {
+	return handle(ctx, t, serde.GetAllLedgerChannelsMethod, req, getAllLedgerChannelsRequestFromProto, ledgerChannelListToProto)
+}
+
+func (t *serverGrpcTransport) GetPaymentChannelsByLedger(ctx context.Context, req *nitropb.GetPaymentChannelsByLedgerRequest) (*nitropb.PaymentChannelList, error) {
+	return handle(ctx, t, serde.GetPaymentChannelsByLedgerMethod, req, getPaymentChannelsByLedgerRequestFromProto, paymentChannelListToProto)
+}
+
+func (t *serverGrpcTransport) GetLedgerChannels(ctx context.Context, req *nitropb.GetChannelsRequest) (*nitropb.LedgerChannelsPage, error) {
+	return handle(ctx, t, serde.GetLedgerChannelsMethod, req, getChannelsRequestFromProto, ledgerChannelsPageToProto)
+}
+
+func (t *serverGrpcTransport) GetPaymentChannels(ctx context.Context, req *nitropb.GetChannelsRequest) (*nitropb.PaymentChannelsPage, error) {
+	return handle(ctx, t, serde.GetPaymentChannelsMethod, req, getChannelsRequestFromProto, paymentChannelsPageToProto)
+}
+
+func (t *serverGrpcTransport) GetPaymentHistory(ctx context.Context, req *nitropb.GetPaymentHistoryRequest) (*nitropb.PaymentHistory, error) {
+	return handle(ctx, t, serde.GetPaymentHistoryMethod, req, getPaymentHistoryRequestFromProto, paymentHistoryToProto)
+}
+
+func (t *serverGrpcTransport) GetBalanceHistory(ctx context.Context, req *nitropb.GetBalanceHistoryRequest) (*nitropb.BalanceSnapshotList, error) {
+	return handle(ctx, t, serde.GetBalanceHistoryMethod, req, getBalanceHistoryRequestFromProto, balanceSnapshotListToProto)
+}
+
+func (t *serverGrpcTransport) GetAssetBalanceHistory(ctx context.Context, req *nitropb.GetAssetBalanceHistoryRequest) (*nitropb.AssetBalanceList, error) {
+	return handle(ctx, t, serde.GetAssetBalanceHistoryMethod, req, getAssetBalanceHistoryRequestFromProto, assetBalanceListToProto)
+}
+
+func (t *serverGrpcTransport) GetEvents(ctx context.Context, req *nitropb.GetEventsRequest) (*nitropb.EventsPage, error) {
+	return handle(ctx, t, serde.GetEventsMethod, req, getEventsRequestFromProto, eventsPageToProto)
+}
+
+func (t *serverGrpcTransport) AddSubscription(ctx context.Context, req *nitropb.AddSubscriptionRequest) (*nitropb.AddSubscriptionResponse, error) {
+	return handle(ctx, t, serde.SubscribeMethod, req, subscribeRequestFromProto, subscribeResponseToProto)
+}
+
+func (t *serverGrpcTransport) RemoveSubscription(ctx context.Context, req *nitropb.RemoveSubscriptionRequest) (*nitropb.RemoveSubscriptionResponse, error) {
+	return handle(ctx, t, serde.UnsubscribeMethod, req, unsubscribeRequestFromProto, unsubscribeResponseToProto)
+}
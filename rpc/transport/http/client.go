@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,20 +13,42 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 type clientHttpTransport struct {
 	logger           *slog.Logger
 	notificationChan chan []byte
 	clientWebsocket  *websocket.Conn
+	httpClient       *http.Client
 	url              string
-	wg               *sync.WaitGroup
+	// connectionId identifies the websocket connection to the server, and is sent with each request
+	connectionId string
+	wg           *sync.WaitGroup
 }
 
 // NewHttpTransportAsClient creates a transport that can be used to send http requests and a websocket connection for receiving notifications
 // Initialization will block for 10 retries until the server endpoint is ready
 func NewHttpTransportAsClient(url string, retryTimeout time.Duration) (*clientHttpTransport, error) {
-	err := blockUntilHttpServerIsReady(url, retryTimeout)
+	return NewHttpTransportAsClientWithTLS(url, retryTimeout, nil)
+}
+
+// NewHttpTransportAsClientWithTLS creates an http transport that uses the supplied TLS config for both requests and notifications.
+// It can be used to present a client certificate to the server. A nil config uses the default TLS settings.
+func NewHttpTransportAsClientWithTLS(url string, retryTimeout time.Duration, tlsConfig *tls.Config) (*clientHttpTransport, error) {
+	httpClient := http.DefaultClient
+	dialer := websocket.DefaultDialer
+	if tlsConfig != nil {
+		httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+		httpTransport.TLSClientConfig = tlsConfig
+		httpClient = &http.Client{Transport: httpTransport}
+
+		tlsDialer := *websocket.DefaultDialer
+		tlsDialer.TLSClientConfig = tlsConfig
+		dialer = &tlsDialer
+	}
+
+	err := blockUntilHttpServerIsReady(httpClient, url, retryTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -35,12 +58,12 @@ func NewHttpTransportAsClient(url string, retryTimeout time.Duration) (*clientHt
 		return nil, err
 	}
 
-	conn, _, err := websocket.DefaultDialer.Dial(subscribeUrl, nil)
+	conn, resp, err := dialer.Dial(subscribeUrl, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	t := &clientHttpTransport{notificationChan: make(chan []byte, 10), clientWebsocket: conn, url: url, wg: &sync.WaitGroup{}, logger: slog.Default()}
+	t := &clientHttpTransport{notificationChan: make(chan []byte, 10), clientWebsocket: conn, httpClient: httpClient, url: url, connectionId: resp.Header.Get(transport.ConnectionIdHeader), wg: &sync.WaitGroup{}, logger: slog.Default()}
 
 	t.wg.Add(1)
 	go t.readMessages()
@@ -54,7 +77,16 @@ func (t *clientHttpTransport) Request(data []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	resp, err := http.Post(requestUrl, "application/json", bytes.NewBuffer(data))
+	req, err := http.NewRequest(http.MethodPost, requestUrl, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.connectionId != "" {
+		req.Header.Set(transport.ConnectionIdHeader, t.connectionId)
+	}
+
+	resp, err := t.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +139,7 @@ func httpUrl(url string) (string, error) {
 }
 
 // blockUntilHttpServerIsReady pings the health endpoint until the server is ready
-func blockUntilHttpServerIsReady(url string, retryTimeout time.Duration) error {
+func blockUntilHttpServerIsReady(httpClient *http.Client, url string, retryTimeout time.Duration) error {
 	waitForServer := func(iteration int) {
 		time.Sleep(retryTimeout * time.Duration(math.Pow(2, float64(iteration))))
 	}
@@ -122,7 +154,7 @@ func blockUntilHttpServerIsReady(url string, retryTimeout time.Duration) error {
 	}
 	numAttempts := 10
 	for i := 0; i < numAttempts; i++ {
-		resp, err := http.Get(healthUrl)
+		resp, err := httpClient.Get(healthUrl)
 		if err != nil {
 			waitForServer(i)
 			continue
@@ -9,14 +9,13 @@ import (
 	"net"
 	"net/http"
 	"path"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/statechannels/go-nitro/internal/safesync"
-	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 const (
@@ -27,17 +26,19 @@ const (
 
 type serverHttpTransport struct {
 	httpServer            *http.Server
-	requestHandlers       map[string]func([]byte) []byte
+	requestHandlers       map[string]transport.RequestHandler
 	port                  string
 	notificationListeners safesync.Map[chan []byte]
+	onDisconnect          func(connectionId string)
 	logger                *slog.Logger
 
 	wg *sync.WaitGroup
 }
 
-// NewHttpTransportAsServer starts an http server
-func NewHttpTransportAsServer(port string, cert *tls.Certificate) (*serverHttpTransport, error) {
-	transport := &serverHttpTransport{port: port, notificationListeners: safesync.Map[chan []byte]{}, logger: slog.Default()}
+// NewHttpTransportAsServer starts an http server.
+// If a certificate is supplied, clientAuth determines whether clients are asked for a TLS client certificate.
+func NewHttpTransportAsServer(port string, cert *tls.Certificate, clientAuth tls.ClientAuthType) (*serverHttpTransport, error) {
+	t := &serverHttpTransport{port: port, notificationListeners: safesync.Map[chan []byte]{}, logger: slog.Default()}
 
 	var serveMux http.ServeMux
 
@@ -48,25 +49,25 @@ func NewHttpTransportAsServer(port string, cert *tls.Certificate) (*serverHttpTr
 			panic(err)
 		}
 	})
-	serveMux.HandleFunc(apiVersionPath, transport.request)
-	serveMux.HandleFunc(path.Join(apiVersionPath, "subscribe"), transport.subscribe)
-	transport.httpServer = &http.Server{
+	serveMux.HandleFunc(apiVersionPath, t.request)
+	serveMux.HandleFunc(path.Join(apiVersionPath, "subscribe"), t.subscribe)
+	t.httpServer = &http.Server{
 		Addr:         ":" + port,
 		Handler:      &serveMux,
 		ReadTimeout:  time.Second * 10,
 		WriteTimeout: time.Second * 10,
 	}
 
-	transport.requestHandlers = make(map[string]func([]byte) []byte)
-	transport.wg = &sync.WaitGroup{}
+	t.requestHandlers = make(map[string]transport.RequestHandler)
+	t.wg = &sync.WaitGroup{}
 
-	transport.wg.Add(1)
+	t.wg.Add(1)
 
 	var listener net.Listener
 	var err error
 
 	if cert == nil {
-		listener, err = net.Listen("tcp", ":"+transport.port)
+		listener, err = net.Listen("tcp", ":"+t.port)
 		if err != nil {
 			return nil, err
 		}
@@ -74,6 +75,7 @@ func NewHttpTransportAsServer(port string, cert *tls.Certificate) (*serverHttpTr
 		// Create a TLS config
 		tlsConfig := &tls.Config{
 			Certificates: []tls.Certificate{*cert},
+			ClientAuth:   clientAuth,
 		}
 		// Create a new TLS listener
 		listener, err = tls.Listen("tcp", ":"+port, tlsConfig)
@@ -82,8 +84,8 @@ func NewHttpTransportAsServer(port string, cert *tls.Certificate) (*serverHttpTr
 		}
 	}
 
-	go transport.serveHttp(listener)
-	return transport, nil
+	go t.serveHttp(listener)
+	return t, nil
 }
 
 func (t *serverHttpTransport) serveHttp(tcpListener net.Listener) {
@@ -99,13 +101,20 @@ func (t *serverHttpTransport) serveHttp(tcpListener net.Listener) {
 	}
 }
 
-func (t *serverHttpTransport) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
+func (t *serverHttpTransport) RegisterRequestHandler(apiVersion string, handler transport.RequestHandler) error {
 	t.requestHandlers[apiVersion] = handler
 	return nil
 }
 
-func (t *serverHttpTransport) Notify(data []byte) error {
+func (t *serverHttpTransport) RegisterDisconnectHandler(handler func(connectionId string)) {
+	t.onDisconnect = handler
+}
+
+func (t *serverHttpTransport) Notify(data []byte, send func(connectionId string) bool) error {
 	t.notificationListeners.Range(func(key string, value chan []byte) bool {
+		if !send(key) {
+			return true
+		}
 		value <- data
 		return true
 	})
@@ -156,7 +165,7 @@ func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
 			return
 		}
-		_, err = w.Write(handler(msg))
+		_, err = w.Write(handler(t.requestPeer(r), msg))
 		if err != nil {
 			panic(err)
 		}
@@ -170,17 +179,18 @@ func (t *serverHttpTransport) subscribe(w http.ResponseWriter, r *http.Request)
 	// TODO: We currently allow requests from any origins. We should probably use a whitelist.
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 
-	c, err := upgrader.Upgrade(w, r, nil)
+	// The id of the websocket connection is returned to the client, which sends it with its requests so that they can refer to the connection
+	key := transport.NewConnectionId()
+	c, err := upgrader.Upgrade(w, r, http.Header{transport.ConnectionIdHeader: []string{key}})
 	if err != nil {
 		panic(err)
 	}
 
 	defer c.Close()
 	notificationChan := make(chan []byte)
-	key := strconv.Itoa(int(rand.Uint64()))
 	t.notificationListeners.Store(key, notificationChan)
 	t.logger.Debug("Websocket transport added a notification listener")
-	defer t.notificationListeners.Delete(key)
+	defer t.removeListener(key)
 
 	closeChan := make(chan error)
 
@@ -208,6 +218,29 @@ EventLoop:
 	}
 }
 
+// removeListener forgets the notification listener of a websocket connection that has closed
+func (t *serverHttpTransport) removeListener(key string) {
+	t.notificationListeners.Delete(key)
+	if t.onDisconnect != nil {
+		t.onDisconnect(key)
+	}
+}
+
+// requestPeer returns the peer that sent the request, identified by its TLS client certificate if it presented one.
+// The peer's connection id is set if the request names an open websocket connection.
+func (t *serverHttpTransport) requestPeer(r *http.Request) transport.Peer {
+	peer := transport.Peer{}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		peer.CertificateFingerprint = transport.CertificateFingerprint(r.TLS.PeerCertificates[0])
+	}
+	if key := r.Header.Get(transport.ConnectionIdHeader); key != "" {
+		if _, ok := t.notificationListeners.Load(key); ok {
+			peer.ConnectionId = key
+		}
+	}
+	return peer
+}
+
 // enableCors sets the CORS headers on the response allowing all origins
 func enableCors(w *http.ResponseWriter) {
 	(*w).Header().Set("Access-Control-Allow-Origin", "*")
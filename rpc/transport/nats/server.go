@@ -5,6 +5,7 @@ import (
 
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 const (
@@ -78,9 +79,9 @@ func NewNatsTransportAsServer(rpcPort int) (*natsTransportServer, error) {
 	return con, nil
 }
 
-func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
+func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler transport.RequestHandler) error {
 	sub, err := c.nc.Subscribe(nitroRequestTopic+"/api/"+apiVersion, func(msg *nats.Msg) {
-		responseData := handler(msg.Data)
+		responseData := handler(transport.Peer{}, msg.Data)
 		err := c.nc.Publish(msg.Reply, responseData)
 		if err != nil {
 			panic(err)
@@ -91,10 +92,18 @@ func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler
 	return err
 }
 
-func (c *natsTransportServer) Notify(data []byte) error {
+// Notify publishes the notification to every client, as clients share the notification topic.
+// It is only published if send accepts the notification for clients that cannot be told apart.
+func (c *natsTransportServer) Notify(data []byte, send func(connectionId string) bool) error {
+	if !send("") {
+		return nil
+	}
 	return c.nc.Publish(nitroNotificationTopic, data)
 }
 
+// RegisterDisconnectHandler does nothing, as the connections of clients cannot be told apart
+func (c *natsTransportServer) RegisterDisconnectHandler(func(connectionId string)) {}
+
 func (c *natsTransportServer) Url() string {
 	return c.ns.ClientURL()
 }
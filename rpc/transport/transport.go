@@ -1,10 +1,19 @@
 package transport
 
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+)
+
 type TransportType string
 
 const (
 	Nats TransportType = "nats"
 	Http TransportType = "http"
+	Grpc TransportType = "grpc"
+	Unix TransportType = "unix"
 )
 
 // Requester is a transport that can send requests and subscribe to notifications
@@ -28,7 +37,45 @@ type Responder interface {
 
 	// RegisterRequestHandler registers a handler that accepts a request and returns a response.
 	// It returns an error if the registration setup fails
-	RegisterRequestHandler(string, func([]byte) []byte) error
-	// Notify sends notification data without expecting a response
-	Notify([]byte) error
+	RegisterRequestHandler(string, RequestHandler) error
+	// Notify sends notification data, without expecting a response, to every connection for which send returns true.
+	// Transports that cannot tell connections apart call send with an empty connection id, and send the data to every connection if it returns true.
+	Notify(data []byte, send func(connectionId string) bool) error
+	// RegisterDisconnectHandler registers a handler that is called with the id of each connection that closes
+	RegisterDisconnectHandler(func(connectionId string))
+}
+
+// ConnectionIdHeader is the http header, or gRPC metadata key, that carries the id of the connection over which a client receives notifications.
+// The server sends it when the client subscribes to notifications, and the client sends it back with each request.
+const ConnectionIdHeader = "Nitro-Connection-Id"
+
+// RequestHandler accepts request data sent by a peer and returns the response data
+type RequestHandler func(peer Peer, data []byte) []byte
+
+// Peer describes the sender of a request, as far as the transport is able to authenticate it
+type Peer struct {
+	// CertificateFingerprint is the fingerprint of the TLS client certificate presented by the peer (see CertificateFingerprint).
+	// It is empty if the peer did not present a client certificate.
+	CertificateFingerprint string
+	// Local is true if the peer connected over a unix domain socket, whose file permissions restrict who can connect
+	Local bool
+	// ConnectionId identifies the connection over which the peer receives notifications.
+	// It is empty if the transport cannot tell which connection that is.
+	ConnectionId string
+}
+
+// NewConnectionId returns a random connection id.
+// Connection ids cannot be guessed, so that a peer cannot pass itself off as the owner of another peer's connection.
+func NewConnectionId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// CertificateFingerprint returns the hex encoded SHA-256 hash of the DER encoding of the certificate
+func CertificateFingerprint(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(hash[:])
 }
@@ -0,0 +1,105 @@
+package unix
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+)
+
+type clientUnixTransport struct {
+	logger           *slog.Logger
+	conn             *connection
+	requestMu        sync.Mutex
+	responses        chan []byte
+	notificationChan chan []byte
+	closed           chan struct{}
+	wg               *sync.WaitGroup
+}
+
+// NewUnixTransportAsClient connects to a server listening on the unix domain socket at socketPath.
+// Requests and notifications share the connection, and requests are sent one at a time.
+func NewUnixTransportAsClient(socketPath string) (*clientUnixTransport, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &clientUnixTransport{
+		logger:           slog.Default(),
+		conn:             &connection{conn: conn},
+		responses:        make(chan []byte),
+		notificationChan: make(chan []byte, 10),
+		closed:           make(chan struct{}),
+		wg:               &sync.WaitGroup{},
+	}
+
+	t.wg.Add(1)
+	go t.readMessages()
+
+	return t, nil
+}
+
+func (t *clientUnixTransport) Request(data []byte) ([]byte, error) {
+	t.requestMu.Lock()
+	defer t.requestMu.Unlock()
+
+	if err := t.conn.write(data); err != nil {
+		return nil, err
+	}
+	response, ok := <-t.responses
+	if !ok {
+		return nil, errors.New("unix socket connection closed")
+	}
+	return response, nil
+}
+
+func (t *clientUnixTransport) Subscribe() (<-chan []byte, error) {
+	return t.notificationChan, nil
+}
+
+func (t *clientUnixTransport) Close() error {
+	// This causes the readMessages go-routine to exit
+	close(t.closed)
+	err := t.conn.conn.Close()
+	if err != nil {
+		return err
+	}
+	t.wg.Wait()
+
+	close(t.notificationChan)
+	return nil
+}
+
+// readMessages passes notifications to the notification channel, and responses to the pending request
+func (t *clientUnixTransport) readMessages() {
+	defer t.wg.Done()
+	defer close(t.responses)
+
+	scanner := newScanner(t.conn.conn)
+	for scanner.Scan() {
+		// The scanner reuses its buffer, so the message is copied before being passed on
+		data := append([]byte(nil), scanner.Bytes()...)
+		t.logger.Debug("Unix socket received message", "data", string(data))
+
+		messages := t.responses
+		if isNotification(data) {
+			messages = t.notificationChan
+		}
+		select {
+		case messages <- data:
+		case <-t.closed:
+			return
+		}
+	}
+	t.logger.Info("Unix socket connection ended", "error", scanner.Err())
+}
+
+// isNotification returns true if the message is a JSON-RPC notification rather than a response. Only notifications have a method.
+func isNotification(data []byte) bool {
+	var message struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(data, &message) == nil && message.Method != ""
+}
@@ -0,0 +1,217 @@
+package unix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/rpc/transport"
+)
+
+const (
+	apiVersion = "v1"
+	// maxMessageSize is the largest request, response or notification that can be sent over the socket
+	maxMessageSize = 1 << 20
+)
+
+// DefaultSocketMode only allows the user running the node to connect to the socket
+const DefaultSocketMode fs.FileMode = 0o600
+
+type serverUnixTransport struct {
+	listener        net.Listener
+	socketPath      string
+	requestHandlers map[string]transport.RequestHandler
+	connections     safesync.Map[*connection]
+	onDisconnect    func(connectionId string)
+	logger          *slog.Logger
+
+	wg *sync.WaitGroup
+}
+
+// NewUnixTransportAsServer listens for connections on a unix domain socket at socketPath.
+// Access is controlled by the permissions of the socket file, which are set to mode: a process can only connect if it can write to the file.
+// A stale socket file left behind by a previous server is removed.
+func NewUnixTransportAsServer(socketPath string, mode fs.FileMode) (*serverUnixTransport, error) {
+	if err := removeStaleSocket(socketPath); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	// Until the mode is set, the umask applies, which by default does not let other users connect
+	if err := os.Chmod(socketPath, mode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	t := &serverUnixTransport{
+		listener:        listener,
+		socketPath:      socketPath,
+		requestHandlers: make(map[string]transport.RequestHandler),
+		connections:     safesync.Map[*connection]{},
+		logger:          slog.Default(),
+		wg:              &sync.WaitGroup{},
+	}
+
+	t.wg.Add(1)
+	go t.acceptConnections()
+	return t, nil
+}
+
+// removeStaleSocket removes the socket file at socketPath, unless a server is still listening on it
+func removeStaleSocket(socketPath string) error {
+	info, err := os.Stat(socketPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", socketPath)
+	}
+
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another server", socketPath)
+	}
+	return os.Remove(socketPath)
+}
+
+func (t *serverUnixTransport) acceptConnections() {
+	defer t.wg.Done()
+
+	for {
+		conn, err := t.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			t.logger.Error("Could not accept unix socket connection", "error", err)
+			continue
+		}
+
+		c := &connection{conn: conn}
+		key := transport.NewConnectionId()
+		t.connections.Store(key, c)
+		t.logger.Debug("Unix socket transport accepted a connection")
+
+		t.wg.Add(1)
+		go t.serveConnection(key, c)
+	}
+}
+
+// serveConnection handles the requests sent over a connection, one at a time, until the connection is closed
+func (t *serverUnixTransport) serveConnection(key string, c *connection) {
+	defer t.wg.Done()
+	defer c.conn.Close()
+	defer t.disconnect(key)
+
+	// Any process that can connect has passed the file permission check of the socket.
+	// Requests and notifications share the connection, so its key identifies the connection over which the peer receives notifications.
+	peer := transport.Peer{Local: true, ConnectionId: key}
+
+	scanner := newScanner(c.conn)
+	for scanner.Scan() {
+		handler, ok := t.requestHandlers[apiVersion]
+		if !ok {
+			t.logger.Error("No request handler registered for the unix socket transport")
+			return
+		}
+		// A batch of notifications has no response
+		response := handler(peer, scanner.Bytes())
+		if len(response) == 0 {
+			continue
+		}
+		if err := c.write(response); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		t.logger.Info("Unix socket read error", "error", err)
+	}
+}
+
+func (t *serverUnixTransport) RegisterRequestHandler(apiVersion string, handler transport.RequestHandler) error {
+	t.requestHandlers[apiVersion] = handler
+	return nil
+}
+
+// disconnect forgets a connection that has closed
+func (t *serverUnixTransport) disconnect(key string) {
+	t.connections.Delete(key)
+	if t.onDisconnect != nil {
+		t.onDisconnect(key)
+	}
+}
+
+func (t *serverUnixTransport) RegisterDisconnectHandler(handler func(connectionId string)) {
+	t.onDisconnect = handler
+}
+
+func (t *serverUnixTransport) Notify(data []byte, send func(connectionId string) bool) error {
+	t.connections.Range(func(key string, c *connection) bool {
+		if !send(key) {
+			return true
+		}
+		if err := c.write(data); err != nil {
+			t.logger.Info("Could not send notification over unix socket", "error", err)
+		}
+		return true
+	})
+	return nil
+}
+
+func (t *serverUnixTransport) Close() error {
+	// Closing the listener removes the socket file, and causes acceptConnections to exit
+	err := t.listener.Close()
+	if err != nil {
+		return err
+	}
+
+	// Closing the connections causes the serveConnection go-routines to exit
+	t.connections.Range(func(key string, c *connection) bool {
+		c.conn.Close()
+		return true
+	})
+
+	t.wg.Wait()
+	return nil
+}
+
+func (t *serverUnixTransport) Url() string {
+	return t.socketPath
+}
+
+// connection is a connection over which messages are written by several go-routines
+type connection struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+}
+
+// write writes a message to the connection. Messages are separated by newlines, which JSON encoded messages do not contain.
+func (c *connection) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	message := make([]byte, len(data)+1)
+	copy(message, data)
+	message[len(data)] = '\n'
+	_, err := c.conn.Write(message)
+	return err
+}
+
+// newScanner returns a scanner that reads the newline separated messages of a connection
+func newScanner(conn net.Conn) *bufio.Scanner {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxMessageSize)
+	return scanner
+}
@@ -0,0 +1,45 @@
+package unix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSocketFile(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "nitro.sock")
+
+	server, err := NewUnixTransportAsServer(socketPath, 0o660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o660 {
+		t.Fatalf("expected the socket to have mode 0660, got %v", info.Mode().Perm())
+	}
+
+	if _, err := NewUnixTransportAsServer(socketPath, DefaultSocketMode); err == nil {
+		t.Fatal("expected a socket in use to be rejected")
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Fatal("expected the socket file to be removed on close, got", err)
+	}
+}
+
+func TestNotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nitro.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewUnixTransportAsServer(path, DefaultSocketMode); err == nil {
+		t.Fatal("expected a file that is not a socket to be rejected")
+	}
+}